@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// stubReview answers the review screen from a script and simulates a terminal
+func stubReview(t *testing.T, attached bool, answers ...string) *[]string {
+	t.Helper()
+	var prompts []string
+	origInput, origTerminal := reviewInput, stdinIsTerminal
+	reviewInput = func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if len(answers) == 0 {
+			return "", fmt.Errorf("unexpected prompt %q", prompt)
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+	stdinIsTerminal = func() bool { return attached }
+	t.Cleanup(func() {
+		reviewInput = origInput
+		stdinIsTerminal = origTerminal
+	})
+	return &prompts
+}
+
+// reviewFields stands in for the real prompts, recording which field was edited
+func reviewFields(edited *[]string) []environmentField {
+	record := func(key string) func(Config, promptDefaults, *Environment) error {
+		return func(_ Config, _ promptDefaults, env *Environment) error {
+			*edited = append(*edited, key)
+			if key == "url" {
+				env.URL = "https://fixed.example.com"
+			}
+			return nil
+		}
+	}
+	return []environmentField{{"name", record("name")}, {"url", record("url")}, {"model", record("model")}}
+}
+
+var reviewEnv = Environment{
+	Name:    "staging",
+	URL:     "https://typo.example.com",
+	APIKey:  "sk-ant-review-secret-key",
+	EnvVars: map[string]string{"PROXY_TOKEN": "tok-very-secret-value", "HTTPS_PROXY": "http://proxy:8080"},
+}
+
+func editWith(fields []environmentField) func(environmentField, *Environment) error {
+	return func(field environmentField, env *Environment) error {
+		return field.prompt(Config{}, promptDefaults{}, env)
+	}
+}
+
+func TestRenderEnvironmentSummaryMasksSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderEnvironmentSummary(&buf, reviewEnv); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Name:    staging", "URL:     https://typo.example.com", "Model:   default", "Key Var: ANTHROPIC_API_KEY", "HTTPS_PROXY=http://proxy:8080"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+	for _, secret := range []string{reviewEnv.APIKey, "tok-very-secret-value"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("summary leaks %q:\n%s", secret, out)
+		}
+	}
+}
+
+func TestReviewEnvironmentSave(t *testing.T) {
+	for _, answer := range []string{"", "y", "YES"} {
+		stubReview(t, true, answer)
+		var edited []string
+		fields := reviewFields(&edited)
+		var got Environment
+		var err error
+		captureStdout(t, func() { got, err = reviewEnvironment(reviewEnv, fields, editWith(fields)) })
+		if err != nil || got.URL != reviewEnv.URL || len(edited) != 0 {
+			t.Errorf("answer %q: got %+v, %v (edited %v)", answer, got, err, edited)
+		}
+	}
+}
+
+func TestReviewEnvironmentEditThenSave(t *testing.T) {
+	prompts := stubReview(t, true, "e", "bogus", "e", "URL", "y")
+	var edited []string
+	fields := reviewFields(&edited)
+
+	var got Environment
+	var err error
+	out := captureStdout(t, func() { got, err = reviewEnvironment(reviewEnv, fields, editWith(fields)) })
+	if err != nil {
+		t.Fatalf("reviewEnvironment() failed: %v", err)
+	}
+	if got.URL != "https://fixed.example.com" || strings.Join(edited, ",") != "url" {
+		t.Errorf("expected only url edited, got %+v (edited %v)", got, edited)
+	}
+	if !strings.Contains(out, "Unknown field 'bogus'") {
+		t.Errorf("expected unknown field message:\n%s", out)
+	}
+	if !strings.Contains((*prompts)[1], "name, url, model") {
+		t.Errorf("field prompt should list the fields, got %q", (*prompts)[1])
+	}
+	if strings.Count(out, "Review:") != 3 {
+		t.Errorf("summary should be shown again after each edit:\n%s", out)
+	}
+}
+
+func TestReviewEnvironmentShorthandAndCancel(t *testing.T) {
+	stubReview(t, true, "e model", "huh", "c")
+	var edited []string
+	fields := reviewFields(&edited)
+
+	var err error
+	out := captureStdout(t, func() { _, err = reviewEnvironment(reviewEnv, fields, editWith(fields)) })
+	if err == nil || !strings.Contains(err.Error(), "nothing was saved") {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if strings.Join(edited, ",") != "model" {
+		t.Errorf("'e model' should edit the model, edited %v", edited)
+	}
+	if !strings.Contains(out, "Please answer y, e or c.") {
+		t.Errorf("expected hint for an unknown answer:\n%s", out)
+	}
+}
+
+func TestReviewEnvironmentSkippedWithoutTerminal(t *testing.T) {
+	prompts := stubReview(t, false)
+	var edited []string
+	fields := reviewFields(&edited)
+
+	got, err := reviewEnvironment(reviewEnv, fields, editWith(fields))
+	if err != nil || got.Name != reviewEnv.Name || len(*prompts) != 0 {
+		t.Errorf("non-interactive review should pass through, got %+v, %v, prompts %v", got, err, *prompts)
+	}
+}
+
+func TestEditEnvironmentFieldRevalidates(t *testing.T) {
+	env := Environment{Name: "lab", URL: "http://lab", APIKey: "sk-ant-review-key-1", Unvalidated: []string{fieldAPIKey, fieldURL}}
+	var seen promptDefaults
+	field := environmentField{"url", func(_ Config, defaults promptDefaults, env *Environment) error {
+		seen = defaults
+		return nil
+	}}
+	if err := editEnvironmentField(Config{}, false, field, &env); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(env.Unvalidated, ",") != fieldAPIKey {
+		t.Errorf("editing url should clear only its unvalidated mark, got %v", env.Unvalidated)
+	}
+	if seen.Env.URL != "http://lab" || !seen.ReuseKey {
+		t.Errorf("current values should be offered as defaults, got %+v", seen)
+	}
+}
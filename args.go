@@ -0,0 +1,29 @@
+package main
+
+import "strings"
+
+// isShellSafe reports whether arg needs no quoting in a POSIX shell
+func isShellSafe(arg string) bool {
+	if arg == "" {
+		return false
+	}
+	for _, r := range arg {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_@%+=:,./-", r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// joinArgs renders args as one shell-safe line, single-quoting any argument that needs it
+func joinArgs(args []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if isShellSafe(arg) {
+			parts[i] = arg
+		} else {
+			parts[i] = shellQuote(arg)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestJoinArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"claude", "chat", "--verbose"}, "claude chat --verbose"},
+		{[]string{"--model=claude-sonnet-4-20250514", "path/to/file.go:12"}, "--model=claude-sonnet-4-20250514 path/to/file.go:12"},
+		{[]string{"-p", "fix the bug"}, "-p 'fix the bug'"},
+		{[]string{"-p", "it's"}, `-p 'it'\''s'`},
+		{[]string{"", "$HOME", "`cmd`"}, "'' '$HOME' '`cmd`'"},
+		{[]string{`back\slash`, "new\nline"}, "'back\\slash' 'new\nline'"},
+	}
+	for _, tt := range tests {
+		if got := joinArgs(tt.args); got != tt.want {
+			t.Errorf("joinArgs(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func withAssumeYes(t *testing.T, value bool) {
+	t.Helper()
+	original := assumeYes
+	assumeYes = value
+	t.Cleanup(func() { assumeYes = original })
+}
+
+func TestParseArgumentsGlobalYes(t *testing.T) {
+	for _, args := range [][]string{{"-y", "remove", "--all"}, {"--no-color", "--yes", "remove", "--all"}} {
+		result := parseArguments(args)
+		if result.Error != nil || result.Subcommand != "remove" || result.CCEFlags["yes"] != "true" || result.CCEFlags["remove_all"] != "true" {
+			t.Errorf("parseArguments(%q) = %+v", args, result)
+		}
+	}
+	result := parseArguments([]string{"--no-color", "remove", "prod"})
+	if result.CCEFlags["no_color"] != "true" || result.CCEFlags["remove_target"] != "prod" {
+		t.Errorf("remove should keep global flags, got %+v", result.CCEFlags)
+	}
+	result = parseArguments([]string{"-y", "--env", "prod"})
+	if result.CCEFlags["yes"] != "true" || result.CCEFlags["env"] != "prod" {
+		t.Errorf("launch flags after -y should still parse, got %+v", result.CCEFlags)
+	}
+}
+
+func TestAssumeYesFromEnv(t *testing.T) {
+	for value, want := range map[string]bool{"1": true, "true": true, "YES": true, "": false, "0": false} {
+		t.Setenv("CCE_ASSUME_YES", value)
+		if got := assumeYesFromEnv(); got != want {
+			t.Errorf("CCE_ASSUME_YES=%q: got %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestRemoveAllAutoConfirmedWithoutTerminal(t *testing.T) {
+	path := withTempConfigPath(t)
+	content := `{"environments": [{"name": "prod", "url": "https://api.anthropic.com", "api_key": "sk-ant-api03-yes-key-1234"}]}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	t.Setenv("CCE_ASSUME_YES", "1")
+	stubReview(t, false)
+	withAssumeYes(t, false) // handleCommand sets it; restore afterwards
+
+	var err error
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() { err = handleCommand([]string{"remove", "--all"}) })
+	})
+	if err != nil {
+		t.Fatalf("remove --all with CCE_ASSUME_YES failed: %v", err)
+	}
+	if !strings.Contains(stderr, "Auto-confirmed (--yes): removing all 1 environment(s)") {
+		t.Errorf("auto-confirmation should be logged, got %q", stderr)
+	}
+	config, err := loadConfig()
+	if err != nil || len(config.Environments) != 0 {
+		t.Errorf("environments should be removed, got %+v, %v", config.Environments, err)
+	}
+}
+
+func TestReviewEnvironmentAutoConfirmed(t *testing.T) {
+	stubReview(t, true)
+	withAssumeYes(t, true)
+	var got Environment
+	var err error
+	stderr := captureStderr(t, func() { got, err = reviewEnvironment(reviewEnv, nil, nil) })
+	if err != nil || got.Name != reviewEnv.Name {
+		t.Fatalf("reviewEnvironment() = %+v, %v", got, err)
+	}
+	if !strings.Contains(stderr, "saving environment 'staging'") {
+		t.Errorf("expected auto-confirm note, got %q", stderr)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func backupFiles(t *testing.T, configPath string) []string {
+	t.Helper()
+	backups, err := filepath.Glob(filepath.Join(filepath.Dir(configPath), "backups", "config-*.json"))
+	if err != nil {
+		t.Fatalf("failed to list backups: %v", err)
+	}
+	return backups
+}
+
+func TestAddCreatesBackup(t *testing.T) {
+	path := withTempConfigPath(t)
+	content := `{"environments": [{"name": "prod", "url": "https://api.anthropic.com", "api_key": "sk-ant-api03-prod-key-1234"}]}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	env := Environment{Name: "staging", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}
+	if err := addEnvironmentToConfig(&config, env); err != nil {
+		t.Fatalf("addEnvironmentToConfig() failed: %v", err)
+	}
+	captureStdout(t, func() {
+		if err := saveConfig(config); err != nil {
+			t.Fatalf("saveConfig() failed: %v", err)
+		}
+	})
+
+	backups := backupFiles(t, path)
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup after add, got %v", backups)
+	}
+	data, err := ioutil.ReadFile(backups[0])
+	if err != nil || string(data) != content {
+		t.Errorf("backup should hold the config from before the add, got %q (%v)", data, err)
+	}
+	info, err := os.Stat(backups[0])
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("backup should be 0600, got %v (%v)", info.Mode().Perm(), err)
+	}
+}
+
+func TestAutoBackupDisabled(t *testing.T) {
+	path := withTempConfigPath(t)
+	content := `{"settings": {"auto_backup": false}, "environments": []}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	if backups := backupFiles(t, path); len(backups) != 0 {
+		t.Errorf("auto_backup=false should skip backups, got %v", backups)
+	}
+}
+
+func TestBackupsArePruned(t *testing.T) {
+	path := withTempConfigPath(t)
+	if err := ioutil.WriteFile(path, []byte(`{"environments": []}`), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	backupDir := filepath.Join(filepath.Dir(path), "backups")
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxConfigBackups+3; i++ {
+		name := filepath.Join(backupDir, fmt.Sprintf("config-20200101-0000%02d.000.json", i))
+		if err := ioutil.WriteFile(name, []byte(`{"environments": []}`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	captureStdout(t, func() {
+		if err := configStore.Backup(); err != nil {
+			t.Fatalf("Backup() failed: %v", err)
+		}
+	})
+
+	backups := backupFiles(t, path)
+	if len(backups) != maxConfigBackups {
+		t.Fatalf("expected %d backups after pruning, got %d", maxConfigBackups, len(backups))
+	}
+	if filepath.Base(backups[0]) != "config-20200101-000004.000.json" {
+		t.Errorf("oldest backups should be pruned first, oldest left is %s", backups[0])
+	}
+}
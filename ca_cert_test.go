@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeServerCA saves the test server's certificate as a PEM bundle and returns its path
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+	return path
+}
+
+func TestCACertInjectedForClaude(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	caPath := writeServerCA(t, server)
+	t.Setenv(caCertVar, "/inherited/ca.pem")
+
+	env := Environment{
+		Name:    "corp",
+		URL:     "https://api.anthropic.com",
+		APIKey:  "sk-ant-REDACTED",
+		CACert:  caPath,
+		EnvVars: map[string]string{caCertVar: "/from/env_vars.pem"},
+	}
+	vars, err := prepareEnvironment(env)
+	if err != nil {
+		t.Fatalf("prepareEnvironment() failed: %v", err)
+	}
+	var found []string
+	for _, kv := range vars {
+		if strings.HasPrefix(kv, caCertVar+"=") {
+			found = append(found, kv)
+		}
+	}
+	if want := caCertVar + "=" + caPath; len(found) != 1 || found[0] != want {
+		t.Errorf("expected only %s, got %v", want, found)
+	}
+
+	// A missing bundle stops the launch instead of leaving claude to fail on TLS
+	env.CACert = filepath.Join(t.TempDir(), "missing.pem")
+	if _, err := prepareEnvironment(env); err == nil || !strings.Contains(err.Error(), "ca_cert") {
+		t.Errorf("expected a ca_cert error for a missing file, got %v", err)
+	}
+
+	// So does a file without certificates
+	env.CACert = filepath.Join(t.TempDir(), "empty.pem")
+	if err := ioutil.WriteFile(env.CACert, []byte("not a certificate\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prepareEnvironment(env); err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Errorf("expected a PEM error, got %v", err)
+	}
+
+	env.CACert = "relative/ca.pem"
+	if err := validateEnvironment(env); err == nil || !strings.Contains(err.Error(), "invalid ca_cert") {
+		t.Errorf("expected a relative ca_cert to be rejected, got %v", err)
+	}
+}
+
+func TestCACertTrustedByNetworkCheck(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	env := Environment{Name: "corp", URL: server.URL, APIKey: "sk-ant-REDACTED"}
+	nv := newNetworkValidator(0).withRetries(0)
+
+	_, err := nv.checkEnvironment(env)
+	if err == nil || !strings.Contains(err.Error(), "ca_cert=") {
+		t.Fatalf("expected an untrusted-certificate failure suggesting ca_cert, got %v", err)
+	}
+
+	env.CACert = writeServerCA(t, server)
+	result, err := nv.checkEnvironment(env)
+	if err != nil {
+		t.Fatalf("check with ca_cert failed: %v", err)
+	}
+	if !result.Authenticated {
+		t.Errorf("expected an authenticated result, got %+v", result)
+	}
+}
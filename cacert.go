@@ -0,0 +1,72 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// caCertVar is how claude, a Node program, is told about an extra CA bundle. Node adds
+// it to its built-in roots, so public endpoints keep working behind a proxy's CA.
+const caCertVar = "NODE_EXTRA_CA_CERTS"
+
+// validateCACert allows empty (system roots only); otherwise requires an absolute or ~/ path.
+// Whether the file exists is checked at use, since the config may be shared across machines.
+func validateCACert(path string) error {
+	return validateSettingsDir(path)
+}
+
+// readCACert returns the environment's CA bundle, failing when the file is missing,
+// unreadable or holds no PEM certificate
+func readCACert(env Environment) ([]byte, error) {
+	path, err := expandSettingsDir(env.CACert)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		errorCtx := newErrorContext("CA certificate loading", "launcher")
+		errorCtx.addContext("path", path)
+		errorCtx.addSuggestion("Point ca_cert at a readable PEM file with 'cce set " + env.Name + " ca_cert=<path>'")
+		return nil, errorCtx.formatError(err)
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA certificate %s contains no PEM certificates", path)
+	}
+	return data, nil
+}
+
+// checkCACert verifies the environment's CA bundle can be used, if it has one
+func checkCACert(env Environment) error {
+	if env.CACert == "" {
+		return nil
+	}
+	_, err := readCACert(env)
+	return err
+}
+
+// caCertTransport returns a copy of base that trusts the system roots plus env's CA bundle
+func caCertTransport(base http.RoundTripper, env Environment) (http.RoundTripper, error) {
+	data, err := readCACert(env)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	pool.AppendCertsFromPEM(data)
+
+	transport, ok := base.(*http.Transport)
+	if !ok || transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.RootCAs = pool
+	return transport, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupEnvironmentCaseSensitiveByDefault(t *testing.T) {
+	config := Config{Environments: []Environment{{Name: "prod"}}}
+	if _, exists := findEnvironmentByName(config, "Prod"); exists {
+		t.Error("lookup should be case-sensitive unless case_insensitive_names is set")
+	}
+	if _, taken := environmentNameTaken(config, "PROD"); taken {
+		t.Error("'PROD' should not collide with 'prod' by default")
+	}
+}
+
+func TestLookupEnvironmentCaseInsensitive(t *testing.T) {
+	config := Config{
+		Environments: []Environment{{Name: "prod"}, {Name: "Dev"}, {Name: "dev"}},
+		Settings:     &ConfigSettings{CaseInsensitiveNames: true},
+	}
+
+	index, err := lookupEnvironment(config, "PROD")
+	if err != nil || index != 0 {
+		t.Errorf("expected 'PROD' to match prod, got %d, %v", index, err)
+	}
+
+	// Exact matches win even when other names differ only in case
+	if index, err := lookupEnvironment(config, "dev"); err != nil || index != 2 {
+		t.Errorf("expected exact match for 'dev', got %d, %v", index, err)
+	}
+
+	_, err = lookupEnvironment(config, "DEV")
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected ambiguity error for 'DEV', got %v", err)
+	}
+	if _, exists := findEnvironmentByName(config, "DEV"); exists {
+		t.Error("findEnvironmentByName should not pick one of several case-only matches")
+	}
+}
+
+func TestAddEnvironmentRejectsCaseOnlyDuplicate(t *testing.T) {
+	config := Config{
+		Environments: []Environment{{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-prod-key-123"}},
+		Settings:     &ConfigSettings{CaseInsensitiveNames: true},
+	}
+	err := addEnvironmentToConfig(&config, Environment{Name: "Prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-prod-key-456"})
+	if err == nil || !strings.Contains(err.Error(), "as 'prod'") {
+		t.Errorf("expected case-only duplicate to be rejected, got %v", err)
+	}
+
+	config.Settings = nil
+	if err := addEnvironmentToConfig(&config, Environment{Name: "Prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-prod-key-456"}); err != nil {
+		t.Errorf("case-only distinct names should be allowed by default: %v", err)
+	}
+}
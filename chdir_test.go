@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChdirParsing(t *testing.T) {
+	for _, args := range [][]string{
+		{"--chdir", "/src/app", "list"},
+		{"--chdir=/src/app", "list"},
+		{"--yes", "--chdir", "/src/app", "list"},
+	} {
+		result := parseArguments(args)
+		if result.Error != nil || result.CCEFlags["chdir"] != "/src/app" || result.Subcommand != "list" {
+			t.Errorf("%v: got chdir %q, subcommand %q, error %v", args, result.CCEFlags["chdir"], result.Subcommand, result.Error)
+		}
+	}
+
+	// Among the launch flags it is consumed, not passed to claude
+	result := parseArguments([]string{"--env", "prod", "--chdir", "/src/app", "--wk", "-p", "hi"})
+	if result.Error != nil || result.CCEFlags["chdir"] != "/src/app" || !result.WorktreeEnabled {
+		t.Fatalf("launch --chdir: got %+v", result)
+	}
+	if strings.Join(result.ClaudeArgs, " ") != "-p hi" {
+		t.Errorf("expected only claude's arguments to pass through, got %v", result.ClaudeArgs)
+	}
+
+	if result := parseArguments([]string{"--chdir"}); result.Error == nil {
+		t.Error("expected --chdir without a value to fail")
+	}
+}
+
+func TestChdirValidatesAndResolvesProjectFiles(t *testing.T) {
+	defer func() { workDir = "" }()
+	project := t.TempDir()
+	file := filepath.Join(project, ".cce-env")
+	if err := ioutil.WriteFile(file, []byte("staging\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bad := range []string{filepath.Join(project, "missing"), file} {
+		_, err := resolveChdir(bad)
+		if err == nil || exitCodeFor(err) != 7 {
+			t.Errorf("%s: expected an argument validation error, got %v", bad, err)
+		}
+	}
+
+	dir, err := resolveChdir(project)
+	if err != nil || dir != project {
+		t.Fatalf("resolveChdir(%s) = %q, %v", project, dir, err)
+	}
+
+	// A relative --env @file is read from the --chdir directory, not the process's own
+	wd, _ := os.Getwd()
+	if wd == project {
+		t.Skip("test already runs in the project directory")
+	}
+	workDir = dir
+	name, err := resolveEnvFlag("@.cce-env")
+	if err != nil || name != "staging" {
+		t.Errorf("expected staging from %s, got %q, %v", file, name, err)
+	}
+	if got := workPath("/abs/path"); got != "/abs/path" {
+		t.Errorf("absolute paths must not move, got %s", got)
+	}
+
+	// Each command starts from its own --chdir, or none
+	withTempConfigPath(t)
+	captureStdout(t, func() {
+		if err := handleCommand([]string{"list", "--names"}); err != nil {
+			t.Errorf("list failed: %v", err)
+		}
+	})
+	if workDir != "" {
+		t.Errorf("expected workDir to reset without --chdir, got %s", workDir)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFlakyServer fails the first failures probes with status, then answers 200
+func newFlakyServer(t *testing.T, failures int32, status int) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func withFastRetries(t *testing.T) {
+	t.Helper()
+	original := checkRetryDelay
+	checkRetryDelay = time.Millisecond
+	t.Cleanup(func() { checkRetryDelay = original })
+}
+
+func TestCheckRetriesRecoverFromTransientFailures(t *testing.T) {
+	withFastRetries(t)
+
+	server, calls := newFlakyServer(t, 2, http.StatusServiceUnavailable)
+	env := Environment{Name: "flaky", URL: server.URL, APIKey: "sk-ant-flaky-key", CheckRetries: 2}
+
+	result, err := newNetworkValidator(0).checkEnvironment(env)
+	if err != nil {
+		t.Fatalf("expected success on the third attempt, got %v", err)
+	}
+	if result.Attempts != 3 || atomic.LoadInt32(calls) != 3 {
+		t.Errorf("attempts = %d, calls = %d; want 3", result.Attempts, atomic.LoadInt32(calls))
+	}
+	if !strings.Contains(result.describe(), "attempt 3") {
+		t.Errorf("describe() should mention the retry: %s", result.describe())
+	}
+}
+
+func TestCheckRetriesExhausted(t *testing.T) {
+	withFastRetries(t)
+
+	server, calls := newFlakyServer(t, 5, http.StatusBadGateway)
+	env := Environment{Name: "down", URL: server.URL, APIKey: "sk-ant-flaky-key", CheckRetries: 2}
+
+	_, err := newNetworkValidator(0).checkEnvironment(env)
+	if err == nil || !strings.Contains(err.Error(), "server error (HTTP 502)") {
+		t.Fatalf("expected server error, got %v", err)
+	}
+	if atomic.LoadInt32(calls) != 3 {
+		t.Errorf("calls = %d, want 1 attempt + 2 retries", atomic.LoadInt32(calls))
+	}
+}
+
+func TestCheckRetriesSkipAuthFailures(t *testing.T) {
+	withFastRetries(t)
+
+	server, calls := newFlakyServer(t, 5, http.StatusUnauthorized)
+	env := Environment{Name: "badkey", URL: server.URL, APIKey: "sk-ant-flaky-key", CheckRetries: 3}
+
+	if _, err := newNetworkValidator(0).checkEnvironment(env); err == nil {
+		t.Fatal("expected auth failure")
+	}
+	if atomic.LoadInt32(calls) != 1 {
+		t.Errorf("a rejected key must not be retried, calls = %d", atomic.LoadInt32(calls))
+	}
+}
+
+func TestCheckRetriesRateLimit(t *testing.T) {
+	withFastRetries(t)
+
+	server, calls := newFlakyServer(t, 1, http.StatusTooManyRequests)
+	env := Environment{Name: "busy", URL: server.URL, APIKey: "sk-ant-flaky-key", CheckRetries: 1}
+
+	result, err := newNetworkValidator(0).checkEnvironment(env)
+	if err != nil || result.StatusCode != http.StatusOK || atomic.LoadInt32(calls) != 2 {
+		t.Errorf("expected 429 to be retried into a 200, got %+v, %v (calls %d)", result, err, atomic.LoadInt32(calls))
+	}
+}
+
+func TestCheckPolicyPrecedence(t *testing.T) {
+	t.Cleanup(func() { applyNetworkSettings(nil) })
+	if err := applyNetworkSettings(&ConfigSettings{Network: &NetworkSettings{CheckTimeout: "20s", CheckRetries: 1}}); err != nil {
+		t.Fatalf("applyNetworkSettings() failed: %v", err)
+	}
+
+	plain := Environment{Name: "plain"}
+	tuned := Environment{Name: "tuned", CheckTimeout: "45s", CheckRetries: 4}
+
+	tests := []struct {
+		name string
+		nv   *networkValidator
+		env  Environment
+		want checkPolicy
+	}{
+		{"settings defaults", newNetworkValidator(0), plain, checkPolicy{Timeout: 20 * time.Second, Retries: 1}},
+		{"environment wins over settings", newNetworkValidator(0), tuned, checkPolicy{Timeout: 45 * time.Second, Retries: 4}},
+		{"flags win over environment", newNetworkValidator(5 * time.Second).withRetries(0), tuned, checkPolicy{Timeout: 5 * time.Second, Retries: 0}},
+	}
+	for _, tt := range tests {
+		if got := tt.nv.policyFor(tt.env); got != tt.want {
+			t.Errorf("%s: policyFor() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	applyNetworkSettings(nil)
+	if got := newNetworkValidator(0).policyFor(plain); got != (checkPolicy{Timeout: defaultNetworkTimeout}) {
+		t.Errorf("built-in default = %+v", got)
+	}
+}
+
+func TestCheckSettingsValidation(t *testing.T) {
+	for _, value := range []string{"soon", "0s", "-1s", "10m"} {
+		if _, err := parseCheckTimeout(value); err == nil {
+			t.Errorf("parseCheckTimeout(%q) should fail", value)
+		}
+	}
+	for _, retries := range []int{-1, maxCheckRetries + 1} {
+		if err := validateCheckRetries(retries); err == nil {
+			t.Errorf("validateCheckRetries(%d) should fail", retries)
+		}
+	}
+
+	env := Environment{Name: "x", URL: "https://api.anthropic.com", APIKey: "sk-ant-valid-key-123", CheckTimeout: "forever"}
+	if err := validateEnvironment(env); err == nil || !strings.Contains(err.Error(), "check_timeout") {
+		t.Errorf("expected check_timeout error, got %v", err)
+	}
+
+	path := withTempConfigPath(t)
+	t.Cleanup(func() { applyNetworkSettings(nil) })
+	content := `{"environments": [], "settings": {"network": {"check_retries": 99}}}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "invalid network settings") {
+		t.Errorf("expected invalid network settings error, got %v", err)
+	}
+}
+
+func TestParseTestOptions(t *testing.T) {
+	opts, err := parseTestOptions([]string{"lab", "--timeout", "1m", "--retries", "3", "prod"})
+	if err != nil {
+		t.Fatalf("parseTestOptions() failed: %v", err)
+	}
+	if opts.Timeout != time.Minute || opts.Retries != 3 || strings.Join(opts.Names, ",") != "lab,prod" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+
+	if opts, _ := parseTestOptions(nil); opts.Retries != -1 || opts.Timeout != 0 {
+		t.Errorf("defaults should defer to environments, got %+v", opts)
+	}
+
+	for _, args := range [][]string{{"--timeout"}, {"--timeout", "fast"}, {"--retries", "many"}, {"--retries", "11"}, {"--verbose"}} {
+		if _, err := parseTestOptions(args); err == nil {
+			t.Errorf("parseTestOptions(%v) should fail", args)
+		}
+	}
+}
+
+func TestRunTestUsesFlagOverrides(t *testing.T) {
+	withFastRetries(t)
+	path := withTempConfigPath(t)
+
+	// One failure for the plain run, one more for the run that retries
+	server, calls := newFlakyServer(t, 2, http.StatusServiceUnavailable)
+	config := Config{Environments: []Environment{{Name: "flaky", URL: server.URL, APIKey: "sk-ant-flaky-key"}}}
+	if err := saveConfigDirect(config, path); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	captureStdout(t, func() { err = handleCommand([]string{"test", "flaky"}) })
+	if err == nil {
+		t.Fatal("without retries the first 503 should fail the check")
+	}
+
+	out := captureStdout(t, func() { err = handleCommand([]string{"test", "flaky", "--retries", "1"}) })
+	if err != nil {
+		t.Fatalf("expected --retries 1 to recover, got %v\n%s", err, out)
+	}
+	if atomic.LoadInt32(calls) != 3 {
+		t.Errorf("calls = %d, want 3", atomic.LoadInt32(calls))
+	}
+}
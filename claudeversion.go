@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Policies for a claude version outside an environment's pinned range
+const (
+	claudeVersionBlock = "block" // Refuse to launch (default)
+	claudeVersionWarn  = "warn"  // Print a warning and launch anyway
+)
+
+// claudeVersionPolicy is the active settings.claude_version_policy
+var claudeVersionPolicy = claudeVersionBlock
+
+// claudeVersionPattern finds the dotted version in `claude --version` output, e.g. "1.0.51 (Claude Code)"
+var claudeVersionPattern = regexp.MustCompile(`\d+(\.\d+){0,2}`)
+
+// claudeVersion is a major.minor.patch version; missing parts are zero
+type claudeVersion [3]int
+
+// String renders the version as major.minor.patch
+func (v claudeVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// compare returns -1, 0 or 1 as v is older than, equal to or newer than other
+func (v claudeVersion) compare(other claudeVersion) int {
+	for i := range v {
+		if v[i] != other[i] {
+			if v[i] < other[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseClaudeVersion reads the first dotted version number in s
+func parseClaudeVersion(s string) (claudeVersion, error) {
+	var v claudeVersion
+	match := claudeVersionPattern.FindString(s)
+	if match == "" {
+		return v, fmt.Errorf("no version number in '%s'", strings.TrimSpace(s))
+	}
+	for i, part := range strings.Split(match, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return v, fmt.Errorf("invalid version '%s'", match)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// validateClaudeVersionRange checks that pinned versions parse and that min is not above max
+func validateClaudeVersionRange(min, max string) error {
+	var minVersion, maxVersion claudeVersion
+	var err error
+	if min != "" {
+		if minVersion, err = parseClaudeVersion(min); err != nil {
+			return fmt.Errorf("invalid min_claude_version: %w", err)
+		}
+	}
+	if max != "" {
+		if maxVersion, err = parseClaudeVersion(max); err != nil {
+			return fmt.Errorf("invalid max_claude_version: %w", err)
+		}
+	}
+	if min != "" && max != "" && minVersion.compare(maxVersion) > 0 {
+		return fmt.Errorf("min_claude_version %s is newer than max_claude_version %s", min, max)
+	}
+	return nil
+}
+
+// applyClaudeVersionSettings validates and activates settings.claude_version_policy
+func applyClaudeVersionSettings(settings *ConfigSettings) error {
+	claudeVersionPolicy = claudeVersionBlock
+	if settings == nil || settings.ClaudeVersionPolicy == "" {
+		return nil
+	}
+	switch policy := strings.ToLower(settings.ClaudeVersionPolicy); policy {
+	case claudeVersionBlock, claudeVersionWarn:
+		claudeVersionPolicy = policy
+		return nil
+	default:
+		return fmt.Errorf("'%s' must be block or warn", settings.ClaudeVersionPolicy)
+	}
+}
+
+// claudeVersionOutput runs `claude --version`; tests replace it with canned output
+var claudeVersionOutput = func() (string, error) {
+	out, err := exec.Command("claude", "--version").Output()
+	return string(out), err
+}
+
+// Version detected by the first detectClaudeVersion call; claude is only asked once per run
+var (
+	claudeVersionDetected bool
+	claudeVersionCached   claudeVersion
+	claudeVersionErr      error
+)
+
+// detectClaudeVersion returns the installed claude version, running claude at most once
+func detectClaudeVersion() (claudeVersion, error) {
+	if !claudeVersionDetected {
+		claudeVersionDetected = true
+		out, err := claudeVersionOutput()
+		if err != nil {
+			claudeVersionErr = fmt.Errorf("'claude --version' failed: %w", err)
+		} else {
+			claudeVersionCached, claudeVersionErr = parseClaudeVersion(out)
+		}
+	}
+	return claudeVersionCached, claudeVersionErr
+}
+
+// describeClaudeVersionRange renders an environment's pin, e.g. ">= 1.0.0, <= 1.9.9"
+func describeClaudeVersionRange(env Environment) string {
+	var parts []string
+	if env.MinClaudeVersion != "" {
+		parts = append(parts, ">= "+env.MinClaudeVersion)
+	}
+	if env.MaxClaudeVersion != "" {
+		parts = append(parts, "<= "+env.MaxClaudeVersion)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// checkClaudeVersion compares the installed claude with env's pinned range. Outside the
+// range it blocks the launch, or only warns when settings.claude_version_policy is warn.
+func checkClaudeVersion(env Environment) error {
+	if env.MinClaudeVersion == "" && env.MaxClaudeVersion == "" {
+		return nil
+	}
+
+	errorCtx := newErrorContext("claude version check", "launcher")
+	errorCtx.addContext("environment", env.Name)
+	errorCtx.addContext("required", describeClaudeVersionRange(env))
+
+	installed, err := detectClaudeVersion()
+	if err != nil {
+		errorCtx.addSuggestion("Run 'claude --version' to check the installation")
+		errorCtx.addSuggestion("Remove min_claude_version/max_claude_version to skip the check")
+		return errorCtx.formatError(err)
+	}
+	errorCtx.addContext("installed", installed.String())
+
+	// Range validity is enforced when the config loads
+	min, _ := parseClaudeVersion(env.MinClaudeVersion)
+	max, _ := parseClaudeVersion(env.MaxClaudeVersion)
+	switch {
+	case env.MinClaudeVersion != "" && installed.compare(min) < 0:
+		errorCtx.addSuggestion("Upgrade with 'claude update' or 'npm install -g @anthropic-ai/claude-code@latest'")
+	case env.MaxClaudeVersion != "" && installed.compare(max) > 0:
+		errorCtx.addSuggestion(fmt.Sprintf("Install a supported release with 'npm install -g @anthropic-ai/claude-code@%s'", env.MaxClaudeVersion))
+	default:
+		return nil
+	}
+
+	mismatch := fmt.Errorf("claude %s is outside the range %s required by '%s'", installed, describeClaudeVersionRange(env), env.Name)
+	if claudeVersionPolicy == claudeVersionWarn {
+		fmt.Fprintf(os.Stderr, "Warning: %v; launching anyway (claude_version_policy is warn)\n", mismatch)
+		return nil
+	}
+	errorCtx.addSuggestion("Set settings.claude_version_policy to \"warn\" to launch anyway")
+	return errorCtx.formatError(mismatch)
+}
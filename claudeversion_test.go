@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// stubClaudeVersion makes claude --version print output and clears the per-run cache
+func stubClaudeVersion(t *testing.T, output string, err error) *int {
+	t.Helper()
+	calls := 0
+	original := claudeVersionOutput
+	claudeVersionOutput = func() (string, error) {
+		calls++
+		return output, err
+	}
+	resetCache := func() {
+		claudeVersionDetected, claudeVersionCached, claudeVersionErr = false, claudeVersion{}, nil
+		claudeVersionPolicy = claudeVersionBlock
+	}
+	resetCache()
+	t.Cleanup(func() {
+		claudeVersionOutput = original
+		resetCache()
+	})
+	return &calls
+}
+
+func TestParseClaudeVersion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  claudeVersion
+	}{
+		{"1.0.51 (Claude Code)\n", claudeVersion{1, 0, 51}},
+		{"claude 2.1", claudeVersion{2, 1, 0}},
+		{"3", claudeVersion{3, 0, 0}},
+	}
+	for _, tt := range tests {
+		if got, err := parseClaudeVersion(tt.input); err != nil || got != tt.want {
+			t.Errorf("parseClaudeVersion(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+	if _, err := parseClaudeVersion("unknown"); err == nil {
+		t.Error("parseClaudeVersion(unknown) should fail")
+	}
+}
+
+func TestValidateClaudeVersionRange(t *testing.T) {
+	if err := validateClaudeVersionRange("1.0.0", "1.2.0"); err != nil {
+		t.Errorf("valid range rejected: %v", err)
+	}
+	if err := validateClaudeVersionRange("2.0.0", "1.9.9"); err == nil || !strings.Contains(err.Error(), "newer than") {
+		t.Errorf("inverted range should fail, got %v", err)
+	}
+	if err := validateClaudeVersionRange("latest", ""); err == nil {
+		t.Error("non-numeric version should fail")
+	}
+}
+
+func TestCheckClaudeVersion(t *testing.T) {
+	env := Environment{Name: "legacy", MinClaudeVersion: "1.0.30", MaxClaudeVersion: "1.0.99"}
+	tests := []struct {
+		output  string
+		wantErr string
+	}{
+		{"1.0.51 (Claude Code)", ""},
+		{"1.0.30 (Claude Code)", ""},
+		{"1.0.12 (Claude Code)", "claude update"},
+		{"1.1.0 (Claude Code)", "@anthropic-ai/claude-code@1.0.99"},
+	}
+	for _, tt := range tests {
+		stubClaudeVersion(t, tt.output, nil)
+		err := checkClaudeVersion(env)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.output, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), ">= 1.0.30, <= 1.0.99") {
+			t.Errorf("%s: error = %v, want guidance %q", tt.output, err, tt.wantErr)
+			continue
+		}
+		if got := categorizeError(err); got != "claude_version" {
+			t.Errorf("%s: categorizeError() = %q, want claude_version", tt.output, got)
+		}
+	}
+}
+
+func TestCheckClaudeVersionWarnPolicy(t *testing.T) {
+	stubClaudeVersion(t, "2.0.0", nil)
+	if err := applyClaudeVersionSettings(&ConfigSettings{ClaudeVersionPolicy: "warn"}); err != nil {
+		t.Fatalf("applyClaudeVersionSettings() failed: %v", err)
+	}
+	env := Environment{Name: "legacy", MaxClaudeVersion: "1.0.99"}
+	stderr := captureStderr(t, func() {
+		if err := checkClaudeVersion(env); err != nil {
+			t.Errorf("warn policy should not block, got %v", err)
+		}
+	})
+	if !strings.Contains(stderr, "Warning: claude 2.0.0 is outside the range <= 1.0.99") {
+		t.Errorf("expected warning, got %q", stderr)
+	}
+	if err := applyClaudeVersionSettings(&ConfigSettings{ClaudeVersionPolicy: "ignore"}); err == nil {
+		t.Error("unknown policy should be rejected")
+	}
+}
+
+func TestClaudeVersionDetectedOncePerRun(t *testing.T) {
+	calls := stubClaudeVersion(t, "1.0.51", nil)
+	env := Environment{Name: "legacy", MinClaudeVersion: "1.0.0"}
+	for i := 0; i < 3; i++ {
+		if err := checkClaudeVersion(env); err != nil {
+			t.Fatalf("checkClaudeVersion() failed: %v", err)
+		}
+	}
+	if *calls != 1 {
+		t.Errorf("claude --version ran %d times, want 1", *calls)
+	}
+	if err := checkClaudeVersion(Environment{Name: "free"}); err != nil || *calls != 1 {
+		t.Errorf("unpinned environments should not need claude, got %v (%d calls)", err, *calls)
+	}
+}
+
+func TestCheckClaudeVersionUndetectable(t *testing.T) {
+	stubClaudeVersion(t, "", fmt.Errorf("exit status 1"))
+	err := checkClaudeVersion(Environment{Name: "legacy", MinClaudeVersion: "1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "'claude --version' failed") {
+		t.Errorf("expected detection failure, got %v", err)
+	}
+}
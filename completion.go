@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Commands whose first argument is an environment name, and those taking several
+var (
+	completionNameCommands  = []string{"remove", "set", "copy", "rename", "default", "shell", "use", "ping", "rotate"}
+	completionNamesCommands = []string{"test", "verify", "models"}
+)
+
+// completionShells are the shells cce completion can generate a script for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completionWords answers the hidden __complete command the generated scripts call: envs
+// (environment names), commands (subcommands) or flags (global options). Errors print
+// nothing so a broken config never spills into the user's prompt.
+func completionWords(kind string) []string {
+	switch kind {
+	case "envs":
+		names, err := loadEnvironmentNames()
+		if err != nil {
+			return nil
+		}
+		return names
+	case "commands":
+		var words []string
+		for _, cmd := range helpCommands {
+			words = append(words, cmd.Name)
+		}
+		sort.Strings(words)
+		return words
+	case "flags":
+		var words []string
+		for _, entry := range globalHelpFlags {
+			for _, field := range strings.Fields(strings.ReplaceAll(entry.Usage, ",", " ")) {
+				if strings.HasPrefix(field, "-") {
+					words = append(words, field)
+				}
+			}
+		}
+		return words
+	}
+	return nil
+}
+
+// runComplete prints one completion candidate per line
+func runComplete(args []string) error {
+	if len(args) != 1 {
+		return nil
+	}
+	for _, word := range completionWords(args[0]) {
+		if _, err := fmt.Println(word); err != nil {
+			return fmt.Errorf("failed to write completions: %w", err)
+		}
+	}
+	return nil
+}
+
+// runCompletion writes the completion script for the named shell to stdout
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("argument parsing failed: completion requires a shell (%s)", strings.Join(completionShells, ", "))
+	}
+	return writeCompletionScript(os.Stdout, args[0])
+}
+
+// writeCompletionScript renders the script for shell. Environment names are not baked in:
+// the scripts ask cce __complete each time, so new environments complete immediately.
+func writeCompletionScript(w io.Writer, shell string) error {
+	var script string
+	switch shell {
+	case "bash":
+		script = fmt.Sprintf(bashCompletion, strings.Join(completionNameCommands, "|"), strings.Join(completionNamesCommands, "|"))
+	case "zsh":
+		script = fmt.Sprintf(zshCompletion, strings.Join(completionNameCommands, "|"), strings.Join(completionNamesCommands, "|"))
+	case "fish":
+		script = fmt.Sprintf(fishCompletion, strings.Join(completionNameCommands, " "), strings.Join(completionNamesCommands, " "))
+	default:
+		return fmt.Errorf("argument validation failed: unsupported shell '%s' (expected %s)", shell, strings.Join(completionShells, ", "))
+	}
+	if _, err := io.WriteString(w, script); err != nil {
+		return fmt.Errorf("failed to write completion script: %w", err)
+	}
+	return nil
+}
+
+const bashCompletion = `# cce bash completion; load with: source <(cce completion bash)
+_cce() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local cmd="" i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            -e|--env|-k|--key-var|-m|--model|--env-file|--group|--log-file|--watch-max|--chdir|--config|--wk-path|--wk-name) ((i++)) ;;
+            -*) ;;
+            *) cmd="${COMP_WORDS[i]}"; break ;;
+        esac
+    done
+
+    case "$prev" in
+        -e|--env)
+            COMPREPLY=($(compgen -W "$(cce __complete envs 2>/dev/null)" -- "$cur"))
+            return ;;
+    esac
+    if [[ -z "$cmd" ]]; then
+        if [[ "$cur" == -* ]]; then
+            COMPREPLY=($(compgen -W "$(cce __complete flags 2>/dev/null)" -- "$cur"))
+        else
+            COMPREPLY=($(compgen -W "$(cce __complete commands 2>/dev/null)" -- "$cur"))
+        fi
+        return
+    fi
+    case "$cmd" in
+        %s)
+            [[ "$prev" == "$cmd" && "$cur" != -* ]] && COMPREPLY=($(compgen -W "$(cce __complete envs 2>/dev/null)" -- "$cur")) ;;
+        %s)
+            [[ "$cur" != -* ]] && COMPREPLY=($(compgen -W "$(cce __complete envs 2>/dev/null)" -- "$cur")) ;;
+        completion)
+            [[ "$prev" == "$cmd" ]] && COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur")) ;;
+        help)
+            [[ "$prev" == "$cmd" ]] && COMPREPLY=($(compgen -W "$(cce __complete commands 2>/dev/null)" -- "$cur")) ;;
+    esac
+}
+complete -o default -F _cce cce
+`
+
+const zshCompletion = `#compdef cce
+# cce zsh completion; load with: source <(cce completion zsh)
+_cce() {
+    local -a words_
+    local prev="${words[CURRENT-1]}" cmd="" i
+    for ((i = 2; i < CURRENT; i++)); do
+        case "${words[i]}" in
+            -e|--env|-k|--key-var|-m|--model|--env-file|--group|--log-file|--watch-max|--chdir|--config|--wk-path|--wk-name) ((i++)) ;;
+            -*) ;;
+            *) cmd="${words[i]}"; break ;;
+        esac
+    done
+
+    case "$prev" in
+        -e|--env)
+            words_=(${(f)"$(cce __complete envs 2>/dev/null)"})
+            compadd -a words_
+            return ;;
+    esac
+    if [[ -z "$cmd" ]]; then
+        if [[ "$PREFIX" == -* ]]; then
+            words_=(${(f)"$(cce __complete flags 2>/dev/null)"})
+        else
+            words_=(${(f)"$(cce __complete commands 2>/dev/null)"})
+        fi
+        compadd -a words_
+        return
+    fi
+    case "$cmd" in
+        %s)
+            [[ "$prev" == "$cmd" ]] || { _files; return }
+            words_=(${(f)"$(cce __complete envs 2>/dev/null)"}) ;;
+        %s)
+            words_=(${(f)"$(cce __complete envs 2>/dev/null)"}) ;;
+        completion)
+            words_=(bash zsh fish) ;;
+        help)
+            words_=(${(f)"$(cce __complete commands 2>/dev/null)"}) ;;
+        *)
+            _files
+            return ;;
+    esac
+    compadd -a words_
+}
+compdef _cce cce
+`
+
+const fishCompletion = `# cce fish completion; load with: cce completion fish | source
+function __cce_no_command
+    set -l tokens (commandline -opc)
+    for token in $tokens[2..-1]
+        if not string match -q -- '-*' $token
+            return 1
+        end
+    end
+    return 0
+end
+
+complete -c cce -f -n __cce_no_command -a '(cce __complete commands 2>/dev/null)'
+complete -c cce -f -s e -l env -r -a '(cce __complete envs 2>/dev/null)' -d 'Environment'
+complete -c cce -f -n '__fish_seen_subcommand_from %s; and test (count (commandline -opc)) -le 2' -a '(cce __complete envs 2>/dev/null)'
+complete -c cce -f -n '__fish_seen_subcommand_from %s' -a '(cce __complete envs 2>/dev/null)'
+complete -c cce -f -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'
+complete -c cce -f -n '__fish_seen_subcommand_from help' -a '(cce __complete commands 2>/dev/null)'
+`
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestCompleteListsEnvironmentsAndCommands(t *testing.T) {
+	withTempConfigPath(t)
+	config := Config{Environments: []Environment{
+		{Name: "staging", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"__complete", "envs"}); err != nil {
+			t.Fatalf("__complete envs failed: %v", err)
+		}
+	})
+	if out != "staging\nprod\n" {
+		t.Errorf("expected names in config order, got %q", out)
+	}
+
+	commands := strings.Join(completionWords("commands"), " ")
+	for _, want := range []string{"list", "rename", "completion", "help"} {
+		if !strings.Contains(commands, want) {
+			t.Errorf("commands missing %q: %s", want, commands)
+		}
+	}
+	if strings.Contains(commands, "__complete") {
+		t.Error("the hidden __complete command should not be offered")
+	}
+	flags := completionWords("flags")
+	if len(flags) == 0 || flags[0] != "-e" || !strings.Contains(strings.Join(flags, " "), "--env ") {
+		t.Errorf("unexpected flags: %v", flags)
+	}
+}
+
+func TestCompleteIsSilentOnBrokenConfig(t *testing.T) {
+	path := withTempConfigPath(t)
+	if err := ioutil.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	out := captureStdout(t, func() {
+		if err := runComplete([]string{"envs"}); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+	if out != "" {
+		t.Errorf("expected no output, got %q", out)
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range completionShells {
+		var buf bytes.Buffer
+		if err := writeCompletionScript(&buf, shell); err != nil {
+			t.Fatalf("%s: %v", shell, err)
+		}
+		script := buf.String()
+		if !strings.Contains(script, "cce __complete envs") || strings.Contains(script, "%!") {
+			t.Errorf("%s script does not look up names dynamically:\n%s", shell, script)
+		}
+	}
+	if err := runCompletion([]string{"tcsh"}); err == nil || !strings.Contains(err.Error(), "unsupported shell") {
+		t.Errorf("expected an unsupported shell error, got %v", err)
+	}
+}
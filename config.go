@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 )
 
+// maxConfigBackups is how many backups are kept; older ones are pruned after each backup
+const maxConfigBackups = 10
+
 // configBackup manages configuration backup operations
 type configBackup struct {
 	originalPath string
@@ -36,8 +41,8 @@ func (cb *configBackup) createBackup() (string, error) {
 		return "", nil // No file to backup
 	}
 
-	// Create timestamped backup filename
-	timestamp := time.Now().Format("20060102-150405")
+	// Create timestamped backup filename; milliseconds keep back-to-back saves apart
+	timestamp := time.Now().Format("20060102-150405.000")
 	backupPath := filepath.Join(cb.backupDir, fmt.Sprintf("config-%s.json", timestamp))
 
 	// Read original file
@@ -54,6 +59,23 @@ func (cb *configBackup) createBackup() (string, error) {
 	return backupPath, nil
 }
 
+// pruneBackups removes the oldest config-*.json backups beyond keep. Names embed the
+// timestamp, so name order is age order.
+func (cb *configBackup) pruneBackups(keep int) error {
+	backups, err := filepath.Glob(filepath.Join(cb.backupDir, "config-*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(backups)
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to prune backup: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
 // detectCorruption attempts to detect configuration corruption
 func detectCorruption(configPath string) error {
 	data, err := ioutil.ReadFile(configPath)
@@ -146,14 +168,42 @@ func saveConfigDirect(config Config, configPath string) error {
 	return ioutil.WriteFile(configPath, data, 0600)
 }
 
+// peekConfigSettings reads only the settings block, without validation, for decisions
+// that must be made before the full config is loaded. Any problem yields nil; loadConfig
+// reports it properly later.
+func peekConfigSettings() *ConfigSettings {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil
+	}
+	var partial struct {
+		Settings *ConfigSettings `json:"settings"`
+	}
+	if err := json.Unmarshal(data, &partial); err != nil {
+		return nil
+	}
+	return partial.Settings
+}
+
 // configPathOverride allows tests to override the config path
 var configPathOverride string
 
+// configPathFlag is the config file chosen with --config or CCE_CONFIG_PATH, already
+// resolved by resolveConfigPath; empty means the default under the home directory
+var configPathFlag string
+
 // getConfigPath returns the path to the configuration file
 func getConfigPath() (string, error) {
 	if configPathOverride != "" {
 		return configPathOverride, nil
 	}
+	if configPathFlag != "" {
+		return configPathFlag, nil
+	}
 
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -162,6 +212,38 @@ func getConfigPath() (string, error) {
 	return filepath.Join(home, ".claude-code-env", "config.json"), nil
 }
 
+// resolveConfigPath validates a --config (or CCE_CONFIG_PATH) value and returns it as an
+// absolute path. A missing parent directory is created with 0700 permissions right away,
+// so a path that can never be saved to fails before anything runs.
+func resolveConfigPath(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	expanded, err := expandSettingsDir(path)
+	if err != nil {
+		return "", fmt.Errorf("argument validation failed: --config %s: %w", path, err)
+	}
+	abs, err := filepath.Abs(expanded)
+	if err != nil {
+		return "", fmt.Errorf("argument validation failed: --config %s: %w", path, err)
+	}
+	if info, err := os.Stat(abs); err == nil && info.IsDir() {
+		return "", fmt.Errorf("argument validation failed: --config %s: is a directory; name the config file itself", path)
+	}
+
+	dir := filepath.Dir(abs)
+	if info, err := os.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return "", fmt.Errorf("argument validation failed: --config %s: %s is not a directory", path, dir)
+		}
+		return abs, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("argument validation failed: --config %s: cannot create its directory: %w", path, err)
+	}
+	return abs, nil
+}
+
 // ensureConfigDir creates the configuration directory with proper permissions
 func ensureConfigDir() error {
 	configPath, err := getConfigPath()
@@ -199,44 +281,45 @@ func ensureConfigDir() error {
 	return nil
 }
 
-// loadConfig reads and parses the configuration file with comprehensive error handling and recovery
-func loadConfig() (Config, error) {
-	configPath, err := getConfigPath()
-	if err != nil {
-		return Config{}, fmt.Errorf("configuration loading failed: %w", err)
+// validateConfigSettings checks the settings and activates the ones kept in package state
+func validateConfigSettings(settings *ConfigSettings) error {
+	if settings != nil {
+		if _, err := resolveKeyBindings(settings.KeyBindings); err != nil {
+			return fmt.Errorf("invalid key_bindings: %w", err)
+		}
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// Return empty configuration if file doesn't exist (not an error)
-		return Config{Environments: []Environment{}}, nil
-	} else if err != nil {
-		return Config{}, fmt.Errorf("configuration file access failed: %w", err)
+	if err := applyModelSettings(settings); err != nil {
+		return fmt.Errorf("invalid default_model: %w", err)
 	}
 
-	// Read file contents
-	data, err := ioutil.ReadFile(configPath)
-	if err != nil {
-		return Config{}, fmt.Errorf("configuration file read failed: %w", err)
+	if err := applyNetworkSettings(settings); err != nil {
+		return fmt.Errorf("invalid network settings: %w", err)
 	}
 
-	// Handle empty file
-	if len(data) == 0 {
-		return Config{Environments: []Environment{}}, nil
+	if err := applyPreflightSettings(settings); err != nil {
+		return fmt.Errorf("invalid preflight: %w", err)
 	}
 
-	// Parse JSON
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return Config{}, fmt.Errorf("configuration file parsing failed (invalid JSON): %w", err)
+	if err := applyClaudeVersionSettings(settings); err != nil {
+		return fmt.Errorf("invalid claude_version_policy: %w", err)
 	}
 
-	// Validate structure includes environments key when file isn't empty
-	var raw map[string]json.RawMessage
-	if err := json.Unmarshal(data, &raw); err == nil {
-		if _, ok := raw["environments"]; !ok {
-			return Config{}, fmt.Errorf("configuration validation failed: missing environments field")
-		}
+	if err := applyWorktreeSettings(settings); err != nil {
+		return fmt.Errorf("invalid worktree settings: %w", err)
+	}
+
+	if err := applyKeyFormatSettings(settings); err != nil {
+		return fmt.Errorf("invalid validation.key_format: %w", err)
+	}
+	return nil
+}
+
+// loadConfig reads the configuration from the active store and validates it
+func loadConfig() (Config, error) {
+	config, err := configStore.Load()
+	if err != nil {
+		return Config{}, err
 	}
 
 	// Initialize environments slice if nil
@@ -244,116 +327,106 @@ func loadConfig() (Config, error) {
 		config.Environments = []Environment{}
 	}
 
-	// Validate all environments
+	if err := validateConfigSettings(config.Settings); err != nil {
+		return Config{}, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	// Validate all environments; short legacy keys only warn
+	applyValidationSettings(config.Settings)
 	for i, env := range config.Environments {
-		if err := validateEnvironment(env); err != nil {
+		if err := validateStoredEnvironment(env); err != nil {
 			return Config{}, fmt.Errorf("configuration validation failed for environment %d (%s): %w", i, env.Name, err)
 		}
+		if warning := shortAPIKeyWarning(env); warning != "" {
+			fmt.Fprintln(os.Stderr, warning)
+		}
 	}
 
 	return config, nil
 }
 
-// saveConfig writes the configuration to file with atomic operations, backup, and proper permissions
+// saveConfig validates the configuration, backs up the stored copy and writes it to the active store
 func saveConfig(config Config) error {
 	// Validate configuration before saving
 	for i, env := range config.Environments {
-		if err := validateEnvironment(env); err != nil {
+		if err := validateStoredEnvironment(env); err != nil {
 			return fmt.Errorf("configuration save failed - invalid environment %d (%s): %w", i, env.Name, err)
 		}
 	}
 
-	// Ensure configuration directory exists
-	if err := ensureConfigDir(); err != nil {
-		return fmt.Errorf("configuration save failed: %w", err)
-	}
-
-	configPath, err := getConfigPath()
-	if err != nil {
-		return fmt.Errorf("configuration save failed: %w", err)
-	}
-
-	// Create backup before saving (if file exists)
-	backup := newConfigBackup(configPath)
-	if _, err := os.Stat(configPath); err == nil {
-		if backupPath, backupErr := backup.createBackup(); backupErr != nil {
-			fmt.Printf("Warning: failed to create backup: %v\n", backupErr)
-		} else if backupPath != "" {
-			fmt.Printf("Configuration backed up to: %s\n", backupPath)
+	// A failed backup should not block the save itself
+	if autoBackupEnabled(config.Settings) {
+		if err := configStore.Backup(); err != nil {
+			fmt.Printf("Warning: failed to create backup: %v\n", err)
 		}
 	}
 
-	// Marshal to JSON with proper formatting
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("configuration serialization failed: %w", err)
-	}
+	return configStore.Save(config)
+}
 
-	// Use atomic write pattern (temp file + rename)
-	tempPath := configPath + ".tmp"
+// autoBackupEnabled reports whether saves back up the stored config (settings.auto_backup)
+func autoBackupEnabled(settings *ConfigSettings) bool {
+	return settings == nil || settings.AutoBackup == nil || *settings.AutoBackup
+}
 
-	// Write to temporary file with 0600 permissions (owner read/write only)
-	if err := ioutil.WriteFile(tempPath, data, 0600); err != nil {
-		return fmt.Errorf("configuration temporary file write failed: %w", err)
-	}
+// findEnvironmentByName searches for an environment by name and returns its index.
+// Ambiguous case-insensitive matches are reported as not found; use lookupEnvironment
+// to surface the ambiguity.
+func findEnvironmentByName(config Config, name string) (int, bool) {
+	index, err := lookupEnvironment(config, name)
+	return index, err == nil
+}
 
-	// Verify temporary file permissions
-	if info, err := os.Stat(tempPath); err != nil {
-		// Clean up temp file
-		os.Remove(tempPath)
-		return fmt.Errorf("configuration temporary file verification failed: %w", err)
-	} else if info.Mode().Perm() != 0600 {
-		// Try to fix permissions
-		if err := os.Chmod(tempPath, 0600); err != nil {
-			os.Remove(tempPath)
-			return fmt.Errorf("configuration temporary file permission setting failed: %w", err)
-		}
-	}
+// caseInsensitiveNames reports whether the config enables case-insensitive name matching
+func caseInsensitiveNames(config Config) bool {
+	return config.Settings != nil && config.Settings.CaseInsensitiveNames
+}
 
-	// If destination exists, verify writability to surface permission issues
-	if _, err := os.Stat(configPath); err == nil {
-		f, openErr := os.OpenFile(configPath, os.O_WRONLY, 0)
-		if openErr != nil {
-			// Clean up temp file
-			os.Remove(tempPath)
-			return fmt.Errorf("configuration file save failed (permission denied): %w", openErr)
+// lookupEnvironment resolves a name to an index. An exact match always wins; with
+// case_insensitive_names enabled a single case-insensitive match is accepted, and
+// names that differ only in case (e.g. "prod" and "PROD") make the lookup ambiguous.
+func lookupEnvironment(config Config, name string) (int, error) {
+	for i, env := range config.Environments {
+		if env.Name == name {
+			return i, nil
 		}
-		f.Close()
-	}
-
-	// Atomic move (rename) from temp to final location
-	if err := os.Rename(tempPath, configPath); err != nil {
-		// Clean up temp file on error
-		os.Remove(tempPath)
-		return fmt.Errorf("configuration file save failed (atomic move): %w", err)
 	}
 
-	// Verify final file permissions
-	if info, err := os.Stat(configPath); err != nil {
-		return fmt.Errorf("configuration file verification failed: %w", err)
-	} else if info.Mode().Perm() != 0600 {
-		// Try to fix permissions
-		if err := os.Chmod(configPath, 0600); err != nil {
-			return fmt.Errorf("configuration file permission setting failed: %w", err)
+	if caseInsensitiveNames(config) {
+		index := -1
+		var matches []string
+		for i, env := range config.Environments {
+			if strings.EqualFold(env.Name, name) {
+				index = i
+				matches = append(matches, env.Name)
+			}
+		}
+		if len(matches) == 1 {
+			return index, nil
+		}
+		if len(matches) > 1 {
+			return -1, fmt.Errorf("environment name '%s' is ambiguous (matches %s); use the exact name", name, strings.Join(matches, ", "))
 		}
 	}
 
-	return nil
+	return -1, fmt.Errorf("environment '%s' not found", name)
 }
 
-// findEnvironmentByName searches for an environment by name and returns its index
-func findEnvironmentByName(config Config, name string) (int, bool) {
-	for i, env := range config.Environments {
-		if env.Name == name {
-			return i, true
+// environmentNameTaken reports the existing name a new environment would collide with.
+// With case_insensitive_names enabled, names differing only in case collide so that
+// every name stays resolvable.
+func environmentNameTaken(config Config, name string) (string, bool) {
+	for _, env := range config.Environments {
+		if env.Name == name || (caseInsensitiveNames(config) && strings.EqualFold(env.Name, name)) {
+			return env.Name, true
 		}
 	}
-	return -1, false
+	return "", false
 }
 
-// equalEnvironments compares two environments for equality, including EnvVars maps
+// equalEnvironments compares two environments for equality, including EnvVars and Headers maps
 func equalEnvironments(a, b Environment) bool {
-	if a.Name != b.Name || a.URL != b.URL || a.APIKey != b.APIKey || a.Model != b.Model || a.APIKeyEnv != b.APIKeyEnv {
+	if a.Name != b.Name || a.URL != b.URL || a.APIKey != b.APIKey || a.Model != b.Model || a.APIKeyEnv != b.APIKeyEnv || a.AuthScheme != b.AuthScheme || a.Notes != b.Notes || a.SettingsDir != b.SettingsDir || a.CACert != b.CACert || a.CheckTimeout != b.CheckTimeout || a.CheckRetries != b.CheckRetries || a.Provider != b.Provider || a.Region != b.Region || a.Project != b.Project || a.PreviousAPIKey != b.PreviousAPIKey || a.KeyRotatedAt != b.KeyRotatedAt || !sameTime(a.LastUsed, b.LastUsed) || a.MinClaudeVersion != b.MinClaudeVersion || a.MaxClaudeVersion != b.MaxClaudeVersion || a.TrustArgs != b.TrustArgs || strings.Join(a.APIKeys, ",") != strings.Join(b.APIKeys, ",") || a.KeyStrategy != b.KeyStrategy || strings.Join(a.Tags, ",") != strings.Join(b.Tags, ",") || strings.Join(a.Unvalidated, ",") != strings.Join(b.Unvalidated, ",") {
 		return false
 	}
 
@@ -369,26 +442,123 @@ func equalEnvironments(a, b Environment) bool {
 		}
 	}
 
+	if len(a.Headers) != len(b.Headers) {
+		return false
+	}
+	for name, valueA := range a.Headers {
+		if valueB, exists := b.Headers[name]; !exists || valueA != valueB {
+			return false
+		}
+	}
+
 	return true
 }
 
 // addEnvironmentToConfig adds a new environment to the configuration after validation
 func addEnvironmentToConfig(config *Config, env Environment) error {
+	return addEnvironmentToConfigWithOptions(config, env, true)
+}
+
+// addEnvironmentToConfigWithOptions adds an environment; a URL already used by another
+// environment is a warning when allowDupURL is set and an error otherwise
+func addEnvironmentToConfigWithOptions(config *Config, env Environment, allowDupURL bool) error {
 	// Validate environment first
 	if err := validateEnvironment(env); err != nil {
 		return fmt.Errorf("environment addition failed: %w", err)
 	}
 
-	// Check for duplicate name
-	if _, exists := findEnvironmentByName(*config, env.Name); exists {
+	// Check for duplicate name (case-insensitively when case_insensitive_names is set)
+	if existing, taken := environmentNameTaken(*config, env.Name); taken {
+		if existing != env.Name {
+			return fmt.Errorf("environment with name '%s' already exists (as '%s'; names are case-insensitive)", env.Name, existing)
+		}
 		return fmt.Errorf("environment with name '%s' already exists", env.Name)
 	}
 
+	// Sharing an endpoint is usually deliberate (several keys), occasionally a copy-paste slip
+	if others := environmentsWithURL(*config, env.URL); len(others) > 0 {
+		if !allowDupURL {
+			return fmt.Errorf("URL '%s' is already used by %s", env.URL, strings.Join(others, ", "))
+		}
+		fmt.Fprintf(os.Stderr, "Warning: URL '%s' is already used by %s\n", env.URL, strings.Join(others, ", "))
+	}
+	if warning := trailingSlashWarning(*config, env.URL); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
 	// Add to configuration
 	config.Environments = append(config.Environments, env)
 	return nil
 }
 
+// environmentsWithURL names the environments whose URL matches, ignoring trailing slashes and case
+func environmentsWithURL(config Config, rawURL string) []string {
+	target := strings.TrimRight(rawURL, "/")
+	var names []string
+	if target == "" {
+		return nil
+	}
+	for _, env := range config.Environments {
+		if strings.EqualFold(strings.TrimRight(env.URL, "/"), target) {
+			names = append(names, env.Name)
+		}
+	}
+	return names
+}
+
+// splitURLSuffix separates a URL's query or fragment, which a trailing-slash check must skip
+func splitURLSuffix(rawURL string) (string, string) {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		return rawURL[:i], rawURL[i:]
+	}
+	return rawURL, ""
+}
+
+// hasTrailingSlash reports whether the URL's path ends in '/'
+func hasTrailingSlash(rawURL string) bool {
+	base, _ := splitURLSuffix(rawURL)
+	return strings.HasSuffix(base, "/")
+}
+
+// normalizeURLSlash strips trailing slashes from the URL's path (add --normalize-url)
+func normalizeURLSlash(rawURL string) string {
+	base, suffix := splitURLSuffix(rawURL)
+	return strings.TrimRight(base, "/") + suffix
+}
+
+// trailingSlashWarning flags a URL whose trailing slash disagrees with other environments
+// on the same scheme and host; some proxies route ".../v1" and ".../v1/" differently.
+// The URL is stored as entered either way.
+func trailingSlashWarning(config Config, rawURL string) string {
+	target, err := url.Parse(rawURL)
+	if err != nil || target.Host == "" {
+		return ""
+	}
+	slash := hasTrailingSlash(rawURL)
+
+	var others []string
+	for _, env := range config.Environments {
+		other, err := url.Parse(env.URL)
+		if err != nil || !strings.EqualFold(other.Scheme, target.Scheme) || !strings.EqualFold(other.Host, target.Host) {
+			continue
+		}
+		if hasTrailingSlash(env.URL) != slash {
+			others = append(others, env.Name)
+		}
+	}
+	if len(others) == 0 {
+		return ""
+	}
+
+	form := "has no trailing '/'"
+	if slash {
+		form = "ends with '/'"
+	}
+	return fmt.Sprintf("Warning: URL '%s' %s, unlike %s on the same host; some proxies treat the two forms differently. "+
+		"Use one form for all of them (cce add --normalize-url strips the slash, cce set <name> url=... fixes an existing one)",
+		rawURL, form, strings.Join(others, ", "))
+}
+
 // removeEnvironmentFromConfig removes an environment from the configuration
 func removeEnvironmentFromConfig(config *Config, name string) error {
 	index, exists := findEnvironmentByName(*config, name)
@@ -400,3 +570,213 @@ func removeEnvironmentFromConfig(config *Config, name string) error {
 	config.Environments = append(config.Environments[:index], config.Environments[index+1:]...)
 	return nil
 }
+
+// Config file formats recognized by detectConfigFormat
+const (
+	configFormatCanonical  = "canonical"
+	configFormatLegacyList = "legacy-list"
+	configFormatLegacyMap  = "legacy-map"
+	configFormatUnknown    = "unknown"
+)
+
+// legacyManagedConfig is the map-based layout written by the internal config manager
+type legacyManagedConfig struct {
+	Version      string                              `json:"version"`
+	DefaultEnv   string                              `json:"default_env"`
+	Default      string                              `json:"default_environment"`
+	Environments map[string]legacyManagedEnvironment `json:"environments"`
+	Settings     *ConfigSettings                     `json:"settings,omitempty"`
+}
+
+// legacyManagedEnvironment is an environment entry in the map-based layout
+type legacyManagedEnvironment struct {
+	Name      string            `json:"name"`
+	URL       string            `json:"url"`
+	BaseURL   string            `json:"base_url"`
+	APIKey    string            `json:"api_key"`
+	Model     string            `json:"model"`
+	APIKeyEnv string            `json:"api_key_env"`
+	EnvVars   map[string]string `json:"env_vars"`
+}
+
+// detectConfigFormat identifies the config layout by its JSON structure
+func detectConfigFormat(data []byte) string {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var envs []Environment
+		if json.Unmarshal(data, &envs) == nil {
+			return configFormatLegacyList
+		}
+		return configFormatUnknown
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return configFormatUnknown
+	}
+	envs, ok := raw["environments"]
+	if !ok {
+		return configFormatUnknown
+	}
+	switch strings.TrimSpace(string(envs))[:1] {
+	case "[":
+		return configFormatCanonical
+	case "{":
+		return configFormatLegacyMap
+	}
+	return configFormatUnknown
+}
+
+// convertLegacyConfig converts any recognized layout into the canonical Config.
+// The map layout's default environment is moved to the front so it stays the headless pick.
+func convertLegacyConfig(data []byte) (Config, string, error) {
+	format := detectConfigFormat(data)
+	switch format {
+	case configFormatCanonical:
+		var config Config
+		if err := json.Unmarshal(data, &config); err != nil {
+			return Config{}, format, fmt.Errorf("failed to parse canonical config: %w", err)
+		}
+		return config, format, nil
+
+	case configFormatLegacyList:
+		var envs []Environment
+		if err := json.Unmarshal(data, &envs); err != nil {
+			return Config{}, format, fmt.Errorf("failed to parse legacy list config: %w", err)
+		}
+		return Config{Environments: envs}, format, nil
+
+	case configFormatLegacyMap:
+		var legacy legacyManagedConfig
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return Config{}, format, fmt.Errorf("failed to parse legacy map config: %w", err)
+		}
+
+		names := make([]string, 0, len(legacy.Environments))
+		for key := range legacy.Environments {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+
+		defaultName := legacy.DefaultEnv
+		if defaultName == "" {
+			defaultName = legacy.Default
+		}
+
+		config := Config{Environments: []Environment{}, Settings: legacy.Settings}
+		for _, key := range names {
+			entry := legacy.Environments[key]
+			env := Environment{
+				Name:      entry.Name,
+				URL:       entry.URL,
+				APIKey:    entry.APIKey,
+				Model:     entry.Model,
+				APIKeyEnv: entry.APIKeyEnv,
+				EnvVars:   entry.EnvVars,
+			}
+			if env.Name == "" {
+				env.Name = key
+			}
+			if env.URL == "" {
+				env.URL = entry.BaseURL
+			}
+			if env.Name == defaultName {
+				config.DefaultEnv = env.Name
+				config.Environments = append([]Environment{env}, config.Environments...)
+			} else {
+				config.Environments = append(config.Environments, env)
+			}
+		}
+		return config, format, nil
+	}
+
+	return Config{}, format, fmt.Errorf("unrecognized configuration format")
+}
+
+// migrateConfig rewrites the config file in the canonical format after backing it up.
+// It returns the detected source format; canonical configs are left untouched.
+func migrateConfig(dryRun bool) (string, Config, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return "", Config{}, fmt.Errorf("configuration migration failed: %w", err)
+	}
+
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return "", Config{}, fmt.Errorf("configuration migration failed: %w", err)
+	}
+
+	config, format, err := convertLegacyConfig(data)
+	if err != nil {
+		return format, Config{}, fmt.Errorf("configuration migration failed: %w", err)
+	}
+	if format == configFormatCanonical || dryRun {
+		return format, config, nil
+	}
+
+	backupPath, err := newConfigBackup(configPath).createBackup()
+	if err != nil {
+		return format, Config{}, fmt.Errorf("configuration migration failed - backup not created: %w", err)
+	}
+	if backupPath != "" {
+		fmt.Printf("Original configuration backed up to: %s\n", backupPath)
+	}
+
+	// Write directly: saveConfig would back up the legacy file a second time
+	for i, env := range config.Environments {
+		if err := validateStoredEnvironment(env); err != nil {
+			return format, Config{}, fmt.Errorf("configuration migration failed - invalid environment %d (%s): %w", i, env.Name, err)
+		}
+		if warning := shortAPIKeyWarning(env); warning != "" {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+	}
+	if err := saveConfigDirect(config, configPath); err != nil {
+		return format, Config{}, fmt.Errorf("configuration migration failed: %w", err)
+	}
+
+	return format, config, nil
+}
+
+// configTemplate is the annotated starter config written by `cce init --template`.
+// JSON has no comments, so documentation lives in "_"-prefixed keys the loader ignores.
+const configTemplate = `{
+  "_comment": [
+    "cce configuration. Add environment objects to the \"environments\" list below,",
+    "or run 'cce add' to create them interactively. Keys starting with _ are ignored.",
+    "This file holds API keys: keep it at 0600 permissions."
+  ],
+  "_example_environment": {
+    "name": "prod",
+    "url": "https://api.anthropic.com",
+    "api_key": "sk-ant-...",
+    "model": "claude-3-5-sonnet-20241022",
+    "auth_scheme": "x-api-key",
+    "env_vars": {
+      "ANTHROPIC_SMALL_FAST_MODEL": "claude-3-5-haiku-20241022"
+    }
+  },
+  "_fields": {
+    "name": "required; letters, digits, - and _",
+    "url": "required; http(s) base URL of the API endpoint",
+    "api_key": "required; injected into Claude Code's environment",
+    "model": "optional; exported as ANTHROPIC_MODEL",
+    "auth_scheme": "optional; x-api-key (ANTHROPIC_API_KEY) or bearer (ANTHROPIC_AUTH_TOKEN)",
+    "api_key_env": "optional; explicit key variable name, overrides auth_scheme",
+    "env_vars": "optional; extra ANTHROPIC_* variables to export",
+    "notes": "optional; free text shown in the selector and 'cce list --wide'"
+  },
+  "environments": []
+}
+`
+
+// writeConfigTemplate writes the annotated starter config with owner-only permissions
+func writeConfigTemplate(configPath string) error {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return fmt.Errorf("failed to create configuration directory: %w", err)
+	}
+	if err := ioutil.WriteFile(configPath, []byte(configTemplate), 0600); err != nil {
+		return fmt.Errorf("failed to write configuration template: %w", err)
+	}
+	return os.Chmod(configPath, 0600)
+}
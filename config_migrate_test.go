@@ -0,0 +1,183 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withTempConfigPath points the config path at a temp dir for the duration of a test
+func withTempConfigPath(t testing.TB) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".claude-code-env", "config.json")
+	original := configPathOverride
+	configPathOverride = path
+	t.Cleanup(func() { configPathOverride = original })
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	return path
+}
+
+func TestDetectConfigFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"canonical", `{"environments": []}`, configFormatCanonical},
+		{"legacy list", `[{"name": "a", "url": "https://x.example", "api_key": "k"}]`, configFormatLegacyList},
+		{"legacy map", `{"version": "1.0", "environments": {"a": {"url": "https://x.example"}}}`, configFormatLegacyMap},
+		{"missing environments", `{"env": []}`, configFormatUnknown},
+		{"garbage", `not json`, configFormatUnknown},
+		{"null environments", `{"environments": null}`, configFormatUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectConfigFormat([]byte(tt.data)); got != tt.want {
+				t.Errorf("detectConfigFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigMigrate(t *testing.T) {
+	t.Run("legacy list", func(t *testing.T) {
+		path := withTempConfigPath(t)
+		legacy := `[{"name": "prod", "url": "https://api.anthropic.com", "api_key": "sk-ant-prod", "model": "claude-3-opus-20240229", "env_vars": {"ANTHROPIC_TIMEOUT": "30"}}]`
+		if err := ioutil.WriteFile(path, []byte(legacy), 0600); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+
+		if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "cce config migrate") {
+			t.Fatalf("expected loadConfig to point at migrate, got %v", err)
+		}
+
+		captureStdout(t, func() {
+			if err := handleCommand([]string{"config", "migrate"}); err != nil {
+				t.Fatalf("config migrate failed: %v", err)
+			}
+		})
+
+		config, err := loadConfig()
+		if err != nil {
+			t.Fatalf("loadConfig() after migrate failed: %v", err)
+		}
+		if len(config.Environments) != 1 {
+			t.Fatalf("expected 1 environment, got %d", len(config.Environments))
+		}
+		env := config.Environments[0]
+		if env.Model != "claude-3-opus-20240229" || env.EnvVars["ANTHROPIC_TIMEOUT"] != "30" {
+			t.Errorf("fields not preserved: %+v", env)
+		}
+
+		backups, _ := ioutil.ReadDir(filepath.Join(filepath.Dir(path), "backups"))
+		if len(backups) == 0 {
+			t.Error("expected a backup of the legacy config")
+		}
+	})
+
+	t.Run("legacy map keeps default first", func(t *testing.T) {
+		path := withTempConfigPath(t)
+		legacy := `{
+			"version": "1.0",
+			"default_env": "beta",
+			"environments": {
+				"alpha": {"name": "alpha", "base_url": "https://alpha.example.com", "api_key": "sk-alpha"},
+				"beta": {"url": "https://beta.example.com", "api_key": "sk-beta", "api_key_env": "ANTHROPIC_AUTH_TOKEN"}
+			}
+		}`
+		if err := ioutil.WriteFile(path, []byte(legacy), 0600); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+
+		captureStdout(t, func() {
+			if err := runConfigMigrate(nil); err != nil {
+				t.Fatalf("runConfigMigrate() failed: %v", err)
+			}
+		})
+
+		config, err := loadConfig()
+		if err != nil {
+			t.Fatalf("loadConfig() after migrate failed: %v", err)
+		}
+		if len(config.Environments) != 2 {
+			t.Fatalf("expected 2 environments, got %d", len(config.Environments))
+		}
+		if config.Environments[0].Name != "beta" || config.Environments[0].APIKeyEnv != "ANTHROPIC_AUTH_TOKEN" {
+			t.Errorf("expected default 'beta' first with key var preserved, got %+v", config.Environments[0])
+		}
+		if config.Environments[1].URL != "https://alpha.example.com" {
+			t.Errorf("expected base_url to map to URL, got %+v", config.Environments[1])
+		}
+	})
+
+	t.Run("dry run does not write", func(t *testing.T) {
+		path := withTempConfigPath(t)
+		legacy := `[{"name": "prod", "url": "https://api.anthropic.com", "api_key": "sk-ant-prod"}]`
+		if err := ioutil.WriteFile(path, []byte(legacy), 0600); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+
+		output := captureStdout(t, func() {
+			if err := runConfigMigrate([]string{"--dry-run"}); err != nil {
+				t.Fatalf("dry run failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "Would migrate 1 environment") {
+			t.Errorf("unexpected dry-run output: %q", output)
+		}
+
+		data, _ := ioutil.ReadFile(path)
+		if string(data) != legacy {
+			t.Error("dry run modified the config file")
+		}
+	})
+
+	t.Run("canonical is a no-op", func(t *testing.T) {
+		withTempConfigPath(t)
+		if err := saveConfig(Config{Environments: []Environment{{Name: "a", URL: "https://a.example.com", APIKey: "sk-a"}}}); err != nil {
+			t.Fatalf("saveConfig() failed: %v", err)
+		}
+
+		output := captureStdout(t, func() {
+			if err := runConfigMigrate(nil); err != nil {
+				t.Fatalf("runConfigMigrate() failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "nothing to migrate") {
+			t.Errorf("unexpected output: %q", output)
+		}
+	})
+
+	t.Run("invalid environment aborts without writing", func(t *testing.T) {
+		path := withTempConfigPath(t)
+		legacy := `[{"name": "bad name", "url": "https://api.anthropic.com", "api_key": "sk"}]`
+		if err := ioutil.WriteFile(path, []byte(legacy), 0600); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+
+		captureStdout(t, func() {
+			if err := runConfigMigrate(nil); err == nil {
+				t.Error("expected invalid environment to abort migration")
+			}
+		})
+
+		data, _ := ioutil.ReadFile(path)
+		if string(data) != legacy {
+			t.Error("failed migration modified the config file")
+		}
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		if err := runConfigCommand([]string{"bogus"}); err == nil {
+			t.Error("expected unknown action error")
+		}
+		if err := runConfigCommand(nil); err == nil {
+			t.Error("expected missing action error")
+		}
+	})
+}
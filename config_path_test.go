@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withoutConfigOverride clears the test-only config override so --config and
+// CCE_CONFIG_PATH take effect
+func withoutConfigOverride(t *testing.T) {
+	t.Helper()
+	original := configPathOverride
+	configPathOverride = ""
+	t.Cleanup(func() { configPathOverride, configPathFlag = original, "" })
+}
+
+func TestConfigFlagSelectsConfigFile(t *testing.T) {
+	withoutConfigOverride(t)
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "ci", "nested", "cce.json")
+
+	captureStdout(t, func() {
+		if err := handleCommand([]string{"--config", path, "copy", "missing", "x"}); err == nil {
+			t.Fatal("expected copy from an empty config to fail")
+		}
+	})
+	info, err := os.Stat(filepath.Dir(path))
+	if err != nil || info.Mode().Perm() != 0700 {
+		t.Fatalf("expected the config directory to be created with 0700, got %v %v", info, err)
+	}
+
+	configPathFlag = path
+	env := Environment{Name: "ci", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-ci-1234567890"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	configPathFlag = ""
+
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"--config=" + path, "list", "--names"}); err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+	})
+	if out != "ci\n" {
+		t.Errorf("expected the --config file's environments, got %q", out)
+	}
+
+	t.Setenv("CCE_CONFIG_PATH", path)
+	out = captureStdout(t, func() {
+		if err := handleCommand([]string{"list", "--names"}); err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+	})
+	if out != "ci\n" {
+		t.Errorf("expected CCE_CONFIG_PATH to be honored, got %q", out)
+	}
+}
+
+func TestConfigFlagNeverReachesClaude(t *testing.T) {
+	for _, args := range [][]string{
+		{"--config", "/tmp/cce.json", "--env", "dev", "chat"},
+		{"--env", "dev", "--config", "/tmp/cce.json", "chat"},
+		{"--env", "dev", "--config=/tmp/cce.json", "chat"},
+	} {
+		result := parseArguments(args)
+		if result.Error != nil {
+			t.Fatalf("%v: %v", args, result.Error)
+		}
+		if result.CCEFlags["config"] != "/tmp/cce.json" || strings.Join(result.ClaudeArgs, " ") != "chat" {
+			t.Errorf("%v: unexpected parse %+v", args, result)
+		}
+	}
+}
+
+func TestConfigFlagAfterClaudeArgsIsClaudes(t *testing.T) {
+	result := parseArguments([]string{"--env", "dev", "chat", "--config", "claude.json"})
+	if result.Error != nil {
+		t.Fatalf("parse failed: %v", result.Error)
+	}
+	if result.CCEFlags["config"] != "" || strings.Join(result.ClaudeArgs, " ") != "chat --config claude.json" {
+		t.Errorf("claude's --config was consumed: %+v", result)
+	}
+}
+
+func TestResolveConfigPathRejectsUnusablePaths(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	for _, path := range []string{dir, filepath.Join(file, "config.json")} {
+		if _, err := resolveConfigPath(path); err == nil || !strings.Contains(err.Error(), "--config") {
+			t.Errorf("%s: expected a --config error, got %v", path, err)
+		}
+	}
+}
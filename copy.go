@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// cloneEnvironment returns a deep copy of env: maps and slices are duplicated so
+// changing the copy never touches the original
+func cloneEnvironment(env Environment) Environment {
+	clone := env
+	if env.EnvVars != nil {
+		clone.EnvVars = make(map[string]string, len(env.EnvVars))
+		for key, value := range env.EnvVars {
+			clone.EnvVars[key] = value
+		}
+	}
+	if env.Headers != nil {
+		clone.Headers = make(map[string]string, len(env.Headers))
+		for name, value := range env.Headers {
+			clone.Headers[name] = value
+		}
+	}
+	clone.APIKeys = append([]string(nil), env.APIKeys...)
+	clone.Tags = append([]string(nil), env.Tags...)
+	clone.Unvalidated = append([]string(nil), env.Unvalidated...)
+	return clone
+}
+
+// promptCopyOverrides asks which fields of a copy should differ from the source: a new
+// API key (hidden, Enter keeps the source's) and any other field=value pairs
+func promptCopyOverrides(source, dest string) (map[string]string, []string, error) {
+	updates := map[string]string{}
+	var order []string
+
+	key, err := secretReader(fmt.Sprintf("API key for '%s' (hidden, Enter to keep %s's): ", dest, source))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read API key: %w", err)
+	}
+	if key = strings.TrimSpace(key); key != "" {
+		updates[fieldAPIKey] = key
+		order = append(order, fieldAPIKey)
+	}
+
+	line, err := confirmationReader("Other fields to change, as field=value separated by spaces (Enter for none): ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read field changes: %w", err)
+	}
+	more, moreOrder, err := parseFieldUpdates(strings.Fields(line))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, field := range moreOrder {
+		if field == fieldAPIKey {
+			return nil, nil, fmt.Errorf("enter the API key at the hidden prompt, not as api_key=")
+		}
+		updates[field] = more[field]
+		order = append(order, field)
+	}
+	return updates, order, nil
+}
+
+// runCopy clones an environment under a new name. Fields given as field=value (as for
+// set) replace the source's; without any, a terminal is asked for a new API key and
+// other changes, and elsewhere the copy is exact.
+func runCopy(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("argument parsing failed: copy requires a source and a new environment name")
+	}
+	source, dest := args[0], args[1]
+	if err := validateName(dest); err != nil {
+		return fmt.Errorf("argument validation failed: invalid name '%s': %w", dest, err)
+	}
+
+	updates, order, err := parseFieldUpdates(args[2:])
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	if key, ok := updates[fieldAPIKey]; ok && key != "-" {
+		return fmt.Errorf("argument parsing failed: use api_key=- to enter the key without exposing it in shell history")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	index, found := findEnvironmentByName(config, source)
+	if !found {
+		_, err := lookupEnvironment(config, source) // Says whether it is missing or ambiguous
+		return err
+	}
+	if existing, taken := environmentNameTaken(config, dest); taken {
+		return fmt.Errorf("argument validation failed: environment '%s' already exists; pick another name or remove it first", existing)
+	}
+	source = config.Environments[index].Name
+
+	switch {
+	case updates[fieldAPIKey] == "-":
+		if noPrompt && stdinIsTerminal() {
+			return promptRefused("api_key=-", "pipe the key on stdin")
+		}
+		if updates[fieldAPIKey], err = secretReader(fmt.Sprintf("API key for '%s' (hidden): ", dest)); err != nil {
+			return fmt.Errorf("failed to read API key: %w", err)
+		}
+	case len(order) == 0 && stdinIsTerminal() && !noPrompt:
+		if updates, order, err = promptCopyOverrides(source, dest); err != nil {
+			return fmt.Errorf("argument validation failed: %w", err)
+		}
+	}
+
+	env := cloneEnvironment(config.Environments[index])
+	env.Name = dest
+	// Rotation history belongs to the source's key
+	env.PreviousAPIKey, env.KeyRotatedAt = "", ""
+	env.LastUsed = nil
+	if len(order) > 0 {
+		if env, err = applyFieldUpdatesWithOptions(env, updates, order, false); err != nil {
+			return fmt.Errorf("failed to copy environment '%s': %w", source, err)
+		}
+	}
+	if warning := keyStyleWarning(env); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+	if warning := keyFormatWarning(env); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
+	// Appended directly: copies share the source's URL by design, which add would warn about
+	if err := validateEnvironment(env); err != nil {
+		return fmt.Errorf("failed to copy environment '%s': %w", source, err)
+	}
+	config.Environments = append(config.Environments, env)
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	message := fmt.Sprintf("Environment '%s' copied from '%s'", dest, source)
+	if len(order) > 0 {
+		message += " with new " + strings.Join(order, ", ")
+	}
+	if _, err := fmt.Println(message + "."); err != nil {
+		return fmt.Errorf("failed to display success message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func saveCopySource(t *testing.T) Environment {
+	t.Helper()
+	source := Environment{
+		Name:           "prod",
+		URL:            "https://api.anthropic.com",
+		APIKey:         "sk-ant-REDACTED",
+		Model:          "claude-sonnet-4-20250514",
+		EnvVars:        map[string]string{"ANTHROPIC_TIMEOUT": "60"},
+		Tags:           []string{"team"},
+		PreviousAPIKey: "sk-ant-REDACTED",
+		KeyRotatedAt:   "2026-01-01T00:00:00Z",
+	}
+	if err := saveConfig(Config{Environments: []Environment{source}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	return source
+}
+
+func TestCopyClonesEnvironment(t *testing.T) {
+	withTempConfigPath(t)
+	source := saveCopySource(t)
+	origTerminal := stdinIsTerminal
+	defer func() { stdinIsTerminal = origTerminal }()
+	stdinIsTerminal = func() bool { return false }
+
+	captureStdout(t, func() {
+		if err := handleCommand([]string{"copy", "prod", "staging", "env.ANTHROPIC_TIMEOUT=90"}); err != nil {
+			t.Fatalf("copy failed: %v", err)
+		}
+	})
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	if len(config.Environments) != 2 {
+		t.Fatalf("expected 2 environments, got %d", len(config.Environments))
+	}
+	prod, staging := config.Environments[0], config.Environments[1]
+	if !equalEnvironments(prod, source) {
+		t.Errorf("source changed: %+v", prod)
+	}
+	if staging.Name != "staging" || staging.URL != source.URL || staging.APIKey != source.APIKey || staging.Model != source.Model || staging.Tags[0] != "team" {
+		t.Errorf("copy lost fields: %+v", staging)
+	}
+	if staging.EnvVars["ANTHROPIC_TIMEOUT"] != "90" || prod.EnvVars["ANTHROPIC_TIMEOUT"] != "60" {
+		t.Errorf("expected only the copy's env var to change, got %v and %v", staging.EnvVars, prod.EnvVars)
+	}
+	if staging.PreviousAPIKey != "" || staging.KeyRotatedAt != "" {
+		t.Errorf("rotation history should stay with the source: %+v", staging)
+	}
+
+	// Clones never share maps or slices with their source
+	clone := cloneEnvironment(source)
+	clone.EnvVars["ANTHROPIC_TIMEOUT"] = "1"
+	clone.Tags[0] = "other"
+	if source.EnvVars["ANTHROPIC_TIMEOUT"] != "60" || source.Tags[0] != "team" {
+		t.Error("cloneEnvironment shares data with the original")
+	}
+}
+
+func TestCopyPromptsForOverrides(t *testing.T) {
+	withTempConfigPath(t)
+	saveCopySource(t)
+	origTerminal, origSecret, origConfirm := stdinIsTerminal, secretReader, confirmationReader
+	defer func() { stdinIsTerminal, secretReader, confirmationReader = origTerminal, origSecret, origConfirm }()
+	stdinIsTerminal = func() bool { return true }
+	secretReader = func(string) (string, error) { return "sk-ant-REDACTED", nil }
+	confirmationReader = func(string) (string, error) { return "model=claude-opus-4-20250514 notes=qa", nil }
+
+	out := captureStdout(t, func() {
+		if err := runCopy([]string{"prod", "staging"}); err != nil {
+			t.Fatalf("copy failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "with new api_key, model, notes") {
+		t.Errorf("expected the changed fields in the message, got %q", out)
+	}
+	config, _ := loadConfig()
+	staging := config.Environments[1]
+	if staging.APIKey != "sk-ant-REDACTED" || staging.Model != "claude-opus-4-20250514" || staging.Notes != "qa" {
+		t.Errorf("overrides not applied: %+v", staging)
+	}
+}
+
+func TestCopyRejectsBadTargets(t *testing.T) {
+	withTempConfigPath(t)
+	saveCopySource(t)
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"prod", "prod"}, "already exists"},
+		{[]string{"missing", "staging"}, "not found"},
+		{[]string{"prod", "bad name"}, "invalid name"},
+		{[]string{"prod", "staging", "api_key=sk-ant-api03-x-1234567890"}, "api_key=-"},
+		{[]string{"prod"}, "requires a source"},
+	} {
+		if err := runCopy(tc.args); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected %q, got %v", tc.args, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// currentMarkerName is the runtime file recording the most recently launched environment
+const currentMarkerName = "cce-current"
+
+// currentMarkerPath is $XDG_RUNTIME_DIR/cce-current, or cce-current in the config
+// directory when no runtime directory is set
+func currentMarkerPath() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, currentMarkerName), nil
+	}
+	configPath, err := getConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), currentMarkerName), nil
+}
+
+// writeCurrentMarker records name as the active environment of process pid. The first
+// line is the name alone so prompt scripts can read the file without running cce.
+func writeCurrentMarker(name string, pid int) error {
+	path, err := currentMarkerPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create marker directory: %w", err)
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".cce-current-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create marker: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := fmt.Fprintf(tmp, "%s\n%d\n", name, pid); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write marker: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write marker: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// clearCurrentMarker removes the marker if it still belongs to pid, so a newer launch
+// from another terminal is left in place
+func clearCurrentMarker(pid int) {
+	path, err := currentMarkerPath()
+	if err != nil {
+		return
+	}
+	if _, owner, err := readCurrentMarker(path); err == nil && owner == pid {
+		os.Remove(path)
+	}
+}
+
+// readCurrentMarker parses the marker into the environment name and owning process
+func readCurrentMarker(path string) (string, int, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", 0, err
+	}
+	lines := strings.SplitN(strings.TrimSpace(string(data)), "\n", 2)
+	if len(lines) != 2 {
+		return "", 0, fmt.Errorf("malformed marker %s", path)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[1]))
+	if err != nil || pid <= 0 {
+		return "", 0, fmt.Errorf("malformed marker %s", path)
+	}
+	return strings.TrimSpace(lines[0]), pid, nil
+}
+
+// currentEnvironment returns the environment of the most recent launch that is still
+// running. claude replaces the cce process on launch, so nothing can clear the marker
+// when it exits; a marker whose process is gone is removed here instead.
+func currentEnvironment() (string, bool, error) {
+	path, err := currentMarkerPath()
+	if err != nil {
+		return "", false, err
+	}
+	name, pid, err := readCurrentMarker(path)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil || !processAlive(pid) {
+		os.Remove(path)
+		return "", false, nil
+	}
+	return name, true, nil
+}
+
+// runCurrent prints the active environment name for shell prompts; it fails quietly
+// enough for $(cce current 2>/dev/null) when nothing is running
+func runCurrent(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("argument parsing failed: current takes no arguments")
+	}
+	name, ok, err := currentEnvironment()
+	if err != nil {
+		return fmt.Errorf("failed to read current environment: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("nothing launched by cce is running")
+	}
+	if _, err := fmt.Println(name); err != nil {
+		return fmt.Errorf("failed to display current environment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCurrentMarkerRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	if _, ok, err := currentEnvironment(); ok || err != nil {
+		t.Fatalf("no marker should mean no current environment, got %v, %v", ok, err)
+	}
+
+	if err := writeCurrentMarker("prod", os.Getpid()); err != nil {
+		t.Fatalf("writeCurrentMarker() failed: %v", err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, currentMarkerName))
+	if err != nil || strings.SplitN(string(data), "\n", 2)[0] != "prod" {
+		t.Fatalf("marker should start with the name, got %q (%v)", data, err)
+	}
+	info, _ := os.Stat(filepath.Join(dir, currentMarkerName))
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("marker mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	out := captureStdout(t, func() {
+		if err := runCurrent(nil); err != nil {
+			t.Fatalf("runCurrent() failed: %v", err)
+		}
+	})
+	if out != "prod\n" {
+		t.Errorf("runCurrent() printed %q", out)
+	}
+
+	// Another process's marker is left alone
+	clearCurrentMarker(os.Getpid() + 1)
+	if _, ok, _ := currentEnvironment(); !ok {
+		t.Error("clearing with a different pid should keep the marker")
+	}
+	clearCurrentMarker(os.Getpid())
+	if _, ok, _ := currentEnvironment(); ok {
+		t.Error("marker should be gone after clearing")
+	}
+	if err := runCurrent(nil); err == nil {
+		t.Error("runCurrent() should fail with nothing running")
+	}
+}
+
+func TestCurrentMarkerStaleProcess(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot run true: %v", err)
+	}
+	if err := writeCurrentMarker("staging", cmd.Process.Pid); err != nil {
+		t.Fatalf("writeCurrentMarker() failed: %v", err)
+	}
+
+	if _, ok, err := currentEnvironment(); ok || err != nil {
+		t.Fatalf("marker of an exited process should be ignored, got %v, %v", ok, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, currentMarkerName)); !os.IsNotExist(err) {
+		t.Errorf("stale marker should be removed, stat err = %v", err)
+	}
+}
+
+func TestCurrentMarkerFallsBackToConfigDir(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	path := withTempConfigPath(t)
+	got, err := currentMarkerPath()
+	if err != nil || got != filepath.Join(filepath.Dir(path), currentMarkerName) {
+		t.Errorf("currentMarkerPath() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "syscall"
+
+// processAlive reports whether pid still exists; EPERM means it does but belongs to someone else
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
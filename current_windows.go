@@ -0,0 +1,25 @@
+//go:build windows
+
+package main
+
+import "syscall"
+
+// stillActive is the exit code Windows reports for a process that has not exited (STILL_ACTIVE)
+const stillActive = 259
+
+// processAlive reports whether pid still exists. A process that cannot be opened at all is
+// gone; one that can be opened is alive until it reports an exit code. Access denied means
+// it exists but belongs to someone else.
+func processAlive(pid int) bool {
+	handle, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return err == syscall.ERROR_ACCESS_DENIED
+	}
+	defer syscall.CloseHandle(handle)
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(handle, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// defaultEnvironment returns the stored default environment. A default that no longer
+// names an environment is reported with a warning and ignored.
+func defaultEnvironment(config Config) (Environment, bool) {
+	if config.DefaultEnv == "" {
+		return Environment{}, false
+	}
+	index, err := lookupEnvironment(config, config.DefaultEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: default environment '%s' no longer exists; choose one below or run 'cce default --clear'\n", config.DefaultEnv)
+		return Environment{}, false
+	}
+	return config.Environments[index], true
+}
+
+// launchDefault returns the default environment for a launch that names none. On a
+// terminal the picker is shown instead, and select --launch always asks; elsewhere
+// (scripts, pipes) the default is launched without asking.
+func launchDefault(config Config, opts launchOptions) (Environment, bool) {
+	if opts.Pick || stdinIsTerminal() {
+		return Environment{}, false
+	}
+	return defaultEnvironment(config)
+}
+
+// runDefaultCommand shows, sets (cce default <name>) or clears (--clear) the environment used
+// when cce is launched without --env
+func runDefaultCommand(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("argument parsing failed: default takes one environment name or --clear")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+
+	if len(args) == 0 {
+		message := "No default environment; cce without --env asks which to use."
+		if config.DefaultEnv != "" {
+			message = fmt.Sprintf("Default environment: %s", config.DefaultEnv)
+			if _, found := findEnvironmentByName(config, config.DefaultEnv); !found {
+				message += " (no longer exists)"
+			}
+		}
+		if _, err := fmt.Println(message); err != nil {
+			return fmt.Errorf("failed to display message: %w", err)
+		}
+		return nil
+	}
+
+	message := "Default environment cleared."
+	if args[0] == "--clear" {
+		config.DefaultEnv = ""
+	} else {
+		index, err := lookupEnvironment(config, args[0])
+		if err != nil {
+			return err
+		}
+		config.DefaultEnv = config.Environments[index].Name
+		message = fmt.Sprintf("Default environment set to '%s'.", config.DefaultEnv)
+	}
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	if _, err := fmt.Println(message); err != nil {
+		return fmt.Errorf("failed to display success message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestDefaultModelAppliesToEnvironmentsWithoutOne(t *testing.T) {
+	path := withTempConfigPath(t)
+	t.Cleanup(func() { configDefaultModel = "" })
+
+	content := `{
+  "environments": [
+    {"name": "inherits", "url": "https://api.anthropic.com", "api_key": "sk-ant-inherit-key"},
+    {"name": "own", "url": "https://api.anthropic.com", "api_key": "sk-ant-own-key-123", "model": "claude-3-haiku-20240307"},
+    {"name": "envvar", "url": "https://api.anthropic.com", "api_key": "sk-ant-envvar-key", "env_vars": {"ANTHROPIC_MODEL": "claude-opus-4-20250514"}}
+  ],
+  "settings": {"default_model": "claude-3-5-sonnet-20241022"}
+}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+
+	want := map[string]modelResolution{
+		"inherits": {Model: "claude-3-5-sonnet-20241022", Source: modelSourceConfig},
+		"own":      {Model: "claude-3-haiku-20240307", Source: modelSourceEnvironment},
+		"envvar":   {Model: "claude-opus-4-20250514", Source: modelSourceEnvVars},
+	}
+	for _, env := range config.Environments {
+		if got := resolveModel(env, ""); got != want[env.Name] {
+			t.Errorf("%s: resolveModel() = %+v, want %+v", env.Name, got, want[env.Name])
+		}
+	}
+	if got := resolveModel(config.Environments[0], "claude-override"); got.Source != modelSourceOverride {
+		t.Errorf("override should beat the default model, got %+v", got)
+	}
+
+	vars, err := prepareEnvironment(config.Environments[0])
+	if err != nil {
+		t.Fatalf("prepareEnvironment() failed: %v", err)
+	}
+	found := false
+	for _, kv := range vars {
+		if kv == "ANTHROPIC_MODEL=claude-3-5-sonnet-20241022" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected default model to be injected, got %v", vars)
+	}
+
+	out := captureStdout(t, func() {
+		if err := displayEnvironments(config); err != nil {
+			t.Fatalf("displayEnvironments() failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Model: claude-3-5-sonnet-20241022 (inherits default)") {
+		t.Errorf("expected inherited model in list output:\n%s", out)
+	}
+	if strings.Count(out, "(inherits default)") != 1 {
+		t.Errorf("only environments without a model should inherit:\n%s", out)
+	}
+}
+
+func TestDefaultModelValidatedAtLoad(t *testing.T) {
+	path := withTempConfigPath(t)
+	t.Cleanup(func() { configDefaultModel = "" })
+
+	content := `{"environments": [], "settings": {"default_model": "bad;model"}}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "default_model") {
+		t.Errorf("expected invalid default_model to fail loading, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultEnvironmentIsLaunchedWithoutEnv(t *testing.T) {
+	withTempConfigPath(t)
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+		{Name: "dev", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	origSelector := environmentSelector
+	defer func() { environmentSelector = origSelector }()
+	selected := 0
+	environmentSelector = func(config Config) (Environment, error) {
+		selected++
+		return config.Environments[0], nil
+	}
+
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"default", "dev"}); err != nil {
+			t.Fatalf("default failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Default environment set to 'dev'.") {
+		t.Errorf("unexpected output: %q", out)
+	}
+
+	env, err := resolveLaunchEnvironment("", launchOptions{})
+	if err != nil || env.Name != "dev" || selected != 0 {
+		t.Errorf("expected dev without asking, got %q (selector called %d times, err %v)", env.Name, selected, err)
+	}
+	if env, _ := resolveLaunchEnvironment("prod", launchOptions{}); env.Name != "prod" {
+		t.Errorf("--env should win over the default, got %q", env.Name)
+	}
+
+	list := captureStdout(t, func() {
+		if err := runListWithOptions(listOptions{}); err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+	})
+	if !strings.Contains(list, "Name:  dev *") || strings.Contains(list, "prod *") {
+		t.Errorf("expected only dev marked as default:\n%s", list)
+	}
+
+	// Renaming the default keeps it the default
+	captureStdout(t, func() {
+		if err := runRename([]string{"dev", "qa"}); err != nil {
+			t.Fatalf("rename failed: %v", err)
+		}
+	})
+	if config, _ := loadConfig(); config.DefaultEnv != "qa" {
+		t.Errorf("expected the default to follow the rename, got %q", config.DefaultEnv)
+	}
+}
+
+func TestMissingDefaultFallsBackToSelection(t *testing.T) {
+	withTempConfigPath(t)
+	config := Config{
+		DefaultEnv:   "gone",
+		Environments: []Environment{{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}},
+	}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	origSelector := environmentSelector
+	defer func() { environmentSelector = origSelector }()
+	environmentSelector = func(config Config) (Environment, error) { return config.Environments[0], nil }
+
+	var env Environment
+	_, stderr, err := captureStdoutAndStderr(t, func() error {
+		var err error
+		env, err = resolveLaunchEnvironment("", launchOptions{})
+		return err
+	})
+	if err != nil || env.Name != "prod" {
+		t.Fatalf("expected the selector's choice, got %q (%v)", env.Name, err)
+	}
+	if !strings.Contains(stderr, "default environment 'gone' no longer exists") {
+		t.Errorf("expected a warning, got %q", stderr)
+	}
+
+	if err := runDefaultCommand([]string{"gone"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected setting a missing default to fail, got %v", err)
+	}
+	captureStdout(t, func() {
+		if err := runDefaultCommand([]string{"--clear"}); err != nil {
+			t.Fatalf("clear failed: %v", err)
+		}
+	})
+	if config, _ := loadConfig(); config.DefaultEnv != "" {
+		t.Errorf("expected the default to be cleared, got %q", config.DefaultEnv)
+	}
+}
+
+func TestDefaultIsSkippedOnTerminalAndBySelect(t *testing.T) {
+	withTempConfigPath(t)
+	if err := saveConfig(Config{DefaultEnv: "dev", Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+		{Name: "dev", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	origSelector := environmentSelector
+	defer func() { environmentSelector = origSelector }()
+	selected := 0
+	environmentSelector = func(config Config) (Environment, error) {
+		selected++
+		return config.Environments[0], nil
+	}
+	origLauncher := claudeLauncher
+	defer func() { claudeLauncher = origLauncher }()
+	var launched string
+	claudeLauncher = func(env Environment, _ []string, _ string) error {
+		launched = env.Name
+		return nil
+	}
+
+	// select --launch always asks, even without a terminal
+	captureStdout(t, func() {
+		if err := runSelect(selectOptions{Launch: true}); err != nil {
+			t.Fatalf("select --launch failed: %v", err)
+		}
+	})
+	if selected != 1 || launched != "prod" {
+		t.Errorf("expected the picker's prod, got %q (selector called %d times)", launched, selected)
+	}
+
+	// On a terminal a bare cce asks too; the default is for scripts and pipes
+	origTerminal := stdinIsTerminal
+	defer func() { stdinIsTerminal = origTerminal }()
+	stdinIsTerminal = func() bool { return true }
+	if env, err := resolveLaunchEnvironment("", launchOptions{}); err != nil || env.Name != "prod" || selected != 2 {
+		t.Errorf("expected the picker on a terminal, got %q (selector called %d times, err %v)", env.Name, selected, err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateDetach(t *testing.T) {
+	if err := validateDetach(nil, true); err == nil {
+		t.Error("interactive session from a terminal should reject --detach")
+	}
+	if err := validateDetach([]string{"-p", "summarize"}, true); err != nil {
+		t.Errorf("print mode should allow --detach: %v", err)
+	}
+	if err := validateDetach([]string{"--print"}, true); err != nil {
+		t.Errorf("--print should allow --detach: %v", err)
+	}
+	if err := validateDetach(nil, false); err != nil {
+		t.Errorf("no terminal should allow --detach: %v", err)
+	}
+}
+
+func TestParseArgumentsDetachAndWait(t *testing.T) {
+	result := parseArguments([]string{"--detach", "-e", "prod", "-p", "hello"})
+	if result.CCEFlags["detach"] != "true" || result.CCEFlags["env"] != "prod" {
+		t.Errorf("unexpected flags: %+v", result.CCEFlags)
+	}
+	if strings.Join(result.ClaudeArgs, " ") != "-p hello" {
+		t.Errorf("--detach should not reach claude: %v", result.ClaudeArgs)
+	}
+
+	result = parseArguments([]string{"--wait", "chat"})
+	if result.CCEFlags["wait"] != "true" || strings.Join(result.ClaudeArgs, " ") != "chat" {
+		t.Errorf("unexpected parse for --wait: %+v", result)
+	}
+
+	result = parseArguments([]string{"-e", "prod", "chat", "--wait", "--detach"})
+	if result.CCEFlags["wait"] != "" || result.CCEFlags["detach"] != "" || strings.Join(result.ClaudeArgs, " ") != "chat --wait --detach" {
+		t.Errorf("claude's flags after its arguments were consumed: %+v", result)
+	}
+
+	if err := handleCommand([]string{"--detach", "--wait"}); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+		t.Errorf("expected --detach --wait to be rejected, got %v", err)
+	}
+}
+
+func TestDetachUsesBackgroundLauncher(t *testing.T) {
+	withTempConfigPath(t)
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-prod-key-123"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	origDetached, origLauncher, origTerminal := detachedLauncher, claudeLauncher, stdinIsTerminal
+	defer func() { detachedLauncher, claudeLauncher, stdinIsTerminal = origDetached, origLauncher, origTerminal }()
+
+	var detachedArgs []string
+	detachedLauncher = func(e Environment, args []string, workdir string) error {
+		detachedArgs = args
+		return nil
+	}
+	claudeLauncher = func(e Environment, args []string, workdir string) error {
+		t.Error("foreground launcher should not be used with --detach")
+		return nil
+	}
+	stdinIsTerminal = func() bool { return true }
+
+	captureStdout(t, func() {
+		if err := handleCommand([]string{"--detach", "-e", "prod", "-p", "hello"}); err != nil {
+			t.Fatalf("detached launch failed: %v", err)
+		}
+	})
+	if strings.Join(detachedArgs, " ") != "-p hello" {
+		t.Errorf("unexpected detached args: %v", detachedArgs)
+	}
+
+	err := handleCommand([]string{"--detach", "-e", "prod"})
+	if err == nil || !strings.Contains(err.Error(), "argument validation failed") {
+		t.Errorf("expected interactive detach to be rejected, got %v", err)
+	}
+}
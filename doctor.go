@@ -0,0 +1,417 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// doctorOptions holds flags accepted by the doctor subcommand
+type doctorOptions struct {
+	Fix       bool // Offer to repair what the checks find
+	AssumeYes bool // Apply fixes without asking
+}
+
+// doctorFix is a repair for a failed check. Describe says what will change before asking;
+// apply performs it and reports what changed.
+type doctorFix struct {
+	Describe string
+	apply    func() (string, error)
+}
+
+// doctorFinding is the outcome of one diagnostic check
+type doctorFinding struct {
+	OK         bool
+	Warning    bool // Worth a look, but not a failure: reported without affecting the exit code
+	Detail     string
+	Suggestion string
+	Fix        *doctorFix // nil when the problem cannot be repaired automatically
+}
+
+// doctorCheck is one diagnostic; detection and its fix live together so --fix
+// never repairs anything the plain checklist did not report
+type doctorCheck struct {
+	Name string
+	run  func() doctorFinding
+}
+
+// doctorChecks lists the diagnostics in the order they are reported
+func doctorChecks() []doctorCheck {
+	return []doctorCheck{
+		{Name: "Configuration file", run: checkConfigFile},
+		{Name: "Configuration permissions", run: checkConfigPermissions},
+		{Name: "Environment URLs", run: checkEnvironmentURLs},
+		{Name: "Default environment", run: checkDefaultEnvironment},
+		{Name: "Claude binary", run: checkClaudeBinary},
+		{Name: "Stale worktrees", run: checkStaleWorktrees},
+		{Name: "Claude settings.json", run: checkSettingsConflicts},
+		{Name: "API key variables", run: checkKeyStyles},
+	}
+}
+
+// parseDoctorOptions parses flags following the doctor subcommand
+func parseDoctorOptions(args []string) (doctorOptions, error) {
+	var opts doctorOptions
+	for _, arg := range args {
+		switch arg {
+		case "--fix":
+			opts.Fix = true
+		case "--yes", "-y":
+			opts.AssumeYes = true
+		default:
+			return doctorOptions{}, fmt.Errorf("unknown doctor flag: %s", arg)
+		}
+	}
+	if opts.AssumeYes && !opts.Fix {
+		return doctorOptions{}, fmt.Errorf("--yes requires --fix")
+	}
+	return opts, nil
+}
+
+// runDoctor prints a checklist and, with --fix, repairs what it can after confirmation
+func runDoctor(args []string) error {
+	opts, err := parseDoctorOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	if assumeYes && opts.Fix {
+		opts.AssumeYes = true
+	}
+
+	if opts.Fix && !opts.AssumeYes && noPrompt {
+		return promptRefused("doctor --fix", "pass --yes to apply every fix")
+	}
+	if opts.Fix && !opts.AssumeYes && !stdinIsTerminal() {
+		return fmt.Errorf("argument validation failed: doctor --fix needs a terminal to confirm each fix; pass --yes to apply them all")
+	}
+
+	failed := 0
+	for _, check := range doctorChecks() {
+		finding := check.run()
+		if finding.OK {
+			fmt.Printf("[ok]   %s: %s\n", check.Name, finding.Detail)
+			continue
+		}
+
+		if finding.Warning {
+			fmt.Printf("[warn] %s: %s\n", check.Name, finding.Detail)
+			if finding.Suggestion != "" {
+				fmt.Printf("       → %s\n", finding.Suggestion)
+			}
+			continue
+		}
+
+		fmt.Printf("[fail] %s: %s\n", check.Name, finding.Detail)
+		if !opts.Fix || finding.Fix == nil {
+			failed++
+			if finding.Suggestion != "" {
+				fmt.Printf("       → %s\n", finding.Suggestion)
+			}
+			continue
+		}
+
+		if opts.AssumeYes {
+			noteAutoConfirmed(finding.Fix.Describe)
+		} else {
+			answer, err := confirmationReader(fmt.Sprintf("       %s? [y/N]: ", finding.Fix.Describe))
+			if err != nil || !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+				fmt.Println("       skipped")
+				failed++
+				continue
+			}
+		}
+		report, err := finding.Fix.apply()
+		if err != nil {
+			fmt.Printf("       fix failed: %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("       fixed: %s\n", report)
+	}
+
+	if failed > 0 {
+		errorCtx := newErrorContext("configuration diagnosis", "doctor")
+		if !opts.Fix {
+			errorCtx.addSuggestion("Run 'cce doctor --fix' to repair what can be repaired automatically")
+		}
+		return errorCtx.formatError(fmt.Errorf("%d check(s) failed", failed))
+	}
+	return nil
+}
+
+// checkConfigFile verifies the config file parses and validates
+func checkConfigFile() doctorFinding {
+	path, err := getConfigPath()
+	if err != nil {
+		return doctorFinding{Detail: err.Error()}
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return doctorFinding{OK: true, Detail: fmt.Sprintf("%s does not exist yet", path)}
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return doctorFinding{Detail: firstLine(err.Error()), Suggestion: "Fix the file by hand or restore a backup from the backups directory"}
+	}
+	return doctorFinding{OK: true, Detail: fmt.Sprintf("%s (%d environments)", path, len(config.Environments))}
+}
+
+// doctorClaudeCheck verifies the claude binary; tests replace it
+var doctorClaudeCheck = checkClaudeCodeExists
+
+// checkClaudeBinary verifies claude resolves on PATH and is executable
+func checkClaudeBinary() doctorFinding {
+	if err := doctorClaudeCheck(); err != nil {
+		return doctorFinding{
+			Detail:     firstLine(err.Error()),
+			Suggestion: "Install Claude Code (npm install -g @anthropic-ai/claude-code) and make sure 'claude --version' works in this shell",
+		}
+	}
+	path, err := exec.LookPath("claude")
+	if err != nil {
+		return doctorFinding{OK: true, Detail: "found"}
+	}
+	return doctorFinding{OK: true, Detail: path}
+}
+
+// checkEnvironmentURLs runs the strict URL check on every environment. URLs saved with
+// --no-validate are only a warning, since they were accepted on purpose.
+func checkEnvironmentURLs() doctorFinding {
+	config, err := loadConfig()
+	if err != nil {
+		return doctorFinding{OK: true, Detail: "skipped (configuration does not load)"}
+	}
+	var failed, relaxed []string
+	for _, env := range config.Environments {
+		if env.URL == "" && isCloudProvider(env) {
+			continue
+		}
+		if err := validateURL(env.URL); err != nil {
+			problem := fmt.Sprintf("%s (%s)", env.Name, firstLine(err.Error()))
+			if isUnvalidated(env, fieldURL) {
+				relaxed = append(relaxed, problem)
+			} else {
+				failed = append(failed, problem)
+			}
+		}
+	}
+	switch {
+	case len(failed) > 0:
+		return doctorFinding{
+			Detail:     strings.Join(failed, "; "),
+			Suggestion: "Fix the URL with 'cce set <name> url=https://...'",
+		}
+	case len(relaxed) > 0:
+		return doctorFinding{
+			Warning:    true,
+			Detail:     fmt.Sprintf("saved with --no-validate: %s", strings.Join(relaxed, "; ")),
+			Suggestion: "Check these endpoints with 'cce test <name>'",
+		}
+	}
+	return doctorFinding{OK: true, Detail: fmt.Sprintf("%d valid", len(config.Environments))}
+}
+
+// checkDefaultEnvironment verifies default_env still names an environment; otherwise
+// launches without --env outside a terminal have nothing to fall back on
+func checkDefaultEnvironment() doctorFinding {
+	config, err := loadConfig()
+	if err != nil {
+		return doctorFinding{OK: true, Detail: "skipped (configuration does not load)"}
+	}
+	if config.DefaultEnv == "" {
+		return doctorFinding{OK: true, Detail: "none set"}
+	}
+	if _, err := lookupEnvironment(config, config.DefaultEnv); err == nil {
+		return doctorFinding{OK: true, Detail: config.DefaultEnv}
+	}
+	stale := config.DefaultEnv
+	return doctorFinding{
+		Detail:     fmt.Sprintf("default '%s' no longer exists", stale),
+		Suggestion: "Choose another with 'cce default <name>' or clear it with 'cce default --clear'",
+		Fix: &doctorFix{
+			Describe: fmt.Sprintf("Clear the default '%s'", stale),
+			apply: func() (string, error) {
+				config.DefaultEnv = ""
+				if err := saveConfig(config); err != nil {
+					return "", fmt.Errorf("failed to save configuration: %w", err)
+				}
+				return fmt.Sprintf("cleared default '%s'", stale), nil
+			},
+		},
+	}
+}
+
+// configModeProblem is a config path whose permissions differ from the owner-only mode
+type configModeProblem struct {
+	path string
+	have os.FileMode
+	want os.FileMode
+}
+
+// loose reports whether the path grants access beyond the expected mode, rather than less
+func (p configModeProblem) loose() bool {
+	return p.have&^p.want != 0
+}
+
+// configPermissionProblems compares the config dir with 0700 and the config file with 0600;
+// paths that do not exist yet are skipped
+func configPermissionProblems(path string) ([]configModeProblem, error) {
+	var wrong []configModeProblem
+	for _, target := range []struct {
+		path string
+		want os.FileMode
+	}{{filepath.Dir(path), 0700}, {path, 0600}} {
+		info, err := os.Stat(target.path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if info.Mode().Perm() != target.want {
+			wrong = append(wrong, configModeProblem{target.path, info.Mode().Perm(), target.want})
+		}
+	}
+	return wrong, nil
+}
+
+// checkConfigPermissions expects 0700 on the config dir and 0600 on the config file
+func checkConfigPermissions() doctorFinding {
+	path, err := getConfigPath()
+	if err != nil {
+		return doctorFinding{Detail: err.Error()}
+	}
+
+	wrong, err := configPermissionProblems(path)
+	if err != nil {
+		return doctorFinding{Detail: err.Error()}
+	}
+
+	if len(wrong) == 0 {
+		return doctorFinding{OK: true, Detail: "owner-only access"}
+	}
+
+	details := make([]string, len(wrong))
+	for i, w := range wrong {
+		details[i] = fmt.Sprintf("%s is %04o, want %04o", w.path, w.have, w.want)
+	}
+	return doctorFinding{
+		Detail:     strings.Join(details, "; "),
+		Suggestion: "Restrict access so other users cannot read your API keys",
+		Fix: &doctorFix{
+			Describe: "Restrict permissions to owner-only",
+			apply: func() (string, error) {
+				var changed []string
+				for _, w := range wrong {
+					if err := os.Chmod(w.path, w.want); err != nil {
+						return "", fmt.Errorf("failed to chmod %s: %w", w.path, err)
+					}
+					changed = append(changed, fmt.Sprintf("%s → %04o", w.path, w.want))
+				}
+				return strings.Join(changed, ", "), nil
+			},
+		},
+	}
+}
+
+// checkStaleWorktrees finds --wk worktrees in the current repository whose directory was deleted
+func checkStaleWorktrees() doctorFinding {
+	wm := NewWorktreeManager(workDir)
+	if err := wm.detectGitRepo(); err != nil {
+		return doctorFinding{OK: true, Detail: "not in a git repository"}
+	}
+	stale, err := wm.staleWorktrees()
+	if err != nil {
+		return doctorFinding{Detail: firstLine(err.Error())}
+	}
+	if len(stale) == 0 {
+		return doctorFinding{OK: true, Detail: "none"}
+	}
+
+	paths := make([]string, len(stale))
+	for i, entry := range stale {
+		paths[i] = entry.Path
+	}
+	return doctorFinding{
+		Detail:     fmt.Sprintf("%d worktree(s) no longer on disk: %s", len(stale), strings.Join(paths, ", ")),
+		Suggestion: "Remove them with 'git worktree remove --force <path>'",
+		Fix: &doctorFix{
+			Describe: fmt.Sprintf("Unregister %d stale worktree(s), keeping their branches", len(stale)),
+			apply: func() (string, error) {
+				for _, path := range paths {
+					if err := wm.removeWorktree(path); err != nil {
+						return "", err
+					}
+				}
+				return "removed " + strings.Join(paths, ", "), nil
+			},
+		},
+	}
+}
+
+// checkSettingsConflicts finds keys in ~/.claude/settings.json that override what cce sets
+func checkSettingsConflicts() doctorFinding {
+	settingsEnv, path, err := loadClaudeSettingsEnv()
+	if err != nil {
+		return doctorFinding{Detail: firstLine(err.Error())}
+	}
+	if settingsEnv == nil {
+		return doctorFinding{OK: true, Detail: "no env block"}
+	}
+
+	// Any variable some environment injects counts, not just ANTHROPIC_*
+	var assignments []envAssignment
+	if config, err := loadConfig(); err == nil {
+		for _, env := range config.Environments {
+			assignments = append(assignments, launchVariables(env)...)
+		}
+	}
+	conflicts := detectSettingsConflicts(assignments, settingsEnv)
+	if len(conflicts) == 0 {
+		return doctorFinding{OK: true, Detail: path}
+	}
+
+	return doctorFinding{
+		Detail:     fmt.Sprintf("%s overrides %s", path, strings.Join(conflicts, ", ")),
+		Suggestion: "Remove those keys from the env block so environment switching takes effect",
+		Fix: &doctorFix{
+			Describe: fmt.Sprintf("Delete %s from %s (a .bak copy is kept)", strings.Join(conflicts, ", "), path),
+			apply: func() (string, error) {
+				backup, err := removeSettingsEnvKeys(path, conflicts)
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("removed %s (backup at %s)", strings.Join(conflicts, ", "), backup), nil
+			},
+		},
+	}
+}
+
+// checkKeyStyles flags environments whose key prefix suggests a different key variable.
+// Proxies vary, so this is a warning rather than a failure.
+func checkKeyStyles() doctorFinding {
+	config, err := loadConfig()
+	if err != nil {
+		return doctorFinding{OK: true, Detail: "skipped (configuration does not load)"}
+	}
+	var mismatched []string
+	for _, env := range config.Environments {
+		if keyStyleWarning(env) != "" {
+			mismatched = append(mismatched, env.Name)
+		}
+	}
+	if len(mismatched) == 0 {
+		return doctorFinding{OK: true, Detail: "keys match their variables"}
+	}
+	return doctorFinding{
+		Warning:    true,
+		Detail:     fmt.Sprintf("key prefix suggests a different variable for %s", strings.Join(mismatched, ", ")),
+		Suggestion: "sk-ant-api keys go in ANTHROPIC_API_KEY, sk-ant-oat tokens in ANTHROPIC_AUTH_TOKEN; change with 'cce set <name> key_var=...' if authentication fails",
+	}
+}
+
+// removeSettingsEnvKeys deletes keys from the env block of the settings.json at path,
+// leaving every other setting untouched, and returns the path of the backup it made
+func removeSettingsEnvKeys(path string, keys []string) (string, error) {
+	return updateSettingsEnv(path, nil, keys)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDoctorOptions(t *testing.T) {
+	if opts, err := parseDoctorOptions([]string{"--fix", "-y"}); err != nil || !opts.Fix || !opts.AssumeYes {
+		t.Errorf("parseDoctorOptions(--fix -y) = %+v, %v", opts, err)
+	}
+	if _, err := parseDoctorOptions([]string{"--yes"}); err == nil {
+		t.Error("--yes without --fix should be rejected")
+	}
+	if _, err := parseDoctorOptions([]string{"--bogus"}); err == nil {
+		t.Error("unknown flag should be rejected")
+	}
+}
+
+// withDoctorClaude stubs the claude binary check; nil means claude is installed
+func withDoctorClaude(t *testing.T, err error) {
+	t.Helper()
+	original := doctorClaudeCheck
+	doctorClaudeCheck = func() error { return err }
+	t.Cleanup(func() { doctorClaudeCheck = original })
+}
+
+func TestDoctorReportsWithoutFixing(t *testing.T) {
+	path := withTempConfigPath(t)
+	withClaudeSettings(t, "")
+	withDoctorClaude(t, nil)
+	if err := ioutil.WriteFile(path, []byte(`{"environments": []}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() { err = runDoctor(nil) })
+	if err == nil || !strings.Contains(err.Error(), "1 check(s) failed") {
+		t.Fatalf("expected one failed check, got %v", err)
+	}
+	if !strings.Contains(out, "[fail] Configuration permissions") || !strings.Contains(out, "want 0600") {
+		t.Errorf("expected permission failure in output:\n%s", out)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0644 {
+		t.Errorf("doctor without --fix changed permissions to %04o", info.Mode().Perm())
+	}
+}
+
+func TestDoctorFixRepairsPermissionsAndSettings(t *testing.T) {
+	path := withTempConfigPath(t)
+	withDoctorClaude(t, nil)
+	config := `{"environments": [{"name": "prod", "url": "https://api.anthropic.com", "api_key": "sk-ant-api03-test-key-123"}]}`
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.Chmod(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to chmod config dir: %v", err)
+	}
+	withClaudeSettings(t, `{"model": "opus", "env": {"ANTHROPIC_BASE_URL": "https://stale.example.com", "DISABLE_TELEMETRY": "1"}}`)
+	settingsPath, _ := claudeSettingsPath()
+
+	out := captureStdout(t, func() {
+		if err := runDoctor([]string{"--fix", "--yes"}); err != nil {
+			t.Fatalf("runDoctor(--fix --yes) failed: %v", err)
+		}
+	})
+	if strings.Count(out, "fixed:") != 2 {
+		t.Errorf("expected two fixes reported, got:\n%s", out)
+	}
+
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("config file mode = %04o, want 0600", info.Mode().Perm())
+	}
+	if info, _ := os.Stat(filepath.Dir(path)); info.Mode().Perm() != 0700 {
+		t.Errorf("config dir mode = %04o, want 0700", info.Mode().Perm())
+	}
+
+	data, err := ioutil.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("failed to read settings: %v", err)
+	}
+	var settings struct {
+		Model string            `json:"model"`
+		Env   map[string]string `json:"env"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("settings no longer parse: %v", err)
+	}
+	if _, ok := settings.Env["ANTHROPIC_BASE_URL"]; ok {
+		t.Error("conflicting key should have been removed")
+	}
+	if settings.Env["DISABLE_TELEMETRY"] != "1" || settings.Model != "opus" {
+		t.Errorf("unrelated settings were lost: %s", data)
+	}
+	if _, err := os.Stat(settingsPath + ".bak"); err != nil {
+		t.Errorf("expected settings backup: %v", err)
+	}
+}
+
+func TestDoctorClearsStaleDefault(t *testing.T) {
+	withTempConfigPath(t)
+	withClaudeSettings(t, "")
+	withDoctorClaude(t, nil)
+	if err := saveConfig(Config{DefaultEnv: "gone", Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-test-key-123"},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() { err = runDoctor(nil) })
+	if err == nil || !strings.Contains(out, "[fail] Default environment") || !strings.Contains(out, "'gone' no longer exists") {
+		t.Fatalf("expected a stale default failure, got %v:\n%s", err, out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := runDoctor([]string{"--fix", "--yes"}); err != nil {
+			t.Fatalf("runDoctor(--fix --yes) failed: %v", err)
+		}
+	})
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	if config.DefaultEnv != "" || len(config.Environments) != 1 {
+		t.Errorf("expected only the default cleared, got %+v", config)
+	}
+	if !strings.Contains(out, "cleared default 'gone'") {
+		t.Errorf("expected the fix to be reported:\n%s", out)
+	}
+}
+
+func TestDoctorFixDeclined(t *testing.T) {
+	path := withTempConfigPath(t)
+	withClaudeSettings(t, "")
+	if err := ioutil.WriteFile(path, []byte(`{"environments": []}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	stubTerminal(t, true, "n")
+
+	var err error
+	out := captureStdout(t, func() { err = runDoctor([]string{"--fix"}) })
+	if err == nil {
+		t.Fatal("a declined fix should leave the check failing")
+	}
+	if !strings.Contains(out, "skipped") {
+		t.Errorf("expected skipped fix, got:\n%s", out)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0644 {
+		t.Errorf("declined fix changed permissions to %04o", info.Mode().Perm())
+	}
+}
+
+func TestDoctorFixNeedsTerminalOrYes(t *testing.T) {
+	withTempConfigPath(t)
+	stubTerminal(t, false, "")
+	if err := runDoctor([]string{"--fix"}); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("expected --yes hint without a terminal, got %v", err)
+	}
+}
+
+func TestDoctorChecksClaudeAndURLs(t *testing.T) {
+	withTempConfigPath(t)
+	withClaudeSettings(t, "")
+	withDoctorClaude(t, fmt.Errorf("claude not found in PATH"))
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-test-key-123"},
+		{Name: "lab", URL: "gateway.lab:8080", APIKey: "sk-ant-api03-test-key-456", Unvalidated: []string{fieldURL}},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() { err = runDoctor(nil) })
+	if err == nil || !strings.Contains(err.Error(), "1 check(s) failed") {
+		t.Fatalf("expected the missing claude to fail doctor, got %v", err)
+	}
+	if !strings.Contains(out, "[fail] Claude binary: claude not found in PATH") {
+		t.Errorf("expected a claude failure:\n%s", out)
+	}
+	if !strings.Contains(out, "[warn] Environment URLs: saved with --no-validate: lab") {
+		t.Errorf("expected a warning for the unvalidated URL:\n%s", out)
+	}
+}
+
+func TestStaleWorktrees(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.email=cce@example.com", "-c", "user.name=cce"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "init")
+
+	gone := filepath.Join(t.TempDir(), "gone")
+	kept := filepath.Join(t.TempDir(), "kept")
+	other := filepath.Join(t.TempDir(), "other")
+	git("worktree", "add", "-q", "-b", "repo-main-20240101-120000-000000001", gone)
+	git("worktree", "add", "-q", "-b", "repo-main-20240101-120000-000000002", kept)
+	git("worktree", "add", "-q", "-b", "feature", other)
+	os.RemoveAll(gone)
+	os.RemoveAll(other)
+
+	wm := NewWorktreeManager(repo)
+	stale, err := wm.staleWorktrees()
+	if err != nil {
+		t.Fatalf("staleWorktrees() failed: %v", err)
+	}
+	if len(stale) != 1 || !strings.HasSuffix(stale[0].Path, "gone") {
+		t.Fatalf("expected only the missing cce worktree, got %+v", stale)
+	}
+
+	if err := wm.removeWorktree(stale[0].Path); err != nil {
+		t.Fatalf("removeWorktree() failed: %v", err)
+	}
+	if stale, _ := wm.staleWorktrees(); len(stale) != 0 {
+		t.Errorf("expected no stale worktrees after removal, got %+v", stale)
+	}
+}
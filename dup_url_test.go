@@ -0,0 +1,103 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	original := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = original }()
+	fn()
+	w.Close()
+	out, _ := ioutil.ReadAll(r)
+	return string(out)
+}
+
+func TestEnvironmentsWithURL(t *testing.T) {
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com"},
+		{Name: "proxy", URL: "https://proxy.example.com/v1/"},
+		{Name: "prod-b", URL: "https://API.anthropic.com/"},
+	}}
+	if got := environmentsWithURL(config, "https://api.anthropic.com/"); strings.Join(got, ",") != "prod,prod-b" {
+		t.Errorf("environmentsWithURL() = %v", got)
+	}
+	if got := environmentsWithURL(config, "https://proxy.example.com/v1"); strings.Join(got, ",") != "proxy" {
+		t.Errorf("environmentsWithURL() = %v", got)
+	}
+	if got := environmentsWithURL(config, "https://other.example.com"); got != nil {
+		t.Errorf("expected no matches, got %v", got)
+	}
+}
+
+func TestAddDuplicateURLWarnsByDefault(t *testing.T) {
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-dup-url-one"},
+	}}
+	env := Environment{Name: "prod-alt", URL: "https://api.anthropic.com/", APIKey: "sk-ant-dup-url-two"}
+
+	stderr := captureStderr(t, func() {
+		if err := addEnvironmentToConfig(&config, env); err != nil {
+			t.Fatalf("duplicate URL should only warn, got %v", err)
+		}
+	})
+	if !strings.Contains(stderr, "Warning: URL 'https://api.anthropic.com/' is already used by prod") {
+		t.Errorf("expected duplicate URL warning, got %q", stderr)
+	}
+	if len(config.Environments) != 2 {
+		t.Errorf("environment should be added, got %d", len(config.Environments))
+	}
+}
+
+func TestAddDuplicateURLStrict(t *testing.T) {
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-dup-url-one"},
+	}}
+	env := Environment{Name: "prod-alt", URL: "https://api.anthropic.com", APIKey: "sk-ant-dup-url-two"}
+
+	err := addEnvironmentToConfigWithOptions(&config, env, false)
+	if err == nil || !strings.Contains(err.Error(), "already used by prod") {
+		t.Fatalf("expected duplicate URL error, got %v", err)
+	}
+	if len(config.Environments) != 1 {
+		t.Errorf("environment should not be added, got %d", len(config.Environments))
+	}
+
+	env.URL = "https://other.example.com"
+	if err := addEnvironmentToConfigWithOptions(&config, env, false); err != nil {
+		t.Errorf("distinct URL should be accepted in strict mode: %v", err)
+	}
+}
+
+func TestParseAddOptionsAllowDupURL(t *testing.T) {
+	tests := []struct {
+		args   []string
+		reject bool
+	}{
+		{nil, false},
+		{[]string{"--allow-dup-url"}, false},
+		{[]string{"--allow-dup-url=true"}, false},
+		{[]string{"--allow-dup-url=false"}, true},
+	}
+	for _, tt := range tests {
+		opts, err := parseAddOptions(tt.args)
+		if err != nil {
+			t.Fatalf("%v: unexpected error %v", tt.args, err)
+		}
+		if opts.RejectDupURL != tt.reject {
+			t.Errorf("%v: RejectDupURL = %v, want %v", tt.args, opts.RejectDupURL, tt.reject)
+		}
+	}
+	if _, err := parseAddOptions([]string{"--allow-dup-url=maybe"}); err == nil {
+		t.Error("expected error for invalid --allow-dup-url value")
+	}
+}
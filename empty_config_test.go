@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBareLaunchWithEmptyConfigExplainsHowToStart(t *testing.T) {
+	withTempConfigPath(t)
+	stubTerminal(t, false, "")
+
+	err := runDefaultWithOptions("", nil, launchOptions{})
+	if err == nil {
+		t.Fatal("expected an error for an empty config")
+	}
+	for _, want := range []string{"no environments configured", "cce add", "cce init"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %q, got: %v", want, err)
+		}
+	}
+	if got := categorizeError(err); got != "cce_config" {
+		t.Errorf("categorizeError() = %q, want cce_config", got)
+	}
+}
+
+func TestBareLaunchWithEmptyConfigDeclinedAdd(t *testing.T) {
+	withTempConfigPath(t)
+	stubTerminal(t, true, "n")
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runDefaultWithOptions("", nil, launchOptions{})
+	})
+	if err == nil || !strings.Contains(err.Error(), "no environments configured") {
+		t.Fatalf("expected empty-config error, got %v", err)
+	}
+	if !strings.Contains(out, "No environments configured yet.") {
+		t.Errorf("expected friendly notice, got:\n%s", out)
+	}
+}
+
+func TestBareLaunchWithEmptyConfigRunsAdd(t *testing.T) {
+	withTempConfigPath(t)
+	stubTerminal(t, true, "")
+	stubReview(t, true, "y")
+
+	origPrompter, origLauncher := environmentPrompter, claudeLauncher
+	t.Cleanup(func() {
+		environmentPrompter = origPrompter
+		claudeLauncher = origLauncher
+	})
+	environmentPrompter = func(Config) (Environment, error) {
+		return Environment{Name: "first", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-first-key-123"}, nil
+	}
+	var launched string
+	claudeLauncher = func(env Environment, args []string, workdir string) error {
+		launched = env.Name
+		return nil
+	}
+
+	captureStdout(t, func() {
+		if err := runDefaultWithOptions("", nil, launchOptions{}); err != nil {
+			t.Fatalf("runDefaultWithOptions() failed: %v", err)
+		}
+	})
+	if launched != "first" {
+		t.Errorf("expected the newly added environment to launch, got %q", launched)
+	}
+}
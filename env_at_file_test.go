@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveEnvFlag(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	named := write("env", "  staging  \r\nignored\n")
+	if got, err := resolveEnvFlag("@" + named); err != nil || got != "staging" {
+		t.Errorf("resolveEnvFlag(@file) = %q, %v; want staging", got, err)
+	}
+	if got, err := resolveEnvFlag("prod"); err != nil || got != "prod" {
+		t.Errorf("plain names should pass through, got %q, %v", got, err)
+	}
+	if got, err := resolveEnvFlag(""); err != nil || got != "" {
+		t.Errorf("empty value should pass through, got %q, %v", got, err)
+	}
+
+	errorCases := map[string]string{
+		"@":                                 "requires a file path",
+		"@" + filepath.Join(dir, "missing"): "failed to read",
+		"@" + write("empty", ""):            "does not start with",
+		"@" + write("blank", "   \nprod\n"): "does not start with",
+	}
+	for value, want := range errorCases {
+		if _, err := resolveEnvFlag(value); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("resolveEnvFlag(%q) error = %v, want it to mention %q", value, err, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiffEnvironments(t *testing.T) {
+	current := []string{
+		"PATH=/bin",
+		"ANTHROPIC_BASE_URL=https://old.example.com",
+		"ANTHROPIC_AUTH_TOKEN=sk-old-token-value-123",
+		"HTTPS_PROXY=http://old-proxy",
+	}
+	next := []string{
+		"PATH=/bin",
+		"HTTPS_PROXY=http://new-proxy",
+		"ANTHROPIC_BASE_URL=https://api.anthropic.com",
+		"ANTHROPIC_API_KEY=sk-ant-new-key-value-456",
+	}
+
+	entries, unchanged := diffEnvironments(current, next, "ANTHROPIC_API_KEY")
+	if unchanged != 1 {
+		t.Errorf("unchanged = %d, want 1", unchanged)
+	}
+
+	want := []envDiffEntry{
+		{Key: "ANTHROPIC_API_KEY", Kind: envDiffAdded, New: maskAPIKey("sk-ant-new-key-value-456")},
+		{Key: "ANTHROPIC_AUTH_TOKEN", Kind: envDiffCleared, Old: maskAPIKey("sk-old-token-value-123")},
+		{Key: "ANTHROPIC_BASE_URL", Kind: envDiffChanged, Old: "https://old.example.com", New: "https://api.anthropic.com"},
+		{Key: "HTTPS_PROXY", Kind: envDiffChanged, Old: "http://old-proxy", New: "http://new-proxy"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", entries, want)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, entries[i], want[i])
+		}
+	}
+}
+
+func TestRenderEnvDiffShowsCollisions(t *testing.T) {
+	entries := []envDiffEntry{{Key: "ANTHROPIC_BASE_URL", Kind: envDiffChanged, Old: "https://old", New: "https://new"}}
+	plan := launchPlan{SettingsPath: "/home/u/.claude/settings.json", SettingsConflicts: []string{"ANTHROPIC_BASE_URL"}}
+
+	var buf bytes.Buffer
+	if err := renderEnvDiff(&buf, "prod", entries, 3, plan); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Environment changes for 'prod'",
+		"~ ANTHROPIC_BASE_URL: https://old -> https://new",
+		"3 other variable(s) passed through unchanged",
+		`Collisions: /home/u/.claude/settings.json sets these in "env"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintEnvDiffDoesNotLaunch(t *testing.T) {
+	path := withTempConfigPath(t)
+	withClaudeSettings(t, `{"env": {"ANTHROPIC_BASE_URL": "https://shadow.example.com"}}`)
+	config := Config{Environments: []Environment{{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-env-diff-key-1"}}}
+	if err := saveConfigDirect(config, path); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ANTHROPIC_BASE_URL", "https://stale.example.com")
+
+	launched := false
+	original := claudeLauncher
+	claudeLauncher = func(Environment, []string, string) error { launched = true; return nil }
+	t.Cleanup(func() { claudeLauncher = original })
+
+	parsed := parseArguments([]string{"--env", "prod", "--print-env-diff", "chat"})
+	if parsed.CCEFlags["print_env_diff"] != "true" || strings.Join(parsed.ClaudeArgs, " ") != "chat" {
+		t.Fatalf("unexpected parse result %+v", parsed)
+	}
+
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"--env", "prod", "--print-env-diff"}); err != nil {
+			t.Fatalf("--print-env-diff failed: %v", err)
+		}
+	})
+	if launched {
+		t.Error("--print-env-diff must not launch claude")
+	}
+	if !strings.Contains(out, "~ ANTHROPIC_BASE_URL: https://stale.example.com -> https://api.anthropic.com") {
+		t.Errorf("expected override of the inherited base URL:\n%s", out)
+	}
+	if strings.Contains(out, "sk-ant-env-diff-key-1") {
+		t.Errorf("API key must be masked:\n%s", out)
+	}
+	if !strings.Contains(out, "Collisions: "+claudeSettingsPathOverride) {
+		t.Errorf("expected settings.json collision:\n%s", out)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeEnvFile writes dotenv content to a temp file and returns its path
+func writeEnvFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write env file: %v", err)
+	}
+	return path
+}
+
+func TestParseEnvFile(t *testing.T) {
+	path := writeEnvFile(t, `# project settings
+PLAIN=value
+export EXPORTED=yes
+SPACED = padded  # trailing comment
+DOUBLE="with spaces # not a comment\nnext"
+SINGLE='literal \n $HOME'
+EMPTY=
+`)
+
+	vars, err := parseEnvFile(path)
+	if err != nil {
+		t.Fatalf("parseEnvFile() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"PLAIN":    "value",
+		"EXPORTED": "yes",
+		"SPACED":   "padded",
+		"DOUBLE":   "with spaces # not a comment\nnext",
+		"SINGLE":   `literal \n $HOME`,
+		"EMPTY":    "",
+	}
+	for key, value := range want {
+		if vars[key] != value {
+			t.Errorf("%s = %q, want %q", key, vars[key], value)
+		}
+	}
+
+	malformed := []string{
+		"NO_EQUALS\n",
+		"1BAD=value\n",
+		"UNTERMINATED=\"open\n",
+		"TRAILING='a' b\n",
+	}
+	for _, content := range malformed {
+		if _, err := parseEnvFile(writeEnvFile(t, content)); err == nil || !strings.Contains(err.Error(), ":1:") {
+			t.Errorf("expected line-numbered error for %q, got %v", content, err)
+		}
+	}
+
+	if _, err := parseEnvFile(filepath.Join(t.TempDir(), "missing.env")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestMergeEnvFileVars(t *testing.T) {
+	env := Environment{
+		Name:    "merge",
+		URL:     "https://api.anthropic.com",
+		APIKey:  "sk-ant-merge-key",
+		EnvVars: map[string]string{"SHARED": "from-env"},
+	}
+	fileVars := map[string]string{
+		"SHARED":             "from-file",
+		"FILE_ONLY":          "1",
+		"ANTHROPIC_BASE_URL": "https://evil.example.com",
+		"ANTHROPIC_API_KEY":  "sk-file",
+	}
+
+	merged, ignored := mergeEnvFileVars(env, fileVars)
+	if merged.EnvVars["SHARED"] != "from-env" || merged.EnvVars["FILE_ONLY"] != "1" {
+		t.Errorf("unexpected merged vars: %v", merged.EnvVars)
+	}
+	if strings.Join(ignored, ",") != "ANTHROPIC_API_KEY,ANTHROPIC_BASE_URL" {
+		t.Errorf("unexpected ignored vars: %v", ignored)
+	}
+	if len(env.EnvVars) != 1 {
+		t.Error("merge must not mutate the configured environment")
+	}
+
+	envVars, err := prepareEnvironment(merged)
+	if err != nil {
+		t.Fatalf("prepareEnvironment() failed: %v", err)
+	}
+	joined := strings.Join(envVars, "\n")
+	if !strings.Contains(joined, "FILE_ONLY=1") || strings.Contains(joined, "evil.example.com") {
+		t.Errorf("unexpected child environment:\n%s", joined)
+	}
+}
+
+func TestEnvFileFlagParsing(t *testing.T) {
+	result := parseArguments([]string{"--env-file", ".env", "--env", "prod", "chat"})
+	if result.CCEFlags["env_file"] != ".env" || result.CCEFlags["env"] != "prod" {
+		t.Fatalf("unexpected flags: %+v", result.CCEFlags)
+	}
+	if len(result.ClaudeArgs) != 1 || result.ClaudeArgs[0] != "chat" {
+		t.Errorf("env file leaked into claude args: %v", result.ClaudeArgs)
+	}
+
+	if result := parseArguments([]string{"--env-file"}); result.Error == nil {
+		t.Error("expected missing value error")
+	}
+
+	err := runDefaultWithOptions("prod", nil, launchOptions{EnvFile: writeEnvFile(t, "BROKEN\n")})
+	if err == nil || !strings.Contains(err.Error(), "invalid --env-file") {
+		t.Errorf("expected env file error before launch, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// stubEnvVarInput answers the variable editor from a script
+func stubEnvVarInput(t *testing.T, answers ...string) {
+	t.Helper()
+	original := envVarInput
+	envVarInput = func(prompt string) (string, error) {
+		if len(answers) == 0 {
+			t.Fatalf("unexpected prompt %q", prompt)
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+	t.Cleanup(func() { envVarInput = original })
+}
+
+func TestPromptEnvironmentEnvVarsEditsMap(t *testing.T) {
+	stubEnvVarInput(t,
+		"ANTHROPIC_TIMEOUT=30s",
+		"HTTPS_PROXY", "http://proxy:8080",
+		"ANTHROPIC_TIMEOUT=60s",
+		"-OLD_VAR",
+		"-MISSING",
+		"1BAD=x",
+		"PROXY_TOKEN=tok-secret\x1b[2J",
+		"EMPTY=",
+		"",
+	)
+	defaults := promptDefaults{Env: Environment{EnvVars: map[string]string{"OLD_VAR": "1", "PROXY_TOKEN": "tok-very-secret-value"}}}
+
+	var env Environment
+	out := captureStdout(t, func() {
+		if err := promptEnvironmentEnvVars(Config{}, defaults, &env); err != nil {
+			t.Fatalf("promptEnvironmentEnvVars() failed: %v", err)
+		}
+	})
+
+	want := map[string]string{"ANTHROPIC_TIMEOUT": "60s", "HTTPS_PROXY": "http://proxy:8080", "PROXY_TOKEN": "tok-very-secret-value"}
+	if len(env.EnvVars) != len(want) {
+		t.Fatalf("EnvVars = %v, want %v", env.EnvVars, want)
+	}
+	for key, value := range want {
+		if env.EnvVars[key] != value {
+			t.Errorf("EnvVars[%s] = %q, want %q", key, env.EnvVars[key], value)
+		}
+	}
+	for _, snippet := range []string{"Set ANTHROPIC_TIMEOUT", "Updated ANTHROPIC_TIMEOUT", "Removed OLD_VAR",
+		"no variable named 'MISSING'", "invalid variable name '1BAD'", "control characters", "use -EMPTY to remove"} {
+		if !strings.Contains(out, snippet) {
+			t.Errorf("output missing %q:\n%s", snippet, out)
+		}
+	}
+	if strings.Contains(out, "tok-very-secret-value") {
+		t.Errorf("editor should mask secret-looking values:\n%s", out)
+	}
+}
+
+func TestPromptEnvironmentEnvVarsKeepsValueOnEnter(t *testing.T) {
+	stubEnvVarInput(t, "ANTHROPIC_TIMEOUT", "", "")
+	defaults := promptDefaults{Env: Environment{EnvVars: map[string]string{"ANTHROPIC_TIMEOUT": "30s"}}}
+	var env Environment
+	captureStdout(t, func() {
+		if err := promptEnvironmentEnvVars(Config{}, defaults, &env); err != nil {
+			t.Fatalf("promptEnvironmentEnvVars() failed: %v", err)
+		}
+	})
+	if env.EnvVars["ANTHROPIC_TIMEOUT"] != "30s" {
+		t.Errorf("Enter should keep the current value, got %v", env.EnvVars)
+	}
+}
+
+func TestSetRejectsControlCharsInEnvVars(t *testing.T) {
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-prod-key-1234"}
+	_, err := applyFieldUpdates(env, map[string]string{"env.proxy": "a\nb"}, []string{"env.proxy"})
+	if err == nil || !strings.Contains(err.Error(), "control characters") {
+		t.Errorf("expected control character error, got %v", err)
+	}
+}
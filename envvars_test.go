@@ -263,3 +263,76 @@ func TestCommonSystemVarDetection(t *testing.T) {
 		}
 	}
 }
+
+func TestAuthSchemeMapping(t *testing.T) {
+	base := Environment{Name: "scheme", URL: "https://api.example.com", APIKey: "sk-test-key"}
+
+	tests := []struct {
+		name      string
+		scheme    string
+		keyEnv    string
+		wantVar   string
+		wantError bool
+	}{
+		{"default is x-api-key", "", "", "ANTHROPIC_API_KEY", false},
+		{"x-api-key scheme", authSchemeAPIKey, "", "ANTHROPIC_API_KEY", false},
+		{"bearer scheme", authSchemeBearer, "", "ANTHROPIC_AUTH_TOKEN", false},
+		{"api_key_env alone still works", "", "ANTHROPIC_AUTH_TOKEN", "ANTHROPIC_AUTH_TOKEN", false},
+		{"matching scheme and key var", authSchemeBearer, "ANTHROPIC_AUTH_TOKEN", "ANTHROPIC_AUTH_TOKEN", false},
+		{"conflicting scheme and key var", authSchemeBearer, "ANTHROPIC_API_KEY", "", true},
+		{"unknown scheme", "basic", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := base
+			env.AuthScheme = tt.scheme
+			env.APIKeyEnv = tt.keyEnv
+
+			vars, err := prepareEnvironment(env)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("expected validation error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("prepareEnvironment() failed: %v", err)
+			}
+
+			found := 0
+			for _, v := range vars {
+				if v == tt.wantVar+"="+env.APIKey {
+					found++
+				}
+				other := "ANTHROPIC_API_KEY="
+				if tt.wantVar == "ANTHROPIC_API_KEY" {
+					other = "ANTHROPIC_AUTH_TOKEN="
+				}
+				if strings.HasPrefix(v, other) {
+					t.Errorf("unexpected %s injected for scheme %q", other, tt.scheme)
+				}
+			}
+			if found != 1 {
+				t.Errorf("expected exactly one %s, found %d", tt.wantVar, found)
+			}
+		})
+	}
+}
+
+func TestKeyVarOverrideUpdatesAuthScheme(t *testing.T) {
+	if got := authSchemeForKeyVar("ANTHROPIC_AUTH_TOKEN"); got != authSchemeBearer {
+		t.Errorf("authSchemeForKeyVar(AUTH_TOKEN) = %q", got)
+	}
+	if got := authSchemeForKeyVar("ANTHROPIC_API_KEY"); got != authSchemeAPIKey {
+		t.Errorf("authSchemeForKeyVar(API_KEY) = %q", got)
+	}
+
+	// An override applied on top of a bearer environment must not trip the conflict check
+	env := Environment{Name: "o", URL: "https://api.example.com", APIKey: "sk-ant-REDACTED", AuthScheme: authSchemeBearer}
+	env.APIKeyEnv = "ANTHROPIC_API_KEY"
+	env.AuthScheme = authSchemeForKeyVar(env.APIKeyEnv)
+	if err := validateEnvironment(env); err != nil {
+		t.Errorf("override produced invalid environment: %v", err)
+	}
+}
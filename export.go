@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// exportFormatVersion identifies the portable export layout
+const exportFormatVersion = 1
+
+// Export formats
+const (
+	exportFormatJSON = "json" // Portable document for import
+	exportFormatEnv  = "env"  // Shell export lines for a single environment
+)
+
+// exportDocument is the portable representation written by cce export
+type exportDocument struct {
+	Version      int           `json:"version"`
+	Redacted     bool          `json:"redacted,omitempty"` // API keys were stripped
+	Environments []Environment `json:"environments"`
+}
+
+// exportOptions holds flags accepted by the export subcommand
+type exportOptions struct {
+	Env    string   // Export only this environment
+	Names  []string // Export only these environments (positional arguments)
+	Redact bool     // Strip API keys so the output is safe to share
+	Format string
+	Output string // Write to this file (0600) instead of stdout
+}
+
+// selectedNames returns --env and the positional names, in that order
+func (o exportOptions) selectedNames() []string {
+	if o.Env == "" {
+		return o.Names
+	}
+	return append([]string{o.Env}, o.Names...)
+}
+
+// parseExportOptions parses export subcommand flags
+func parseExportOptions(args []string) (exportOptions, error) {
+	opts := exportOptions{Format: exportFormatJSON}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--env", "-e":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return opts, fmt.Errorf("%s flag requires an environment name", args[i])
+			}
+			i++
+			opts.Env = args[i]
+		case "--redact":
+			opts.Redact = true
+		case "--format":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--format flag requires a value (json or env)")
+			}
+			i++
+			opts.Format = strings.ToLower(args[i])
+			if opts.Format != exportFormatJSON && opts.Format != exportFormatEnv {
+				return opts, fmt.Errorf("unknown export format '%s' (expected json or env)", args[i])
+			}
+		case "--output", "-o":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return opts, fmt.Errorf("%s flag requires a file path", args[i])
+			}
+			i++
+			opts.Output = args[i]
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				return opts, fmt.Errorf("unknown export flag '%s'", args[i])
+			}
+			opts.Names = append(opts.Names, args[i])
+		}
+	}
+	if opts.Format == exportFormatEnv && len(opts.selectedNames()) != 1 {
+		return opts, fmt.Errorf("--format env exports a single environment; name exactly one")
+	}
+	return opts, nil
+}
+
+// buildExportDocument selects and copies environments for export, stripping keys when redacting
+func buildExportDocument(config Config, opts exportOptions) (exportDocument, error) {
+	doc := exportDocument{Version: exportFormatVersion, Redacted: opts.Redact}
+
+	envs := config.Environments
+	if names := opts.selectedNames(); len(names) > 0 {
+		envs = make([]Environment, 0, len(names))
+		for _, name := range names {
+			index, err := lookupEnvironment(config, name)
+			if err != nil {
+				return doc, err
+			}
+			envs = append(envs, config.Environments[index])
+		}
+	}
+
+	doc.Environments = make([]Environment, 0, len(envs))
+	for _, env := range envs {
+		exported := env
+		exported.EnvVars = nil
+		if len(env.EnvVars) > 0 {
+			exported.EnvVars = make(map[string]string, len(env.EnvVars))
+			for key, value := range env.EnvVars {
+				if opts.Redact && isSecretVar(key, "") {
+					value = ""
+				}
+				exported.EnvVars[key] = value
+			}
+		}
+		exported.Headers = nil
+		if len(env.Headers) > 0 {
+			exported.Headers = make(map[string]string, len(env.Headers))
+			for name, value := range env.Headers {
+				if opts.Redact && isSecretHeader(name) {
+					value = ""
+				}
+				exported.Headers[name] = value
+			}
+		}
+		if opts.Redact {
+			exported.APIKey = ""
+			exported.APIKeys = nil
+			exported.PreviousAPIKey = ""
+		}
+		doc.Environments = append(doc.Environments, exported)
+	}
+	return doc, nil
+}
+
+// shellQuote single-quotes a value for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// writeEnvExport renders one environment as the export lines a launch would set. Keychain
+// references are resolved like a launch resolves them, except when the keys are redacted.
+func writeEnvExport(w io.Writer, env Environment, redacted bool) error {
+	if !redacted {
+		resolved, err := resolveSecretRefs(env)
+		if err != nil {
+			return err
+		}
+		env = resolved
+	}
+	keyVar := resolveAPIKeyVar(env)
+	if _, err := fmt.Fprintf(w, "# cce environment: %s\n", env.Name); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	for _, a := range launchVariables(env) {
+		line := fmt.Sprintf("export %s=%s", a.Key, shellQuote(a.Value))
+		_, secretHeaders := maskCustomHeaders(a.Value)
+		if redacted && (a.Key == keyVar || isSecretVar(a.Key, keyVar) || a.Key == customHeadersVar && secretHeaders) {
+			line = fmt.Sprintf("# export %s=<redacted>", a.Key)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+	}
+	return nil
+}
+
+// runExport writes all environments, or just the named ones, to stdout or --output
+func runExport(args []string) error {
+	opts, err := parseExportOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+
+	doc, err := buildExportDocument(config, opts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if opts.Format == exportFormatEnv {
+		if err := writeEnvExport(&buf, doc.Environments[0], opts.Redact); err != nil {
+			return err
+		}
+	} else {
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode export: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	if opts.Output == "" {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		return nil
+	}
+	// 0600 like the config itself: unless redacted, the file holds API keys
+	if err := ioutil.WriteFile(opts.Output, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	note := ""
+	if !opts.Redact {
+		note = " (includes API keys; use --redact to share it)"
+	}
+	if _, err := fmt.Printf("Exported %d environment(s) to %s%s\n", len(doc.Environments), opts.Output, note); err != nil {
+		return fmt.Errorf("failed to display success message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func exportTestConfig() Config {
+	return Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-prod-key-123", Model: "claude-3-5-sonnet-20241022",
+			EnvVars: map[string]string{"ANTHROPIC_TIMEOUT": "60", "PROXY_TOKEN": "secret"}},
+		{Name: "dev", URL: "https://dev.example.com", APIKey: "sk-ant-dev-key-123"},
+	}}
+}
+
+func TestBuildExportDocumentSingleEnvironment(t *testing.T) {
+	config := exportTestConfig()
+	doc, err := buildExportDocument(config, exportOptions{Env: "prod", Redact: true, Format: exportFormatJSON})
+	if err != nil {
+		t.Fatalf("buildExportDocument() failed: %v", err)
+	}
+	if len(doc.Environments) != 1 || doc.Environments[0].Name != "prod" || !doc.Redacted {
+		t.Fatalf("unexpected export document: %+v", doc)
+	}
+	exported := doc.Environments[0]
+	if exported.APIKey != "" || exported.EnvVars["PROXY_TOKEN"] != "" {
+		t.Errorf("secrets should be redacted: %+v", exported)
+	}
+	if exported.EnvVars["ANTHROPIC_TIMEOUT"] != "60" || exported.Model != "claude-3-5-sonnet-20241022" {
+		t.Errorf("non-secret fields should be kept: %+v", exported)
+	}
+
+	// Redaction must not touch the loaded config
+	if config.Environments[0].APIKey == "" || config.Environments[0].EnvVars["PROXY_TOKEN"] != "secret" {
+		t.Error("export modified the source config")
+	}
+
+	if _, err := buildExportDocument(config, exportOptions{Env: "missing"}); err == nil {
+		t.Error("expected unknown environment to be rejected")
+	}
+}
+
+func TestBuildExportDocumentAll(t *testing.T) {
+	doc, err := buildExportDocument(exportTestConfig(), exportOptions{Format: exportFormatJSON})
+	if err != nil {
+		t.Fatalf("buildExportDocument() failed: %v", err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to encode export: %v", err)
+	}
+	if !strings.Contains(string(data), `"version":1`) || !strings.Contains(string(data), "sk-ant-dev-key-123") {
+		t.Errorf("unexpected unredacted export: %s", data)
+	}
+	if len(doc.Environments) != 2 {
+		t.Errorf("expected both environments, got %d", len(doc.Environments))
+	}
+}
+
+func TestWriteEnvExport(t *testing.T) {
+	env := exportTestConfig().Environments[0]
+	var buf bytes.Buffer
+	if err := writeEnvExport(&buf, env, true); err != nil {
+		t.Fatalf("writeEnvExport() failed: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"export ANTHROPIC_BASE_URL='https://api.anthropic.com'",
+		"# export ANTHROPIC_API_KEY=<redacted>",
+		"export ANTHROPIC_MODEL='claude-3-5-sonnet-20241022'",
+		"# export PROXY_TOKEN=<redacted>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "sk-ant-prod-key-123") {
+		t.Error("redacted env export leaked the API key")
+	}
+}
+
+func TestParseExportOptions(t *testing.T) {
+	if _, err := parseExportOptions([]string{"--format", "env"}); err == nil {
+		t.Error("expected --format env without --env to be rejected")
+	}
+	if _, err := parseExportOptions([]string{"--format", "yaml"}); err == nil {
+		t.Error("expected unknown format to be rejected")
+	}
+	opts, err := parseExportOptions([]string{"-e", "prod", "--redact", "--format", "ENV"})
+	if err != nil {
+		t.Fatalf("parseExportOptions() failed: %v", err)
+	}
+	if opts.Env != "prod" || !opts.Redact || opts.Format != exportFormatEnv {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+	if got := shellQuote("it's"); got != `'it'\''s'` {
+		t.Errorf("unexpected shell quoting: %s", got)
+	}
+}
+
+func TestExportNamedEnvironmentsToFile(t *testing.T) {
+	withTempConfigPath(t)
+	if err := saveConfig(exportTestConfig()); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "team.json")
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"export", "dev", "prod", "--redact", "-o", path}); err != nil {
+			t.Fatalf("export failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Exported 2 environment(s)") {
+		t.Errorf("unexpected output: %q", out)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("expected a 0600 file, got %v %v", info, err)
+	}
+	doc, err := readImportDocument(path)
+	if err != nil {
+		t.Fatalf("readImportDocument() failed: %v", err)
+	}
+	if len(doc.Environments) != 2 || doc.Environments[0].Name != "dev" || doc.Environments[1].APIKey != "" {
+		t.Errorf("unexpected export: %+v", doc)
+	}
+	if _, err := parseExportOptions([]string{"dev", "prod", "--format", "env"}); err == nil {
+		t.Error("expected --format env with two names to be rejected")
+	}
+}
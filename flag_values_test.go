@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseArgumentsAttachedFlagValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantEnv    string
+		wantKeyVar string
+		wantClaude string
+	}{
+		{"long with equals", []string{"--env=prod", "chat", "--verbose"}, "prod", "", "chat --verbose"},
+		{"short separate", []string{"-e", "prod", "chat"}, "prod", "", "chat"},
+		{"short with equals", []string{"-e=prod", "chat"}, "prod", "", "chat"},
+		{"short attached", []string{"-eprod", "--", "chat", "--model=x"}, "prod", "", "chat --model=x"},
+		{"key var with equals", []string{"--env=prod", "--key-var=ANTHROPIC_AUTH_TOKEN", "chat"}, "prod", "ANTHROPIC_AUTH_TOKEN", "chat"},
+		{"short key var attached", []string{"-kANTHROPIC_AUTH_TOKEN", "-e", "dev"}, "dev", "ANTHROPIC_AUTH_TOKEN", ""},
+		{"value containing equals", []string{"--env=a=b", "chat"}, "a=b", "", "chat"},
+		{"switch before value flag", []string{"--wk", "--env=prod", "--yolo", "chat"}, "prod", "", "--dangerously-skip-permissions chat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseArguments(tt.args)
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if result.CCEFlags["env"] != tt.wantEnv || result.CCEFlags["key_var"] != tt.wantKeyVar {
+				t.Errorf("flags = %v, want env %q key_var %q", result.CCEFlags, tt.wantEnv, tt.wantKeyVar)
+			}
+			if got := strings.Join(result.ClaudeArgs, " "); got != tt.wantClaude {
+				t.Errorf("claude args = %q, want %q", got, tt.wantClaude)
+			}
+		})
+	}
+}
+
+func TestParseArgumentsLeavesClaudeFlagValuesAlone(t *testing.T) {
+	// Attached values after the first claude argument belong to claude
+	result := parseArguments([]string{"-e", "prod", "chat", "-eval", "--env-file=x"})
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if result.CCEFlags["env"] != "prod" || result.CCEFlags["env_file"] != "" {
+		t.Errorf("only the leading flags are CCE's, got %v", result.CCEFlags)
+	}
+	if got := strings.Join(result.ClaudeArgs, " "); got != "chat -eval --env-file=x" {
+		t.Errorf("claude args = %q", got)
+	}
+
+	if result := parseArguments([]string{"--env=", "chat"}); result.Error == nil || !strings.Contains(result.Error.Error(), "requires a value") {
+		t.Errorf("expected an empty --env= to be rejected, got %v", result.Error)
+	}
+}
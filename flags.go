@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// cceValueFlags are the launch flags that take a value, in every spelling
+var cceValueFlags = map[string]bool{
+	"--env": true, "-e": true,
+	"--key-var": true, "-k": true,
+	"--model": true, "-m": true,
+	"--env-file": true, "--chdir": true, "--config": true, "--group": true, "--log-file": true, "--watch-max": true,
+	"--wk-path": true, "--wk-name": true,
+}
+
+// cceSwitchFlags are the launch flags that take no value
+var cceSwitchFlags = map[string]bool{
+	"--help": true, "-h": true,
+	"--yolo": true, "--wk": true, "--wk-fresh": true, "--wk-cleanup": true, "--force": true, "--override-settings": true, "--dry-run": true,
+	"--strict-args": true, "--strict-flags": true, "--no-prompt": true, "--log-only": true, "--print-env-diff": true, "--skip-preflight": true,
+	"--detach": true, "--wait": true, "--watch": true,
+}
+
+// splitFlagValues rewrites --env=prod, -e=prod and -eprod (and the same spellings of the
+// other value flags) into "--env prod", so the launch parser only sees separate values.
+// Only the leading run of CCE flags is rewritten; scanning stops at -- or the first
+// argument that is not a CCE flag, and everything from there is left for claude.
+func splitFlagValues(args []string) ([]string, error) {
+	out := make([]string, 0, len(args)+1)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--" || !strings.HasPrefix(arg, "-"):
+			return append(out, args[i:]...), nil
+		case cceValueFlags[arg]:
+			out = append(out, arg)
+			if i+1 < len(args) {
+				i++
+				out = append(out, args[i])
+			}
+		case cceSwitchFlags[arg]:
+			out = append(out, arg)
+		default:
+			name, value, ok := splitAttachedValue(arg)
+			if !ok {
+				return append(out, args[i:]...), nil
+			}
+			if value == "" {
+				return nil, fmt.Errorf("flag %s requires a value", name)
+			}
+			out = append(out, name, value)
+		}
+	}
+	return out, nil
+}
+
+// splitAttachedValue splits a value flag written as --flag=value, -f=value or -fvalue
+func splitAttachedValue(arg string) (name, value string, ok bool) {
+	if eq := strings.Index(arg, "="); eq > 0 && cceValueFlags[arg[:eq]] {
+		return arg[:eq], arg[eq+1:], true
+	}
+	if len(arg) > 2 && arg[1] != '-' && cceValueFlags[arg[:2]] {
+		return arg[:2], arg[2:], true
+	}
+	return "", "", false
+}
+
+// strictFlagsEnabled reports whether --strict-flags, CCE_STRICT_FLAGS, or the strict_flags
+// setting asks for unknown leading flags to be rejected rather than forwarded to claude
+func strictFlagsEnabled(parseResult ParseResult) bool {
+	if parseResult.CCEFlags["strict_flags"] == "true" {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("CCE_STRICT_FLAGS")) {
+	case "1", "true", "yes":
+		return true
+	}
+	settings := peekConfigSettings()
+	return settings != nil && settings.StrictFlags
+}
+
+// checkUnknownFlag fails in strict mode when a flag before the claude arguments is not
+// one of cce's, which is most often a typo such as --ene for --env
+func checkUnknownFlag(parseResult ParseResult) error {
+	if parseResult.UnknownFlag == "" || !strictFlagsEnabled(parseResult) {
+		return nil
+	}
+	flag := parseResult.UnknownFlag
+	if suggestion := suggestFlag(flag); suggestion != "" {
+		return fmt.Errorf("unknown flag %s, did you mean %s? (pass claude flags after --)", flag, suggestion)
+	}
+	return fmt.Errorf("unknown flag %s (pass claude flags after --)", flag)
+}
+
+// suggestFlag returns the long CCE flag closest to flag, or "" when none is close enough
+// to be a plausible typo. Any =value is ignored for the comparison.
+func suggestFlag(flag string) string {
+	name := flag
+	if eq := strings.Index(name, "="); eq > 0 {
+		name = name[:eq]
+	}
+	if !strings.HasPrefix(name, "--") {
+		return ""
+	}
+
+	var candidates []string
+	for _, known := range []map[string]bool{cceValueFlags, cceSwitchFlags} {
+		for candidate := range known {
+			if strings.HasPrefix(candidate, "--") {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	sort.Strings(candidates) // Ties go to the alphabetically first flag, not map order
+
+	best, bestDistance := "", 0
+	for _, candidate := range candidates {
+		distance := editDistance(name, candidate)
+		if distance > 2 || distance*3 > len(candidate) {
+			continue
+		}
+		if best == "" || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// environmentsWithTag returns the environments carrying tag, in config order
+func environmentsWithTag(envs []Environment, tag string) []Environment {
+	var tagged []Environment
+	for _, env := range envs {
+		for _, t := range env.Tags {
+			if t == tag {
+				tagged = append(tagged, env)
+				break
+			}
+		}
+	}
+	return tagged
+}
+
+// configWithTag narrows config to the environments carrying tag, so list, the picker and
+// --group all see the same set. An empty tag leaves config unchanged.
+func configWithTag(config Config, tag string) (Config, error) {
+	if tag == "" {
+		return config, nil
+	}
+	tagged := environmentsWithTag(config.Environments, tag)
+	if len(tagged) == 0 {
+		errorCtx := newErrorContext("tag lookup", "main runner")
+		errorCtx.addContext("tag", tag)
+		errorCtx.addSuggestion(fmt.Sprintf("Tag environments with 'cce set <name> tags=%s'", tag))
+		return Config{}, fmt.Errorf("configuration lookup failed: %w", errorCtx.formatError(fmt.Errorf("no environments are tagged '%s'", tag)))
+	}
+	config.Environments = tagged
+	return config, nil
+}
+
+// parseTags splits a comma-separated tag list, dropping blanks and repeats
+func parseTags(value string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// validateTags rejects empty tags and ones that cannot round-trip through tags=a,b
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if tag == "" || strings.ContainsAny(tag, ", \t\r\n") {
+			return fmt.Errorf("invalid tag '%s': tags must be non-empty and contain no spaces or commas", tag)
+		}
+	}
+	return nil
+}
+
+// groupLaunchError reports a --group run in which some environments failed. Its code is
+// the process exit code: the highest one among the failed environments.
+type groupLaunchError struct {
+	Group  string
+	Failed []string // Names of the environments that failed, in launch order
+	Total  int
+	code   int
+}
+
+func (e *groupLaunchError) Error() string {
+	return fmt.Sprintf("group %s: claude failed in %d of %d environments (%s)", e.Group, len(e.Failed), e.Total, strings.Join(e.Failed, ", "))
+}
+
+// prefixWriter writes each complete line to w with prefix in front, so output from
+// several environments stays attributable when interleaved
+type prefixWriter struct {
+	mu      *sync.Mutex // Shared by writers on the same destination
+	w       io.Writer
+	prefix  string
+	partial []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.partial = append(p.partial, data...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			return len(data), nil
+		}
+		if err := p.emit(p.partial[:i+1]); err != nil {
+			return 0, err
+		}
+		p.partial = p.partial[i+1:]
+	}
+}
+
+// flush writes a trailing line that had no newline
+func (p *prefixWriter) flush() error {
+	if len(p.partial) == 0 {
+		return nil
+	}
+	line := append(p.partial, '\n')
+	p.partial = nil
+	return p.emit(line)
+}
+
+func (p *prefixWriter) emit(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+	return err
+}
+
+// groupLauncher runs claude for one environment of a group and returns its exit code;
+// tests replace it to avoid starting claude
+var groupLauncher = launchClaudeCodeForGroup
+
+// launchClaudeCodeForGroup runs claude as a child with its output sent to stdout and
+// stderr. Stdin is not connected: a group run is for non-interactive (-p) commands.
+func launchClaudeCodeForGroup(env Environment, args []string, stdout, stderr io.Writer) (int, error) {
+	if err := checkClaudeCodeExists(); err != nil {
+		return 0, fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+	envVars, err := prepareEnvironment(env)
+	if err != nil {
+		return 0, fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+	if err := ensureSettingsDir(env); err != nil {
+		return 0, fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+
+	cmd := exec.Command("claude", args...)
+	cmd.Env = envVars
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) {
+			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
+				return status.ExitStatus(), nil
+			}
+		}
+		return 0, fmt.Errorf("Claude Code execution failed: %w", err)
+	}
+	return 0, nil
+}
+
+// validateGroupLaunch rejects launch flags that only make sense for a single environment
+func validateGroupLaunch(parseResult ParseResult) error {
+	switch {
+	case parseResult.CCEFlags["env"] != "":
+		return fmt.Errorf("--group and --env cannot be combined")
+	case parseResult.CCEFlags["detach"] == "true":
+		return fmt.Errorf("--group runs claude in the foreground and cannot be combined with --detach")
+	case parseResult.WorktreeEnabled:
+		return fmt.Errorf("--group cannot be combined with --wk")
+	case parseResult.CCEFlags["print_env_diff"] == "true":
+		return fmt.Errorf("--group cannot be combined with --print-env-diff")
+	case parseResult.CCEFlags["watch"] == "true":
+		return fmt.Errorf("--group cannot be combined with --watch")
+	case parseResult.CCEFlags["dry_run"] == "true":
+		return fmt.Errorf("--group cannot be combined with --dry-run; use 'cce plan --env <name>' per environment")
+	case parseResult.CCEFlags["override_settings"] == "true":
+		return fmt.Errorf("--group cannot be combined with --override-settings; one settings.json cannot match every environment")
+	case parseResult.CCEFlags["log_file"] != "":
+		return fmt.Errorf("--group cannot be combined with --log-file; redirect its prefixed output instead")
+	}
+	return nil
+}
+
+// runGroup launches claude once per environment tagged group, one after another, with
+// every output line prefixed by the environment name. A failure in one environment does
+// not stop the others; the summary lists them and the exit code is the highest seen.
+func runGroup(group string, claudeArgs []string, opts launchOptions) error {
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	config, err = configWithTag(config, group)
+	if err != nil {
+		return err
+	}
+	envs := config.Environments
+
+	var outMu, errMu sync.Mutex
+	result := &groupLaunchError{Group: group, Total: len(envs)}
+	for _, env := range envs {
+		stdout := &prefixWriter{mu: &outMu, w: os.Stdout, prefix: "[" + env.Name + "] "}
+		stderr := &prefixWriter{mu: &errMu, w: os.Stderr, prefix: "[" + env.Name + "] "}
+		code, err := runGroupMember(env, claudeArgs, opts, stdout, stderr)
+		stdout.flush()
+		stderr.flush()
+		if err != nil {
+			fmt.Fprintf(stderr, "%v\n", err)
+			stderr.flush()
+			code = exitCodeFor(err)
+		}
+		if code != 0 {
+			result.Failed = append(result.Failed, env.Name)
+			if code > result.code {
+				result.code = code
+			}
+		}
+	}
+
+	fmt.Printf("Group %s: %d succeeded, %d failed\n", group, len(envs)-len(result.Failed), len(result.Failed))
+	if len(result.Failed) > 0 {
+		return result
+	}
+	return nil
+}
+
+// runGroupMember applies the same per-launch steps as a single launch, then runs claude
+func runGroupMember(env Environment, claudeArgs []string, opts launchOptions, stdout, stderr io.Writer) (int, error) {
+	env, err := resolveLaunchEnvironment(env.Name, opts)
+	if err != nil {
+		return 0, err
+	}
+	if err := validateArgsForEnvironment(claudeArgs, env, opts.StrictArgs); err != nil {
+		return 0, fmt.Errorf("argument validation failed: %w", err)
+	}
+	env, keyLabel := pickLaunchKey(env)
+
+	banner := fmt.Sprintf("Using environment: %s (%s)", env.Name, env.URL)
+	if keyLabel != "" {
+		banner += " [" + keyLabel + "]"
+	}
+	fmt.Fprintln(stdout, banner)
+
+	if err := checkClaudeVersion(env); err != nil {
+		return 0, err
+	}
+	if !opts.SkipPreflight {
+		if err := runPreflight(env, ""); err != nil {
+			return 0, err
+		}
+	}
+	code, err := groupLauncher(env, claudeArgs, stdout, stderr)
+	if err != nil {
+		return code, err
+	}
+	// claude ran, whatever its exit code; members run one at a time, so the stamps never race
+	if err := recordLastUsed(env.Name); err != nil {
+		fmt.Fprintf(stderr, "Warning: failed to record last use of '%s': %v\n", env.Name, err)
+	}
+	return code, nil
+}
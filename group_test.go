@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func stubGroupLauncher(t *testing.T, launcher func(env Environment, args []string, stdout, stderr io.Writer) (int, error)) {
+	t.Helper()
+	original := groupLauncher
+	t.Cleanup(func() { groupLauncher = original })
+	groupLauncher = launcher
+}
+
+func saveGroupConfig(t *testing.T) {
+	t.Helper()
+	withTempConfigPath(t)
+	if err := saveConfig(Config{Environments: []Environment{
+		{Name: "stage-us", URL: "https://us.example.com", APIKey: "sk-ant-api03-us-1234567890", Tags: []string{"staging"}},
+		{Name: "prod", URL: "https://prod.example.com", APIKey: "sk-ant-REDACTED"},
+		{Name: "stage-eu", URL: "https://eu.example.com", APIKey: "sk-ant-api03-eu-1234567890", Tags: []string{"eu", "staging"}},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+}
+
+func TestEnvironmentsWithTag(t *testing.T) {
+	envs := []Environment{{Name: "a", Tags: []string{"x"}}, {Name: "b"}, {Name: "c", Tags: []string{"y", "x"}}}
+	var names []string
+	for _, env := range environmentsWithTag(envs, "x") {
+		names = append(names, env.Name)
+	}
+	if strings.Join(names, ",") != "a,c" {
+		t.Errorf("expected a,c in config order, got %v", names)
+	}
+	if len(environmentsWithTag(envs, "z")) != 0 {
+		t.Error("unknown tag should match nothing")
+	}
+	if err := validateTags([]string{"ok", "has space"}); err == nil {
+		t.Error("tags with spaces should be rejected")
+	}
+}
+
+func TestGroupLaunchPrefixesOutputAndAggregatesExitCodes(t *testing.T) {
+	saveGroupConfig(t)
+	var launched []string
+	stubGroupLauncher(t, func(env Environment, args []string, stdout, stderr io.Writer) (int, error) {
+		launched = append(launched, env.Name)
+		fmt.Fprintf(stdout, "reply for %s\npartial", strings.Join(args, " "))
+		if env.Name == "stage-eu" {
+			fmt.Fprintln(stderr, "rate limited")
+			return 2, nil
+		}
+		return 0, nil
+	})
+
+	stdout, stderr, err := captureStdoutAndStderr(t, func() error {
+		return handleCommand([]string{"--group", "staging", "--", "-p", "hi"})
+	})
+	if strings.Join(launched, ",") != "stage-us,stage-eu" {
+		t.Errorf("expected tagged environments in order, got %v", launched)
+	}
+	for _, want := range []string{"[stage-us] reply for -p hi\n", "[stage-us] partial\n", "[stage-eu] Using environment: stage-eu", "Group staging: 1 succeeded, 1 failed"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout missing %q:\n%s", want, stdout)
+		}
+	}
+	if !strings.Contains(stderr, "[stage-eu] rate limited") {
+		t.Errorf("stderr should be prefixed too:\n%s", stderr)
+	}
+	if err == nil || exitCodeFor(err) != 2 || !strings.Contains(err.Error(), "stage-eu") {
+		t.Errorf("expected the failing environment's exit code, got %v", err)
+	}
+}
+
+func TestGroupLaunchSucceedsAndRejectsMisuse(t *testing.T) {
+	saveGroupConfig(t)
+	stubGroupLauncher(t, func(Environment, []string, io.Writer, io.Writer) (int, error) { return 0, nil })
+
+	if _, _, err := captureStdoutAndStderr(t, func() error {
+		return handleCommand([]string{"--group=staging", "-p", "hi"})
+	}); err != nil {
+		t.Errorf("expected success, got %v", err)
+	}
+
+	if err := handleCommand([]string{"--group", "missing", "--", "-p", "hi"}); err == nil || exitCodeFor(err) != 2 {
+		t.Errorf("expected a configuration error for an unknown group, got %v", err)
+	}
+	if err := handleCommand([]string{"--group", "staging", "--env", "prod"}); err == nil || exitCodeFor(err) != 6 {
+		t.Errorf("expected --group with --env to be rejected, got %v", err)
+	}
+}
+
+func TestListAndSelectFilterByTag(t *testing.T) {
+	saveGroupConfig(t)
+
+	opts, err := parseListOptions([]string{"--tag", "staging", "--names"})
+	if err != nil {
+		t.Fatalf("parseListOptions() failed: %v", err)
+	}
+	output := captureStdout(t, func() {
+		if err := runListWithOptions(opts); err != nil {
+			t.Fatalf("list --tag failed: %v", err)
+		}
+	})
+	if output != "stage-eu\nstage-us\n" {
+		t.Errorf("list --tag staging --names printed %q", output)
+	}
+
+	output = captureStdout(t, func() {
+		if err := runListWithOptions(listOptions{Tag: "eu"}); err != nil {
+			t.Fatalf("list --tag failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Configured environments (1):") || !strings.Contains(output, "Tags:  eu, staging") || strings.Contains(output, "stage-us") {
+		t.Errorf("list --tag eu should show only stage-eu with its tags, got %q", output)
+	}
+
+	if err := runListWithOptions(listOptions{Tag: "missing"}); err == nil || !strings.Contains(err.Error(), "no environments are tagged 'missing'") {
+		t.Errorf("expected an unknown tag error, got %v", err)
+	}
+	if _, err := parseListOptions([]string{"--tag="}); err == nil {
+		t.Error("list --tag= should require a tag")
+	}
+
+	// The picker is offered only the tagged environments
+	original := environmentSelector
+	t.Cleanup(func() { environmentSelector = original })
+	var offered []string
+	environmentSelector = func(config Config) (Environment, error) {
+		offered = nil
+		for _, env := range config.Environments {
+			offered = append(offered, env.Name)
+		}
+		return config.Environments[0], nil
+	}
+	selectOpts, err := parseSelectOptions([]string{"--tag", "staging"})
+	if err != nil {
+		t.Fatalf("parseSelectOptions() failed: %v", err)
+	}
+	output = captureStdout(t, func() {
+		if err := runSelect(selectOpts); err != nil {
+			t.Fatalf("select --tag failed: %v", err)
+		}
+	})
+	if strings.Join(offered, ",") != "stage-us,stage-eu" || output != "stage-us\n" {
+		t.Errorf("select --tag staging offered %v and printed %q", offered, output)
+	}
+}
+
+func TestConfigWithTag(t *testing.T) {
+	config := Config{Environments: []Environment{{Name: "prod"}, {Name: "lab", Tags: []string{"exp"}}}}
+	tagged, err := configWithTag(config, "exp")
+	if err != nil {
+		t.Fatalf("configWithTag() failed: %v", err)
+	}
+	if len(tagged.Environments) != 1 || tagged.Environments[0].Name != "lab" {
+		t.Errorf("expected only lab, got %+v", tagged)
+	}
+	if _, err := configWithTag(config, "none"); err == nil {
+		t.Error("a tag nothing carries should be an error")
+	}
+	if unchanged, _ := configWithTag(config, ""); len(unchanged.Environments) != 2 {
+		t.Errorf("an empty tag should leave the config alone, got %+v", unchanged)
+	}
+	if got := parseTags(" work, ,exp,work "); strings.Join(got, ",") != "work,exp" {
+		t.Errorf("parseTags() = %v", got)
+	}
+}
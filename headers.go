@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// customHeadersVar carries an environment's extra HTTP headers to claude, one "Name: value" per line
+const customHeadersVar = "ANTHROPIC_CUSTOM_HEADERS"
+
+// validateHeaderName accepts RFC 7230 token characters only, so a name can never
+// smuggle a separator or a line break into the header block
+func validateHeaderName(name string) error {
+	if name == "" {
+		return fmt.Errorf("header name cannot be empty")
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return fmt.Errorf("header name '%s' may only contain letters, digits and !#$%%&'*+-.^_`|~", name)
+		}
+	}
+	return nil
+}
+
+// validateHeaderValue rejects control characters, like validateAPIKey does for keys;
+// a newline would start a second header in ANTHROPIC_CUSTOM_HEADERS
+func validateHeaderValue(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("header value cannot be empty")
+	}
+	for _, r := range value {
+		if (r < 32 && r != '\t') || r == 127 {
+			return fmt.Errorf("header value contains control characters")
+		}
+	}
+	return nil
+}
+
+// validateHeaders checks every custom header of an environment
+func validateHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if err := validateHeaderName(name); err != nil {
+			return err
+		}
+		if err := validateHeaderValue(value); err != nil {
+			return fmt.Errorf("invalid header %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// headerNames returns the header names sorted for stable output
+func headerNames(headers map[string]string) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatCustomHeaders renders headers in the ANTHROPIC_CUSTOM_HEADERS format claude reads
+func formatCustomHeaders(headers map[string]string) string {
+	lines := make([]string, 0, len(headers))
+	for _, name := range headerNames(headers) {
+		lines = append(lines, name+": "+headers[name])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isSecretHeader reports whether a header's value should be masked when shown
+func isSecretHeader(name string) bool {
+	return isSecretVar(name, "") || strings.Contains(strings.ToUpper(name), "AUTH") || strings.Contains(strings.ToUpper(name), "COOKIE")
+}
+
+// maskCustomHeaders masks the secret-looking values in an ANTHROPIC_CUSTOM_HEADERS value
+// and reports whether it masked anything
+func maskCustomHeaders(value string) (string, bool) {
+	lines := strings.Split(value, "\n")
+	masked := false
+	for i, line := range lines {
+		name, headerValue, found := strings.Cut(line, ":")
+		if found && isSecretHeader(strings.TrimSpace(name)) {
+			lines[i] = name + ": " + maskAPIKey(strings.TrimSpace(headerValue))
+			masked = true
+		}
+	}
+	return strings.Join(lines, "\n"), masked
+}
+
+// headerLines renders env's headers sorted by name, masking secret-looking values
+func headerLines(env Environment, indent string) []string {
+	lines := make([]string, 0, len(env.Headers))
+	for _, name := range headerNames(env.Headers) {
+		value := env.Headers[name]
+		if isSecretHeader(name) {
+			value = maskAPIKey(value)
+		}
+		lines = append(lines, fmt.Sprintf("%s%s: %s", indent, name, value))
+	}
+	return lines
+}
+
+// headerInput reads commands in the header editor; tests replace it
+var headerInput = regularInput
+
+// promptEnvironmentHeaders edits custom HTTP headers in a loop: "Name: value" adds or
+// replaces, -Name removes, and Enter finishes. The current headers are shown each time.
+func promptEnvironmentHeaders(config Config, defaults promptDefaults, env *Environment) error {
+	env.Headers = make(map[string]string, len(defaults.Env.Headers))
+	for name, value := range defaults.Env.Headers {
+		env.Headers[name] = value
+	}
+	if _, printErr := fmt.Println("Custom HTTP headers sent with every request (optional), e.g. X-Gateway-Team: research:"); printErr != nil {
+		return fmt.Errorf("failed to display prompt: %w", printErr)
+	}
+
+	for {
+		lines := headerLines(*env, "  ")
+		if len(lines) == 0 {
+			lines = []string{"  (none)"}
+		}
+		if _, printErr := fmt.Println(strings.Join(lines, "\n")); printErr != nil {
+			return fmt.Errorf("failed to display headers: %w", printErr)
+		}
+
+		command, err := headerInput("Name: value to set, -Name to remove, Enter when done: ")
+		if err != nil {
+			return fmt.Errorf("failed to get header: %w", err)
+		}
+		command = strings.TrimSpace(command)
+		if command == "" {
+			break
+		}
+
+		message, err := applyHeaderCommand(env, command)
+		if err != nil {
+			message = "Not applied: " + err.Error()
+		}
+		if _, printErr := fmt.Println(message); printErr != nil {
+			return fmt.Errorf("failed to display result: %w", printErr)
+		}
+	}
+
+	if len(env.Headers) == 0 {
+		env.Headers = nil
+	}
+	return nil
+}
+
+// applyHeaderCommand applies one header editor command to env and describes the change;
+// an error explains why the command was rejected and leaves env untouched
+func applyHeaderCommand(env *Environment, command string) (string, error) {
+	if strings.HasPrefix(command, "-") {
+		name := command[1:]
+		if _, ok := env.Headers[name]; !ok {
+			return "", fmt.Errorf("no header named '%s'", name)
+		}
+		delete(env.Headers, name)
+		return fmt.Sprintf("Removed %s", name), nil
+	}
+
+	name, value, found := strings.Cut(command, ":")
+	if !found {
+		return "", fmt.Errorf("expected 'Name: value', got '%s'", command)
+	}
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if err := validateHeaderName(name); err != nil {
+		return "", err
+	}
+	if err := validateHeaderValue(value); err != nil {
+		return "", fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+
+	_, exists := env.Headers[name]
+	env.Headers[name] = value
+	if exists {
+		return fmt.Sprintf("Updated %s", name), nil
+	}
+	return fmt.Sprintf("Set %s", name), nil
+}
@@ -0,0 +1,759 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// colorDisabled is set by the global --no-color flag
+var colorDisabled bool
+
+// helpEntry is one aligned row in a help section
+type helpEntry struct {
+	Usage       string
+	Description string
+}
+
+// helpSection groups related help entries under a title
+type helpSection struct {
+	Title   string
+	Entries []helpEntry
+	Notes   []string // Free-form lines rendered after the entries
+}
+
+// commandHelp describes a subcommand for the overview and for `cce help <command>`
+type commandHelp struct {
+	Name     string
+	Args     string
+	Summary  string
+	Details  []string
+	Flags    []helpEntry
+	Examples []helpEntry
+}
+
+// helpCommands lists every subcommand in display order
+var helpCommands = []commandHelp{
+	{
+		Name:    "list",
+		Summary: "List all configured environments",
+		Flags: []helpEntry{
+			{"--names, -q", "Print bare environment names, one per line, sorted (reads names only, without validating, so it stays fast on large configs)"},
+			{"--wide, -w", "Also show each environment's notes"},
+			{"--check", "Probe each endpoint's connectivity and auth (Ctrl-C cancels)"},
+			{"--format <template>", "Print each environment with a Go text/template; helpers: mask, fingerprint, keyvar, model, provider, json"},
+			{"--json", "Print all environments as a JSON array, keys masked to their last four characters"},
+			{"--tag <tag>", "Only show environments carrying <tag> (combines with every other flag)"},
+			{"--sort <order>", "Order by last-used (most recent first, never-used last) or name instead of config order"},
+		},
+		Examples: []helpEntry{
+			{"cce list", "Show all environments with model information"},
+			{"for e in $(cce list --names); do ...; done", "Loop over environment names in a script"},
+			{"cce list --format '{{.Name}} {{.URL}} {{mask .APIKey}}'", "Custom columns for scripts"},
+			{"cce list --json | jq -r '.[].model'", "Read fields from scripts"},
+			{"cce list --tag work", "Show only the environments tagged 'work'"},
+			{"cce list --sort last-used", "See which environments you actually use"},
+		},
+	},
+	{
+		Name:    "add",
+		Summary: "Add a new environment configuration (supports model specification)",
+		Details: []string{
+			"Prompts for name, base URL, API key (hidden), key variable, model, and extra variables.",
+			"Before saving, a summary with the key masked is shown: Enter saves, 'e <field>' re-asks",
+			"one field, 'c' cancels. The review is skipped when stdin is not a terminal.",
+			"The endpoint is then probed once (5s timeout) and its status and latency shown; a",
+			"failure is a warning and the environment is saved anyway.",
+		},
+		Flags: []helpEntry{
+			{"--test", "Refuse to save unless the endpoint is reachable, accepts the key and is not a 404"},
+			{"--no-network-check", "Skip the reachability probe, e.g. when offline"},
+			{"--copy-env <name>", "Pre-fill URL, model, key variable and env vars from an environment"},
+			{"--copy-key", "With --copy-env, also offer the source API key as the default"},
+			{"--no-validate", "Accept a URL, key or model that fails strict checks; recorded and warned about at launch"},
+			{"--allow-dup-url=false", "Fail instead of warning when another environment uses the same URL"},
+			{"--normalize-url", "Strip trailing slashes from the URL before saving"},
+		},
+		Examples: []helpEntry{
+			{"cce add", "Add new environment interactively (with optional model)"},
+			{"cce add --copy-env prod", "Start from prod's settings; only a name and key are required"},
+			{"cce add --test", "Only save the environment if a connectivity+auth check passes"},
+			{"cce add --allow-dup-url=false", "Refuse to save if another environment already uses the URL"},
+		},
+	},
+	{
+		Name:    "remove",
+		Args:    "<name> | --all",
+		Summary: "Remove an environment configuration",
+		Details: []string{
+			"--all lists every environment and asks you to type 'yes' or the count before wiping them.",
+			"The configuration is backed up first; settings are kept.",
+		},
+		Flags: []helpEntry{
+			{"--all", "Remove every environment (full reset)"},
+			{"--yes, -y", "Skip the typed confirmation; required with --all when stdin is not a terminal"},
+		},
+		Examples: []helpEntry{
+			{"cce remove staging", "Delete the 'staging' environment"},
+			{"cce remove --all --yes", "Reset a machine from a script"},
+		},
+	},
+	{
+		Name:    "select",
+		Summary: "Pick an environment interactively and print or launch it",
+		Details: []string{
+			"Without --launch the chosen name is printed, e.g. for cce --env \"$(cce select)\".",
+			"'cce select --launch -- <args>' is equivalent to 'cce -- <args>'.",
+			"With --check each entry shows its endpoint's round-trip latency as probes finish;",
+			"results are cached for a minute. Numbered and headless selection skip the probes.",
+		},
+		Flags: []helpEntry{
+			{"--launch, -l", "Launch Claude Code with the picked environment"},
+			{"--check", "Probe endpoints in the background and show their latency"},
+			{"--tag <tag>", "Only offer environments carrying <tag>"},
+			{"-- <claude-args>", "Arguments passed to claude (with --launch)"},
+		},
+		Examples: []helpEntry{
+			{"cce select", "Print the picked environment name"},
+			{"cce select --launch -- chat --interactive", "Pick, then launch claude with chat --interactive"},
+			{"cce select --check --launch", "Pick the fastest reachable endpoint and launch"},
+			{"cce select --tag experiments --launch", "Pick among the 'experiments' environments and launch"},
+		},
+	},
+	{
+		Name:    "plan",
+		Args:    "[launch options] [-- claude-args...]",
+		Summary: "Show what a launch would do without starting claude",
+		Details: []string{
+			"Prints the selected environment, resolved model, every variable CCE sets (secrets masked),",
+			"inherited ANTHROPIC_* variables that are cleared, ~/.claude/settings.json env conflicts,",
+			"the claude binary path, and the final argv. Start here when switching \"doesn't work\".",
+		},
+		Flags: []helpEntry{
+			{"--json", "Print the plan as JSON"},
+		},
+		Examples: []helpEntry{
+			{"cce plan --env prod", "Explain a launch with the prod environment"},
+			{"cce plan --json -e dev -- chat", "Machine-readable plan including claude args"},
+		},
+	},
+	{
+		Name:    "export",
+		Args:    "[<name>...] [--redact] [--format json|env] [--output <file>]",
+		Summary: "Print environments in a portable form",
+		Details: []string{
+			"Writes every environment, or just the named ones, as JSON for cce import. --format env",
+			"prints the shell export lines a launch would set instead (single environment only).",
+		},
+		Flags: []helpEntry{
+			{"--env, -e <name>", "Export only this environment (same as naming it)"},
+			{"--redact", "Strip API keys and secret-looking variables so the output is safe to share"},
+			{"--format <json|env>", "Output format (default json)"},
+			{"--output, -o <file>", "Write to <file> with 0600 permissions instead of stdout"},
+		},
+		Examples: []helpEntry{
+			{"cce export --redact > envs.json", "Share all endpoints without keys"},
+			{"cce export prod staging --redact -o team.json", "Share two environments as a file"},
+			{"cce export --env prod --redact", "Send a teammate exactly one endpoint"},
+			{"eval \"$(cce export -e dev --format env)\"", "Load dev's variables into the current shell"},
+		},
+	},
+	{
+		Name:    "import",
+		Args:    "<file> [--on-conflict skip|rename|overwrite] | --from-env --name <name>",
+		Summary: "Merge environments from a cce export file, or save the current shell's variables",
+		Details: []string{
+			"A file ('-' for stdin) is merged into the config; every environment is validated first and",
+			"nothing is saved if one fails. Environments exported with --redact ask for their key on a",
+			"terminal. An overwrite never replaces an existing API key without confirmation (or --yes).",
+			"--from-env reads ANTHROPIC_BASE_URL (default https://api.anthropic.com), ANTHROPIC_API_KEY or",
+			"ANTHROPIC_AUTH_TOKEN, and ANTHROPIC_MODEL. The key variable found becomes the environment's",
+			"key_var. Fails if none are set, or if both key variables are.",
+		},
+		Flags: []helpEntry{
+			{"--on-conflict <mode>", "For names that exist: skip (default), rename (name-2, ...) or overwrite"},
+			{"--from-env", "Read ANTHROPIC_* variables from the current shell"},
+			{"--name <name>", "Name for the new environment"},
+		},
+		Examples: []helpEntry{
+			{"cce import team.json", "Add a teammate's environments, skipping names you have"},
+			{"cce import team.json --on-conflict rename", "Keep both: clashing names get a -2 suffix"},
+			{"cce import --from-env --name prod", "Capture a setup that already works in this shell"},
+		},
+	},
+	{
+		Name:    "serve",
+		Args:    "--stdio",
+		Summary: "Answer JSON requests on stdin for editors and other tools",
+		Details: []string{
+			"Reads one request per line: {\"id\": 1, \"method\": \"get\", \"params\": {\"name\": \"prod\"}}.",
+			"Methods: list, get (name), add (environment), remove (name), prepare-env (name).",
+			"Each response line echoes id with result, or error {category, exit_code, message}",
+			"using the CLI's exit codes. list and get omit API keys; prepare-env returns the",
+			"variables a launch sets, key included, and the inherited ones it unsets.",
+		},
+		Flags: []helpEntry{
+			{"--stdio", "Use stdin/stdout as the transport (required)"},
+		},
+		Examples: []helpEntry{
+			{"echo '{\"id\":1,\"method\":\"list\"}' | cce serve --stdio", "List environments as JSON"},
+		},
+	},
+	{
+		Name:    "shell",
+		Args:    "<name> [--shell posix|fish|powershell] | --unset [<name>]",
+		Summary: "Print statements that load an environment into the current shell",
+		Details: []string{
+			"Output is meant for eval and includes the API key unmasked. Inherited ANTHROPIC_*",
+			"variables the environment does not set are unset first, as a launch would drop them.",
+			"--unset removes the environment's variables, or every ANTHROPIC_* variable without a name.",
+		},
+		Flags: []helpEntry{
+			{"--shell <dialect>", "posix (bash/zsh, default), fish, or powershell"},
+			{"--unset", "Print unset statements instead of exports"},
+		},
+		Examples: []helpEntry{
+			{"eval \"$(cce shell prod)\"", "Talk to prod from this shell without launching claude"},
+			{"cce shell prod --shell fish | source", "Same in fish"},
+			{"cce shell prod --shell powershell | Invoke-Expression", "Same in PowerShell"},
+			{"eval \"$(cce shell --unset)\"", "Clear the Anthropic variables again"},
+		},
+	},
+	{
+		Name:    "use",
+		Args:    "<name> [--shell posix|fish|powershell]",
+		Summary: "Same as shell: print export statements for eval",
+		Details: []string{
+			"The exported API key stays in the shell and in every process started from it;",
+			"prefer launching through cce when you do not need the variables afterwards.",
+		},
+		Examples: []helpEntry{
+			{"eval \"$(cce use prod)\"", "Export prod's URL, key variable and model here"},
+		},
+	},
+	{
+		Name:    "models",
+		Args:    "[--refresh] [name...] | list-patterns [--test <model>] | add-pattern <regex> | remove-pattern <regex>",
+		Summary: "Show or refresh each endpoint's cached model list",
+		Details: []string{
+			"Model lists come from each endpoint's /v1/models and are cached per URL in",
+			"~/.claude-code-env/models-cache.json so model checks keep working offline.",
+			"'models patterns' lists the model validator's patterns in match order with their source",
+			"(builtin, env, config or file) and whether strict mode is on; --test shows which pattern",
+			"a model matches and whether it is accepted, exiting non-zero if it is rejected.",
+			"'list-patterns' is the same. 'add-pattern' checks that a regex compiles and saves it to",
+			"settings.validation.model_patterns; 'remove-pattern' deletes one saved there.",
+		},
+		Flags: []helpEntry{
+			{"--refresh", "Fetch and cache model lists now (Ctrl-C cancels)"},
+			{"--test <model>", "With patterns: explain how the validator treats <model>"},
+		},
+		Examples: []helpEntry{
+			{"cce models --refresh", "Refresh every environment's model list"},
+			{"cce models prod", "Show prod's cached models and when they were fetched"},
+			{"cce models patterns --test acme-large-1", "Find out why acme-large-1 is rejected"},
+			{"cce models add-pattern '^acme-(large|small)-[0-9]+$'", "Accept a gateway's own model names"},
+		},
+	},
+	{
+		Name:    "set",
+		Args:    "[--no-validate] [--no-network-check] <name> <field=value>...",
+		Summary: "Update individual fields of an environment",
+		Details: []string{
+			"Only the named fields change; everything else, including the API key, is left as is.",
+			"Fields: url, api_key, model, key_var, auth_scheme, notes, settings_dir, ca_cert, provider, region, project,",
+			"check_timeout, check_retries, min_claude_version, max_claude_version, trust_args, api_keys,",
+			"key_strategy, tags, env.NAME, header.NAME. An empty value clears an optional field. api_key=- prompts for the new key",
+			"(or reads it from piped stdin).",
+			"--no-validate keeps url, api_key or model values that fail strict checks (marked unvalidated).",
+			"A new url is probed like in add and saved even if unreachable; --no-network-check skips that.",
+			"trust_args=true stops warning about shell metacharacters ($, |, ;, &, `) in claude arguments",
+			"for that environment. Only use it if you never pass untrusted text to claude: anything that",
+			"later hands the arguments to a shell sees them unchecked. --strict-args still rejects them.",
+		},
+		Examples: []helpEntry{
+			{"cce set prod url=https://new.example.com", "Point prod at a new endpoint, keeping its key"},
+			{"cce set prod model=", "Clear prod's model so claude uses its default"},
+			{"cce set prod api_key=-", "Rotate prod's key via hidden input"},
+			{"cce set dev env.ANTHROPIC_TIMEOUT=60", "Set one extra variable"},
+			{"cce set work settings_dir=~/.claude-work", "Give work its own claude settings and history"},
+			{"cce set corp ca_cert=~/certs/proxy-ca.pem", "Trust a corporate proxy's CA for corp's launches and checks"},
+			{"cce set lab check_timeout=30s check_retries=2", "Give a slow, flaky endpoint more time in checks"},
+			{"cce set aws provider=bedrock region=us-east-1", "Route aws through Amazon Bedrock"},
+			{"cce set legacy max_claude_version=1.0.99", "Refuse to launch legacy with a newer claude"},
+			{"cce set scratch trust_args=true", "Launch scratch without metacharacter warnings"},
+			{"cce set team api_keys=KEY2,KEY3", "Rotate team's launches across three keys (round-robin)"},
+		},
+	},
+	{
+		Name:    "copy",
+		Args:    "<source> <new-name> [<field=value>...]",
+		Summary: "Clone an environment under a new name",
+		Details: []string{
+			"Copies every field, including env vars, model and tags, but not the source's key rotation",
+			"history. field=value pairs change the copy as with set; api_key=- prompts for its key.",
+			"Without them, a terminal asks for a new API key (Enter keeps the source's) and any other",
+			"changes; without a terminal the copy is exact. The new name must not exist yet.",
+			"For the full add prompts seeded from an environment, use 'cce add --copy-env <source>'.",
+		},
+		Examples: []helpEntry{
+			{"cce copy prod staging", "Clone prod, asked for staging's key"},
+			{"cce copy prod staging api_key=- model=claude-sonnet-4-20250514", "Clone with a new key and model"},
+		},
+	},
+	{
+		Name:    "rename",
+		Args:    "<name> <new-name>",
+		Summary: "Rename an environment, keeping its place in the list",
+		Details: []string{
+			"Only the name changes; the environment keeps its position, fields and key rotation state.",
+			"The new name must not belong to another environment.",
+		},
+		Examples: []helpEntry{
+			{"cce rename staging qa", "Call staging qa from now on"},
+		},
+	},
+	{
+		Name:    "default",
+		Args:    "[<name> | --clear]",
+		Summary: "Show, set or clear the environment launched when --env is not given",
+		Details: []string{
+			"With a default set, cce launches it directly when stdin is not a terminal (scripts, pipes);",
+			"on a terminal, and with select --launch, the picker is still shown. cce list marks it with *.",
+			"If the default is removed or renamed away, cce warns and asks as before.",
+		},
+		Examples: []helpEntry{
+			{"cce default prod", "Launch prod when no --env is given and stdin is not a terminal"},
+			{"cce default --clear", "Stop launching a default without asking"},
+		},
+	},
+	{
+		Name:    "test",
+		Args:    "[<name>...] [--timeout <duration>] [--retries <n>] [--insecure]",
+		Summary: "Check connectivity and authentication of environments",
+		Details: []string{
+			"Checks all environments, or only the named ones, 4 at a time. Each environment's",
+			"check_timeout and check_retries apply (then settings.network, then 10s and no retries).",
+			"Unreachable endpoints, 5xx and 429 responses are retried; a rejected key is not.",
+			"Environments with api_keys are checked once per key, labelled by key fingerprint.",
+		},
+		Flags: []helpEntry{
+			{"--timeout <duration>", "Per-attempt timeout for every environment, e.g. 30s"},
+			{"--retries <n>", "Retries after a transient failure for every environment (0-10)"},
+			{"--insecure", "Skip TLS certificate checks, to tell trust problems from auth ones (never used for launches)"},
+		},
+		Examples: []helpEntry{
+			{"cce test", "Check every environment"},
+			{"cce test lab --timeout 1m --retries 3", "Give one flaky endpoint more chances"},
+			{"cce test corp --insecure", "Does corp fail on its certificate or on its key?"},
+		},
+	},
+	{
+		Name:    "ping",
+		Args:    "<name> [--timeout <duration>] [--retries <n>] [--insecure]",
+		Summary: "Check one environment's endpoint and key before a session",
+		Details: []string{
+			"GETs <url>/v1/models with the environment's key and custom headers, then shows the",
+			"endpoint, the key (masked), the HTTP status, the response time and whether the key was",
+			"accepted. Exits non-zero when the endpoint is unreachable, errors or rejects the key.",
+			"Takes the same flags as test; with api_keys every key is pinged.",
+		},
+		Flags: []helpEntry{
+			{"--timeout <duration>", "Per-attempt timeout instead of the environment's check_timeout"},
+			{"--retries <n>", "Retries after a transient failure (0-10)"},
+			{"--insecure", "Skip TLS certificate checks (never used for launches)"},
+		},
+		Examples: []helpEntry{
+			{"cce ping prod", "Is prod up, and does it accept the key?"},
+			{"cce ping lab --timeout 3s && cce --env lab", "Only start a session if lab answers quickly"},
+		},
+	},
+	{
+		Name:    "verify",
+		Args:    "--all | <name>... [--concurrency <n>] [--insecure]",
+		Summary: "Run the full launch pipeline checks, for CI smoke tests",
+		Details: []string{
+			"Checks that claude is on PATH, then for each environment: the stored config validates,",
+			"the launch environment can be prepared, and the endpoint is reachable and accepts the key.",
+			"Prints a per-stage report and exits non-zero if anything failed.",
+		},
+		Flags: []helpEntry{
+			{"--all", "Verify every environment"},
+			{"--concurrency <n>", "Environments verified at once (default 4)"},
+			{"--insecure", "Skip TLS certificate checks in the network stage (never used for launches)"},
+		},
+		Examples: []helpEntry{
+			{"cce verify --all", "Smoke-test every environment in CI"},
+			{"cce verify prod staging", "Verify two environments"},
+		},
+	},
+	{
+		Name:    "current",
+		Summary: "Print the environment of the running claude session, for shell prompts",
+		Details: []string{
+			"Each launch records its environment in $XDG_RUNTIME_DIR/cce-current (or cce-current in the",
+			"config directory); the first line is the name. Exits non-zero when that session has ended.",
+		},
+		Examples: []helpEntry{
+			{"PS1='$(cce current 2>/dev/null) '$PS1", "Show the active environment in a bash prompt"},
+		},
+	},
+	{
+		Name:    "rotate",
+		Args:    "[--test] <name> | --rollback <name>",
+		Summary: "Replace an environment's API key, keeping the old one for rollback",
+		Details: []string{
+			"Prompts for the new key with hidden input (or reads it from piped stdin). The replaced",
+			"key is kept with a timestamp; --rollback swaps it back, and rolling back twice undoes it.",
+		},
+		Flags: []helpEntry{
+			{"--test", "Save only if the endpoint accepts the new key"},
+			{"--rollback", "Restore the previous key"},
+		},
+		Examples: []helpEntry{
+			{"cce rotate --test prod", "Rotate prod's key after checking the new one works"},
+			{"pass show api/prod | cce rotate prod", "Rotate from a password manager"},
+			{"cce rotate --rollback prod", "Go back to the previous key"},
+		},
+	},
+	{
+		Name:    "secrets",
+		Args:    "migrate [<name>...]",
+		Summary: "Move plaintext API keys out of the config into the OS keychain",
+		Details: []string{
+			"Keys go to the macOS Keychain (security) or the Secret Service (secret-tool) on Linux, and",
+			"to secrets.json (0600) next to the config when neither is available or CCE_SECRET_STORE=file.",
+			"The config keeps references like keychain:cce/prod-3f9a1c2e, read when claude launches or cce tests.",
+		},
+		Examples: []helpEntry{
+			{"cce secrets migrate", "Move every environment's keys"},
+			{"cce set prod api_key=keychain:cce/prod", "Point at a key you stored yourself"},
+		},
+	},
+	{
+		Name:    "doctor",
+		Args:    "[--fix [--yes|-y]]",
+		Summary: "Diagnose common setup problems and optionally repair them",
+		Details: []string{
+			"Checks that the config parses, that its directory and file are 0700/0600, that every",
+			"environment URL is valid, that the default environment still exists, that claude is",
+			"on PATH, that no --wk worktree in the current repository has lost its directory, and",
+			"that ~/.claude/settings.json does not override variables cce sets. Warns ([warn]) when",
+			"a key's prefix suggests a different key variable (sk-ant-api: ANTHROPIC_API_KEY,",
+			"sk-ant-oat: ANTHROPIC_AUTH_TOKEN).",
+			"Exits non-zero if any check still fails; warnings do not count.",
+			"--fix offers each repair in turn and reports what it changed.",
+		},
+		Flags: []helpEntry{
+			{"--fix", "Offer to repair each failed check"},
+			{"--yes, -y", "Apply every fix without asking (required without a terminal)"},
+		},
+		Examples: []helpEntry{
+			{"cce doctor", "Show the checklist"},
+			{"cce doctor --fix", "Repair problems one confirmation at a time"},
+		},
+	},
+	{
+		Name:    "config",
+		Args:    "<action>",
+		Summary: "Manage the configuration file (actions: migrate, set-setting, get-setting)",
+		Details: []string{
+			"migrate converts a legacy list or map-based config into the canonical format.",
+			"The original file is backed up first; a map config's default environment is listed first.",
+			"set-setting <key> <value> changes one entry under settings by its dotted JSON path,",
+			"checking the value's type and validating it as loading would. Lists are comma-separated",
+			"and an empty value clears the setting. get-setting <key> prints one (sections as JSON).",
+		},
+		Flags: []helpEntry{
+			{"--dry-run", "Report what migrate would do without writing"},
+		},
+		Examples: []helpEntry{
+			{"cce config migrate", "Rewrite a legacy config in the canonical format"},
+			{"cce config migrate --dry-run", "Preview the migration"},
+			{"cce config set-setting validation.strict_validation false", "Change one setting from a script"},
+			{"cce config get-setting network.check_timeout", "Print one setting"},
+		},
+	},
+	{
+		Name:    "init",
+		Summary: "Create a first configuration with guidance",
+		Details: []string{
+			"Prompts for a first environment with inline field guidance, creating the config",
+			"directory with 0700 permissions. Refuses to touch an existing config without --force.",
+		},
+		Flags: []helpEntry{
+			{"--template", "Write an annotated template config instead of prompting"},
+			{"--force, -f", "Overwrite an existing config (a backup is taken first)"},
+		},
+		Examples: []helpEntry{
+			{"cce init", "Create your first environment interactively"},
+			{"cce init --template", "Write a documented starter config to edit by hand"},
+		},
+	},
+	{
+		Name:    "completion",
+		Args:    "bash|zsh|fish",
+		Summary: "Print a shell completion script for commands, flags and environment names",
+		Details: []string{
+			"Environment names are looked up each time you press Tab, so new environments complete at once.",
+			"bash: add 'source <(cce completion bash)' to ~/.bashrc",
+			"zsh:  add 'source <(cce completion zsh)' to ~/.zshrc (after compinit)",
+			"fish: cce completion fish > ~/.config/fish/completions/cce.fish",
+		},
+		Examples: []helpEntry{
+			{"source <(cce completion bash)", "Enable completion in the current bash session"},
+		},
+	},
+	{
+		Name:    "version",
+		Args:    "[--json]",
+		Summary: "Show version and build information",
+		Flags: []helpEntry{
+			{"--json", "Print version, commit, build date, Go version, OS and arch as JSON"},
+		},
+	},
+	{
+		Name:    "help",
+		Args:    "[command]",
+		Summary: "Show this help message or help for a command",
+		Examples: []helpEntry{
+			{"cce help add", "Show help for the add command"},
+		},
+	},
+}
+
+// globalHelpFlags lists options accepted before a command or for launching
+var globalHelpFlags = []helpEntry{
+	{"-e, --env <name>", "Use specific environment (@path reads the name from a file's first line); --env=name and -ename also work"},
+	{"-k, --key-var <name>", "Override API key env var for this run (ANTHROPIC_API_KEY|ANTHROPIC_AUTH_TOKEN)"},
+	{"-m, --model <name>", "Use this model for this run, over the environment's model, env_vars and default_model"},
+	{"    --env-file <path>", "Merge KEY=value lines from a dotenv file (environment env_vars win)"},
+	{"    --group <tag>", "Run claude in turn for every environment tagged <tag>, output prefixed with [name]; exit code is the highest seen"},
+	{"    --wk", "Launch from a git worktree, reusing this branch's CCE worktree if present"},
+	{"    --wk-fresh", "Like --wk but always create a new worktree"},
+	{"    --wk-path <dir>", "Create the worktree in <dir> instead of the temp dir (settings.worktree.base_path); implies --wk"},
+	{"    --wk-name <name>", "Name the worktree <project>-<branch>-<name> instead of adding a timestamp; implies --wk"},
+	{"    --wk-cleanup", "Like --wk, then remove the worktree cce created once claude exits (kept if it has uncommitted changes)"},
+	{"    --force", "With --wk-cleanup, remove the worktree even with uncommitted changes"},
+	{"    --yolo", "Shortcut for --dangerously-skip-permissions (passed to claude)"},
+	{"    --detach", "Start claude in the background and print its PID (requires -p/--print on a terminal)"},
+	{"    --log-file <path>", "Also append claude's output to <path> (0600); ignored with a warning for interactive sessions"},
+	{"    --log-only", "With --log-file, send claude's output only to the file"},
+	{"    --wait", "Wait for claude to exit (the default)"},
+	{"    --watch", "Relaunch claude each time it exits, pausing longer after quick exits; Ctrl-C between runs stops"},
+	{"    --watch-max <n>", "With --watch, stop after <n> restarts"},
+	{"    --print-env-diff", "Show variables the launch would add, override or clear (and settings.json collisions), then exit"},
+	{"    --dry-run", "Print the environment, variables (keys masked) and claude argv, then exit without launching"},
+	{"    --override-settings", "Write this environment's values into a ~/.claude/settings.json that would override them (backup kept)"},
+	{"    --skip-preflight", "Launch without running settings.preflight.command"},
+	{"    --strict-args", "Reject claude arguments containing shell metacharacters instead of warning, even for trust_args environments"},
+	{"    --strict-flags", "Reject unknown flags before the claude arguments (suggesting the closest cce flag) instead of passing them to claude"},
+	{"    --no-color", "Disable colored output (also honors NO_COLOR)"},
+	{"-y, --yes", "Answer yes to every confirmation (before the command; also CCE_ASSUME_YES=1)"},
+	{"    --config <path>", "Use this config file instead of ~/.claude-code-env/config.json (also CCE_CONFIG_PATH)"},
+	{"    --chdir <path>", "Run git/--wk and read relative --env @file and --env-file paths as if from <path>; claude starts where you are"},
+	{"    --no-prompt", "Fail (exit code 7) instead of waiting for any input: selection, confirmation or a typed key (also CCE_NO_PROMPT=1)"},
+	{"-h, --help", "Show help"},
+	{"    --version", "Show version information"},
+}
+
+// findCommandHelp returns the help topic for a subcommand name
+func findCommandHelp(name string) (commandHelp, bool) {
+	for _, cmd := range helpCommands {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return commandHelp{}, false
+}
+
+// commandUsage renders "name args" for a subcommand
+func (c commandHelp) commandUsage() string {
+	if c.Args == "" {
+		return c.Name
+	}
+	return c.Name + " " + c.Args
+}
+
+// buildHelpSections assembles the overview help model
+func buildHelpSections() []helpSection {
+	commands := helpSection{Title: "Commands"}
+	for _, cmd := range helpCommands {
+		commands.Entries = append(commands.Entries, helpEntry{cmd.commandUsage(), cmd.Summary})
+	}
+
+	return []helpSection{
+		{
+			Title: "Usage",
+			Notes: []string{"cce [command] [options] [-- claude-args...]"},
+		},
+		commands,
+		{
+			Title:   "Options",
+			Entries: globalHelpFlags,
+		},
+		{
+			Title: "Flag Passthrough",
+			Notes: []string{
+				"Any arguments after CCE options are passed directly to the claude command.",
+				"Use '--' to explicitly separate CCE options from claude arguments.",
+			},
+		},
+		{
+			Title: "Environment Variables",
+			Entries: []helpEntry{
+				{"CCE_VERBOSE=1", "Show the resolved model and its source when launching"},
+				{"NO_COLOR=1", "Disable colored output"},
+				{"CCE_STRICT_ARGS=1", "Same as --strict-args"},
+				{"CCE_STRICT_FLAGS=1", "Same as --strict-flags"},
+				{"CCE_MODEL_PATTERNS_FILE=<path>", "Extra model patterns, one regex per line"},
+				{"CCE_CONFIG_PATH=<path>", "Same as --config"},
+			},
+		},
+		{
+			Title: "Features",
+			Notes: []string{
+				"• Interactive arrow key navigation (↑↓ arrows, Enter to select, Esc to cancel, / to filter)",
+				"• Long lists are paged to fit the terminal (PgUp/PgDn; n/p in numbered mode)",
+				"• Configurable selector keys via settings.key_bindings (preset \"vim\" adds j/k/q)",
+				"• Optional model specification per environment (e.g., claude-3-5-sonnet-20241022)",
+				"• Automatic fallback to numbered selection on incompatible terminals",
+				"• Responsive UI layout adapts to terminal width",
+				"• Smart content truncation for long environment names and URLs",
+			},
+		},
+		{
+			Title: "Examples",
+			Entries: []helpEntry{
+				{"cce", "Interactive selection and launch Claude Code"},
+				{"cce --env prod", "Launch Claude Code with 'prod' environment"},
+				{"cce list", "Show all environments with model information"},
+				{"cce add", "Add new environment interactively (with optional model)"},
+			},
+		},
+		{
+			Title: "Flag Passthrough Examples",
+			Entries: []helpEntry{
+				{"cce --env staging -r", "Launch claude with 'staging' env and -r flag"},
+				{"cce --verbose --model claude-3", "Pass --verbose and --model flags to claude"},
+				{"cce -- --help", "Show claude's help (-- separates CCE from claude flags)"},
+				{"cce -- chat --interactive", "Pick an environment interactively, then pass chat flags to claude"},
+				{"cce -e dev -- chat --interactive", "Use 'dev' env and pass chat flags to claude"},
+				{"cce --env dev --key-var ANTHROPIC_AUTH_TOKEN -- chat", "Override key var for this run"},
+				{"cce --env dev --model claude-opus-4-1", "Launch 'dev' with another model for this run only"},
+				{"cce --yolo", "Launch claude with --dangerously-skip-permissions"},
+				{"cce --env prod --yolo", "Use 'prod' env and bypass permissions"},
+				{"cce --yolo --yolo -- command", "Multiple --yolo flags (each becomes --dangerously-skip-permissions)"},
+			},
+		},
+		{
+			Title: "Worktree (--wk) Examples",
+			Entries: []helpEntry{
+				{"cce --wk --env prod -- chat --verbose", "Create git worktree then launch Claude Code with prod env"},
+				{"cce --wk -- --help", "Create git worktree and pass --help to Claude Code"},
+				{"cce --wk-fresh", "Create a new worktree even if one exists for this branch"},
+				{"git worktree remove <path>", "Manually remove a worktree after use"},
+				{"git worktree prune", "Clean up stale git worktrees"},
+			},
+		},
+	}
+}
+
+// buildCommandHelpSections assembles the help model for a single subcommand
+func buildCommandHelpSections(cmd commandHelp) []helpSection {
+	usage := "cce " + cmd.commandUsage()
+	if len(cmd.Flags) > 0 {
+		usage += " [flags]"
+	}
+
+	sections := []helpSection{
+		{Title: "Usage", Notes: []string{usage}},
+		{Title: "Description", Notes: append([]string{cmd.Summary}, cmd.Details...)},
+	}
+	if len(cmd.Flags) > 0 {
+		sections = append(sections, helpSection{Title: "Flags", Entries: cmd.Flags})
+	}
+	if len(cmd.Examples) > 0 {
+		sections = append(sections, helpSection{Title: "Examples", Entries: cmd.Examples})
+	}
+	return sections
+}
+
+// maxHelpColumn caps the usage column so one long entry doesn't push every description away
+const maxHelpColumn = 36
+
+// renderHelp writes help sections with aligned columns and optional color
+func renderHelp(w io.Writer, title string, sections []helpSection, color bool) error {
+	paint := func(code, text string) string {
+		if !color {
+			return text
+		}
+		return "\033[" + code + "m" + text + "\033[0m"
+	}
+
+	if _, err := fmt.Fprintln(w, paint("1", title)); err != nil {
+		return fmt.Errorf("failed to display help: %w", err)
+	}
+
+	for _, section := range sections {
+		if _, err := fmt.Fprintf(w, "\n%s:\n", paint("1", section.Title)); err != nil {
+			return fmt.Errorf("failed to display help: %w", err)
+		}
+
+		width := 0
+		for _, entry := range section.Entries {
+			if l := len(entry.Usage); l > width && l <= maxHelpColumn {
+				width = l
+			}
+		}
+
+		for _, entry := range section.Entries {
+			usage := paint("36", entry.Usage)
+			var line string
+			if len(entry.Usage) > maxHelpColumn {
+				line = fmt.Sprintf("  %s\n  %s  %s", usage, strings.Repeat(" ", width), entry.Description)
+			} else {
+				line = fmt.Sprintf("  %s%s  %s", usage, strings.Repeat(" ", width-len(entry.Usage)), entry.Description)
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return fmt.Errorf("failed to display help: %w", err)
+			}
+		}
+
+		for _, note := range section.Notes {
+			if _, err := fmt.Fprintf(w, "  %s\n", note); err != nil {
+				return fmt.Errorf("failed to display help: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// helpColorEnabled decides whether help output should use ANSI colors
+func helpColorEnabled() bool {
+	if colorDisabled || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if termType := os.Getenv("TERM"); termType == "" || termType == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// showHelp displays usage information including flag passthrough capability
+func showHelp() {
+	_ = renderHelp(os.Stdout, "Claude Code Environment Switcher", buildHelpSections(), helpColorEnabled())
+}
+
+// showCommandHelp displays help for a single subcommand
+func showCommandHelp(name string) error {
+	cmd, ok := findCommandHelp(name)
+	if !ok {
+		return fmt.Errorf("unknown help topic '%s' (run 'cce help' for the command list)", name)
+	}
+	return renderHelp(os.Stdout, "cce "+cmd.Name, buildCommandHelpSections(cmd), helpColorEnabled())
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderHelpAlignment(t *testing.T) {
+	sections := []helpSection{
+		{
+			Title: "Commands",
+			Entries: []helpEntry{
+				{"list", "List environments"},
+				{"remove <name>", "Remove an environment"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := renderHelp(&buf, "Title", sections, false); err != nil {
+		t.Fatalf("renderHelp() failed: %v", err)
+	}
+
+	output := buf.String()
+	if strings.Contains(output, "\033[") {
+		t.Errorf("expected no ANSI codes when color is disabled: %q", output)
+	}
+
+	listCol := -1
+	removeCol := -1
+	for _, line := range strings.Split(output, "\n") {
+		if strings.Contains(line, "List environments") {
+			listCol = strings.Index(line, "List environments")
+		}
+		if strings.Contains(line, "Remove an environment") {
+			removeCol = strings.Index(line, "Remove an environment")
+		}
+	}
+	if listCol == -1 || listCol != removeCol {
+		t.Errorf("descriptions not aligned: list=%d remove=%d\n%s", listCol, removeCol, output)
+	}
+}
+
+func TestRenderHelpColor(t *testing.T) {
+	var buf bytes.Buffer
+	sections := []helpSection{{Title: "Options", Entries: []helpEntry{{"--env", "Use env"}}}}
+	if err := renderHelp(&buf, "Title", sections, true); err != nil {
+		t.Fatalf("renderHelp() failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\033[36m--env\033[0m") {
+		t.Errorf("expected colored flag usage, got %q", buf.String())
+	}
+}
+
+func TestHelpColorEnabledRespectsNoColor(t *testing.T) {
+	original := colorDisabled
+	defer func() { colorDisabled = original }()
+
+	colorDisabled = true
+	if helpColorEnabled() {
+		t.Error("expected --no-color to disable color")
+	}
+
+	colorDisabled = false
+	t.Setenv("NO_COLOR", "1")
+	if helpColorEnabled() {
+		t.Error("expected NO_COLOR to disable color")
+	}
+}
+
+func TestCommandHelp(t *testing.T) {
+	t.Run("add help documents its flags", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			if err := showCommandHelp("add"); err != nil {
+				t.Fatalf("showCommandHelp(add) failed: %v", err)
+			}
+		})
+		for _, snippet := range []string{"cce add", "--test", "Examples:"} {
+			if !strings.Contains(output, snippet) {
+				t.Errorf("add help missing %q:\n%s", snippet, output)
+			}
+		}
+	})
+
+	t.Run("every command has a topic", func(t *testing.T) {
+		for _, cmd := range helpCommands {
+			if _, ok := findCommandHelp(cmd.Name); !ok {
+				t.Errorf("missing help topic for %s", cmd.Name)
+			}
+		}
+	})
+
+	t.Run("unknown topic errors", func(t *testing.T) {
+		if err := showCommandHelp("bogus"); err == nil {
+			t.Error("expected unknown help topic error")
+		}
+	})
+
+	t.Run("help subcommand routes topic", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			if err := handleCommand([]string{"help", "remove"}); err != nil {
+				t.Fatalf("handleCommand(help remove) failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "cce remove <name>") {
+			t.Errorf("expected remove usage, got %q", output)
+		}
+	})
+}
+
+func TestNoColorGlobalFlag(t *testing.T) {
+	original := colorDisabled
+	defer func() { colorDisabled = original }()
+
+	result := parseArguments([]string{"--no-color", "help"})
+	if result.Subcommand != "help" || result.CCEFlags["no_color"] != "true" {
+		t.Fatalf("unexpected parse result: %+v", result)
+	}
+
+	result = parseArguments([]string{"--no-color", "--env", "prod", "chat"})
+	if result.CCEFlags["env"] != "prod" || len(result.ClaudeArgs) != 1 || result.ClaudeArgs[0] != "chat" {
+		t.Fatalf("--no-color leaked into launch parsing: %+v", result)
+	}
+
+	output := captureStdout(t, func() {
+		if err := handleCommand([]string{"--no-color", "help"}); err != nil {
+			t.Fatalf("handleCommand failed: %v", err)
+		}
+	})
+	if strings.Contains(output, "\033[") || !colorDisabled {
+		t.Errorf("expected uncolored help output")
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// defaultImportURL is used by import --from-env when ANTHROPIC_BASE_URL is unset, matching claude's own default
+const defaultImportURL = "https://api.anthropic.com"
+
+// What import does with an environment whose name is already taken
+const (
+	conflictSkip      = "skip"      // Keep the existing environment (default)
+	conflictRename    = "rename"    // Import under the first free name-2, name-3, ...
+	conflictOverwrite = "overwrite" // Replace the existing environment
+)
+
+// importOptions holds flags accepted by the import subcommand
+type importOptions struct {
+	FromEnv    bool   // Read ANTHROPIC_* variables from the current shell
+	Name       string // Name for the imported environment
+	File       string // cce export document to merge ("-" reads stdin)
+	OnConflict string
+}
+
+// parseImportOptions parses `import --from-env --name <name>` and `import <file> [--on-conflict ...]`
+func parseImportOptions(args []string) (importOptions, error) {
+	opts := importOptions{OnConflict: conflictSkip}
+	conflictSet := false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--from-env":
+			opts.FromEnv = true
+		case arg == "--name":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--name flag requires an environment name")
+			}
+			i++
+			opts.Name = args[i]
+		case strings.HasPrefix(arg, "--name="):
+			opts.Name = strings.TrimPrefix(arg, "--name=")
+		case arg == "--on-conflict" || strings.HasPrefix(arg, "--on-conflict="):
+			value := strings.TrimPrefix(arg, "--on-conflict=")
+			if arg == "--on-conflict" {
+				if i+1 >= len(args) {
+					return opts, fmt.Errorf("--on-conflict flag requires skip, rename or overwrite")
+				}
+				i++
+				value = args[i]
+			}
+			switch value {
+			case conflictSkip, conflictRename, conflictOverwrite:
+				opts.OnConflict, conflictSet = value, true
+			default:
+				return opts, fmt.Errorf("invalid --on-conflict '%s' (expected skip, rename or overwrite)", value)
+			}
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			if opts.File != "" {
+				return opts, fmt.Errorf("import reads one file at a time")
+			}
+			opts.File = arg
+		default:
+			return opts, fmt.Errorf("unknown import flag '%s'", arg)
+		}
+	}
+	switch {
+	case opts.FromEnv && opts.File != "":
+		return opts, fmt.Errorf("import reads either --from-env or a file, not both")
+	case opts.File != "":
+		if opts.Name != "" {
+			return opts, fmt.Errorf("--name only applies to --from-env; imported environments keep their names")
+		}
+		return opts, nil
+	case !opts.FromEnv:
+		return opts, fmt.Errorf("import needs a source: a file from cce export, or --from-env")
+	case conflictSet:
+		return opts, fmt.Errorf("--on-conflict only applies to importing a file")
+	case opts.Name == "":
+		return opts, fmt.Errorf("--from-env requires --name <name> for the new environment")
+	}
+	return opts, nil
+}
+
+// environmentFromShell builds an environment from the ANTHROPIC_* variables claude reads.
+// The key variable it was found in becomes APIKeyEnv, so the launch sets the same one.
+func environmentFromShell(name string, getenv func(string) string) (Environment, error) {
+	env := Environment{
+		Name:  name,
+		URL:   getenv("ANTHROPIC_BASE_URL"),
+		Model: getenv("ANTHROPIC_MODEL"),
+	}
+	apiKey, authToken := getenv("ANTHROPIC_API_KEY"), getenv("ANTHROPIC_AUTH_TOKEN")
+	if env.URL == "" && env.Model == "" && apiKey == "" && authToken == "" {
+		return Environment{}, fmt.Errorf("none of ANTHROPIC_BASE_URL, ANTHROPIC_API_KEY, ANTHROPIC_AUTH_TOKEN or ANTHROPIC_MODEL is set")
+	}
+
+	switch {
+	case apiKey != "" && authToken != "":
+		return Environment{}, fmt.Errorf("both ANTHROPIC_API_KEY and ANTHROPIC_AUTH_TOKEN are set; clear the one claude should not use (e.g. ANTHROPIC_API_KEY= cce import ...)")
+	case authToken != "":
+		env.APIKey, env.AuthScheme = authToken, authSchemeBearer
+	default:
+		env.APIKey, env.AuthScheme = apiKey, authSchemeAPIKey
+	}
+	env.APIKeyEnv = authSchemeKeyVars[env.AuthScheme]
+
+	if env.URL == "" {
+		env.URL = defaultImportURL
+	}
+	return env, nil
+}
+
+// runImport captures a working setup into the config as a new environment, or merges
+// the environments of a cce export file
+func runImport(args []string) error {
+	opts, err := parseImportOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	if opts.File != "" {
+		return runImportFile(opts)
+	}
+
+	env, err := environmentFromShell(opts.Name, os.Getenv)
+	if err != nil {
+		errorCtx := newErrorContext("environment import", "import")
+		errorCtx.addSuggestion("Export the variables that work for claude, then run the import again")
+		errorCtx.addSuggestion("Use 'cce add' to enter the values interactively")
+		return errorCtx.formatError(err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	if err := addEnvironmentToConfig(&config, env); err != nil {
+		return fmt.Errorf("failed to import environment: %w", err)
+	}
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	model := env.Model
+	if model == "" {
+		model = "claude default"
+	}
+	if _, err := fmt.Printf("Environment '%s' imported: %s, key %s in %s, model %s\n",
+		env.Name, env.URL, maskAPIKey(env.APIKey), env.APIKeyEnv, model); err != nil {
+		return fmt.Errorf("failed to display success message: %w", err)
+	}
+	return nil
+}
+
+// readImportDocument reads a document written by cce export; "-" reads stdin
+func readImportDocument(path string) (exportDocument, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return exportDocument{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var doc exportDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return exportDocument{}, fmt.Errorf("%s is not a cce export file: %w", path, err)
+	}
+	if doc.Version > exportFormatVersion {
+		return exportDocument{}, fmt.Errorf("%s uses export format %d; this cce reads up to %d, upgrade it first", path, doc.Version, exportFormatVersion)
+	}
+	if len(doc.Environments) == 0 {
+		return exportDocument{}, fmt.Errorf("%s contains no environments", path)
+	}
+	return doc, nil
+}
+
+// freeImportName returns name-2, name-3, ... whichever is not taken yet
+func freeImportName(config Config, name string) (string, error) {
+	for n := 2; n < 1000; n++ {
+		candidate := fmt.Sprintf("%s-%d", name, n)
+		if _, taken := environmentNameTaken(config, candidate); !taken && validateName(candidate) == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free name left for '%s'", name)
+}
+
+// confirmKeyReplacement asks before an overwrite changes an existing API key. Without a
+// terminal to ask on, only --yes replaces it.
+func confirmKeyReplacement(existing, imported Environment) bool {
+	what := fmt.Sprintf("replace the API key of '%s' (fingerprint %s) with the imported one (fingerprint %s)",
+		existing.Name, keyFingerprint(existing.APIKey), keyFingerprint(imported.APIKey))
+	if assumeYes {
+		noteAutoConfirmed(what)
+		return true
+	}
+	if !stdinIsTerminal() || noPrompt {
+		return false
+	}
+	answer, err := confirmationReader(strings.ToUpper(what[:1]) + what[1:] + "? [y/N]: ")
+	return err == nil && (strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes"))
+}
+
+// keepKeys copies every API key field from existing into env
+func keepKeys(env, existing Environment) Environment {
+	env.APIKey, env.APIKeys = existing.APIKey, existing.APIKeys
+	env.PreviousAPIKey, env.KeyRotatedAt = existing.PreviousAPIKey, existing.KeyRotatedAt
+	return env
+}
+
+// mergeImportedEnvironments applies the document to config and returns one line per
+// environment describing what happened. Nothing is applied unless every environment
+// that would be imported is valid.
+func mergeImportedEnvironments(config *Config, doc exportDocument, onConflict string) ([]string, error) {
+	merged := *config
+	merged.Environments = append([]Environment(nil), config.Environments...)
+	var report []string
+
+	for _, env := range doc.Environments {
+		env = cloneEnvironment(env)
+		existingName, taken := environmentNameTaken(merged, env.Name)
+		index := -1
+		if taken {
+			switch onConflict {
+			case conflictSkip:
+				report = append(report, fmt.Sprintf("Skipped '%s': an environment with that name exists (use --on-conflict rename or overwrite)", env.Name))
+				continue
+			case conflictRename:
+				name, err := freeImportName(merged, env.Name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to import environment '%s': %w", env.Name, err)
+				}
+				report = append(report, fmt.Sprintf("Imported '%s' as '%s'", env.Name, name))
+				env.Name = name
+			case conflictOverwrite:
+				index, _ = findEnvironmentByName(merged, existingName)
+			}
+		}
+
+		// Redacted exports carry no keys; an overwrite keeps the ones already configured
+		if index >= 0 {
+			existing := merged.Environments[index]
+			switch {
+			case env.APIKey == "":
+				env = keepKeys(env, existing)
+			case env.APIKey != existing.APIKey && !confirmKeyReplacement(existing, env):
+				fmt.Fprintf(os.Stderr, "Warning: kept the existing API key of '%s'; pass --yes to take the imported one\n", existing.Name)
+				env = keepKeys(env, existing)
+			}
+			env.Name = existing.Name
+		} else if env.APIKey == "" && !isCloudProvider(env) {
+			if !stdinIsTerminal() || noPrompt {
+				return nil, fmt.Errorf("failed to import environment '%s': it has no API key (exported with --redact); import on a terminal to enter it, or add it afterwards with cce set %s api_key=-", env.Name, env.Name)
+			}
+			key, err := secretReader(fmt.Sprintf("API key for '%s' (hidden): ", env.Name))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read API key: %w", err)
+			}
+			env.APIKey = strings.TrimSpace(key)
+		}
+
+		if err := validateEnvironment(env); err != nil {
+			return nil, fmt.Errorf("failed to import environment '%s': %w", env.Name, err)
+		}
+		if index >= 0 {
+			merged.Environments[index] = env
+			report = append(report, fmt.Sprintf("Overwrote '%s'", env.Name))
+			continue
+		}
+		merged.Environments = append(merged.Environments, env)
+		if !taken {
+			report = append(report, fmt.Sprintf("Imported '%s'", env.Name))
+		}
+	}
+
+	*config = merged
+	return report, nil
+}
+
+// runImportFile merges the environments of an export file into the config
+func runImportFile(opts importOptions) error {
+	doc, err := readImportDocument(opts.File)
+	if err != nil {
+		return fmt.Errorf("argument validation failed: %w", err)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	report, err := mergeImportedEnvironments(&config, doc, opts.OnConflict)
+	if err != nil {
+		return err
+	}
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	for _, line := range report {
+		if _, err := fmt.Println(line); err != nil {
+			return fmt.Errorf("failed to display import summary: %w", err)
+		}
+	}
+	return nil
+}
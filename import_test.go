@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseImportOptions(t *testing.T) {
+	for _, args := range [][]string{{"--from-env", "--name", "prod"}, {"--name=prod", "--from-env"}} {
+		if opts, err := parseImportOptions(args); err != nil || !opts.FromEnv || opts.Name != "prod" {
+			t.Errorf("parseImportOptions(%q) = %+v, %v", args, opts, err)
+		}
+	}
+	for _, args := range [][]string{{}, {"--from-env"}, {"--name", "prod"}, {"--from-env", "--name"}, {"--from-env", "--name", "prod", "--bogus"}} {
+		if _, err := parseImportOptions(args); err == nil {
+			t.Errorf("parseImportOptions(%q) should fail", args)
+		}
+	}
+}
+
+func TestEnvironmentFromShell(t *testing.T) {
+	vars := map[string]string{
+		"ANTHROPIC_BASE_URL":   "https://proxy.example.com/v1",
+		"ANTHROPIC_AUTH_TOKEN": "tok-import-bearer-1234",
+		"ANTHROPIC_MODEL":      "claude-sonnet-4-20250514",
+	}
+	env, err := environmentFromShell("prod", func(key string) string { return vars[key] })
+	if err != nil {
+		t.Fatalf("environmentFromShell() failed: %v", err)
+	}
+	if env.URL != vars["ANTHROPIC_BASE_URL"] || env.APIKey != vars["ANTHROPIC_AUTH_TOKEN"] || env.Model != vars["ANTHROPIC_MODEL"] ||
+		env.APIKeyEnv != "ANTHROPIC_AUTH_TOKEN" || env.AuthScheme != authSchemeBearer {
+		t.Errorf("unexpected environment %+v", env)
+	}
+
+	env, err = environmentFromShell("prod", func(key string) string {
+		return map[string]string{"ANTHROPIC_API_KEY": "sk-ant-api03-import-1234"}[key]
+	})
+	if err != nil || env.URL != defaultImportURL || env.APIKeyEnv != "ANTHROPIC_API_KEY" {
+		t.Errorf("API key only: got %+v, %v", env, err)
+	}
+
+	if _, err := environmentFromShell("prod", func(string) string { return "" }); err == nil || !strings.Contains(err.Error(), "none of") {
+		t.Errorf("expected error with no variables, got %v", err)
+	}
+	both := func(key string) string {
+		return map[string]string{"ANTHROPIC_API_KEY": "sk-ant-api03-a-1234", "ANTHROPIC_AUTH_TOKEN": "tok-b-1234567"}[key]
+	}
+	if _, err := environmentFromShell("prod", both); err == nil || !strings.Contains(err.Error(), "both") {
+		t.Errorf("expected error with both key variables, got %v", err)
+	}
+}
+
+func TestRunImportFromEnv(t *testing.T) {
+	withTempConfigPath(t)
+	t.Setenv("ANTHROPIC_BASE_URL", "https://api.example.com")
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-api03-import-5678")
+	t.Setenv("ANTHROPIC_AUTH_TOKEN", "")
+	t.Setenv("ANTHROPIC_MODEL", "")
+
+	out := captureStdout(t, func() {
+		if err := runImport([]string{"--from-env", "--name", "shell"}); err != nil {
+			t.Fatalf("runImport() failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Environment 'shell' imported") || strings.Contains(out, "sk-ant-api03-import-5678") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	if len(config.Environments) != 1 || config.Environments[0].APIKey != "sk-ant-api03-import-5678" {
+		t.Fatalf("unexpected config %+v", config.Environments)
+	}
+
+	// A second import under the same name is refused
+	if err := runImport([]string{"--from-env", "--name", "shell"}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected duplicate name error, got %v", err)
+	}
+}
+
+func writeImportFile(t *testing.T, doc exportDocument) string {
+	t.Helper()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to encode document: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "team.json")
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	return path
+}
+
+func TestImportFileConflicts(t *testing.T) {
+	origTerminal := stdinIsTerminal
+	defer func() { stdinIsTerminal = origTerminal }()
+	stdinIsTerminal = func() bool { return false }
+
+	doc := exportDocument{Version: exportFormatVersion, Environments: []Environment{
+		{Name: "prod", URL: "https://team.example.com", APIKey: "sk-ant-REDACTED", Model: "claude-sonnet-4-20250514"},
+		{Name: "lab", URL: "https://lab.example.com", APIKey: "sk-ant-REDACTED"},
+	}}
+	mine := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}
+
+	for _, tc := range []struct {
+		mode     string
+		wantEnvs string
+		check    func(Config) bool
+	}{
+		{conflictSkip, "prod,lab", func(c Config) bool { return c.Environments[0].URL == mine.URL }},
+		{conflictRename, "prod,prod-2,lab", func(c Config) bool { return c.Environments[1].URL == "https://team.example.com" }},
+		// Without a terminal or --yes the existing key survives an overwrite
+		{conflictOverwrite, "prod,lab", func(c Config) bool {
+			return c.Environments[0].URL == "https://team.example.com" && c.Environments[0].APIKey == mine.APIKey
+		}},
+	} {
+		withTempConfigPath(t)
+		if err := saveConfig(Config{Environments: []Environment{mine}}); err != nil {
+			t.Fatalf("saveConfig() failed: %v", err)
+		}
+		path := writeImportFile(t, doc)
+		_, _, err := captureStdoutAndStderr(t, func() error {
+			return handleCommand([]string{"import", path, "--on-conflict", tc.mode})
+		})
+		if err != nil {
+			t.Fatalf("%s: import failed: %v", tc.mode, err)
+		}
+		config, _ := loadConfig()
+		var names []string
+		for _, env := range config.Environments {
+			names = append(names, env.Name)
+		}
+		if strings.Join(names, ",") != tc.wantEnvs || !tc.check(config) {
+			t.Errorf("%s: unexpected config %+v", tc.mode, config.Environments)
+		}
+	}
+}
+
+func TestImportFileIsAllOrNothing(t *testing.T) {
+	withTempConfigPath(t)
+	origTerminal := stdinIsTerminal
+	defer func() { stdinIsTerminal = origTerminal }()
+	stdinIsTerminal = func() bool { return false }
+
+	path := writeImportFile(t, exportDocument{Version: exportFormatVersion, Redacted: true, Environments: []Environment{
+		{Name: "ok", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-ok-1234567890"},
+		{Name: "keyless", URL: "https://api.anthropic.com"},
+	}})
+	err := runImport([]string{path})
+	if err == nil || !strings.Contains(err.Error(), "'keyless'") || !strings.Contains(err.Error(), "no API key") {
+		t.Fatalf("expected the keyless environment to fail the import, got %v", err)
+	}
+	if config, _ := loadConfig(); len(config.Environments) != 0 {
+		t.Errorf("nothing should be saved when an environment fails, got %+v", config.Environments)
+	}
+
+	bad := writeImportFile(t, exportDocument{Version: exportFormatVersion, Environments: []Environment{{Name: "x", URL: "not a url", APIKey: "sk-ant-api03-x-1234567890"}}})
+	if err := runImport([]string{bad}); err == nil || !strings.Contains(err.Error(), "invalid URL") {
+		t.Errorf("expected validation to reject the URL, got %v", err)
+	}
+	for _, args := range [][]string{{path, "--from-env"}, {path, "--name", "x"}, {path, "--on-conflict", "merge"}, {"--from-env", "--name", "x", "--on-conflict", "skip"}} {
+		if _, err := parseImportOptions(args); err == nil {
+			t.Errorf("parseImportOptions(%q) should fail", args)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitCommand(t *testing.T) {
+	originalPrompter := environmentPrompter
+	defer func() { environmentPrompter = originalPrompter }()
+	environmentPrompter = func(Config) (Environment, error) {
+		return Environment{Name: "first", URL: "https://api.anthropic.com", APIKey: "sk-ant-first-key"}, nil
+	}
+
+	t.Run("creates first environment with private dir", func(t *testing.T) {
+		path := withTempConfigPath(t)
+		if err := os.RemoveAll(filepath.Dir(path)); err != nil {
+			t.Fatalf("cleanup failed: %v", err)
+		}
+
+		output := captureStdout(t, func() {
+			if err := handleCommand([]string{"init"}); err != nil {
+				t.Fatalf("init failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "Configuration created") {
+			t.Errorf("unexpected output: %q", output)
+		}
+
+		info, err := os.Stat(filepath.Dir(path))
+		if err != nil || info.Mode().Perm() != 0700 {
+			t.Errorf("expected 0700 config dir, got %v (%v)", info, err)
+		}
+		config, err := loadConfig()
+		if err != nil || len(config.Environments) != 1 || config.Environments[0].Name != "first" {
+			t.Errorf("unexpected config: %+v (%v)", config, err)
+		}
+	})
+
+	t.Run("refuses existing config without force", func(t *testing.T) {
+		withTempConfigPath(t)
+		existing := Config{Environments: []Environment{{Name: "keep", URL: "https://keep.example.com", APIKey: "sk-ant-keep"}}}
+		if err := saveConfig(existing); err != nil {
+			t.Fatalf("saveConfig() failed: %v", err)
+		}
+
+		err := runInit(initOptions{})
+		if err == nil || !strings.Contains(err.Error(), "--force") {
+			t.Fatalf("expected refusal mentioning --force, got %v", err)
+		}
+
+		captureStdout(t, func() {
+			if err := runInit(initOptions{Force: true}); err != nil {
+				t.Fatalf("init --force failed: %v", err)
+			}
+		})
+		config, _ := loadConfig()
+		if _, exists := findEnvironmentByName(config, "keep"); exists {
+			t.Error("expected --force to replace the existing config")
+		}
+	})
+
+	t.Run("template loads as an empty config", func(t *testing.T) {
+		path := withTempConfigPath(t)
+		captureStdout(t, func() {
+			if err := runInit(initOptions{Template: true}); err != nil {
+				t.Fatalf("init --template failed: %v", err)
+			}
+		})
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil || !strings.Contains(string(data), "_fields") {
+			t.Fatalf("template not written: %v", err)
+		}
+		config, err := loadConfig()
+		if err != nil {
+			t.Fatalf("template should load cleanly: %v", err)
+		}
+		if len(config.Environments) != 0 {
+			t.Errorf("expected no environments, got %d", len(config.Environments))
+		}
+	})
+
+	t.Run("rejects unknown flags", func(t *testing.T) {
+		if _, err := parseInitOptions([]string{"--bogus"}); err == nil {
+			t.Error("expected unknown flag error")
+		}
+	})
+}
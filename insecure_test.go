@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInsecureCheckSkipsCertificateVerification(t *testing.T) {
+	// httptest's TLS server presents a self-signed certificate no system pool trusts
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-ant-REDACTED" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+	env := Environment{Name: "internal", URL: server.URL, APIKey: "sk-ant-REDACTED"}
+
+	if _, err := newNetworkValidator(0).withRetries(0).checkEnvironment(env); err == nil || !strings.Contains(err.Error(), "x509") {
+		t.Fatalf("expected a certificate failure without --insecure, got %v", err)
+	}
+
+	insecure := newNetworkValidator(0).withRetries(0).withInsecure()
+	if _, err := insecure.checkEnvironment(env); err != nil {
+		t.Fatalf("expected --insecure to pass the certificate check, got %v", err)
+	}
+
+	// With TLS out of the way, a bad key is reported as the real problem
+	env.APIKey = "sk-ant-REDACTED"
+	if _, err := insecure.checkEnvironment(env); err == nil || !strings.Contains(err.Error(), "authentication rejected") {
+		t.Errorf("expected an auth failure, got %v", err)
+	}
+
+	// A ca_cert that cannot be loaded does not matter when certificates are not checked
+	env.APIKey = "sk-ant-REDACTED"
+	env.CACert = "/nonexistent/ca.pem"
+	if _, err := insecure.checkEnvironment(env); err != nil {
+		t.Errorf("expected --insecure to ignore ca_cert, got %v", err)
+	}
+}
+
+func TestInsecureFlagParsing(t *testing.T) {
+	testOpts, err := parseTestOptions([]string{"corp", "--insecure"})
+	if err != nil || !testOpts.Insecure || len(testOpts.Names) != 1 {
+		t.Errorf("test --insecure: got %+v, %v", testOpts, err)
+	}
+	verifyOpts, err := parseVerifyOptions([]string{"--insecure", "corp"})
+	if err != nil || !verifyOpts.Insecure || len(verifyOpts.Names) != 1 {
+		t.Errorf("verify --insecure: got %+v, %v", verifyOpts, err)
+	}
+
+	// Launches have no such flag: it is passed through to claude untouched
+	result := parseArguments([]string{"--insecure"})
+	if result.Error != nil || len(result.ClaudeArgs) != 1 || result.ClaudeArgs[0] != "--insecure" {
+		t.Errorf("expected --insecure to reach claude on launch, got %+v", result)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestKeyStyleWarning(t *testing.T) {
+	tests := []struct {
+		name string
+		env  Environment
+		warn bool
+	}{
+		{"api key as api key", Environment{APIKey: "sk-ant-api03-abcdefghij"}, false},
+		{"api key as auth token", Environment{APIKey: "sk-ant-api03-abcdefghij", APIKeyEnv: "ANTHROPIC_AUTH_TOKEN"}, true},
+		{"api key with bearer scheme", Environment{APIKey: "sk-ant-api03-abcdefghij", AuthScheme: authSchemeBearer}, true},
+		{"oauth token as auth token", Environment{APIKey: "sk-ant-oat01-abcdefghij", APIKeyEnv: "ANTHROPIC_AUTH_TOKEN"}, false},
+		{"oauth token as api key", Environment{APIKey: "sk-ant-oat01-abcdefghij"}, true},
+		{"proxy key as auth token", Environment{APIKey: "gw-1234567890abcdef", APIKeyEnv: "ANTHROPIC_AUTH_TOKEN"}, false},
+		{"proxy key as api key", Environment{APIKey: "sk-proxy-1234567890"}, false},
+		{"mismatch in key pool", Environment{APIKey: "gw-1234567890abcdef", APIKeys: []string{"sk-ant-api03-abcdefghij"}, APIKeyEnv: "ANTHROPIC_AUTH_TOKEN"}, true},
+		{"cloud provider", Environment{Provider: "bedrock", Region: "us-east-1", APIKey: "sk-ant-oat01-abcdefghij"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.env.Name = "work"
+			got := keyStyleWarning(tt.env)
+			if (got != "") != tt.warn {
+				t.Errorf("keyStyleWarning() = %q, want warning %v", got, tt.warn)
+			}
+			if got != "" && (strings.Contains(got, tt.env.APIKey) || !strings.Contains(got, "key_var=")) {
+				t.Errorf("warning should name a fix without the key itself: %q", got)
+			}
+		})
+	}
+}
+
+func TestDoctorWarnsAboutKeyStyleWithoutFailing(t *testing.T) {
+	path := withTempConfigPath(t)
+	withClaudeSettings(t, "")
+	withDoctorClaude(t, nil)
+	config := `{"environments": [{"name": "work", "url": "https://api.anthropic.com", "api_key": "sk-ant-api03-test-key-123", "api_key_env": "ANTHROPIC_AUTH_TOKEN"}]}`
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	var err error
+	out := captureStdout(t, func() { err = runDoctor(nil) })
+	if err != nil {
+		t.Errorf("a key style warning should not fail doctor, got %v", err)
+	}
+	if !strings.Contains(out, "[warn] API key variables") || !strings.Contains(out, "work") {
+		t.Errorf("expected a key style warning:\n%s", out)
+	}
+}
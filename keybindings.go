@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// selectAction is what a key press does in the interactive selector
+type selectAction int
+
+const (
+	actionNone selectAction = iota
+	actionUp
+	actionDown
+	actionSelect
+	actionCancel
+	actionFilter
+	actionPageUp
+	actionPageDown
+)
+
+// selectActionNames maps config action names to selector actions
+var selectActionNames = map[string]selectAction{
+	"up":        actionUp,
+	"down":      actionDown,
+	"select":    actionSelect,
+	"cancel":    actionCancel,
+	"filter":    actionFilter,
+	"page_up":   actionPageUp,
+	"page_down": actionPageDown,
+}
+
+// Key binding presets
+const (
+	keyPresetDefault = "default"
+	keyPresetVim     = "vim"
+)
+
+// KeyBindingSettings configures the interactive selector's keys.
+// Keys are single printable characters or one of: up, down, pgup, pgdown, enter, esc, tab, space.
+type KeyBindingSettings struct {
+	Preset string              `json:"preset,omitempty"` // "default" or "vim"
+	Keys   map[string][]string `json:"keys,omitempty"`   // action name -> keys, added to the preset
+}
+
+// namedKeys are the non-printable key tokens accepted in key binding settings
+var namedKeys = map[string]bool{
+	"up": true, "down": true, "pgup": true, "pgdown": true, "enter": true, "esc": true, "tab": true, "space": true,
+}
+
+// keyBindings maps key tokens to selector actions
+type keyBindings map[string]selectAction
+
+// defaultKeyBindings keeps arrows, Page Up/Down, Enter and Esc working, with / to filter
+func defaultKeyBindings() keyBindings {
+	return keyBindings{
+		"up":     actionUp,
+		"down":   actionDown,
+		"pgup":   actionPageUp,
+		"pgdown": actionPageDown,
+		"enter":  actionSelect,
+		"esc":    actionCancel,
+		"/":      actionFilter,
+	}
+}
+
+// resolveKeyBindings builds the active bindings from settings, starting from the preset
+func resolveKeyBindings(settings *KeyBindingSettings) (keyBindings, error) {
+	bindings := defaultKeyBindings()
+	if settings == nil {
+		return bindings, nil
+	}
+
+	switch settings.Preset {
+	case "", keyPresetDefault:
+	case keyPresetVim:
+		bindings["k"] = actionUp
+		bindings["j"] = actionDown
+		bindings["q"] = actionCancel
+	default:
+		return nil, fmt.Errorf("unknown key binding preset '%s' (expected %s or %s)", settings.Preset, keyPresetDefault, keyPresetVim)
+	}
+
+	// Sorted so conflicting custom bindings resolve deterministically
+	actions := make([]string, 0, len(settings.Keys))
+	for name := range settings.Keys {
+		actions = append(actions, name)
+	}
+	sort.Strings(actions)
+
+	for _, name := range actions {
+		action, ok := selectActionNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown key binding action '%s' (expected up, down, page_up, page_down, select, cancel, or filter)", name)
+		}
+		for _, key := range settings.Keys[name] {
+			token := strings.ToLower(key)
+			if len([]rune(key)) == 1 {
+				token = key
+			} else if !namedKeys[token] {
+				return nil, fmt.Errorf("invalid key '%s' for action '%s'", key, name)
+			}
+			bindings[token] = action
+		}
+	}
+
+	return bindings, nil
+}
+
+// keyToken converts raw selector input into a binding token; "" means unrecognized
+func keyToken(input []byte) string {
+	// Page Up/Down arrive as ESC [ 5 ~ and ESC [ 6 ~
+	switch string(input) {
+	case "\x1b[5~":
+		return "pgup"
+	case "\x1b[6~":
+		return "pgdown"
+	}
+
+	arrow, char, err := parseKeyInput(input)
+	if err != nil {
+		return ""
+	}
+	switch arrow {
+	case ArrowUp:
+		return "up"
+	case ArrowDown:
+		return "down"
+	case ArrowNone:
+	default:
+		return ""
+	}
+
+	switch char {
+	case '\n':
+		return "enter"
+	case '\x1b':
+		return "esc"
+	case '\x03':
+		return "ctrl+c"
+	case '\t':
+		return "tab"
+	case ' ':
+		return "space"
+	case 127, 8:
+		return "backspace"
+	}
+	if char >= 32 && char < 127 {
+		return string(char)
+	}
+	return ""
+}
+
+// selectionState tracks cursor position and the optional name filter for one selector session
+type selectionState struct {
+	all       []Environment
+	visible   []Environment
+	index     int
+	filtering bool
+	filter    string
+	pageSize  int // Entries per page for Page Up/Down; matches the rendered viewport
+}
+
+// newSelectionState starts a selector session over all environments
+func newSelectionState(environments []Environment) *selectionState {
+	return &selectionState{all: environments, visible: environments, pageSize: defaultMenuPageSize}
+}
+
+// header describes the current mode above the menu
+func (s *selectionState) header(base string) string {
+	if !s.filtering {
+		return base
+	}
+	return fmt.Sprintf("Filter: %s (type to narrow, Enter to confirm, Esc to clear)", s.filter)
+}
+
+// applyFilter narrows the visible list to names containing the filter text
+func (s *selectionState) applyFilter() {
+	s.visible = s.all
+	if s.filter != "" {
+		needle := strings.ToLower(s.filter)
+		s.visible = nil
+		for _, env := range s.all {
+			if strings.Contains(strings.ToLower(env.Name), needle) {
+				s.visible = append(s.visible, env)
+			}
+		}
+	}
+	if s.index >= len(s.visible) {
+		s.index = 0
+	}
+}
+
+// handleKey applies one key press. It returns the chosen environment when done is true,
+// or a cancellation error. Unbound and unrecognized keys are ignored.
+func (s *selectionState) handleKey(bindings keyBindings, input []byte) (env Environment, done bool, err error) {
+	token := keyToken(input)
+	if token == "" {
+		return Environment{}, false, nil
+	}
+
+	// Ctrl+C always cancels so a bad binding can never trap the user
+	if token == "ctrl+c" {
+		return Environment{}, true, fmt.Errorf("selection cancelled")
+	}
+
+	if s.filtering {
+		switch token {
+		case "up", "down", "pgup", "pgdown", "enter":
+			// Navigation and confirmation behave as usual while filtering
+		case "esc":
+			s.filtering = false
+			s.filter = ""
+			s.applyFilter()
+			return Environment{}, false, nil
+		case "backspace":
+			if s.filter != "" {
+				s.filter = s.filter[:len(s.filter)-1]
+				s.applyFilter()
+			}
+			return Environment{}, false, nil
+		default:
+			if len(token) == 1 || token == "space" {
+				if token == "space" {
+					token = " "
+				}
+				s.filter += token
+				s.applyFilter()
+			}
+			return Environment{}, false, nil
+		}
+	}
+
+	action := bindings[token]
+	if token == "enter" && s.filtering {
+		action = actionSelect
+	}
+
+	switch action {
+	case actionUp:
+		if len(s.visible) > 0 {
+			s.index = (s.index - 1 + len(s.visible)) % len(s.visible)
+		}
+	case actionDown:
+		if len(s.visible) > 0 {
+			s.index = (s.index + 1) % len(s.visible)
+		}
+	case actionPageUp:
+		s.index = pageStep(s.index, -s.pageSize, len(s.visible))
+	case actionPageDown:
+		s.index = pageStep(s.index, s.pageSize, len(s.visible))
+	case actionSelect:
+		if len(s.visible) > 0 {
+			return s.visible[s.index], true, nil
+		}
+	case actionCancel:
+		return Environment{}, true, fmt.Errorf("selection cancelled")
+	case actionFilter:
+		s.filtering = true
+	}
+	return Environment{}, false, nil
+}
+
+// selectorKeyBindings returns the configured bindings, warning and using defaults when invalid
+func selectorKeyBindings(config Config) keyBindings {
+	var settings *KeyBindingSettings
+	if config.Settings != nil {
+		settings = config.Settings.KeyBindings
+	}
+	bindings, err := resolveKeyBindings(settings)
+	if err != nil {
+		fmt.Printf("Warning: ignoring key bindings: %v\n", err)
+		return defaultKeyBindings()
+	}
+	return bindings
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestResolveKeyBindings(t *testing.T) {
+	defaults, err := resolveKeyBindings(nil)
+	if err != nil {
+		t.Fatalf("resolveKeyBindings(nil) failed: %v", err)
+	}
+	if defaults["up"] != actionUp || defaults["enter"] != actionSelect || defaults["esc"] != actionCancel {
+		t.Errorf("defaults must keep arrows, Enter and Esc: %v", defaults)
+	}
+	if _, bound := defaults["j"]; bound {
+		t.Error("j should not be bound by default")
+	}
+
+	vim, err := resolveKeyBindings(&KeyBindingSettings{Preset: keyPresetVim})
+	if err != nil {
+		t.Fatalf("vim preset failed: %v", err)
+	}
+	if vim["j"] != actionDown || vim["k"] != actionUp || vim["down"] != actionDown {
+		t.Errorf("vim preset should add j/k and keep arrows: %v", vim)
+	}
+
+	custom, err := resolveKeyBindings(&KeyBindingSettings{Keys: map[string][]string{"select": {"l", "Space"}, "filter": {"f"}}})
+	if err != nil {
+		t.Fatalf("custom bindings failed: %v", err)
+	}
+	if custom["l"] != actionSelect || custom["space"] != actionSelect || custom["f"] != actionFilter {
+		t.Errorf("custom bindings not applied: %v", custom)
+	}
+
+	invalid := []*KeyBindingSettings{
+		{Preset: "emacs"},
+		{Keys: map[string][]string{"jump": {"x"}}},
+		{Keys: map[string][]string{"up": {"pageup"}}},
+	}
+	for _, settings := range invalid {
+		if _, err := resolveKeyBindings(settings); err == nil {
+			t.Errorf("expected error for %+v", settings)
+		}
+	}
+}
+
+func TestSelectionStateHandleKey(t *testing.T) {
+	envs := []Environment{{Name: "alpha"}, {Name: "beta"}, {Name: "gamma"}}
+	vim, _ := resolveKeyBindings(&KeyBindingSettings{Preset: keyPresetVim})
+
+	t.Run("vim navigation and arrows", func(t *testing.T) {
+		state := newSelectionState(envs)
+		for _, key := range [][]byte{[]byte("j"), []byte("\x1b[B"), []byte("k")} {
+			if _, done, _ := state.handleKey(vim, key); done {
+				t.Fatalf("unexpected completion on %q", key)
+			}
+		}
+		env, done, err := state.handleKey(vim, []byte("\r"))
+		if !done || err != nil || env.Name != "beta" {
+			t.Errorf("expected beta, got %v done=%v err=%v", env.Name, done, err)
+		}
+	})
+
+	t.Run("unbound and unknown keys are ignored", func(t *testing.T) {
+		state := newSelectionState(envs)
+		for _, key := range [][]byte{[]byte("z"), []byte("\x1b[Z"), []byte("j"), {0x01}} {
+			if _, done, err := state.handleKey(defaultKeyBindings(), key); done || err != nil {
+				t.Fatalf("key %q should be ignored, got done=%v err=%v", key, done, err)
+			}
+		}
+		if state.index != 0 {
+			t.Errorf("ignored keys moved the cursor to %d", state.index)
+		}
+	})
+
+	t.Run("cancel keys", func(t *testing.T) {
+		for _, key := range []string{"\x1b", "\x03"} {
+			if _, done, err := newSelectionState(envs).handleKey(defaultKeyBindings(), []byte(key)); !done || err == nil {
+				t.Errorf("expected %q to cancel", key)
+			}
+		}
+		if _, done, err := newSelectionState(envs).handleKey(vim, []byte("q")); !done || err == nil {
+			t.Error("expected q to cancel with the vim preset")
+		}
+	})
+
+	t.Run("filter narrows and clears", func(t *testing.T) {
+		state := newSelectionState(envs)
+		for _, key := range []string{"/", "m", "m"} {
+			state.handleKey(defaultKeyBindings(), []byte(key))
+		}
+		if len(state.visible) != 1 || state.visible[0].Name != "gamma" {
+			t.Fatalf("expected filter to leave gamma, got %v", state.visible)
+		}
+		if !strings.Contains(state.header("base"), "Filter: mm") {
+			t.Errorf("unexpected header %q", state.header("base"))
+		}
+
+		state.handleKey(defaultKeyBindings(), []byte{127})
+		if state.filter != "m" || len(state.visible) != 1 {
+			t.Errorf("backspace should shorten filter: %q %v", state.filter, state.visible)
+		}
+
+		state.handleKey(defaultKeyBindings(), []byte("\x1b"))
+		if state.filtering || len(state.visible) != len(envs) {
+			t.Error("esc should clear the filter, not cancel")
+		}
+
+		state.handleKey(defaultKeyBindings(), []byte("/"))
+		state.handleKey(defaultKeyBindings(), []byte("x"))
+		if _, done, _ := state.handleKey(defaultKeyBindings(), []byte("\r")); done {
+			t.Error("enter with no matches should not select")
+		}
+	})
+}
+
+func TestInvalidKeyBindingsRejectedOnLoad(t *testing.T) {
+	path := withTempConfigPath(t)
+	content := `{"environments": [], "settings": {"key_bindings": {"preset": "emacs"}}}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "key_bindings") {
+		t.Errorf("expected key_bindings validation error, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Key format checking modes for settings.validation.key_format
+const (
+	keyFormatOff    = "off"    // Never check key shapes
+	keyFormatWarn   = "warn"   // Warn when adding or changing a key that does not fit (the default)
+	keyFormatStrict = "strict" // Reject such keys in add, set, copy and import
+)
+
+// keyFormatMode is the active settings.validation.key_format
+var keyFormatMode = keyFormatWarn
+
+// applyKeyFormatSettings validates and activates settings.validation.key_format
+func applyKeyFormatSettings(settings *ConfigSettings) error {
+	keyFormatMode = keyFormatWarn
+	if settings == nil || settings.Validation == nil || settings.Validation.KeyFormat == "" {
+		return nil
+	}
+	switch mode := strings.ToLower(settings.Validation.KeyFormat); mode {
+	case keyFormatOff, keyFormatWarn, keyFormatStrict:
+		keyFormatMode = mode
+		return nil
+	}
+	return fmt.Errorf("'%s' must be %s, %s or %s", settings.Validation.KeyFormat, keyFormatOff, keyFormatWarn, keyFormatStrict)
+}
+
+// keyShape is what keys issued for one provider's endpoint look like
+type keyShape struct {
+	Provider  string   // Name used in messages
+	Hosts     []string // Endpoint hosts (and their subdomains) that issue these keys
+	Prefix    string
+	MinLength int
+}
+
+// keyShapes lists the endpoints whose keys have a recognizable shape. Every one of them is
+// reached with the anthropic provider; bedrock and vertex use cloud credentials, and keys
+// for other gateways are not judged. Lengths are floors that catch a truncated paste, not
+// the exact length issued.
+var keyShapes = []keyShape{
+	{Provider: "Anthropic", Hosts: []string{"api.anthropic.com"}, Prefix: "sk-ant-", MinLength: 24},
+	{Provider: "OpenRouter", Hosts: []string{"openrouter.ai"}, Prefix: "sk-or-", MinLength: 24},
+	{Provider: "DeepSeek", Hosts: []string{"api.deepseek.com"}, Prefix: "sk-", MinLength: 24},
+	{Provider: "Moonshot", Hosts: []string{"api.moonshot.ai", "api.moonshot.cn"}, Prefix: "sk-", MinLength: 24},
+	{Provider: "DashScope", Hosts: []string{"dashscope.aliyuncs.com", "dashscope-intl.aliyuncs.com"}, Prefix: "sk-", MinLength: 24},
+}
+
+// keyShapeFor returns the key shape expected for env's provider and endpoint, if known
+func keyShapeFor(env Environment) (keyShape, bool) {
+	if isCloudProvider(env) || env.URL == "" {
+		return keyShape{}, false
+	}
+	parsed, err := url.Parse(env.URL)
+	if err != nil {
+		return keyShape{}, false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, shape := range keyShapes {
+		for _, h := range shape.Hosts {
+			if host == h || strings.HasSuffix(host, "."+h) {
+				return shape, true
+			}
+		}
+	}
+	return keyShape{}, false
+}
+
+// validateAPIKeyForProvider checks each of env's keys against the shape its provider's
+// endpoint issues. Keys held in a keychain and keys saved with --no-validate are not
+// checked, and the error names a key only by fingerprint.
+func validateAPIKeyForProvider(env Environment) error {
+	shape, ok := keyShapeFor(env)
+	if !ok || isUnvalidated(env, fieldAPIKey) {
+		return nil
+	}
+	for _, key := range environmentKeys(env) {
+		if key == "" || isSecretRef(key) {
+			continue
+		}
+		if !strings.HasPrefix(key, shape.Prefix) || len(key) < shape.MinLength {
+			return fmt.Errorf("key %s does not look like a %s key (expected %s... of at least %d characters)", keyFingerprint(key), shape.Provider, shape.Prefix, shape.MinLength)
+		}
+	}
+	return nil
+}
+
+// changesKeyShape reports whether set updates touch a key or the endpoint that decides its shape
+func changesKeyShape(updates map[string]string) bool {
+	for _, field := range []string{"url", "api_key", "api_keys", "provider"} {
+		if _, ok := updates[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// keyFormatWarning describes a key that does not fit its endpoint's shape, or returns ""
+// when it fits or the shape is unknown. It only warns in warn mode: strict mode rejects
+// the key during validation instead, and off never checks.
+func keyFormatWarning(env Environment) string {
+	if keyFormatMode != keyFormatWarn {
+		return ""
+	}
+	if err := validateAPIKeyForProvider(env); err != nil {
+		return fmt.Sprintf("Warning: environment '%s': %v; check it was copied whole if authentication fails (settings.validation.key_format=off silences this)", env.Name, err)
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateAPIKeyForProvider(t *testing.T) {
+	tests := []struct {
+		name string
+		env  Environment
+		ok   bool
+	}{
+		{"anthropic key", Environment{URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}, true},
+		{"anthropic oauth token", Environment{URL: "https://api.anthropic.com/", APIKey: "sk-ant-REDACTED"}, true},
+		{"anthropic wrong prefix", Environment{URL: "https://api.anthropic.com", APIKey: "sk-or-v1-abcdefghijklmnopqrs"}, false},
+		{"anthropic truncated", Environment{URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-abc"}, false},
+		{"openrouter key", Environment{URL: "https://openrouter.ai/api", APIKey: "sk-or-v1-abcdefghijklmnopqrs"}, true},
+		{"openrouter anthropic key", Environment{URL: "https://openrouter.ai/api", APIKey: "sk-ant-REDACTED"}, false},
+		{"deepseek key", Environment{URL: "https://api.deepseek.com/anthropic", APIKey: "sk-0123456789abcdef0123456789abcdef"}, true},
+		{"deepseek truncated", Environment{URL: "https://api.deepseek.com/anthropic", APIKey: "sk-0123456789"}, false},
+		{"moonshot key", Environment{URL: "https://api.moonshot.ai/anthropic", APIKey: "sk-0123456789abcdef0123456789abcdef"}, true},
+		{"moonshot cn wrong prefix", Environment{URL: "https://api.moonshot.cn/anthropic", APIKey: "ms-0123456789abcdef0123456789abcdef"}, false},
+		{"dashscope key", Environment{URL: "https://dashscope.aliyuncs.com/api/v2/apps/claude-code-proxy", APIKey: "sk-0123456789abcdef0123456789abcdef"}, true},
+		{"dashscope intl wrong prefix", Environment{URL: "https://dashscope-intl.aliyuncs.com/api", APIKey: "0123456789abcdef0123456789abcdef"}, false},
+		{"unknown gateway", Environment{URL: "https://gateway.example.com", APIKey: "gw-123"}, true},
+		{"lookalike host", Environment{URL: "https://api.anthropic.com.example.com", APIKey: "gw-1234567890"}, true},
+		{"cloud provider", Environment{Provider: "bedrock", Region: "us-east-1", URL: "https://api.anthropic.com", APIKey: "short"}, true},
+		{"keychain reference", Environment{URL: "https://api.anthropic.com", APIKey: "keychain:cce/prod"}, true},
+		{"unvalidated key", Environment{URL: "https://api.anthropic.com", APIKey: "odd-key-1234567890", Unvalidated: []string{fieldAPIKey}}, true},
+		{"bad key in pool", Environment{URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED", APIKeys: []string{"sk-0123456789abcdef0123456789abcdef"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAPIKeyForProvider(tt.env)
+			if (err == nil) != tt.ok {
+				t.Fatalf("validateAPIKeyForProvider() = %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && strings.Contains(err.Error(), tt.env.APIKey) {
+				t.Errorf("error must not reveal the key: %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyFormatSettings(t *testing.T) {
+	t.Cleanup(func() { keyFormatMode = keyFormatWarn })
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "gw-0123456789abcdef"}
+
+	if err := applyKeyFormatSettings(nil); err != nil || keyFormatMode != keyFormatWarn {
+		t.Fatalf("expected warn by default, got %q (%v)", keyFormatMode, err)
+	}
+	if warning := keyFormatWarning(env); !strings.Contains(warning, "Anthropic key") || !strings.Contains(warning, "key_format=off") {
+		t.Errorf("unexpected warning %q", warning)
+	}
+	if err := validateEnvironment(env); err != nil {
+		t.Errorf("warn mode must not reject the key: %v", err)
+	}
+
+	if err := applyKeyFormatSettings(&ConfigSettings{Validation: &ValidationSettings{KeyFormat: "Strict"}}); err != nil {
+		t.Fatalf("applyKeyFormatSettings() failed: %v", err)
+	}
+	if err := validateEnvironment(env); err == nil || !strings.Contains(err.Error(), "invalid API key") {
+		t.Errorf("strict mode should reject the key, got %v", err)
+	}
+	if err := validateStoredEnvironment(env); err != nil {
+		t.Errorf("strict mode must not break stored environments: %v", err)
+	}
+	if warning := keyFormatWarning(env); warning != "" {
+		t.Errorf("strict mode rejects instead of warning, got %q", warning)
+	}
+
+	if err := applyKeyFormatSettings(&ConfigSettings{Validation: &ValidationSettings{KeyFormat: "off"}}); err != nil || keyFormatWarning(env) != "" {
+		t.Errorf("off should silence the warning (err %v)", err)
+	}
+	if err := applyKeyFormatSettings(&ConfigSettings{Validation: &ValidationSettings{KeyFormat: "loud"}}); err == nil {
+		t.Error("expected an unknown key_format to be rejected")
+	}
+}
+
+func TestSetWarnsWhenURLDoesNotFitKey(t *testing.T) {
+	withTempConfigPath(t)
+	withReachabilityCheck(t, networkCheckResult{Reachable: true, StatusCode: 200}, nil)
+	env := Environment{Name: "ds", URL: "https://gateway.example.com", APIKey: "gw-0123456789abcdef"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	_, stderr, err := captureStdoutAndStderr(t, func() error {
+		return handleCommand([]string{"set", "ds", "url=https://api.deepseek.com/anthropic"})
+	})
+	if err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if !strings.Contains(stderr, "does not look like a DeepSeek key") {
+		t.Errorf("expected a key format warning, got %q", stderr)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestMinKeyLength(t *testing.T) {
+	t.Cleanup(func() { applyValidationSettings(nil) })
+
+	t.Run("prompt and environment validation agree", func(t *testing.T) {
+		applyValidationSettings(nil)
+		for _, key := range []string{"a", "sk-ant-12", "0123456789", "sk-ant-api03-long-enough"} {
+			keyErr := validateAPIKey(key)
+			envErr := validateEnvironment(Environment{Name: "agree", URL: "https://api.anthropic.com", APIKey: key})
+			if (keyErr == nil) != (envErr == nil) {
+				t.Errorf("key %q: validateAPIKey=%v validateEnvironment=%v", key, keyErr, envErr)
+			}
+			if wantOK := len(key) >= defaultMinKeyLength; (keyErr == nil) != wantOK {
+				t.Errorf("key %q: expected ok=%v, got %v", key, wantOK, keyErr)
+			}
+		}
+	})
+
+	t.Run("short key error is identifiable", func(t *testing.T) {
+		applyValidationSettings(nil)
+		err := validateAPIKey("short")
+		if !errors.Is(err, errAPIKeyTooShort) || !strings.Contains(err.Error(), "minimum 10") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("configurable minimum", func(t *testing.T) {
+		applyValidationSettings(&ConfigSettings{Validation: &ValidationSettings{MinKeyLength: 4}})
+		if err := validateAPIKey("abcd"); err != nil {
+			t.Errorf("expected 4-char key to pass with min 4: %v", err)
+		}
+		if err := validateAPIKey("abc"); err == nil {
+			t.Error("expected 3-char key to fail with min 4")
+		}
+	})
+
+	t.Run("load warns instead of rejecting short stored keys", func(t *testing.T) {
+		path := withTempConfigPath(t)
+		content := `{"environments": [{"name": "legacy", "url": "https://api.anthropic.com", "api_key": "short"}]}`
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			t.Fatalf("expected short stored key to load, got %v", err)
+		}
+		if warning := shortAPIKeyWarning(config.Environments[0]); !strings.Contains(warning, "legacy") {
+			t.Errorf("expected warning naming the environment, got %q", warning)
+		}
+		if _, err := prepareEnvironment(config.Environments[0]); err != nil {
+			t.Errorf("expected legacy environment to remain launchable: %v", err)
+		}
+
+		// Adding a new environment still enforces the minimum
+		if err := addEnvironmentToConfig(&config, Environment{Name: "fresh", URL: "https://api.anthropic.com", APIKey: "tiny"}); err == nil {
+			t.Error("expected new short key to be rejected")
+		}
+		if err := saveConfig(config); err != nil {
+			t.Errorf("expected config with legacy short key to save: %v", err)
+		}
+	})
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Strategies for picking one key per launch from an environment's key pool
+const (
+	keyStrategyRoundRobin = "round-robin" // Next key after the last launch's (default)
+	keyStrategyRandom     = "random"      // Any key, independently each launch
+)
+
+// environmentKeys is the environment's key pool: api_key first, then api_keys without
+// blanks or repeats. An environment with only api_key has a one-key pool.
+func environmentKeys(env Environment) []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, key := range append([]string{env.APIKey}, env.APIKeys...) {
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// validateKeyStrategy accepts an empty strategy (round-robin) or one of the named ones
+func validateKeyStrategy(strategy string) error {
+	switch strategy {
+	case "", keyStrategyRoundRobin, keyStrategyRandom:
+		return nil
+	default:
+		return fmt.Errorf("'%s' must be %s or %s", strategy, keyStrategyRoundRobin, keyStrategyRandom)
+	}
+}
+
+// validateKeyPool checks the extra api_keys with the same rules as api_key
+func validateKeyPool(env Environment, keyCheck func(string) error) error {
+	for i, key := range env.APIKeys {
+		if err := keyCheck(key); err != nil {
+			return fmt.Errorf("invalid api_keys[%d] (%s): %w", i, keyFingerprint(key), err)
+		}
+	}
+	return validateKeyStrategy(env.KeyStrategy)
+}
+
+// keyState records the next round-robin position per environment, kept out of the
+// config so launches do not rewrite (and back up) it
+type keyState struct {
+	Next map[string]int `json:"next"`
+}
+
+// getKeyStatePath stores the state next to the config file
+func getKeyStatePath() (string, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "key-state.json"), nil
+}
+
+// loadKeyState reads the state; a missing or unreadable file starts every rotation over
+func loadKeyState() keyState {
+	state := keyState{Next: map[string]int{}}
+	path, err := getKeyStatePath()
+	if err != nil {
+		return state
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &state) != nil || state.Next == nil {
+		return keyState{Next: map[string]int{}}
+	}
+	return state
+}
+
+// saveKeyState writes the state with the same permissions as the config
+func saveKeyState(state keyState) error {
+	if err := ensureConfigDir(); err != nil {
+		return err
+	}
+	path, err := getKeyStatePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode key state: %w", err)
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// keyRandom picks the index for the random strategy; tests replace it
+var keyRandom = rand.Intn
+
+// pickLaunchKey returns env with APIKey set to the pool key for this launch, and a
+// label such as "key 2/3 d4e5f6" naming it ("" for single-key environments).
+// Round-robin advances a persisted counter so consecutive launches, even from
+// different terminals, spread across the keys.
+func pickLaunchKey(env Environment) (Environment, string) {
+	keys := environmentKeys(env)
+	if len(keys) <= 1 {
+		return env, ""
+	}
+
+	var index int
+	if env.KeyStrategy == keyStrategyRandom {
+		index = keyRandom(len(keys))
+	} else {
+		state := loadKeyState()
+		index = state.Next[env.Name] % len(keys)
+		state.Next[env.Name] = (index + 1) % len(keys)
+		if err := saveKeyState(state); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save key rotation state: %v\n", err)
+		}
+	}
+	env.APIKey = keys[index]
+	return env, fmt.Sprintf("key %d/%d %s", index+1, len(keys), keyFingerprint(env.APIKey))
+}
+
+// describeKeyPool renders the pool for display, e.g. "3 keys (a1b2c3, d4e5f6, 0718aa), round-robin"
+func describeKeyPool(env Environment) string {
+	keys := environmentKeys(env)
+	fingerprints := make([]string, len(keys))
+	for i, key := range keys {
+		fingerprints[i] = keyFingerprint(key)
+	}
+	strategy := env.KeyStrategy
+	if strategy == "" {
+		strategy = keyStrategyRoundRobin
+	}
+	return fmt.Sprintf("%d keys (%s), %s", len(keys), strings.Join(fingerprints, ", "), strategy)
+}
+
+// expandKeyPools turns each multi-key environment into one entry per key, named
+// "prod [key 2/3 d4e5f6]", so cce test reports every key's validity
+func expandKeyPools(envs []Environment) []Environment {
+	var expanded []Environment
+	for _, env := range envs {
+		keys := environmentKeys(env)
+		if len(keys) <= 1 {
+			expanded = append(expanded, env)
+			continue
+		}
+		for i, key := range keys {
+			single := env
+			single.APIKey = key
+			single.APIKeys = nil
+			single.Name = fmt.Sprintf("%s [key %d/%d %s]", env.Name, i+1, len(keys), keyFingerprint(key))
+			expanded = append(expanded, single)
+		}
+	}
+	return expanded
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	poolKeyA = "sk-ant-REDACTED"
+	poolKeyB = "sk-ant-REDACTED"
+	poolKeyC = "sk-ant-REDACTED"
+)
+
+func TestEnvironmentKeysTreatsAPIKeyAsFirst(t *testing.T) {
+	env := Environment{APIKey: poolKeyA, APIKeys: []string{poolKeyB, "", poolKeyA, poolKeyC}}
+	if got := strings.Join(environmentKeys(env), ","); got != poolKeyA+","+poolKeyB+","+poolKeyC {
+		t.Errorf("unexpected key pool: %s", got)
+	}
+	if got := environmentKeys(Environment{APIKey: poolKeyA}); len(got) != 1 {
+		t.Errorf("a single api_key should be a one-key pool, got %v", got)
+	}
+}
+
+func TestPickLaunchKeyRoundRobinPersists(t *testing.T) {
+	withTempConfigPath(t)
+	env := Environment{Name: "team", APIKey: poolKeyA, APIKeys: []string{poolKeyB, poolKeyC}}
+
+	var picked []string
+	for i := 0; i < 4; i++ {
+		chosen, label := pickLaunchKey(env)
+		if !strings.HasSuffix(label, keyFingerprint(chosen.APIKey)) {
+			t.Fatalf("label %q does not name key %s", label, keyFingerprint(chosen.APIKey))
+		}
+		picked = append(picked, chosen.APIKey)
+	}
+	if strings.Join(picked, ",") != strings.Join([]string{poolKeyA, poolKeyB, poolKeyC, poolKeyA}, ",") {
+		t.Errorf("round-robin should cycle through the pool, got %v", picked)
+	}
+	if loadKeyState().Next["team"] != 1 {
+		t.Errorf("expected the next position to be saved, got %v", loadKeyState().Next)
+	}
+
+	single, label := pickLaunchKey(Environment{Name: "solo", APIKey: poolKeyA})
+	if single.APIKey != poolKeyA || label != "" {
+		t.Errorf("single-key environments should keep their key")
+	}
+	if _, ok := loadKeyState().Next["solo"]; ok {
+		t.Error("single-key environments should not touch the rotation state")
+	}
+}
+
+func TestPickLaunchKeyRandom(t *testing.T) {
+	withTempConfigPath(t)
+	original := keyRandom
+	t.Cleanup(func() { keyRandom = original })
+	keyRandom = func(n int) int { return n - 1 }
+
+	env := Environment{Name: "team", APIKey: poolKeyA, APIKeys: []string{poolKeyB}, KeyStrategy: keyStrategyRandom}
+	if chosen, _ := pickLaunchKey(env); chosen.APIKey != poolKeyB {
+		t.Errorf("expected the randomly chosen key, got %s", keyFingerprint(chosen.APIKey))
+	}
+}
+
+func TestKeyPoolValidation(t *testing.T) {
+	env := Environment{Name: "team", URL: "https://api.anthropic.com", APIKey: poolKeyA, APIKeys: []string{"tiny"}}
+	if err := validateEnvironment(env); err == nil || !strings.Contains(err.Error(), "api_keys[0]") {
+		t.Errorf("expected the bad pool key to be reported, got %v", err)
+	}
+	if strings.Contains(validateEnvironment(env).Error(), "tiny") {
+		t.Error("validation errors must not reveal the key")
+	}
+
+	env.APIKeys = []string{poolKeyB}
+	env.KeyStrategy = "fastest"
+	if err := validateEnvironment(env); err == nil {
+		t.Error("expected an unknown key_strategy to be rejected")
+	}
+}
+
+func TestKeyPoolOutputIsMasked(t *testing.T) {
+	env := Environment{Name: "team", URL: "https://api.anthropic.com", APIKey: poolKeyA, APIKeys: []string{poolKeyB}}
+
+	expanded := expandKeyPools([]Environment{env, {Name: "solo", APIKey: poolKeyC}})
+	if len(expanded) != 3 || expanded[1].APIKey != poolKeyB || expanded[2].Name != "solo" {
+		t.Fatalf("unexpected expansion: %+v", expanded)
+	}
+	if want := "team [key 2/2 " + keyFingerprint(poolKeyB) + "]"; expanded[1].Name != want {
+		t.Errorf("expected %q, got %q", want, expanded[1].Name)
+	}
+
+	described := describeKeyPool(env)
+	if strings.Contains(described, poolKeyB) || !strings.Contains(described, keyFingerprint(poolKeyB)) {
+		t.Errorf("pool description should show fingerprints only: %s", described)
+	}
+	if keyFingerprint(poolKeyA) == keyFingerprint(poolKeyB) {
+		t.Error("pool keys should have distinct fingerprints")
+	}
+
+	doc, err := buildExportDocument(Config{Environments: []Environment{env}}, exportOptions{Redact: true})
+	if err != nil || len(doc.Environments[0].APIKeys) != 0 {
+		t.Errorf("redacted export should strip api_keys, got %v, %v", doc.Environments, err)
+	}
+}
+
+func TestLaunchUsesPoolKey(t *testing.T) {
+	withTempConfigPath(t)
+	if err := saveConfig(Config{Environments: []Environment{
+		{Name: "team", URL: "https://api.anthropic.com", APIKey: poolKeyA, APIKeys: []string{poolKeyB}},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	original := claudeLauncher
+	t.Cleanup(func() { claudeLauncher = original })
+	var launchedKeys []string
+	claudeLauncher = func(e Environment, args []string, workdir string) error {
+		launchedKeys = append(launchedKeys, e.APIKey)
+		return nil
+	}
+
+	output := captureStdout(t, func() {
+		for i := 0; i < 2; i++ {
+			if err := runDefaultWithOptions("team", nil, launchOptions{}); err != nil {
+				t.Fatalf("launch failed: %v", err)
+			}
+		}
+	})
+	if strings.Join(launchedKeys, ",") != poolKeyA+","+poolKeyB {
+		t.Errorf("expected consecutive launches to use each key, got %d launches", len(launchedKeys))
+	}
+	if !strings.Contains(output, "[key 2/2 "+keyFingerprint(poolKeyB)+"]") || strings.Contains(output, poolKeyB) {
+		t.Errorf("banner should name the key by fingerprint only: %q", output)
+	}
+}
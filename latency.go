@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Selector latency probes are short and their results are only reused briefly,
+// since an endpoint's round trip changes from minute to minute
+const (
+	selectorProbeTimeout = 2 * time.Second
+	latencyCacheTTL      = time.Minute
+)
+
+// selectorCheck turns on latency probes in the interactive selector (select --check)
+var selectorCheck bool
+
+// latencyEntry is one endpoint's last measured round trip
+type latencyEntry struct {
+	Latency   time.Duration `json:"latency"`
+	Reachable bool          `json:"reachable"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// label renders the entry for the selector, e.g. "182ms" or "unreachable"
+func (e latencyEntry) label() string {
+	if !e.Reachable {
+		return "unreachable"
+	}
+	return e.Latency.Round(time.Millisecond).String()
+}
+
+// latencyCache holds recent latencies keyed like the model cache
+type latencyCache struct {
+	Endpoints map[string]latencyEntry `json:"endpoints"`
+}
+
+// getLatencyCachePath stores the cache next to the config file
+func getLatencyCachePath() (string, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "latency-cache.json"), nil
+}
+
+// loadLatencyCache reads the cache; a missing or unreadable file yields an empty cache,
+// since the probes simply run again
+func loadLatencyCache() latencyCache {
+	cache := latencyCache{Endpoints: map[string]latencyEntry{}}
+	path, err := getLatencyCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &cache) != nil || cache.Endpoints == nil {
+		return latencyCache{Endpoints: map[string]latencyEntry{}}
+	}
+	return cache
+}
+
+// saveLatencyCache writes the cache with the same permissions as the config
+func saveLatencyCache(cache latencyCache) error {
+	if err := ensureConfigDir(); err != nil {
+		return err
+	}
+	path, err := getLatencyCachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode latency cache: %w", err)
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// latencyProbe is called once per environment; tests replace it to avoid the network
+var latencyProbe = func(ctx context.Context, env Environment) latencyEntry {
+	nv := newNetworkValidator(selectorProbeTimeout).withRetries(0)
+	result, _ := nv.checkEnvironmentContext(ctx, env)
+	return latencyEntry{Latency: result.Latency, Reachable: result.Reachable, CheckedAt: time.Now()}
+}
+
+// latencyTracker collects selector latencies as background probes finish
+type latencyTracker struct {
+	mu      sync.Mutex
+	entries map[string]latencyEntry // Keyed by modelCacheKey of the URL
+	updates chan struct{}           // Signalled (without blocking) when an entry arrives
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// startLatencyTracker seeds the tracker from fresh cache entries and probes the remaining
+// environments in the background, so the first render never waits on the network
+func startLatencyTracker(envs []Environment) *latencyTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	lt := &latencyTracker{
+		entries: map[string]latencyEntry{},
+		updates: make(chan struct{}, 1),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	cache := loadLatencyCache()
+	var pending []Environment
+	seen := map[string]bool{}
+	for _, env := range envs {
+		key := modelCacheKey(env.URL)
+		if seen[key] || isCloudProvider(env) {
+			continue
+		}
+		seen[key] = true
+		if entry, ok := cache.Endpoints[key]; ok && time.Since(entry.CheckedAt) < latencyCacheTTL {
+			lt.entries[key] = entry
+			continue
+		}
+		pending = append(pending, env)
+	}
+
+	go func() {
+		defer close(lt.done)
+		runBounded(ctx, len(pending), defaultCheckConcurrency, func(i int) {
+			entry := latencyProbe(ctx, pending[i])
+			if ctx.Err() != nil {
+				return // Cancelled probes measured nothing useful
+			}
+			lt.mu.Lock()
+			lt.entries[modelCacheKey(pending[i].URL)] = entry
+			lt.mu.Unlock()
+			select {
+			case lt.updates <- struct{}{}:
+			default:
+			}
+		})
+	}()
+	return lt
+}
+
+// label returns the latency shown next to env, or "" while its probe is still running
+func (lt *latencyTracker) label(env Environment) string {
+	if lt == nil {
+		return ""
+	}
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	if entry, ok := lt.entries[modelCacheKey(env.URL)]; ok {
+		return entry.label()
+	}
+	return ""
+}
+
+// stop cancels outstanding probes and caches what was measured for the next run
+func (lt *latencyTracker) stop() {
+	lt.cancel()
+	<-lt.done
+
+	cache := loadLatencyCache()
+	lt.mu.Lock()
+	for key, entry := range lt.entries {
+		cache.Endpoints[key] = entry
+	}
+	lt.mu.Unlock()
+	for key, entry := range cache.Endpoints {
+		if time.Since(entry.CheckedAt) >= latencyCacheTTL {
+			delete(cache.Endpoints, key)
+		}
+	}
+	if err := saveLatencyCache(cache); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save latency cache: %v\n", err)
+	}
+}
+
+// appendLatency adds label after line, shortening line so the result stays within width
+func appendLatency(line, label string, width int) string {
+	if label == "" {
+		return line
+	}
+	suffix := "  " + label
+	room := width - displayWidth(suffix)
+	if room < 10 {
+		// Too narrow to show both; the entry itself matters more
+		return line
+	}
+	line, _ = fitWidth(line, room)
+	return line + suffix
+}
+
+// selectorInput is a stdin read delivered on a channel
+type selectorInput struct {
+	data []byte
+	err  error
+}
+
+// readSelectorInput reads stdin on a goroutine so the selector loop can also wake for
+// latency results. The reader is abandoned once a selection is made.
+func readSelectorInput() <-chan selectorInput {
+	inputs := make(chan selectorInput)
+	go func() {
+		for {
+			buffer := make([]byte, 10)
+			n, err := os.Stdin.Read(buffer)
+			inputs <- selectorInput{data: buffer[:n], err: err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return inputs
+}
+
+// selectorLatency is the tracker behind the selector being shown, nil without --check
+var selectorLatency *latencyTracker
+
+// startSelectorLatency begins probing for an interactive selector when --check was given.
+// It returns the channel keys should be read from (nil to read stdin directly) and a
+// function that ends the probes.
+func startSelectorLatency(envs []Environment) (<-chan selectorInput, func()) {
+	if !selectorCheck {
+		return nil, func() {}
+	}
+	selectorLatency = startLatencyTracker(envs)
+	return readSelectorInput(), func() {
+		selectorLatency.stop()
+		selectorLatency = nil
+	}
+}
+
+// nextSelectorKey waits for a key press. With inputs it also wakes when a latency result
+// arrives, reporting redraw so the menu is rendered again before waiting further.
+func nextSelectorKey(buffer []byte, inputs <-chan selectorInput) (key []byte, redraw bool, err error) {
+	if inputs == nil {
+		n, err := os.Stdin.Read(buffer)
+		return buffer[:n], false, err
+	}
+	select {
+	case in := <-inputs:
+		return in.data, false, in.err
+	case <-selectorLatency.updates:
+		return nil, true, nil
+	}
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -68,52 +73,312 @@ func checkClaudeCodeExists() error {
 	return nil
 }
 
+// Model resolution sources, listed in precedence order
+const (
+	modelSourceOverride    = "--model override"
+	modelSourceEnvironment = "environment model"
+	modelSourceEnvVars     = "env_vars ANTHROPIC_MODEL"
+	modelSourceConfig      = "settings default_model"
+	modelSourceDefault     = "claude default"
+)
+
+// modelResolution records the model injected for a launch and where it came from
+type modelResolution struct {
+	Model  string
+	Source string
+}
+
+// resolveModel picks the single ANTHROPIC_MODEL value for a launch.
+// Precedence: one-run override > env.Model > env.EnvVars["ANTHROPIC_MODEL"] >
+// settings.default_model > claude's own default.
+// An empty Model in the result means ANTHROPIC_MODEL is not set and claude picks its default.
+func resolveModel(env Environment, override string) modelResolution {
+	if override != "" {
+		return modelResolution{Model: override, Source: modelSourceOverride}
+	}
+	if env.Model != "" {
+		return modelResolution{Model: env.Model, Source: modelSourceEnvironment}
+	}
+	if model := env.EnvVars["ANTHROPIC_MODEL"]; model != "" {
+		return modelResolution{Model: model, Source: modelSourceEnvVars}
+	}
+	if configDefaultModel != "" {
+		return modelResolution{Model: configDefaultModel, Source: modelSourceConfig}
+	}
+	return modelResolution{Source: modelSourceDefault}
+}
+
+// describe renders the resolution for banners and diagnostics
+func (mr modelResolution) describe() string {
+	if mr.Model == "" {
+		return mr.Source
+	}
+	return fmt.Sprintf("%s (from %s)", mr.Model, mr.Source)
+}
+
+// claudeConfigDirVar tells claude where to keep settings, credentials and history
+const claudeConfigDirVar = "CLAUDE_CONFIG_DIR"
+
+// managedEnvVars are always set by CCE itself and cannot be supplied by an env file
+var managedEnvVars = map[string]bool{
+	"ANTHROPIC_BASE_URL":   true,
+	"ANTHROPIC_API_KEY":    true,
+	"ANTHROPIC_AUTH_TOKEN": true,
+	"ANTHROPIC_MODEL":      true,
+}
+
+// parseEnvFile reads KEY=value lines from a dotenv file.
+// Blank lines and # comments are skipped, an "export " prefix is allowed, double-quoted
+// values support \n, \" and \\ escapes, single-quoted values are literal, and unquoted
+// values end at an inline " #" comment.
+func parseEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open env file: %w", err)
+	}
+	defer file.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		eq := strings.Index(line, "=")
+		if eq <= 0 {
+			return nil, fmt.Errorf("%s:%d: expected KEY=value", path, lineNum)
+		}
+		key := strings.TrimSpace(line[:eq])
+		if !isValidEnvVarName(key) {
+			return nil, fmt.Errorf("%s:%d: invalid variable name '%s'", path, lineNum, key)
+		}
+
+		value, err := parseEnvFileValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+
+	return vars, nil
+}
+
+// parseEnvFileValue decodes the right-hand side of a dotenv line
+func parseEnvFileValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	switch raw[0] {
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			switch {
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+			case c == '"':
+				if rest := strings.TrimSpace(raw[i+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+					return "", fmt.Errorf("unexpected text after closing quote")
+				}
+				return b.String(), nil
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated double-quoted value")
+	case '\'':
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single-quoted value")
+		}
+		if rest := strings.TrimSpace(raw[end+2:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected text after closing quote")
+		}
+		return raw[1 : end+1], nil
+	}
+
+	if idx := strings.Index(raw, " #"); idx >= 0 {
+		raw = raw[:idx]
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// mergeEnvFileVars layers env-file variables under the environment's own variables.
+// Precedence: CCE-managed vars > per-environment env_vars > env file. Managed keys in the
+// file are dropped and returned so the caller can warn about them.
+func mergeEnvFileVars(env Environment, fileVars map[string]string) (Environment, []string) {
+	merged := make(map[string]string, len(fileVars)+len(env.EnvVars))
+	var ignored []string
+	for key, value := range fileVars {
+		if managedEnvVars[key] {
+			ignored = append(ignored, key)
+			continue
+		}
+		merged[key] = value
+	}
+	for key, value := range env.EnvVars {
+		merged[key] = value
+	}
+	sort.Strings(ignored)
+
+	env.EnvVars = merged
+	return env, ignored
+}
+
 // prepareEnvironment sets up environment variables for Claude Code execution
 func prepareEnvironment(env Environment) ([]string, error) {
-	// Validate environment before setting variables
-	if err := validateEnvironment(env); err != nil {
+	// Validate environment before setting variables; stored keys may predate the length minimum
+	if err := validateStoredEnvironment(env); err != nil {
+		return nil, fmt.Errorf("environment preparation failed: %w", err)
+	}
+	if err := checkCACert(env); err != nil {
+		return nil, fmt.Errorf("environment preparation failed: invalid ca_cert: %w", err)
+	}
+	env, err := resolveSecretRefs(env)
+	if err != nil {
 		return nil, fmt.Errorf("environment preparation failed: %w", err)
 	}
 
 	// Get current environment
 	currentEnv := os.Environ()
-
-	// Calculate capacity for new environment slice
-	envVarsCount := len(env.EnvVars)
-	newEnv := make([]string, 0, len(currentEnv)+3+envVarsCount)
+	assignments := launchVariables(env)
+	newEnv := make([]string, 0, len(currentEnv)+len(assignments))
 
 	// Copy existing environment variables (except Anthropic ones)
 	for _, envVar := range currentEnv {
+		// An inherited config dir must not shadow the environment's own
+		if env.SettingsDir != "" && strings.HasPrefix(envVar, claudeConfigDirVar+"=") {
+			continue
+		}
+		if env.CACert != "" && strings.HasPrefix(envVar, caCertVar+"=") {
+			continue
+		}
+		if isProviderSwitch(envVar) {
+			continue
+		}
 		// Skip existing Anthropic variables to avoid conflicts
 		if len(envVar) >= 9 && envVar[:9] != "ANTHROPIC" {
 			newEnv = append(newEnv, envVar)
 		}
 	}
 
-	// Add Anthropic-specific environment variables
-	newEnv = append(newEnv, fmt.Sprintf("ANTHROPIC_BASE_URL=%s", env.URL))
-	// Determine which env var name to use for API key
-	keyVar := env.APIKeyEnv
-	if keyVar == "" {
-		keyVar = "ANTHROPIC_API_KEY"
+	for _, a := range assignments {
+		newEnv = append(newEnv, a.Key+"="+a.Value)
 	}
-	newEnv = append(newEnv, fmt.Sprintf("%s=%s", keyVar, env.APIKey))
 
-	// Add ANTHROPIC_MODEL if specified
-	if env.Model != "" {
-		newEnv = append(newEnv, fmt.Sprintf("ANTHROPIC_MODEL=%s", env.Model))
+	return newEnv, nil
+}
+
+// envAssignment is one variable CCE sets for the claude process
+type envAssignment struct {
+	Key   string
+	Value string
+}
+
+// launchVariables lists the variables CCE sets for an environment, in injection order:
+// base URL (or the cloud provider bundle), API key variable, the single resolved
+// ANTHROPIC_MODEL, then sorted env_vars.
+func launchVariables(env Environment) []envAssignment {
+	var assignments []envAssignment
+	if isCloudProvider(env) {
+		assignments = providerVariables(env)
+		if env.APIKey != "" {
+			assignments = append(assignments, envAssignment{Key: resolveAPIKeyVar(env), Value: env.APIKey})
+		}
+	} else {
+		assignments = []envAssignment{
+			{Key: "ANTHROPIC_BASE_URL", Value: env.URL},
+			// Determine which env var name to use for API key
+			{Key: resolveAPIKeyVar(env), Value: env.APIKey},
+		}
+	}
+
+	// Add exactly one ANTHROPIC_MODEL, resolved by precedence
+	if resolved := resolveModel(env, ""); resolved.Model != "" {
+		assignments = append(assignments, envAssignment{Key: "ANTHROPIC_MODEL", Value: resolved.Model})
+	}
+
+	// Point claude at the environment's own config dir instead of ~/.claude. Only
+	// CLAUDE_CONFIG_DIR is set: overriding HOME would also move git, ssh and shell config.
+	settingsDir := ""
+	if env.SettingsDir != "" {
+		if dir, err := expandSettingsDir(env.SettingsDir); err == nil {
+			settingsDir = dir
+			assignments = append(assignments, envAssignment{Key: claudeConfigDirVar, Value: dir})
+		}
+	}
+	caCert := ""
+	if env.CACert != "" {
+		if path, err := expandSettingsDir(env.CACert); err == nil {
+			caCert = path
+			assignments = append(assignments, envAssignment{Key: caCertVar, Value: path})
+		}
+	}
+
+	// Headers replace an ANTHROPIC_CUSTOM_HEADERS given in env_vars
+	if len(env.Headers) > 0 {
+		assignments = append(assignments, envAssignment{Key: customHeadersVar, Value: formatCustomHeaders(env.Headers)})
 	}
 
 	// Add additional environment variables
-	if env.EnvVars != nil {
-		for key, value := range env.EnvVars {
-			if key != "" && value != "" {
-				newEnv = append(newEnv, fmt.Sprintf("%s=%s", key, value))
-			}
+	keys := make([]string, 0, len(env.EnvVars))
+	for key, value := range env.EnvVars {
+		// ANTHROPIC_MODEL was already injected by resolveModel
+		if key == "ANTHROPIC_MODEL" || key == "" || value == "" {
+			continue
+		}
+		if key == claudeConfigDirVar && settingsDir != "" {
+			continue
+		}
+		if key == caCertVar && caCert != "" {
+			continue
 		}
+		if key == customHeadersVar && len(env.Headers) > 0 {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		assignments = append(assignments, envAssignment{Key: key, Value: env.EnvVars[key]})
 	}
 
-	return newEnv, nil
+	return assignments
+}
+
+// ensureSettingsDir creates the environment's isolated claude config dir on first use
+func ensureSettingsDir(env Environment) error {
+	if env.SettingsDir == "" {
+		return nil
+	}
+	dir, err := expandSettingsDir(env.SettingsDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		errorCtx := newErrorContext("settings directory creation", "launcher")
+		errorCtx.addContext("path", dir)
+		errorCtx.addSuggestion("Check permissions on the parent directory or change settings_dir with 'cce set'")
+		return errorCtx.formatError(err)
+	}
+	return nil
 }
 
 // launchClaudeCode executes claude with the specified environment and arguments
@@ -129,6 +394,9 @@ func launchClaudeCode(env Environment, args []string, workdir string) error {
 	if err != nil {
 		return fmt.Errorf("Claude Code launcher failed: %w", err)
 	}
+	if err := ensureSettingsDir(env); err != nil {
+		return fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
 
 	// Find claude executable path
 	claudePath, err := exec.LookPath("claude")
@@ -148,8 +416,14 @@ func launchClaudeCode(env Environment, args []string, workdir string) error {
 	// Prepare command arguments
 	cmdArgs := append([]string{"claude"}, args...)
 
+	// claude keeps this PID after exec, which is how cce current tells the marker is live
+	if err := writeCurrentMarker(env.Name, os.Getpid()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record current environment: %v\n", err)
+	}
+
 	// Execute claude and replace current process (Unix exec behavior)
 	if err := syscall.Exec(claudePath, cmdArgs, envVars); err != nil {
+		clearCurrentMarker(os.Getpid())
 		return fmt.Errorf("Claude Code execution failed: %w", err)
 	}
 
@@ -157,6 +431,125 @@ func launchClaudeCode(env Environment, args []string, workdir string) error {
 	return fmt.Errorf("unexpected return from Claude Code execution")
 }
 
+// detachedLogDir holds output files for claude processes started with --detach
+func detachedLogDir() (string, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "logs"), nil
+}
+
+// launchClaudeCodeDetached starts claude in its own session and returns without waiting.
+// Output goes to a log file since the caller's terminal may be gone by the time it is written.
+func launchClaudeCodeDetached(env Environment, args []string, workdir string) error {
+	if err := checkClaudeCodeExists(); err != nil {
+		return fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+
+	envVars, err := prepareEnvironment(env)
+	if err != nil {
+		return fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+	if err := ensureSettingsDir(env); err != nil {
+		return fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+
+	logDir, err := detachedLogDir()
+	if err != nil {
+		return fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+	if err := os.MkdirAll(logDir, 0700); err != nil {
+		return fmt.Errorf("Claude Code launcher failed - cannot create log directory: %w", err)
+	}
+	logPath := filepath.Join(logDir, fmt.Sprintf("claude-%s-%s.log", env.Name, time.Now().Format("20060102-150405")))
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("Claude Code launcher failed - cannot create log file: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command("claude", args...)
+	cmd.Dir = workdir
+	cmd.Env = envVars
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("Claude Code process start failed: %w", err)
+	}
+	pid := cmd.Process.Pid
+	if err := writeCurrentMarker(env.Name, pid); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record current environment: %v\n", err)
+	}
+	if err := cmd.Process.Release(); err != nil {
+		return fmt.Errorf("Claude Code process release failed: %w", err)
+	}
+
+	if _, err := fmt.Printf("Started claude in the background (PID %d)\nOutput: %s\n", pid, logPath); err != nil {
+		return fmt.Errorf("failed to display detached process: %w", err)
+	}
+	return nil
+}
+
+// launchClaudeCodeLogged runs claude as a child with its stdout and stderr written to
+// logPath (mode 0600, appended) and, unless logOnly, to the terminal as well. The file is
+// closed before cce exits with claude's exit code.
+func launchClaudeCodeLogged(env Environment, args []string, workdir, logPath string, logOnly bool) error {
+	if err := checkClaudeCodeExists(); err != nil {
+		return fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+
+	envVars, err := prepareEnvironment(env)
+	if err != nil {
+		return fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+	if err := ensureSettingsDir(env); err != nil {
+		return fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("Claude Code launcher failed - cannot open log file: %w", err)
+	}
+
+	cmd := exec.Command("claude", args...)
+	cmd.Dir = workdir
+	cmd.Env = envVars
+	cmd.Stdin = os.Stdin
+	if logOnly {
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+	} else {
+		cmd.Stdout = io.MultiWriter(os.Stdout, logFile)
+		cmd.Stderr = io.MultiWriter(os.Stderr, logFile)
+	}
+
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		return fmt.Errorf("Claude Code process start failed: %w", err)
+	}
+	if err := writeCurrentMarker(env.Name, os.Getpid()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record current environment: %v\n", err)
+	}
+
+	err = cmd.Wait()
+	clearCurrentMarker(os.Getpid())
+	if closeErr := logFile.Close(); closeErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to close log file %s: %v\n", logPath, closeErr)
+	}
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
+				os.Exit(status.ExitStatus())
+			}
+		}
+		return fmt.Errorf("Claude Code execution failed: %w", err)
+	}
+	return nil
+}
+
 // launchClaudeCodeWithOutput executes claude and waits for it to complete (for testing)
 // If workdir is provided, claude is launched from that directory.
 func launchClaudeCodeWithOutput(env Environment, args []string, workdir string) error {
@@ -170,6 +563,9 @@ func launchClaudeCodeWithOutput(env Environment, args []string, workdir string)
 	if err != nil {
 		return fmt.Errorf("Claude Code launcher failed: %w", err)
 	}
+	if err := ensureSettingsDir(env); err != nil {
+		return fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
 
 	// Create command
 	cmd := exec.Command("claude", args...)
@@ -186,8 +582,14 @@ func launchClaudeCodeWithOutput(env Environment, args []string, workdir string)
 		return fmt.Errorf("Claude Code process start failed: %w", err)
 	}
 
+	if err := writeCurrentMarker(env.Name, os.Getpid()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record current environment: %v\n", err)
+	}
+
 	// Wait for completion and handle exit code
-	if err := cmd.Wait(); err != nil {
+	err = cmd.Wait()
+	clearCurrentMarker(os.Getpid())
+	if err != nil {
 		if exitError, ok := err.(*exec.ExitError); ok {
 			// Get exit code from the process
 			if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -172,3 +173,239 @@ func TestLaunchClaudeCodeWithOutputValidation(t *testing.T) {
 		t.Errorf("Expected launcher error, got: %v", err)
 	}
 }
+
+func TestResolveModelPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        Environment
+		override   string
+		wantModel  string
+		wantSource string
+	}{
+		{
+			name:       "override beats everything",
+			env:        Environment{Model: "claude-env", EnvVars: map[string]string{"ANTHROPIC_MODEL": "claude-var"}},
+			override:   "claude-flag",
+			wantModel:  "claude-flag",
+			wantSource: modelSourceOverride,
+		},
+		{
+			name:       "environment model beats env vars",
+			env:        Environment{Model: "claude-env", EnvVars: map[string]string{"ANTHROPIC_MODEL": "claude-var"}},
+			wantModel:  "claude-env",
+			wantSource: modelSourceEnvironment,
+		},
+		{
+			name:       "env vars used when model empty",
+			env:        Environment{EnvVars: map[string]string{"ANTHROPIC_MODEL": "claude-var"}},
+			wantModel:  "claude-var",
+			wantSource: modelSourceEnvVars,
+		},
+		{
+			name:       "override with nothing else",
+			env:        Environment{},
+			override:   "claude-flag",
+			wantModel:  "claude-flag",
+			wantSource: modelSourceOverride,
+		},
+		{
+			name:       "claude default when nothing set",
+			env:        Environment{EnvVars: map[string]string{"OTHER": "x"}},
+			wantModel:  "",
+			wantSource: modelSourceDefault,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveModel(tt.env, tt.override)
+			if got.Model != tt.wantModel || got.Source != tt.wantSource {
+				t.Errorf("resolveModel() = %+v, want model %q from %q", got, tt.wantModel, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestPrepareEnvironmentInjectsSingleModel(t *testing.T) {
+	countModels := func(vars []string) (int, string) {
+		count := 0
+		value := ""
+		for _, v := range vars {
+			if strings.HasPrefix(v, "ANTHROPIC_MODEL=") {
+				count++
+				value = strings.TrimPrefix(v, "ANTHROPIC_MODEL=")
+			}
+		}
+		return count, value
+	}
+
+	base := Environment{Name: "model", URL: "https://api.anthropic.com", APIKey: "sk-ant-test"}
+
+	t.Run("model and env var both set", func(t *testing.T) {
+		env := base
+		env.Model = "claude-env"
+		env.EnvVars = map[string]string{"ANTHROPIC_MODEL": "claude-var"}
+		vars, err := prepareEnvironment(env)
+		if err != nil {
+			t.Fatalf("prepareEnvironment() failed: %v", err)
+		}
+		if count, value := countModels(vars); count != 1 || value != "claude-env" {
+			t.Errorf("got %d ANTHROPIC_MODEL entries (last %q), want exactly one claude-env", count, value)
+		}
+	})
+
+	t.Run("only env var set", func(t *testing.T) {
+		env := base
+		env.EnvVars = map[string]string{"ANTHROPIC_MODEL": "claude-var"}
+		vars, err := prepareEnvironment(env)
+		if err != nil {
+			t.Fatalf("prepareEnvironment() failed: %v", err)
+		}
+		if count, value := countModels(vars); count != 1 || value != "claude-var" {
+			t.Errorf("got %d ANTHROPIC_MODEL entries (last %q), want exactly one claude-var", count, value)
+		}
+	})
+
+	t.Run("nothing set", func(t *testing.T) {
+		vars, err := prepareEnvironment(base)
+		if err != nil {
+			t.Fatalf("prepareEnvironment() failed: %v", err)
+		}
+		if count, _ := countModels(vars); count != 0 {
+			t.Errorf("expected no ANTHROPIC_MODEL, got %d", count)
+		}
+	})
+}
+
+func TestVerboseBannerShowsResolvedModel(t *testing.T) {
+	tempDir := t.TempDir()
+	originalConfigPath := configPathOverride
+	configPathOverride = tempDir + "/config.json"
+	defer func() { configPathOverride = originalConfigPath }()
+
+	originalLauncher := claudeLauncher
+	defer func() { claudeLauncher = originalLauncher }()
+	claudeLauncher = func(Environment, []string, string) error { return nil }
+
+	env := Environment{Name: "verbose", URL: "https://api.anthropic.com", APIKey: "sk-ant-test", Model: "claude-sonnet-4-20250514"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	t.Setenv("CCE_VERBOSE", "1")
+	output := captureStdout(t, func() {
+		if err := runDefaultWithOverride("verbose", nil, "", false); err != nil {
+			t.Fatalf("runDefaultWithOverride() failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "[model: claude-sonnet-4-20250514 (from environment model)]") {
+		t.Errorf("expected resolved model in banner, got %q", output)
+	}
+
+	os.Unsetenv("CCE_VERBOSE")
+	output = captureStdout(t, func() {
+		if err := runDefaultWithOverride("verbose", nil, "", false); err != nil {
+			t.Fatalf("runDefaultWithOverride() failed: %v", err)
+		}
+	})
+	if strings.Contains(output, "[model:") {
+		t.Errorf("expected plain banner without CCE_VERBOSE, got %q", output)
+	}
+}
+
+func TestModelOverrideFlag(t *testing.T) {
+	parseTests := []struct {
+		name       string
+		args       []string
+		wantModel  string
+		wantClaude []string
+	}{
+		{"long flag", []string{"--model", "claude-opus-4-1", "chat"}, "claude-opus-4-1", []string{"chat"}},
+		{"short flag with env", []string{"-e", "dev", "-m", "claude-opus-4-1", "--", "-r"}, "claude-opus-4-1", []string{"-r"}},
+		{"attached value", []string{"--model=claude-opus-4-1"}, "claude-opus-4-1", []string{}},
+		{"claude's own flag after the claude args", []string{"chat", "--model", "claude-3"}, "", []string{"chat", "--model", "claude-3"}},
+		{"claude's own flag after --", []string{"--", "--model", "claude-3"}, "", []string{"--model", "claude-3"}},
+	}
+	for _, tt := range parseTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseArguments(tt.args)
+			if result.Error != nil {
+				t.Fatalf("parseArguments() error: %v", result.Error)
+			}
+			if result.CCEFlags["model"] != tt.wantModel {
+				t.Errorf("model flag = %q, want %q", result.CCEFlags["model"], tt.wantModel)
+			}
+			if !reflect.DeepEqual(result.ClaudeArgs, tt.wantClaude) {
+				t.Errorf("claude args = %q, want %q", result.ClaudeArgs, tt.wantClaude)
+			}
+		})
+	}
+
+	withTempConfigPath(t)
+	originalLauncher := claudeLauncher
+	defer func() { claudeLauncher = originalLauncher }()
+	var launched Environment
+	claudeLauncher = func(env Environment, _ []string, _ string) error {
+		launched = env
+		return nil
+	}
+	originalDefault := configDefaultModel
+	defer func() { configDefaultModel = originalDefault }()
+	configDefaultModel = "claude-default"
+
+	env := Environment{
+		Name:    "layered",
+		URL:     "https://api.anthropic.com",
+		APIKey:  "sk-ant-REDACTED",
+		Model:   "claude-env",
+		EnvVars: map[string]string{"ANTHROPIC_MODEL": "claude-var"},
+	}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	// The flag beats the environment's model, its env_vars and default_model
+	captureStdout(t, func() {
+		if err := runDefaultWithOptions("layered", nil, launchOptions{ModelOverride: "claude-flag"}); err != nil {
+			t.Fatalf("launch failed: %v", err)
+		}
+	})
+	vars, err := prepareEnvironment(launched)
+	if err != nil {
+		t.Fatalf("prepareEnvironment() failed: %v", err)
+	}
+	var models []string
+	for _, kv := range vars {
+		if strings.HasPrefix(kv, "ANTHROPIC_MODEL=") {
+			models = append(models, kv)
+		}
+	}
+	if !reflect.DeepEqual(models, []string{"ANTHROPIC_MODEL=claude-flag"}) {
+		t.Errorf("launched with %v, want only the override", models)
+	}
+
+	// Without the flag the stored model still wins, and the config is untouched
+	captureStdout(t, func() {
+		if err := runDefaultWithOptions("layered", nil, launchOptions{}); err != nil {
+			t.Fatalf("launch failed: %v", err)
+		}
+	})
+	if launched.Model != "claude-env" {
+		t.Errorf("launched model = %q, want the stored claude-env", launched.Model)
+	}
+
+	err = runDefaultWithOptions("layered", nil, launchOptions{ModelOverride: "claude;rm"})
+	if err == nil || !strings.Contains(err.Error(), "invalid --model") {
+		t.Errorf("expected invalid --model error, got %v", err)
+	}
+
+	// The dry-run plan credits the flag
+	output := captureStdout(t, func() {
+		if err := runDefaultWithOptions("layered", nil, launchOptions{ModelOverride: "claude-flag", DryRun: true}); err != nil {
+			t.Fatalf("dry run failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, modelSourceOverride) {
+		t.Errorf("expected plan to credit %q, got %q", modelSourceOverride, output)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package main
+
+import "syscall"
+
+// detachedProcAttr starts --detach launches in their own session, so closing the
+// terminal does not send SIGHUP to claude
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package main
+
+import "syscall"
+
+// detachedProcAttr starts --detach launches in a new process group, so Ctrl-C in the
+// console that started cce is not delivered to claude
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
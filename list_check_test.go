@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckAllKeepsOrderAndReportsFailures(t *testing.T) {
+	ok := newProbeServer(t, http.StatusOK)
+	denied := newProbeServer(t, http.StatusUnauthorized)
+
+	envs := []Environment{
+		{Name: "alpha", URL: ok.URL, APIKey: "sk-ant-alpha-key"},
+		{Name: "beta", URL: denied.URL, APIKey: "sk-ant-beta-key"},
+		{Name: "gamma", URL: ok.URL, APIKey: "sk-ant-gamma-key"},
+	}
+	checks := newNetworkValidator(time.Second).checkAll(context.Background(), envs, 2)
+
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(checks))
+	}
+	for i, name := range []string{"alpha", "beta", "gamma"} {
+		if checks[i].Name != name {
+			t.Errorf("result %d: expected %s, got %s", i, name, checks[i].Name)
+		}
+		if checks[i].Cancelled {
+			t.Errorf("%s unexpectedly cancelled", name)
+		}
+	}
+	if checks[0].Err != nil || checks[2].Err != nil {
+		t.Errorf("expected alpha and gamma to pass: %v, %v", checks[0].Err, checks[2].Err)
+	}
+	if checks[1].Err == nil {
+		t.Error("expected beta to fail with 401")
+	}
+
+	var buf bytes.Buffer
+	failed, cancelled, err := renderEnvironmentChecks(&buf, checks)
+	if err != nil {
+		t.Fatalf("renderEnvironmentChecks() failed: %v", err)
+	}
+	if failed != 1 || cancelled != 0 {
+		t.Errorf("expected 1 failure and 0 cancelled, got %d and %d", failed, cancelled)
+	}
+	if !strings.Contains(buf.String(), "beta   FAILED") {
+		t.Errorf("expected aligned failure line, got:\n%s", buf.String())
+	}
+}
+
+func TestCheckAllCancellation(t *testing.T) {
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	envs := make([]Environment, 5)
+	for i := range envs {
+		envs[i] = Environment{Name: string(rune('a' + i)), URL: hanging.URL, APIKey: "sk-ant-hanging-key"}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	checks := newNetworkValidator(10*time.Second).checkAll(ctx, envs, 2)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("cancellation took too long: %v", elapsed)
+	}
+
+	if len(checks) != len(envs) {
+		t.Fatalf("expected %d results, got %d", len(envs), len(checks))
+	}
+	for i, check := range checks {
+		if !check.Cancelled {
+			t.Errorf("result %d (%s) should be cancelled: %+v", i, check.Name, check)
+		}
+		if check.Name != envs[i].Name {
+			t.Errorf("result %d: expected %s, got %s", i, envs[i].Name, check.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, cancelled, _ := renderEnvironmentChecks(&buf, checks); cancelled != len(envs) {
+		t.Errorf("expected %d cancelled, got %d", len(envs), cancelled)
+	}
+}
+
+func TestParseListOptionsCheck(t *testing.T) {
+	opts, err := parseListOptions([]string{"--check"})
+	if err != nil {
+		t.Fatalf("parseListOptions() failed: %v", err)
+	}
+	if !opts.Check {
+		t.Error("expected Check to be set")
+	}
+}
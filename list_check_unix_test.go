@@ -0,0 +1,25 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalContextCancelsOnInterrupt(t *testing.T) {
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatalf("failed to send SIGINT: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("signal context was not cancelled by SIGINT")
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListNames(t *testing.T) {
+	withTempConfigPath(t)
+	config := Config{Environments: []Environment{
+		{Name: "staging", URL: "https://staging.example.com", APIKey: "sk-ant-staging"},
+		{Name: "dev", URL: "https://dev.example.com", APIKey: "sk-ant-dev"},
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-prod"},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := handleCommand([]string{"list", "--names"}); err != nil {
+			t.Fatalf("list --names failed: %v", err)
+		}
+	})
+	if output != "dev\nprod\nstaging\n" {
+		t.Errorf("unexpected output: %q", output)
+	}
+
+	empty := Config{}
+	output = captureStdout(t, func() {
+		if err := displayEnvironmentNames(empty); err != nil {
+			t.Fatalf("displayEnvironmentNames() failed: %v", err)
+		}
+	})
+	if output != "" {
+		t.Errorf("expected no output for empty config, got %q", output)
+	}
+
+	if err := handleCommand([]string{"list", "--bogus"}); err == nil || !strings.Contains(err.Error(), "argument parsing failed") {
+		t.Errorf("expected unknown flag error, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// listTemplateFuncs are the helpers available to list --format templates
+var listTemplateFuncs = template.FuncMap{
+	// mask shows the first and last four characters of a secret, e.g. {{mask .APIKey}}
+	"mask": maskAPIKey,
+	// fingerprint is the short SHA-256 prefix cce list prints next to keys
+	"fingerprint": keyFingerprint,
+	// keyvar is the variable the key is exported as, e.g. ANTHROPIC_AUTH_TOKEN
+	"keyvar": resolveAPIKeyVar,
+	// model is the ANTHROPIC_MODEL a launch would set, following settings.default_model
+	"model": func(env Environment) string { return resolveModel(env, "").Model },
+	// provider is anthropic, bedrock or vertex
+	"provider": environmentProvider,
+	// json encodes any value, e.g. {{json .EnvVars}}
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parseListTemplate compiles a --format template and dry-runs it against an empty
+// environment, so unknown fields fail before any output instead of halfway through
+func parseListTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(listTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, Environment{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// displayEnvironmentsFormatted executes tmpl once per environment, in config order,
+// ending each result with a newline
+func displayEnvironmentsFormatted(w io.Writer, config Config, tmpl *template.Template) error {
+	var buf bytes.Buffer
+	for _, env := range config.Environments {
+		buf.Reset()
+		if err := tmpl.Execute(&buf, env); err != nil {
+			return fmt.Errorf("failed to format environment '%s': %w", env.Name, err)
+		}
+		buf.WriteByte('\n')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to display environment: %w", err)
+		}
+	}
+	return nil
+}
+
+// maskKeyTail hides all but the last four characters of a secret, for list --json
+func maskKeyTail(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
+
+// maskedEnvironment copies env with every key, and secret-looking variables, reduced
+// to their last four characters
+func maskedEnvironment(env Environment) Environment {
+	masked := cloneEnvironment(env)
+	masked.APIKey = maskKeyTail(env.APIKey)
+	masked.PreviousAPIKey = maskKeyTail(env.PreviousAPIKey)
+	for i, key := range masked.APIKeys {
+		masked.APIKeys[i] = maskKeyTail(key)
+	}
+	keyVar := resolveAPIKeyVar(env)
+	for name, value := range masked.EnvVars {
+		if isSecretVar(name, keyVar) {
+			masked.EnvVars[name] = maskKeyTail(value)
+		}
+	}
+	for name, value := range masked.Headers {
+		if isSecretHeader(name) {
+			masked.Headers[name] = maskKeyTail(value)
+		}
+	}
+	return masked
+}
+
+// displayEnvironmentsJSON writes the environments as a JSON array with keys masked
+func displayEnvironmentsJSON(w io.Writer, config Config) error {
+	envs := make([]Environment, 0, len(config.Environments))
+	for _, env := range config.Environments {
+		envs = append(envs, maskedEnvironment(env))
+	}
+	data, err := json.MarshalIndent(envs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode environments: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(data)); err != nil {
+		return fmt.Errorf("failed to display environments: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseListFormat(t *testing.T) {
+	opts, err := parseListOptions([]string{"--format", "{{.Name}}"})
+	if err != nil || opts.Format == nil {
+		t.Fatalf("parseListOptions(--format) = %+v, %v", opts, err)
+	}
+	if opts, err := parseListOptions([]string{"--format={{.URL}}"}); err != nil || opts.Format == nil {
+		t.Fatalf("parseListOptions(--format=) = %+v, %v", opts, err)
+	}
+
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--format"}, "requires a template"},
+		{[]string{"--format", "{{.Name"}, "invalid --format template"},
+		{[]string{"--format", "{{.Nope}}"}, "can't evaluate field Nope"},
+		{[]string{"--format", "{{shout .Name}}"}, "function \"shout\" not defined"},
+		{[]string{"--format", "{{.Name}}", "--names"}, "cannot be combined"},
+	}
+	for _, tt := range tests {
+		if _, err := parseListOptions(tt.args); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseListOptions(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDisplayEnvironmentsFormatted(t *testing.T) {
+	t.Cleanup(func() { configDefaultModel = "" })
+	configDefaultModel = "claude-3-5-sonnet-20241022"
+
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-abcdefgh1234", EnvVars: map[string]string{"TIMEOUT": "60"}},
+		{Name: "kimi", URL: "https://api.moonshot.cn/anthropic", APIKey: "sk-moonshot-key-5678", APIKeyEnv: "ANTHROPIC_AUTH_TOKEN", Model: "kimi-k2"},
+	}}
+	tmpl, err := parseListTemplate(`{{.Name}} {{mask .APIKey}} {{keyvar .}} {{model .}} {{provider .}} {{json .EnvVars}}`)
+	if err != nil {
+		t.Fatalf("parseListTemplate() failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := displayEnvironmentsFormatted(&buf, config, tmpl); err != nil {
+		t.Fatalf("displayEnvironmentsFormatted() failed: %v", err)
+	}
+	want := "prod sk-a*****************1234 ANTHROPIC_API_KEY claude-3-5-sonnet-20241022 anthropic {\"TIMEOUT\":\"60\"}\n" +
+		"kimi sk-m************5678 ANTHROPIC_AUTH_TOKEN kimi-k2 anthropic null\n"
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	if strings.Contains(buf.String(), "abcdefgh") {
+		t.Error("mask leaked the key")
+	}
+}
+
+func TestDisplayEnvironmentsJSON(t *testing.T) {
+	withTempConfigPath(t)
+	config := Config{Environments: []Environment{{
+		Name:      "prod",
+		URL:       "https://api.anthropic.com",
+		APIKey:    "sk-ant-api03-abcdefgh1234",
+		Model:     "claude-sonnet-4-20250514",
+		APIKeyEnv: "ANTHROPIC_AUTH_TOKEN",
+		EnvVars:   map[string]string{"ANTHROPIC_TIMEOUT": "60", "PROXY_TOKEN": "secret-5678"},
+	}}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"list", "--json"}); err != nil {
+			t.Fatalf("list --json failed: %v", err)
+		}
+	})
+	var envs []Environment
+	if err := json.Unmarshal([]byte(out), &envs); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, out)
+	}
+	if len(envs) != 1 {
+		t.Fatalf("expected 1 environment, got %d", len(envs))
+	}
+	env := envs[0]
+	if env.APIKey != "*********************1234" || strings.Contains(out, "abcdefgh") {
+		t.Errorf("expected the key masked to its last four characters, got %q", env.APIKey)
+	}
+	if env.Model != "claude-sonnet-4-20250514" || env.APIKeyEnv != "ANTHROPIC_AUTH_TOKEN" || env.EnvVars["ANTHROPIC_TIMEOUT"] != "60" {
+		t.Errorf("fields missing from JSON: %+v", env)
+	}
+	if env.EnvVars["PROXY_TOKEN"] != "*******5678" {
+		t.Errorf("expected secret variables masked, got %q", env.EnvVars["PROXY_TOKEN"])
+	}
+
+	if _, err := parseListOptions([]string{"--json", "--names"}); err == nil {
+		t.Error("expected --json with --names to be rejected")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLaunchClaudeCodeLoggedTeesOutput(t *testing.T) {
+	scriptDir := t.TempDir()
+	script := "#!/bin/sh\necho \"out $@\"\necho \"err line\" >&2\n"
+	if err := ioutil.WriteFile(filepath.Join(scriptDir, "claude"), []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write claude stub: %v", err)
+	}
+	t.Setenv("PATH", scriptDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	withTempConfigPath(t)
+
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-prod-key-123"}
+	logPath := filepath.Join(t.TempDir(), "claude.log")
+
+	stdout, _, err := captureStdoutAndStderr(t, func() error {
+		return launchClaudeCodeLogged(env, []string{"-p", "hi"}, "", logPath, false)
+	})
+	if err != nil {
+		t.Fatalf("launchClaudeCodeLogged() failed: %v", err)
+	}
+	if !strings.Contains(stdout, "out -p hi") {
+		t.Errorf("output should still reach the terminal, got %q", stdout)
+	}
+	data, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("log file not written: %v", err)
+	}
+	if !strings.Contains(string(data), "out -p hi") || !strings.Contains(string(data), "err line") {
+		t.Errorf("log should hold stdout and stderr, got %q", data)
+	}
+	if info, err := os.Stat(logPath); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("log file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	stdout, stderr, err := captureStdoutAndStderr(t, func() error {
+		return launchClaudeCodeLogged(env, []string{"-p", "again"}, "", logPath, true)
+	})
+	if err != nil || strings.Contains(stdout, "out") || strings.Contains(stderr, "err line") {
+		t.Errorf("--log-only should keep claude's output off the terminal: %q %q %v", stdout, stderr, err)
+	}
+	if data, _ := ioutil.ReadFile(logPath); !strings.Contains(string(data), "out -p again") {
+		t.Errorf("log file should be appended to, got %q", data)
+	}
+}
+
+func TestLogFileLaunchOptions(t *testing.T) {
+	withTempConfigPath(t)
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-prod-key-123"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	origLogged, origLauncher, origTerminal := loggedLauncher, claudeLauncher, stdinIsTerminal
+	defer func() { loggedLauncher, claudeLauncher, stdinIsTerminal = origLogged, origLauncher, origTerminal }()
+
+	var loggedPath string
+	var loggedOnly, plain bool
+	loggedLauncher = func(e Environment, args []string, workdir, logPath string, logOnly bool) error {
+		loggedPath, loggedOnly = logPath, logOnly
+		return nil
+	}
+	claudeLauncher = func(Environment, []string, string) error { plain = true; return nil }
+	stdinIsTerminal = func() bool { return true }
+
+	captureStdout(t, func() {
+		if err := handleCommand([]string{"--log-file=run.log", "--log-only", "-e", "prod", "-p", "hi"}); err != nil {
+			t.Fatalf("logged launch failed: %v", err)
+		}
+	})
+	if loggedPath != "run.log" || !loggedOnly || plain {
+		t.Errorf("expected the logged launcher, got path %q only %v plain %v", loggedPath, loggedOnly, plain)
+	}
+
+	// An interactive session keeps the terminal and is launched as usual
+	loggedPath = ""
+	_, stderr, err := captureStdoutAndStderr(t, func() error {
+		return handleCommand([]string{"--log-file", "run.log", "-e", "prod"})
+	})
+	if err != nil || loggedPath != "" || !plain || !strings.Contains(stderr, "ignored for interactive sessions") {
+		t.Errorf("interactive launch should warn and skip logging: %v %q %q", err, loggedPath, stderr)
+	}
+
+	if err := handleCommand([]string{"--log-only", "-e", "prod", "-p", "hi"}); err == nil || !strings.Contains(err.Error(), "requires --log-file") {
+		t.Errorf("expected --log-only without --log-file to fail, got %v", err)
+	}
+	if err := handleCommand([]string{"--log-file", "x.log", "--detach", "-e", "prod", "-p", "hi"}); err == nil || !strings.Contains(err.Error(), "argument parsing failed") {
+		t.Errorf("expected --log-file with --detach to fail, got %v", err)
+	}
+}
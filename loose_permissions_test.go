@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// resetLooseModeWarning lets each test see the once-per-run warning
+func resetLooseModeWarning(t *testing.T) {
+	t.Helper()
+	looseModeWarned = false
+	t.Cleanup(func() { looseModeWarned = false })
+}
+
+func TestLoadWarnsAboutLooseConfigPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX permissions are not checked on Windows")
+	}
+	path := withTempConfigPath(t)
+	resetLooseModeWarning(t)
+	if err := ioutil.WriteFile(path, []byte(`{"environments": []}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.Chmod(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to chmod config dir: %v", err)
+	}
+
+	stderr := captureStderr(t, func() {
+		if _, err := loadConfig(); err != nil {
+			t.Fatalf("loadConfig() should still succeed, got %v", err)
+		}
+		if _, err := loadConfig(); err != nil {
+			t.Fatalf("second loadConfig() failed: %v", err)
+		}
+	})
+	for _, want := range []string{path + " has permissions 0644", filepath.Dir(path) + " has permissions 0755", "cce doctor --fix"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("warning should mention %q, got %q", want, stderr)
+		}
+	}
+	if strings.Count(stderr, "cce doctor --fix") != 1 {
+		t.Errorf("warning should be printed once per run, got %q", stderr)
+	}
+}
+
+func TestLoadQuietForOwnerOnlyOrStricterPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX permissions are not checked on Windows")
+	}
+	path := withTempConfigPath(t)
+	resetLooseModeWarning(t)
+	if err := ioutil.WriteFile(path, []byte(`{"environments": []}`), 0400); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	stderr := captureStderr(t, func() {
+		if _, err := loadConfig(); err != nil {
+			t.Fatalf("loadConfig() failed: %v", err)
+		}
+	})
+	if strings.Contains(stderr, "permissions") {
+		t.Errorf("read-only owner access is not loose, got %q", stderr)
+	}
+}
+
+func TestDoctorFixTightensLoosePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX permissions are not checked on Windows")
+	}
+	path := withTempConfigPath(t)
+	if err := ioutil.WriteFile(path, []byte(`{"environments": []}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	finding := checkConfigPermissions()
+	if finding.OK || finding.Fix == nil {
+		t.Fatalf("expected a fixable finding, got %+v", finding)
+	}
+	if _, err := finding.Fix.apply(); err != nil {
+		t.Fatalf("fix failed: %v", err)
+	}
+	problems, err := configPermissionProblems(path)
+	if err != nil || len(problems) != 0 {
+		t.Errorf("expected owner-only permissions after the fix, got %v, %v", problems, err)
+	}
+}
@@ -1,45 +1,88 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/term"
 )
 
 // Version can be overridden by ldflags during build (e.g., -X main.Version=v1.0.0)
 var Version = "dev"
 
+// Build metadata injected via ldflags (e.g., -X main.BuildCommit=$(git rev-parse --short HEAD))
+var (
+	BuildCommit = "unknown"
+	BuildDate   = "unknown"
+)
+
+// builtinModelPatterns are the model patterns every validator starts with
+var builtinModelPatterns = []string{
+	// Current Anthropic model patterns
+	`^claude-3-5-sonnet-[0-9]{8}$`,
+	`^claude-3-haiku-[0-9]{8}$`,
+	`^claude-3-opus-[0-9]{8}$`,
+	`^claude-sonnet-[0-9]{8}$`,
+	`^claude-opus-[0-9]{8}$`,
+	`^claude-haiku-[0-9]{8}$`,
+	// Future-proofing patterns for anticipated naming conventions
+	`^claude-4-.*-[0-9]{8}$`,
+	`^claude-sonnet-4-[0-9]{8}$`,
+	`^claude-opus-4-[0-9]{8}$`,
+	`^claude-haiku-4-[0-9]{8}$`,
+	// Version-agnostic patterns with date validation
+	`^claude-(sonnet|opus|haiku)-[0-9]{8}$`,
+	`^claude-[0-9]+(-.+)?-[0-9]{8}$`,
+}
+
+// Where a model pattern came from, as shown by `cce models patterns`
+const (
+	patternSourceBuiltin = "builtin"
+	patternSourceEnv     = "env"    // CCE_MODEL_PATTERNS
+	patternSourceConfig  = "config" // settings.validation.model_patterns
+	patternSourceFile    = "file"   // CCE_MODEL_PATTERNS_FILE or settings.validation.model_patterns_file
+)
+
+// patternSource records where one pattern was loaded from; Detail is the file and line
+// for file patterns
+type patternSource struct {
+	Kind   string
+	Detail string
+}
+
 // modelValidator manages configurable model validation patterns
 type modelValidator struct {
 	patterns     []string
+	sources      []patternSource // Where each pattern came from, parallel to patterns
 	customConfig map[string][]string
 	strictMode   bool
+	strictSource string // What decided strictMode
 }
 
 // newModelValidator creates validator with built-in and custom patterns
 func newModelValidator() *modelValidator {
 	mv := &modelValidator{
-		patterns: []string{
-			// Current Anthropic model patterns
-			`^claude-3-5-sonnet-[0-9]{8}$`,
-			`^claude-3-haiku-[0-9]{8}$`,
-			`^claude-3-opus-[0-9]{8}$`,
-			`^claude-sonnet-[0-9]{8}$`,
-			`^claude-opus-[0-9]{8}$`,
-			`^claude-haiku-[0-9]{8}$`,
-			// Future-proofing patterns for anticipated naming conventions
-			`^claude-4-.*-[0-9]{8}$`,
-			`^claude-sonnet-4-[0-9]{8}$`,
-			`^claude-opus-4-[0-9]{8}$`,
-			`^claude-haiku-4-[0-9]{8}$`,
-			// Version-agnostic patterns with date validation
-			`^claude-(sonnet|opus|haiku)-[0-9]{8}$`,
-			`^claude-[0-9]+(-.+)?-[0-9]{8}$`,
-		},
 		customConfig: make(map[string][]string),
 		strictMode:   true,
+		strictSource: "default",
+	}
+	for _, pattern := range builtinModelPatterns {
+		mv.addPattern(pattern, patternSource{Kind: patternSourceBuiltin})
 	}
 
 	// Load custom patterns from environment variable
@@ -48,14 +91,20 @@ func newModelValidator() *modelValidator {
 		for _, pattern := range patterns {
 			pattern = strings.TrimSpace(pattern)
 			if pattern != "" {
-				mv.patterns = append(mv.patterns, pattern)
+				mv.addPattern(pattern, patternSource{Kind: patternSourceEnv, Detail: "CCE_MODEL_PATTERNS"})
 			}
 		}
 	}
 
+	// Load long pattern lists from a file, one regex per line
+	if patternsFile := os.Getenv("CCE_MODEL_PATTERNS_FILE"); patternsFile != "" {
+		mv.addPatternsFile(patternsFile)
+	}
+
 	// Check if strict mode is disabled
 	if os.Getenv("CCE_MODEL_STRICT") == "false" {
 		mv.strictMode = false
+		mv.strictSource = "CCE_MODEL_STRICT"
 	}
 
 	return mv
@@ -70,43 +119,152 @@ func newModelValidatorWithConfig(config Config) *modelValidator {
 		validation := config.Settings.Validation
 
 		// Add custom patterns from config
-		if len(validation.ModelPatterns) > 0 {
-			mv.patterns = append(mv.patterns, validation.ModelPatterns...)
+		for _, pattern := range validation.ModelPatterns {
+			mv.addPattern(pattern, patternSource{Kind: patternSourceConfig, Detail: "settings.validation.model_patterns"})
+		}
+		if validation.ModelPatternsFile != "" {
+			mv.addPatternsFile(resolveConfigRelativePath(validation.ModelPatternsFile))
 		}
 
 		// Override strict mode setting
 		mv.strictMode = validation.StrictValidation
+		mv.strictSource = "settings.validation.strict_validation"
 	}
 
 	return mv
 }
 
+// addPattern appends one pattern and records where it came from
+func (mv *modelValidator) addPattern(pattern string, source patternSource) {
+	mv.patterns = append(mv.patterns, pattern)
+	mv.sources = append(mv.sources, source)
+}
+
 // validatePattern checks if a pattern compiles correctly
 func (mv *modelValidator) validatePattern(pattern string) error {
 	_, err := regexp.Compile(pattern)
 	return err
 }
 
+// addPatternsFile appends the patterns in path. Blank lines and # comments are ignored;
+// an unreadable file or an invalid line is reported on stderr and skipped so a bad
+// allowlist never blocks startup.
+func (mv *modelValidator) addPatternsFile(path string) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring model patterns file: %v\n", err)
+		return
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		pattern := strings.TrimSpace(line)
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		if err := mv.validatePattern(pattern); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s:%d: skipping invalid model pattern: %v\n", path, i+1, err)
+			continue
+		}
+		mv.addPattern(pattern, patternSource{Kind: patternSourceFile, Detail: fmt.Sprintf("%s:%d", path, i+1)})
+	}
+}
+
+// resolveConfigRelativePath expands ~ and resolves relative paths against the config directory
+func resolveConfigRelativePath(path string) string {
+	expanded, err := expandSettingsDir(path)
+	if err != nil {
+		return path
+	}
+	if filepath.IsAbs(expanded) {
+		return expanded
+	}
+	configPath, err := getConfigPath()
+	if err != nil {
+		return expanded
+	}
+	return filepath.Join(filepath.Dir(configPath), expanded)
+}
+
 // Environment represents a single Claude Code API configuration
 type Environment struct {
-	Name      string            `json:"name"`
-	URL       string            `json:"url"`
-	APIKey    string            `json:"api_key"`
-	Model     string            `json:"model,omitempty"`
-	APIKeyEnv string            `json:"api_key_env,omitempty"`
-	EnvVars   map[string]string `json:"env_vars,omitempty"`
+	Name        string            `json:"name"`
+	URL         string            `json:"url"`
+	APIKey      string            `json:"api_key"`
+	Model       string            `json:"model,omitempty"`
+	APIKeyEnv   string            `json:"api_key_env,omitempty"`
+	AuthScheme  string            `json:"auth_scheme,omitempty"`
+	EnvVars     map[string]string `json:"env_vars,omitempty"`
+	Notes       string            `json:"notes,omitempty"`        // Free-text, informational only
+	SettingsDir string            `json:"settings_dir,omitempty"` // Isolated claude config dir (CLAUDE_CONFIG_DIR)
+	CACert      string            `json:"ca_cert,omitempty"`      // Extra CA bundle (PEM) trusted by claude and cce test
+	Unvalidated []string          `json:"unvalidated,omitempty"`  // Fields saved with --no-validate despite failing strict checks
+	// CheckTimeout (a duration such as "30s") and CheckRetries tune network checks for slow or flaky endpoints
+	CheckTimeout string `json:"check_timeout,omitempty"`
+	CheckRetries int    `json:"check_retries,omitempty"`
+	// Provider selects anthropic (default), bedrock or vertex; the cloud providers need
+	// Region (and Project for vertex) instead of a URL and key
+	Provider string `json:"provider,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Project  string `json:"project,omitempty"`
+	// PreviousAPIKey is the key replaced by the last `cce rotate`, kept for --rollback
+	PreviousAPIKey string `json:"previous_api_key,omitempty"`
+	KeyRotatedAt   string `json:"key_rotated_at,omitempty"` // RFC 3339 time of the last rotation
+	// MinClaudeVersion and MaxClaudeVersion pin the claude releases this endpoint works with
+	MinClaudeVersion string `json:"min_claude_version,omitempty"`
+	MaxClaudeVersion string `json:"max_claude_version,omitempty"`
+	// TrustArgs skips the shell metacharacter warning for this environment's launches;
+	// strict mode still rejects them
+	TrustArgs bool `json:"trust_args,omitempty"`
+	// APIKeys are further keys for the same endpoint; each launch uses one key from
+	// api_key plus api_keys, picked by KeyStrategy (round-robin or random)
+	APIKeys     []string `json:"api_keys,omitempty"`
+	KeyStrategy string   `json:"key_strategy,omitempty"`
+	// Tags group environments, e.g. for launching claude against all of them with --group
+	Tags []string `json:"tags,omitempty"`
+	// Headers are extra HTTP headers claude sends with every request (ANTHROPIC_CUSTOM_HEADERS)
+	Headers map[string]string `json:"headers,omitempty"`
+	// LastUsed is when cce last launched claude with this environment; it is recorded, not set by hand
+	LastUsed *time.Time `json:"last_used,omitempty"`
 }
 
 // Config represents the complete configuration with all environments
 type Config struct {
-	Environments []Environment   `json:"environments"`
-	Settings     *ConfigSettings `json:"settings,omitempty"`
+	Environments []Environment `json:"environments"`
+	// DefaultEnv is launched when no --env is given, instead of asking
+	DefaultEnv string          `json:"default_env,omitempty"`
+	Settings   *ConfigSettings `json:"settings,omitempty"`
 }
 
 // ConfigSettings holds optional configuration settings
 type ConfigSettings struct {
-	Terminal   *TerminalSettings   `json:"terminal,omitempty"`
-	Validation *ValidationSettings `json:"validation,omitempty"`
+	Terminal    *TerminalSettings   `json:"terminal,omitempty"`
+	Validation  *ValidationSettings `json:"validation,omitempty"`
+	KeyBindings *KeyBindingSettings `json:"key_bindings,omitempty"`
+	// CaseInsensitiveNames lets --env Prod match "prod"; exact matches still win
+	CaseInsensitiveNames bool `json:"case_insensitive_names,omitempty"`
+	// StrictArgs rejects claude arguments containing shell metacharacters instead of warning
+	StrictArgs bool `json:"strict_args,omitempty"`
+	// StrictFlags rejects unknown leading flags instead of forwarding them to claude
+	StrictFlags bool `json:"strict_flags,omitempty"`
+	// DefaultModel is injected for environments that set no model of their own
+	DefaultModel string           `json:"default_model,omitempty"`
+	Network      *NetworkSettings `json:"network,omitempty"`
+	// PreFlight runs one org-wide command before every launch (e.g. bring up a VPN)
+	PreFlight *PreFlightSettings `json:"preflight,omitempty"`
+	// AutoBackup backs up the stored config before every save; unset means enabled
+	AutoBackup *bool `json:"auto_backup,omitempty"`
+	// ClaudeVersionPolicy is block (default) or warn for a claude outside an environment's pinned range
+	ClaudeVersionPolicy string `json:"claude_version_policy,omitempty"`
+	// CheckModelAvailability warns at launch when the model is missing from the endpoint's cached model list
+	CheckModelAvailability bool `json:"check_model_availability,omitempty"`
+	// Worktree sets where --wk creates worktrees
+	Worktree *WorktreeSettings `json:"worktree,omitempty"`
+}
+
+// NetworkSettings holds defaults for network checks; environments may override them
+type NetworkSettings struct {
+	CheckTimeout string `json:"check_timeout,omitempty"` // Per-attempt timeout, e.g. "10s"
+	CheckRetries int    `json:"check_retries,omitempty"` // Extra attempts after a transient failure
 }
 
 // TerminalSettings configures terminal behavior
@@ -118,8 +276,13 @@ type TerminalSettings struct {
 
 // ValidationSettings configures model validation behavior
 type ValidationSettings struct {
-	ModelPatterns    []string `json:"model_patterns,omitempty"`
-	StrictValidation bool     `json:"strict_validation,omitempty"`
+	ModelPatterns []string `json:"model_patterns,omitempty"`
+	// ModelPatternsFile holds one regex per line; relative paths are resolved against the config directory
+	ModelPatternsFile string `json:"model_patterns_file,omitempty"`
+	StrictValidation  bool   `json:"strict_validation,omitempty"`
+	MinKeyLength      int    `json:"min_key_length,omitempty"` // Minimum API key length for new keys (default 10)
+	// KeyFormat (off, warn or strict) controls checking keys against their endpoint's key shape
+	KeyFormat string `json:"key_format,omitempty"`
 	// UnknownModelAction string   `json:"unknown_model_action,omitempty"`
 }
 
@@ -135,8 +298,11 @@ type ParseResult struct {
 	CCEFlags        map[string]string
 	ClaudeArgs      []string
 	Subcommand      string
+	SubcommandArgs  []string
 	Error           error
 	WorktreeEnabled bool
+	WorktreeFresh   bool
+	UnknownFlag     string // First leading flag cce did not recognize; forwarded unless strict
 }
 
 // CCECommand represents a parsed command with environment and claude arguments
@@ -218,21 +384,239 @@ func (ec *errorContext) formatError(baseErr error) error {
 
 // validateEnvironment performs comprehensive validation of environment data
 func validateEnvironment(env Environment) error {
+	return validateEnvironmentFields(env, true)
+}
+
+// validateStoredEnvironment validates an environment already on disk.
+// Keys shorter than the minimum are tolerated so existing configs keep working;
+// callers use shortAPIKeyWarning to tell the user about them.
+func validateStoredEnvironment(env Environment) error {
+	return validateEnvironmentFields(env, false)
+}
+
+// validateEnvironmentFields validates every field, optionally enforcing the API key minimum length
+func validateEnvironmentFields(env Environment, enforceKeyLength bool) error {
 	if err := validateName(env.Name); err != nil {
 		return fmt.Errorf("invalid name: %w", err)
 	}
-	if err := validateURL(env.URL); err != nil {
+	if err := validateProviderFields(env); err != nil {
+		return fmt.Errorf("invalid provider settings: %w", err)
+	}
+	// Bedrock and Vertex authenticate through cloud credentials; URL and key are optional there
+	cloud := isCloudProvider(env)
+	urlCheck := fieldValidator(env, fieldURL, validateURL)
+	if err := urlCheck(env.URL); err != nil && !(cloud && env.URL == "") {
 		return fmt.Errorf("invalid URL: %w", err)
 	}
-	if err := validateAPIKey(env.APIKey); err != nil {
+	keyCheck := validateAPIKey
+	if !enforceKeyLength {
+		keyCheck = validateAPIKeyFormat
+	}
+	keyCheck = fieldValidator(env, fieldAPIKey, keyCheck)
+	if err := keyCheck(env.APIKey); err != nil && !(cloud && env.APIKey == "") {
 		return fmt.Errorf("invalid API key: %w", err)
 	}
-	if err := validateModel(env.Model); err != nil {
+	if err := validateKeyPool(env, keyCheck); err != nil {
+		return err
+	}
+	// Only new keys are held to their endpoint's shape, so strict mode never breaks a stored config
+	if enforceKeyLength && keyFormatMode == keyFormatStrict {
+		if err := validateAPIKeyForProvider(env); err != nil {
+			return fmt.Errorf("invalid API key: %w", err)
+		}
+	}
+	if err := validateTags(env.Tags); err != nil {
+		return err
+	}
+	if err := validateHeaders(env.Headers); err != nil {
+		return fmt.Errorf("invalid headers: %w", err)
+	}
+	modelCheck := fieldValidator(env, fieldModel, validateModel)
+	if err := modelCheck(env.Model); err != nil {
 		return fmt.Errorf("invalid model: %w", err)
 	}
+	for _, field := range env.Unvalidated {
+		if relaxedValidators[field] == nil {
+			return fmt.Errorf("invalid unvalidated entry '%s' (expected url, api_key, or model)", field)
+		}
+	}
 	if err := validateAPIKeyEnv(env.APIKeyEnv); err != nil {
 		return fmt.Errorf("invalid api_key_env: %w", err)
 	}
+	if err := validateAuthScheme(env.AuthScheme); err != nil {
+		return fmt.Errorf("invalid auth_scheme: %w", err)
+	}
+	if env.AuthScheme != "" && env.APIKeyEnv != "" && authSchemeKeyVars[env.AuthScheme] != env.APIKeyEnv {
+		return fmt.Errorf("auth_scheme '%s' conflicts with api_key_env '%s' (expected %s)", env.AuthScheme, env.APIKeyEnv, authSchemeKeyVars[env.AuthScheme])
+	}
+	if err := validateNotes(env.Notes); err != nil {
+		return fmt.Errorf("invalid notes: %w", err)
+	}
+	if err := validateSettingsDir(env.SettingsDir); err != nil {
+		return fmt.Errorf("invalid settings_dir: %w", err)
+	}
+	if err := validateCACert(env.CACert); err != nil {
+		return fmt.Errorf("invalid ca_cert: %w", err)
+	}
+	if _, err := parseCheckTimeout(env.CheckTimeout); err != nil {
+		return fmt.Errorf("invalid check_timeout: %w", err)
+	}
+	if err := validateCheckRetries(env.CheckRetries); err != nil {
+		return fmt.Errorf("invalid check_retries: %w", err)
+	}
+	if err := validateClaudeVersionRange(env.MinClaudeVersion, env.MaxClaudeVersion); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateSettingsDir allows empty (use ~/.claude); otherwise requires an absolute or ~/ path
+func validateSettingsDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	for _, r := range dir {
+		if r < 32 || r == 127 {
+			return fmt.Errorf("path contains invalid characters")
+		}
+	}
+	if !filepath.IsAbs(dir) && dir != "~" && !strings.HasPrefix(dir, "~/") {
+		return fmt.Errorf("path must be absolute or start with ~/")
+	}
+	return nil
+}
+
+// expandSettingsDir resolves a leading ~ against the user's home directory
+func expandSettingsDir(dir string) (string, error) {
+	if dir != "~" && !strings.HasPrefix(dir, "~/") {
+		return filepath.Clean(dir), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(dir, "~")), nil
+}
+
+// Fields whose strict validation --no-validate can skip
+const (
+	fieldURL    = "url"
+	fieldAPIKey = "api_key"
+	fieldModel  = "model"
+)
+
+// relaxedValidators are the minimum checks kept for fields saved with --no-validate.
+// They still reject control characters so nothing can break env var injection.
+var relaxedValidators = map[string]func(string) error{
+	fieldURL: func(value string) error {
+		if value == "" {
+			return fmt.Errorf("URL cannot be empty")
+		}
+		if strings.IndexFunc(value, func(r rune) bool { return r <= ' ' || r == 127 }) >= 0 {
+			return fmt.Errorf("URL contains whitespace or control characters")
+		}
+		return nil
+	},
+	fieldAPIKey: validateAPIKeyFormat,
+	fieldModel: func(value string) error {
+		if err := checkModelRunes(value); err != nil {
+			return err
+		}
+		if len(value) > 200 {
+			return fmt.Errorf("model name too long")
+		}
+		return nil
+	},
+}
+
+// isUnvalidated reports whether field was saved with --no-validate
+func isUnvalidated(env Environment, field string) bool {
+	for _, f := range env.Unvalidated {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValidator returns the relaxed check for fields saved with --no-validate, else strict
+func fieldValidator(env Environment, field string, strict func(string) error) func(string) error {
+	if isUnvalidated(env, field) {
+		return relaxedValidators[field]
+	}
+	return strict
+}
+
+// checkFieldEscapable runs the strict validator for a field. When skip is set and only the
+// strict check fails, the value is accepted and the strict failure is returned as skipped.
+func checkFieldEscapable(field, value string, strict func(string) error, skip bool) (skipped error, err error) {
+	strictErr := strict(value)
+	if strictErr == nil {
+		return nil, nil
+	}
+	if !skip {
+		return nil, strictErr
+	}
+	if err := relaxedValidators[field](value); err != nil {
+		return nil, err
+	}
+	return strictErr, nil
+}
+
+// markUnvalidated records field as saved without strict validation, keeping the list sorted
+func markUnvalidated(env *Environment, field string) {
+	if isUnvalidated(*env, field) {
+		return
+	}
+	env.Unvalidated = append(env.Unvalidated, field)
+	sort.Strings(env.Unvalidated)
+}
+
+// clearUnvalidated removes field from the skipped list, e.g. after it is changed again
+func clearUnvalidated(env *Environment, field string) {
+	kept := env.Unvalidated[:0:0]
+	for _, f := range env.Unvalidated {
+		if f != field {
+			kept = append(kept, f)
+		}
+	}
+	env.Unvalidated = kept
+	if len(env.Unvalidated) == 0 {
+		env.Unvalidated = nil
+	}
+}
+
+// unvalidatedWarning describes skipped validation for an environment, or "" if none
+func unvalidatedWarning(env Environment) string {
+	if len(env.Unvalidated) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Warning: environment '%s' was saved with --no-validate; unchecked fields: %s", env.Name, strings.Join(env.Unvalidated, ", "))
+}
+
+// maxNotesLength bounds the free-text notes attached to an environment
+const maxNotesLength = 200
+
+// validateNotes allows empty notes; otherwise enforces length and rejects control characters
+func validateNotes(notes string) error {
+	if len([]rune(notes)) > maxNotesLength {
+		return fmt.Errorf("notes too long (maximum %d characters)", maxNotesLength)
+	}
+	for _, r := range notes {
+		if r < 32 || r == 127 {
+			return fmt.Errorf("notes contain invalid characters")
+		}
+	}
+	return nil
+}
+
+// validateEnvVarValue rejects control characters, which would corrupt the launched environment
+func validateEnvVarValue(value string) error {
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("value contains control characters")
+		}
+	}
 	return nil
 }
 
@@ -284,12 +668,20 @@ func validateURL(urlStr string) error {
 
 // validateAPIKey performs basic API key format validation
 func validateAPIKey(apiKey string) error {
+	if err := validateAPIKeyFormat(apiKey); err != nil {
+		return err
+	}
+	if len(apiKey) < minAPIKeyLength {
+		return fmt.Errorf("%w (minimum %d characters)", errAPIKeyTooShort, minAPIKeyLength)
+	}
+	return nil
+}
+
+// validateAPIKeyFormat checks an API key for emptiness and control characters, ignoring length
+func validateAPIKeyFormat(apiKey string) error {
 	if apiKey == "" {
 		return fmt.Errorf("API key cannot be empty")
 	}
-	if len(apiKey) < 1 {
-		return fmt.Errorf("API key too short (minimum 1 characters)")
-	}
 	// Reject control characters
 	for _, r := range apiKey {
 		if r < 32 || r == 127 {
@@ -299,6 +691,48 @@ func validateAPIKey(apiKey string) error {
 	return nil
 }
 
+// defaultMinKeyLength is the API key minimum used when settings don't override it
+const defaultMinKeyLength = 10
+
+// errAPIKeyTooShort marks keys rejected only because of the minimum length
+var errAPIKeyTooShort = errors.New("API key too short")
+
+// minAPIKeyLength is the active minimum, taken from settings.validation.min_key_length on load
+var minAPIKeyLength = defaultMinKeyLength
+
+// configDefaultModel is the active settings.default_model, set when the config is loaded
+var configDefaultModel string
+
+// applyModelSettings validates and activates settings.default_model and check_model_availability
+func applyModelSettings(settings *ConfigSettings) error {
+	configDefaultModel = ""
+	modelAvailabilityCheck = settings != nil && settings.CheckModelAvailability
+	if settings == nil || settings.DefaultModel == "" {
+		return nil
+	}
+	if err := validateModel(settings.DefaultModel); err != nil {
+		return err
+	}
+	configDefaultModel = settings.DefaultModel
+	return nil
+}
+
+// applyValidationSettings activates the configured API key minimum (or the default)
+func applyValidationSettings(settings *ConfigSettings) {
+	minAPIKeyLength = defaultMinKeyLength
+	if settings != nil && settings.Validation != nil && settings.Validation.MinKeyLength > 0 {
+		minAPIKeyLength = settings.Validation.MinKeyLength
+	}
+}
+
+// shortAPIKeyWarning describes a stored key below the active minimum, or returns "" if it is long enough
+func shortAPIKeyWarning(env Environment) string {
+	if len(env.APIKey) >= minAPIKeyLength {
+		return ""
+	}
+	return fmt.Sprintf("Warning: environment '%s' has an API key shorter than %d characters; re-add it with a full key or set settings.validation.min_key_length", env.Name, minAPIKeyLength)
+}
+
 // validateModel allows any model name (no validation)
 func validateModel(model string) error {
 	if model == "" {
@@ -308,11 +742,14 @@ func validateModel(model string) error {
 	if strings.Contains(model, "$(") || strings.Contains(model, "`") || strings.Contains(model, ";") || strings.Contains(model, "../") {
 		return fmt.Errorf("model contains disallowed characters")
 	}
-	// Reject control characters
-	for _, r := range model {
-		if r < 32 || r == 127 {
-			return fmt.Errorf("model contains invalid characters")
-		}
+	// Reject control characters, including invisible and line-separator look-alikes
+	if err := checkModelRunes(model); err != nil {
+		return err
+	}
+	// Reject percent-encoded or backslash-escaped control sequences (e.g. %0a, \n) that a
+	// later decoding step could turn back into a line break
+	if err := checkModelEncodings(model); err != nil {
+		return err
 	}
 	// Reasonable length limit
 	if len(model) > 200 {
@@ -321,6 +758,45 @@ func validateModel(model string) error {
 	return nil
 }
 
+// checkModelRunes rejects invalid UTF-8, ASCII/C1 control characters, and Unicode format
+// and separator characters such as zero-width spaces, bidi overrides, and U+2028
+func checkModelRunes(model string) error {
+	if !utf8.ValidString(model) {
+		return fmt.Errorf("model contains invalid characters")
+	}
+	for _, r := range model {
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) || unicode.In(r, unicode.Zl, unicode.Zp) {
+			return fmt.Errorf("model contains invalid characters")
+		}
+	}
+	return nil
+}
+
+// maxModelDecodeRounds bounds repeated percent-decoding so %250a and deeper nesting are caught
+const maxModelDecodeRounds = 3
+
+// checkModelEncodings decodes percent-encoding (repeatedly, for double-encoded input) and
+// rejects the model if any decoded form carries a control character. Backslash escapes
+// are never part of a model ID and are rejected outright.
+func checkModelEncodings(model string) error {
+	if strings.Contains(model, "\\") {
+		return fmt.Errorf("model contains disallowed characters")
+	}
+
+	decoded := model
+	for round := 0; round < maxModelDecodeRounds && strings.Contains(decoded, "%"); round++ {
+		next, err := url.PathUnescape(decoded)
+		if err != nil || next == decoded {
+			break
+		}
+		if err := checkModelRunes(next); err != nil {
+			return fmt.Errorf("model contains encoded control characters")
+		}
+		decoded = next
+	}
+	return nil
+}
+
 // validateAPIKeyEnv ensures api_key_env is empty (default) or one of supported names
 func validateAPIKeyEnv(name string) error {
 	if name == "" {
@@ -334,22 +810,105 @@ func validateAPIKeyEnv(name string) error {
 	}
 }
 
+// Auth schemes describe how claude sends the key to the endpoint
+const (
+	authSchemeAPIKey = "x-api-key" // x-api-key header, injected as ANTHROPIC_API_KEY
+	authSchemeBearer = "bearer"    // Authorization: Bearer header, injected as ANTHROPIC_AUTH_TOKEN
+)
+
+// authSchemeKeyVars maps each auth scheme to the env var claude reads it from
+var authSchemeKeyVars = map[string]string{
+	authSchemeAPIKey: "ANTHROPIC_API_KEY",
+	authSchemeBearer: "ANTHROPIC_AUTH_TOKEN",
+}
+
+// validateAuthScheme ensures auth_scheme is empty (default) or a known scheme
+func validateAuthScheme(scheme string) error {
+	if scheme == "" {
+		return nil
+	}
+	if _, ok := authSchemeKeyVars[scheme]; !ok {
+		return fmt.Errorf("must be '%s' or '%s'", authSchemeAPIKey, authSchemeBearer)
+	}
+	return nil
+}
+
+// authSchemeForKeyVar returns the scheme that corresponds to an API key env var name
+func authSchemeForKeyVar(keyVar string) string {
+	if keyVar == "ANTHROPIC_AUTH_TOKEN" {
+		return authSchemeBearer
+	}
+	return authSchemeAPIKey
+}
+
+// resolveAPIKeyVar returns the env var that carries the key: api_key_env, then auth_scheme, then ANTHROPIC_API_KEY
+func resolveAPIKeyVar(env Environment) string {
+	if env.APIKeyEnv != "" {
+		return env.APIKeyEnv
+	}
+	if keyVar, ok := authSchemeKeyVars[env.AuthScheme]; ok {
+		return keyVar
+	}
+	return "ANTHROPIC_API_KEY"
+}
+
+// keyPrefixVars maps Anthropic key prefixes to the variable that style of key is read
+// from. Keys with any other prefix (proxies, gateways) are not judged.
+var keyPrefixVars = []struct{ prefix, keyVar string }{
+	{"sk-ant-api", "ANTHROPIC_API_KEY"},    // Console API keys, sent as x-api-key
+	{"sk-ant-oat", "ANTHROPIC_AUTH_TOKEN"}, // OAuth access tokens, sent as a bearer token
+}
+
+// expectedKeyVar returns the variable a key's prefix suggests, or "" when it suggests none
+func expectedKeyVar(key string) string {
+	for _, p := range keyPrefixVars {
+		if strings.HasPrefix(key, p.prefix) {
+			return p.keyVar
+		}
+	}
+	return ""
+}
+
+// keyStyleWarning describes a key whose prefix suggests it belongs under a different
+// variable than the environment sends it as, or returns "". It is only a hint: proxies
+// accept all sorts of keys, so a mismatch never blocks anything.
+func keyStyleWarning(env Environment) string {
+	if isCloudProvider(env) {
+		return ""
+	}
+	keyVar := resolveAPIKeyVar(env)
+	for _, key := range environmentKeys(env) {
+		if want := expectedKeyVar(key); want != "" && want != keyVar {
+			return fmt.Sprintf("Warning: environment '%s' sends key %s as %s, but its prefix suggests %s; if authentication fails, run 'cce set %s key_var=%s'", env.Name, keyFingerprint(key), keyVar, want, env.Name, want)
+		}
+	}
+	return ""
+}
+
+// matchModel returns the index of the first pattern model matches, or -1; patterns that
+// do not compile never match
+func (mv *modelValidator) matchModel(model string) int {
+	for i, pattern := range mv.patterns {
+		if matched, err := regexp.MatchString(pattern, model); err == nil && matched {
+			return i
+		}
+	}
+	return -1
+}
+
 // validateModelAdaptive performs adaptive model validation with graceful degradation
 func (mv *modelValidator) validateModelAdaptive(model string) error {
 	if model == "" {
 		return nil // Optional field
 	}
 
-	// Try each pattern for validation
-	for _, pattern := range mv.patterns {
-		if matched, err := regexp.MatchString(pattern, model); err == nil && matched {
-			return nil // Valid model found
-		}
+	if mv.matchModel(model) >= 0 {
+		return nil // Valid model found
 	}
 
 	// Model doesn't match known patterns
 	if mv.strictMode {
-		return fmt.Errorf("invalid model format. Examples: claude-3-5-sonnet-20241022, claude-3-haiku-20240307, claude-3-opus-20240229")
+		return modelFormatError(model)
 	}
 
 	// Permissive mode: log warning and continue
@@ -373,27 +932,179 @@ func parseArguments(args []string) ParseResult {
 		return result
 	}
 
+	// Phase 0: Consume global flags that may precede any command
+globals:
+	for len(args) > 0 {
+		switch args[0] {
+		case "--no-color":
+			result.CCEFlags["no_color"] = "true"
+		case "--yes", "-y":
+			result.CCEFlags["yes"] = "true"
+		case "--no-prompt":
+			result.CCEFlags["no_prompt"] = "true"
+		case "--chdir", "--config":
+			if len(args) < 2 {
+				result.Error = fmt.Errorf("flag %s requires a value", args[0])
+				return result
+			}
+			result.CCEFlags[strings.TrimPrefix(args[0], "--")] = args[1]
+			args = args[1:]
+		default:
+			if strings.HasPrefix(args[0], "--chdir=") {
+				result.CCEFlags["chdir"] = strings.TrimPrefix(args[0], "--chdir=")
+				break
+			}
+			if strings.HasPrefix(args[0], "--config=") {
+				result.CCEFlags["config"] = strings.TrimPrefix(args[0], "--config=")
+				break
+			}
+			break globals
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return result
+	}
+
 	// Phase 1: Check for subcommands first
 	switch args[0] {
 	case "list":
 		result.Subcommand = "list"
+		result.SubcommandArgs = args[1:]
 		return result
 	case "add":
 		result.Subcommand = "add"
+		result.SubcommandArgs = args[1:]
 		return result
 	case "remove":
-		if len(args) < 2 {
+		flags := make(map[string]string)
+		for _, arg := range args[1:] {
+			switch {
+			case arg == "--all":
+				flags["remove_all"] = "true"
+			case arg == "--yes" || arg == "-y":
+				flags["yes"] = "true"
+			case strings.HasPrefix(arg, "-"):
+				result.Error = fmt.Errorf("unknown remove flag: %s", arg)
+				return result
+			case flags["remove_target"] != "":
+				result.Error = fmt.Errorf("remove command takes a single environment name")
+				return result
+			default:
+				flags["remove_target"] = arg
+			}
+		}
+		if flags["remove_all"] != "" && flags["remove_target"] != "" {
+			result.Error = fmt.Errorf("remove --all does not take an environment name")
+			return result
+		}
+		if flags["remove_all"] == "" && flags["remove_target"] == "" {
 			result.Error = fmt.Errorf("remove command requires environment name")
 			return result
 		}
 		result.Subcommand = "remove"
-		result.CCEFlags["remove_target"] = args[1]
+		for key, value := range flags {
+			result.CCEFlags[key] = value
+		}
+		return result
+	case "config":
+		result.Subcommand = "config"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "set":
+		result.Subcommand = "set"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "copy":
+		result.Subcommand = "copy"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "rename":
+		result.Subcommand = "rename"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "default":
+		result.Subcommand = "default"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "secrets":
+		result.Subcommand = "secrets"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "completion", "__complete":
+		result.Subcommand = args[0]
+		result.SubcommandArgs = args[1:]
+		return result
+	case "init":
+		result.Subcommand = "init"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "select":
+		result.Subcommand = "select"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "plan":
+		result.Subcommand = "plan"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "models":
+		result.Subcommand = "models"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "export":
+		result.Subcommand = "export"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "shell", "use":
+		result.Subcommand = "shell"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "test":
+		result.Subcommand = "test"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "ping":
+		result.Subcommand = "ping"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "doctor":
+		result.Subcommand = "doctor"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "rotate":
+		result.Subcommand = "rotate"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "verify":
+		result.Subcommand = "verify"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "current":
+		result.Subcommand = "current"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "import":
+		result.Subcommand = "import"
+		result.SubcommandArgs = args[1:]
+		return result
+	case "serve":
+		result.Subcommand = "serve"
+		result.SubcommandArgs = args[1:]
 		return result
 	case "help", "--help", "-h":
 		result.Subcommand = "help"
+		result.SubcommandArgs = args[1:]
 		return result
 	case "version", "--version", "-V":
 		result.Subcommand = "version"
+		result.SubcommandArgs = args[1:]
+		return result
+	}
+
+	// Attached values (--env=prod, -eprod) become separate arguments before scanning
+	args, err := splitFlagValues(args)
+	if err != nil {
+		result.Error = err
 		return result
 	}
 
@@ -438,21 +1149,196 @@ func parseArguments(args []string) ParseResult {
 			continue
 		}
 
-		if arg == "--yolo" {
-			// Transform --yolo to --dangerously-skip-permissions for Claude
-			// We don't store this in CCEFlags since it's not a CCE-specific flag
-			// Instead, we'll handle the transformation during Phase 2
-			i++ // Skip this argument, will be transformed later
+		// One-run override for the model, over the environment's own
+		if arg == "--model" || arg == "-m" {
+			if i+1 >= len(args) {
+				result.Error = fmt.Errorf("flag %s requires a value", arg)
+				return result
+			}
+			result.CCEFlags["model"] = args[i+1]
+			i += 2
 			continue
 		}
 
-		if arg == "--wk" {
-			result.WorktreeEnabled = true
-			i++
-			continue
+		// Copy claude's output to a file (--log-only: only to the file)
+		if arg == "--log-file" {
+			if i+1 >= len(args) {
+				result.Error = fmt.Errorf("flag %s requires a value", arg)
+				return result
+			}
+			result.CCEFlags["log_file"] = args[i+1]
+			i += 2
+			continue
+		}
+
+		if arg == "--log-only" {
+			result.CCEFlags["log_only"] = "true"
+			i++
+			continue
+		}
+
+		// Run claude once per environment carrying this tag
+		if arg == "--group" {
+			if i+1 >= len(args) {
+				result.Error = fmt.Errorf("flag %s requires a value", arg)
+				return result
+			}
+			result.CCEFlags["group"] = args[i+1]
+			i += 2
+			continue
+		}
+
+		// Directory git, worktree and project file lookups act from
+		if arg == "--chdir" {
+			if i+1 >= len(args) {
+				result.Error = fmt.Errorf("flag %s requires a value", arg)
+				return result
+			}
+			result.CCEFlags["chdir"] = args[i+1]
+			i += 2
+			continue
+		}
+
+		// Config file to use instead of ~/.claude-code-env/config.json
+		if arg == "--config" {
+			if i+1 >= len(args) {
+				result.Error = fmt.Errorf("flag %s requires a value", arg)
+				return result
+			}
+			result.CCEFlags["config"] = args[i+1]
+			i += 2
+			continue
+		}
+
+		// Extra variables loaded from a dotenv file at launch
+		if arg == "--env-file" {
+			if i+1 >= len(args) {
+				result.Error = fmt.Errorf("flag %s requires a value", arg)
+				return result
+			}
+			result.CCEFlags["env_file"] = args[i+1]
+			i += 2
+			continue
+		}
+
+		if arg == "--yolo" {
+			// Transform --yolo to --dangerously-skip-permissions for Claude
+			// We don't store this in CCEFlags since it's not a CCE-specific flag
+			// Instead, we'll handle the transformation during Phase 2
+			i++ // Skip this argument, will be transformed later
+			continue
+		}
+
+		if arg == "--wk" {
+			result.WorktreeEnabled = true
+			i++
+			continue
+		}
+
+		if arg == "--strict-args" {
+			result.CCEFlags["strict_args"] = "true"
+			i++
+			continue
+		}
+
+		if arg == "--no-prompt" {
+			result.CCEFlags["no_prompt"] = "true"
+			i++
+			continue
+		}
+
+		if arg == "--strict-flags" {
+			result.CCEFlags["strict_flags"] = "true"
+			i++
+			continue
+		}
+
+		if arg == "--print-env-diff" {
+			result.CCEFlags["print_env_diff"] = "true"
+			i++
+			continue
+		}
+
+		if arg == "--skip-preflight" {
+			result.CCEFlags["skip_preflight"] = "true"
+			i++
+			continue
+		}
+
+		// Print the launch plan instead of launching
+		if arg == "--dry-run" {
+			result.CCEFlags["dry_run"] = "true"
+			i++
+			continue
+		}
+
+		// Write the environment into a conflicting ~/.claude/settings.json before launching
+		if arg == "--override-settings" {
+			result.CCEFlags["override_settings"] = "true"
+			i++
+			continue
+		}
+
+		// Relaunch claude whenever it exits, at most --watch-max times
+		if arg == "--watch" {
+			result.CCEFlags["watch"] = "true"
+			i++
+			continue
+		}
+
+		if arg == "--watch-max" {
+			if i+1 >= len(args) {
+				result.Error = fmt.Errorf("flag %s requires a value", arg)
+				return result
+			}
+			result.CCEFlags["watch_max"] = args[i+1]
+			i += 2
+			continue
+		}
+
+		// Launch mode: --detach starts claude in the background, --wait is the explicit default
+		if arg == "--detach" || arg == "--wait" {
+			result.CCEFlags[arg[2:]] = "true"
+			i++
+			continue
+		}
+
+		if arg == "--wk-fresh" {
+			result.WorktreeEnabled = true
+			result.WorktreeFresh = true
+			i++
+			continue
+		}
+
+		// Where the worktree goes and what it is called; either implies --wk
+		if arg == "--wk-path" || arg == "--wk-name" {
+			if i+1 >= len(args) {
+				result.Error = fmt.Errorf("flag %s requires a value", arg)
+				return result
+			}
+			result.WorktreeEnabled = true
+			result.CCEFlags[strings.ReplaceAll(strings.TrimPrefix(arg, "--"), "-", "_")] = args[i+1]
+			i += 2
+			continue
+		}
+
+		// Remove the worktree once claude exits; --force also removes one with changes
+		if arg == "--wk-cleanup" {
+			result.WorktreeEnabled = true
+			result.CCEFlags["wk_cleanup"] = "true"
+			i++
+			continue
+		}
+		if arg == "--force" {
+			result.CCEFlags["force"] = "true"
+			i++
+			continue
 		}
 
 		// If we encounter an unknown flag or argument, stop CCE processing
+		if len(arg) > 1 && strings.HasPrefix(arg, "-") {
+			result.UnknownFlag = arg
+		}
 		break
 	}
 
@@ -480,14 +1366,27 @@ func parseArguments(args []string) ParseResult {
 				j++ // Skip the flag value too
 				continue
 			}
-			if (arg == "--key-var" || arg == "-k") && j+1 < len(args) {
+			if (arg == "--key-var" || arg == "-k" || arg == "--env-file" || arg == "--chdir" || arg == "--group" || arg == "--log-file" || arg == "--wk-path" || arg == "--wk-name") && j+1 < len(args) {
 				j++ // Skip the flag value too
 				continue
 			}
+			// --model is also claude's own flag, so only the one CCE consumed is skipped
+			if (arg == "--model" || arg == "-m") && j+1 < i {
+				j++
+				continue
+			}
 			if arg == "--help" || arg == "-h" {
 				continue
 			}
-			if arg == "--wk" {
+			// Past index i the arguments are claude's, and these names may be its flags too
+			if j < i && (arg == "--config" || arg == "--watch-max") {
+				j++
+				continue
+			}
+			if j < i && (arg == "--wk-cleanup" || arg == "--force" || arg == "--watch" || arg == "--detach" || arg == "--wait" || arg == "--dry-run") {
+				continue
+			}
+			if arg == "--wk" || arg == "--wk-fresh" || arg == "--strict-args" || arg == "--strict-flags" || arg == "--no-prompt" || arg == "--log-only" || arg == "--print-env-diff" || arg == "--skip-preflight" || arg == "--override-settings" {
 				continue
 			}
 
@@ -499,7 +1398,7 @@ func parseArguments(args []string) ParseResult {
 				isCCEFlag := false
 				if j > 0 {
 					prevArg := args[j-1]
-					if prevArg == "--env" || prevArg == "-e" || prevArg == "--key-var" || prevArg == "-k" {
+					if prevArg == "--env" || prevArg == "-e" || prevArg == "--key-var" || prevArg == "-k" || prevArg == "--env-file" || prevArg == "--chdir" || prevArg == "--group" || prevArg == "--log-file" || prevArg == "--wk-path" || prevArg == "--wk-name" {
 						isCCEFlag = true
 					}
 				}
@@ -516,16 +1415,36 @@ func parseArguments(args []string) ParseResult {
 
 // validatePassthroughArgs performs security validation on claude arguments
 func validatePassthroughArgs(args []string) error {
+	return validatePassthroughArgsMode(args, false)
+}
+
+// validatePassthroughArgsMode is validatePassthroughArgs; in strict mode shell
+// metacharacters are rejected instead of only warned about
+func validatePassthroughArgsMode(args []string, strict bool) error {
 	for _, arg := range args {
 		// Check for potential command injection patterns
-		if strings.Contains(arg, ";") || strings.Contains(arg, "&") ||
-			strings.Contains(arg, "|") || strings.Contains(arg, "`") ||
-			strings.Contains(arg, "$(") {
+		if hasShellMetachars(arg) {
+			if strict {
+				return fmt.Errorf("argument contains shell metacharacters (strict mode): %s", arg)
+			}
 			// Allow these in quoted strings, but warn about potential risks
 			fmt.Fprintf(os.Stderr, "Warning: Argument contains shell metacharacters: %s\n", arg)
 		}
+	}
+	return rejectDangerousArgs(args)
+}
+
+// hasShellMetachars reports whether arg contains characters a shell would interpret
+func hasShellMetachars(arg string) bool {
+	return strings.Contains(arg, ";") || strings.Contains(arg, "&") ||
+		strings.Contains(arg, "|") || strings.Contains(arg, "`") ||
+		strings.Contains(arg, "$(")
+}
 
-		// Block obvious command injection attempts
+// rejectDangerousArgs blocks obvious command injection attempts; unlike the
+// metacharacter check it applies to trusted environments too
+func rejectDangerousArgs(args []string) error {
+	for _, arg := range args {
 		if strings.Contains(arg, "rm -rf") || strings.Contains(arg, "sudo") ||
 			strings.Contains(arg, "/etc/passwd") || strings.Contains(arg, "../") {
 			return fmt.Errorf("potentially dangerous argument rejected: %s", arg)
@@ -534,6 +1453,16 @@ func validatePassthroughArgs(args []string) error {
 	return nil
 }
 
+// validateArgsForEnvironment checks claude arguments once the environment is known.
+// An environment with trust_args skips the metacharacter check, unless strict mode
+// (--strict-args, CCE_STRICT_ARGS or settings.strict_args) forces it.
+func validateArgsForEnvironment(args []string, env Environment, strict bool) error {
+	if env.TrustArgs && !strict {
+		return rejectDangerousArgs(args)
+	}
+	return validatePassthroughArgsMode(args, strict)
+}
+
 func main() {
 	if err := handleCommand(os.Args[1:]); err != nil {
 		// Enhanced error categorization with clear messaging
@@ -543,9 +1472,15 @@ func main() {
 		case "cce_argument":
 			fmt.Fprintf(os.Stderr, "CCE Argument Error: %v\n", err)
 			fmt.Fprintf(os.Stderr, "Use 'cce help' for usage information.\n")
+		case "network":
+			fmt.Fprintf(os.Stderr, "Network Error: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Check the endpoint URL, API key, and network connectivity.\n")
 		case "cce_config":
 			fmt.Fprintf(os.Stderr, "CCE Configuration Error: %v\n", err)
 			fmt.Fprintf(os.Stderr, "Check your environment configuration with 'cce list'.\n")
+		case "claude_version":
+			fmt.Fprintf(os.Stderr, "Claude Code Version Error: %v\n", err)
+			fmt.Fprintf(os.Stderr, "The selected environment pins the claude versions it supports.\n")
 		case "claude_execution":
 			fmt.Fprintf(os.Stderr, "Claude Code Error: %v\n", err)
 			fmt.Fprintf(os.Stderr, "This error originated from the claude command.\n")
@@ -559,23 +1494,41 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		}
 
-		// Enhanced error categorization with exit codes
-		switch {
-		case strings.Contains(err.Error(), "terminal"):
-			os.Exit(4) // Terminal compatibility error
-		case strings.Contains(err.Error(), "permission"):
-			os.Exit(5) // Permission/access error
-		case strings.Contains(err.Error(), "configuration"):
-			os.Exit(2) // Configuration error (existing)
-		case strings.Contains(err.Error(), "claude"):
-			os.Exit(3) // Claude Code launcher error (existing)
-		case strings.Contains(err.Error(), "argument parsing"):
-			os.Exit(6) // CCE argument parsing error
-		case strings.Contains(err.Error(), "argument validation"):
-			os.Exit(7) // CCE argument validation error
-		default:
-			os.Exit(1) // General application error
-		}
+		os.Exit(exitCodeFor(err))
+	}
+}
+
+// exitCodeFor maps an error to the process exit code scripts rely on
+func exitCodeFor(err error) int {
+	var groupErr *groupLaunchError
+	if errors.As(err, &groupErr) {
+		return groupErr.code
+	}
+	var watchErr *watchExitError
+	if errors.As(err, &watchErr) {
+		return watchErr.code
+	}
+	var exitErr *claudeExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	switch {
+	case strings.Contains(err.Error(), "terminal"):
+		return 4 // Terminal compatibility error
+	case strings.Contains(err.Error(), "permission"):
+		return 5 // Permission/access error
+	case strings.Contains(err.Error(), "network check"):
+		return 8 // Network connectivity/auth error
+	case strings.Contains(err.Error(), "configuration"):
+		return 2 // Configuration error (existing)
+	case strings.Contains(err.Error(), "claude"):
+		return 3 // Claude Code launcher error (existing)
+	case strings.Contains(err.Error(), "argument parsing"):
+		return 6 // CCE argument parsing error
+	case strings.Contains(err.Error(), "argument validation"):
+		return 7 // CCE argument validation error
+	default:
+		return 1 // General application error
 	}
 }
 
@@ -583,6 +1536,16 @@ func main() {
 func categorizeError(err error) string {
 	errStr := err.Error()
 
+	// A refused config path is an access problem even though it surfaces while loading config
+	if errors.Is(err, errUnsafeConfigPath) {
+		return "permission"
+	}
+
+	// An installed claude outside an environment's pinned range
+	if strings.Contains(errStr, "claude version check") {
+		return "claude_version"
+	}
+
 	// CCE argument-related errors
 	if strings.Contains(errStr, "argument parsing") ||
 		strings.Contains(errStr, "argument validation") ||
@@ -590,6 +1553,11 @@ func categorizeError(err error) string {
 		return "cce_argument"
 	}
 
+	// Network connectivity and auth errors
+	if strings.Contains(errStr, "network check") {
+		return "network"
+	}
+
 	// CCE configuration errors
 	if strings.Contains(errStr, "configuration") ||
 		strings.Contains(errStr, "environment") && !strings.Contains(errStr, "claude") {
@@ -627,86 +1595,276 @@ func handleCommand(args []string) error {
 		return fmt.Errorf("argument parsing failed: %w", parseResult.Error)
 	}
 
+	if parseResult.CCEFlags["no_color"] == "true" {
+		colorDisabled = true
+	}
+	assumeYes = parseResult.CCEFlags["yes"] == "true" || assumeYesFromEnv()
+	noPrompt = parseResult.CCEFlags["no_prompt"] == "true" || noPromptFromEnv()
+	dir, err := resolveChdir(parseResult.CCEFlags["chdir"])
+	if err != nil {
+		return err
+	}
+	workDir = dir
+	configArg := parseResult.CCEFlags["config"]
+	if configArg == "" {
+		configArg = os.Getenv("CCE_CONFIG_PATH")
+	}
+	if configPathFlag, err = resolveConfigPath(configArg); err != nil {
+		return err
+	}
+
 	// Handle subcommands
 	switch parseResult.Subcommand {
 	case "list":
-		return runList()
+		opts, err := parseListOptions(parseResult.SubcommandArgs)
+		if err != nil {
+			return fmt.Errorf("argument parsing failed: %w", err)
+		}
+		return runListWithOptions(opts)
 	case "add":
-		return runAdd()
+		opts, err := parseAddOptions(parseResult.SubcommandArgs)
+		if err != nil {
+			return fmt.Errorf("argument parsing failed: %w", err)
+		}
+		return runAddWithOptions(opts)
 	case "remove":
+		if parseResult.CCEFlags["remove_all"] == "true" {
+			return runRemoveAll(assumeYes)
+		}
 		if target, exists := parseResult.CCEFlags["remove_target"]; exists {
 			return runRemove(target)
 		}
 		return fmt.Errorf("remove command requires environment name")
+	case "config":
+		return runConfigCommand(parseResult.SubcommandArgs)
+	case "set":
+		return runSet(parseResult.SubcommandArgs)
+	case "copy":
+		return runCopy(parseResult.SubcommandArgs)
+	case "rename":
+		return runRename(parseResult.SubcommandArgs)
+	case "default":
+		return runDefaultCommand(parseResult.SubcommandArgs)
+	case "secrets":
+		return runSecrets(parseResult.SubcommandArgs)
+	case "completion":
+		return runCompletion(parseResult.SubcommandArgs)
+	case "__complete":
+		return runComplete(parseResult.SubcommandArgs)
+	case "plan":
+		return runPlan(parseResult.SubcommandArgs)
+	case "models":
+		return runModels(parseResult.SubcommandArgs)
+	case "export":
+		return runExport(parseResult.SubcommandArgs)
+	case "shell":
+		return runShell(parseResult.SubcommandArgs)
+	case "test":
+		return runTest(parseResult.SubcommandArgs)
+	case "ping":
+		return runPing(parseResult.SubcommandArgs)
+	case "doctor":
+		return runDoctor(parseResult.SubcommandArgs)
+	case "rotate":
+		return runRotate(parseResult.SubcommandArgs)
+	case "verify":
+		return runVerify(parseResult.SubcommandArgs)
+	case "current":
+		return runCurrent(parseResult.SubcommandArgs)
+	case "import":
+		return runImport(parseResult.SubcommandArgs)
+	case "serve":
+		return runServe(parseResult.SubcommandArgs)
+	case "select":
+		opts, err := parseSelectOptions(parseResult.SubcommandArgs)
+		if err != nil {
+			return fmt.Errorf("argument parsing failed: %w", err)
+		}
+		return runSelect(opts)
+	case "init":
+		opts, err := parseInitOptions(parseResult.SubcommandArgs)
+		if err != nil {
+			return fmt.Errorf("argument parsing failed: %w", err)
+		}
+		return runInit(opts)
 	case "help":
+		if len(parseResult.SubcommandArgs) > 0 {
+			return showCommandHelp(parseResult.SubcommandArgs[0])
+		}
 		showHelp()
 		return nil
 	case "version":
-		showVersion()
-		return nil
+		return runVersion(parseResult.SubcommandArgs)
 	}
 
-	// Validate passthrough arguments for security
-	if err := validatePassthroughArgs(parseResult.ClaudeArgs); err != nil {
+	// Validate passthrough arguments for security. Metacharacters are only warned about
+	// once the environment is chosen, since it may trust its arguments; strict mode
+	// rejects them regardless, so it can fail before any prompt.
+	if err := checkUnknownFlag(parseResult); err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	strictArgs := strictArgsEnabled(parseResult)
+	if err := rejectDangerousArgs(parseResult.ClaudeArgs); err != nil {
 		return fmt.Errorf("argument validation failed: %w", err)
 	}
+	if strictArgs {
+		if err := validatePassthroughArgsMode(parseResult.ClaudeArgs, true); err != nil {
+			return fmt.Errorf("argument validation failed: %w", err)
+		}
+	}
+
+	if parseResult.CCEFlags["detach"] == "true" && parseResult.CCEFlags["wait"] == "true" {
+		return fmt.Errorf("argument parsing failed: --detach and --wait cannot be combined")
+	}
 
 	// Handle default behavior with environment selection and claude arguments
-	envName := parseResult.CCEFlags["env"]
-	keyVarOverride := parseResult.CCEFlags["key_var"]
-	return runDefaultWithOverride(envName, parseResult.ClaudeArgs, keyVarOverride, parseResult.WorktreeEnabled)
-}
-
-// showHelp displays usage information including flag passthrough capability
-func showHelp() {
-	fmt.Println("Claude Code Environment Switcher")
-	fmt.Println("\nUsage:")
-	fmt.Println("  cce [command] [options] [-- claude-args...]")
-	fmt.Println("\nCommands:")
-	fmt.Println("  list                List all configured environments")
-	fmt.Println("  add                 Add a new environment configuration (supports model specification)")
-	fmt.Println("  remove <name>       Remove an environment configuration")
-	fmt.Println("  help                Show this help message")
-	fmt.Println("\nOptions:")
-	fmt.Println("  -e, --env <name>    Use specific environment")
-	fmt.Println("  -k, --key-var <name> Override API key env var for this run (ANTHROPIC_API_KEY|ANTHROPIC_AUTH_TOKEN)")
-	fmt.Println("      --wk           Create a temporary git worktree before launching Claude Code")
-	fmt.Println("  -h, --help          Show help")
-	fmt.Println("      --version       Show version information")
-	fmt.Println("      --yolo          Shortcut for --dangerously-skip-permissions (passed to claude)")
-	fmt.Println("\nFlag Passthrough:")
-	fmt.Println("  Any arguments after CCE options are passed directly to the claude command.")
-	fmt.Println("  Use '--' to explicitly separate CCE options from claude arguments.")
-	fmt.Println("\nFeatures:")
-	fmt.Println("  • Interactive arrow key navigation (↑↓ arrows, Enter to select, Esc to cancel)")
-	fmt.Println("  • Optional model specification per environment (e.g., claude-3-5-sonnet-20241022)")
-	fmt.Println("  • Automatic fallback to numbered selection on incompatible terminals")
-	fmt.Println("  • Responsive UI layout adapts to terminal width")
-	fmt.Println("  • Smart content truncation for long environment names and URLs")
-	fmt.Println("\nExamples:")
-	fmt.Println("  cce                              Interactive selection and launch Claude Code")
-	fmt.Println("  cce --env prod                   Launch Claude Code with 'prod' environment")
-	fmt.Println("  cce list                         Show all environments with model information")
-	fmt.Println("  cce add                          Add new environment interactively (with optional model)")
-	fmt.Println("\nFlag Passthrough Examples:")
-	fmt.Println("  cce --env staging -r             Launch claude with 'staging' env and -r flag")
-	fmt.Println("  cce --verbose --model claude-3   Pass --verbose and --model flags to claude")
-	fmt.Println("  cce -- --help                    Show claude's help (-- separates CCE from claude flags)")
-	fmt.Println("  cce -e dev -- chat --interactive Use 'dev' env and pass chat flags to claude")
-	fmt.Println("  cce --env dev --key-var ANTHROPIC_AUTH_TOKEN -- chat  Override key var for this run")
-	fmt.Println("  cce --yolo                       Launch claude with --dangerously-skip-permissions")
-	fmt.Println("  cce --env prod --yolo            Use 'prod' env and bypass permissions")
-	fmt.Println("  cce --yolo --yolo -- command     Multiple --yolo flags (each becomes --dangerously-skip-permissions)")
-	fmt.Println("\nWorktree (--wk) Examples:")
-	fmt.Println("  cce --wk --env prod -- chat --verbose  Create git worktree then launch Claude Code with prod env")
-	fmt.Println("  cce --wk -- --help                     Create git worktree and pass --help to Claude Code")
-	fmt.Println("  Cleanup: git worktree remove <path>    Manually remove a worktree after use")
-	fmt.Println("  Cleanup (prune): git worktree prune    Clean up stale git worktrees")
+	envName, err := resolveEnvFlag(parseResult.CCEFlags["env"])
+	if err != nil {
+		return fmt.Errorf("argument validation failed: %w", err)
+	}
+	opts := launchOptions{
+		KeyVarOverride:   parseResult.CCEFlags["key_var"],
+		ModelOverride:    parseResult.CCEFlags["model"],
+		WorktreeEnabled:  parseResult.WorktreeEnabled,
+		WorktreeFresh:    parseResult.WorktreeFresh,
+		EnvFile:          parseResult.CCEFlags["env_file"],
+		Detach:           parseResult.CCEFlags["detach"] == "true",
+		SkipPreflight:    parseResult.CCEFlags["skip_preflight"] == "true",
+		StrictArgs:       strictArgs,
+		LogFile:          parseResult.CCEFlags["log_file"],
+		LogOnly:          parseResult.CCEFlags["log_only"] == "true",
+		Watch:            parseResult.CCEFlags["watch"] == "true",
+		WorktreePath:     parseResult.CCEFlags["wk_path"],
+		WorktreeName:     parseResult.CCEFlags["wk_name"],
+		WorktreeCleanup:  parseResult.CCEFlags["wk_cleanup"] == "true",
+		OverrideSettings: parseResult.CCEFlags["override_settings"] == "true",
+		DryRun:           parseResult.CCEFlags["dry_run"] == "true",
+		WorktreeForce:    parseResult.CCEFlags["force"] == "true",
+	}
+	if opts.WatchMax, err = parseWatchMax(parseResult.CCEFlags["watch_max"]); err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	if err := validateWatch(opts); err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	if opts.LogOnly && opts.LogFile == "" {
+		return fmt.Errorf("argument parsing failed: --log-only requires --log-file <path>")
+	}
+	if opts.LogFile != "" && opts.Detach {
+		return fmt.Errorf("argument parsing failed: --detach already writes claude's output to a log file; drop --log-file")
+	}
+	if err := validateWorktreeCleanup(opts); err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	if err := resolveWorktreeOptions(&opts); err != nil {
+		return err
+	}
+	if group := parseResult.CCEFlags["group"]; group != "" {
+		if err := validateGroupLaunch(parseResult); err != nil {
+			return fmt.Errorf("argument parsing failed: %w", err)
+		}
+		return runGroup(group, parseResult.ClaudeArgs, opts)
+	}
+	if parseResult.CCEFlags["print_env_diff"] == "true" {
+		return runEnvDiff(envName, opts)
+	}
+	return runDefaultWithOptions(envName, parseResult.ClaudeArgs, opts)
+}
+
+// resolveEnvFlag returns the --env value, or for --env @path the first line of that file
+// (trimmed), so CI steps can hand the environment name over in a file
+func resolveEnvFlag(value string) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+	path := value[1:]
+	if path == "" {
+		return "", fmt.Errorf("--env @ requires a file path, e.g. --env @.cce-env")
+	}
+	path = workPath(path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read environment name from %s: %w", path, err)
+	}
+	name := string(data)
+	if i := strings.IndexAny(name, "\r\n"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+	if name == "" {
+		return "", fmt.Errorf("%s does not start with an environment name", path)
+	}
+	return name, nil
+}
+
+// strictArgsEnabled reports whether --strict-args, CCE_STRICT_ARGS, or the strict_args
+// setting asks for shell metacharacters in claude arguments to be rejected
+func strictArgsEnabled(parseResult ParseResult) bool {
+	if parseResult.CCEFlags["strict_args"] == "true" {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("CCE_STRICT_ARGS")) {
+	case "1", "true", "yes":
+		return true
+	}
+	settings := peekConfigSettings()
+	return settings != nil && settings.StrictArgs
+}
+
+// versionInfo is the build metadata reported by version --json
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+}
+
+// currentVersionInfo collects the ldflags-injected metadata and runtime details
+func currentVersionInfo() versionInfo {
+	return versionInfo{
+		Version:   Version,
+		Commit:    BuildCommit,
+		Date:      BuildDate,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
 }
 
 // showVersion prints the CLI version information
 func showVersion() {
-	fmt.Printf("CCE version %s\n", Version)
+	info := currentVersionInfo()
+	fmt.Printf("CCE version %s\n", info.Version)
+	fmt.Printf("  commit: %s, built: %s, %s %s/%s\n", info.Commit, info.Date, info.GoVersion, info.OS, info.Arch)
+}
+
+// runVersion handles the version subcommand and its --json flag
+func runVersion(args []string) error {
+	asJSON := false
+	for _, arg := range args {
+		switch arg {
+		case "--json":
+			asJSON = true
+		default:
+			return fmt.Errorf("argument parsing failed: unknown version flag '%s'", arg)
+		}
+	}
+
+	if !asJSON {
+		showVersion()
+		return nil
+	}
+
+	data, err := json.MarshalIndent(currentVersionInfo(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode version information: %w", err)
+	}
+	if _, err := fmt.Println(string(data)); err != nil {
+		return fmt.Errorf("failed to display version information: %w", err)
+	}
+	return nil
 }
 
 // runDefault handles the default behavior: environment selection and Claude Code launch with arguments
@@ -714,52 +1872,286 @@ func runDefault(envName string, claudeArgs []string) error {
 	return runDefaultWithOverride(envName, claudeArgs, "", false)
 }
 
+// isVerbose reports whether CCE_VERBOSE requests extra launch diagnostics.
+// CCE does not claim --verbose because that flag belongs to claude.
+func isVerbose() bool {
+	switch strings.ToLower(os.Getenv("CCE_VERBOSE")) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
 // claudeLauncher allows tests to replace the exec-based launcher.
 var claudeLauncher = launchClaudeCode
 
+// launchOptions carries per-run launch behavior collected from CCE flags
+type launchOptions struct {
+	KeyVarOverride  string // One-run API key env var name
+	ModelOverride   string // One-run model, over every configured source (--model)
+	Tag             string // Only offer environments with this tag when none is named (select --tag)
+	Pick            bool   // Always show the picker when none is named, even with a default (select --launch)
+	WorktreeEnabled bool   // Launch from a git worktree (--wk)
+	WorktreeFresh   bool   // Never reuse an existing worktree (--wk-fresh)
+	EnvFile         string // Dotenv file merged under the environment's variables (--env-file)
+	Detach          bool   // Start claude in the background and return (--detach)
+	SkipPreflight   bool   // Do not run settings.preflight (--skip-preflight)
+	StrictArgs      bool   // Reject shell metacharacters even for trust_args environments
+	LogFile         string // Also write claude's output to this file (--log-file)
+	LogOnly         bool   // Write claude's output only to LogFile (--log-only)
+	Watch           bool   // Relaunch claude each time it exits (--watch)
+	WatchMax        int    // Restarts allowed with Watch; 0 means until stopped (--watch-max)
+	WorktreePath    string // Directory for a new worktree, over settings.worktree.base_path (--wk-path)
+	WorktreeName    string // Suffix replacing the generated timestamp (--wk-name)
+	WorktreeCleanup bool   // Remove the worktree cce created once claude exits (--wk-cleanup)
+	WorktreeForce   bool   // Clean up even a worktree with uncommitted changes (--force)
+	// OverrideSettings writes the environment into a settings.json that would override it (--override-settings)
+	OverrideSettings bool
+	DryRun           bool // Print the launch plan and stop before anything runs (--dry-run)
+}
+
+// validateWorktreeCleanup rejects launch modes cce does not outlive, since nothing would
+// be left to remove the worktree
+func validateWorktreeCleanup(opts launchOptions) error {
+	switch {
+	case opts.WorktreeForce && !opts.WorktreeCleanup:
+		return fmt.Errorf("--force requires --wk-cleanup")
+	case !opts.WorktreeCleanup:
+		return nil
+	case opts.Detach:
+		return fmt.Errorf("--wk-cleanup cannot be combined with --detach; claude would still be using the worktree")
+	case opts.LogFile != "":
+		return fmt.Errorf("--wk-cleanup cannot be combined with --log-file")
+	}
+	return nil
+}
+
+// resolveWorktreeOptions checks --wk-name and makes --wk-path absolute (relative paths are
+// taken from the current directory, like --config)
+func resolveWorktreeOptions(opts *launchOptions) error {
+	if opts.WorktreeName != "" && sanitizeBranchName(opts.WorktreeName) != opts.WorktreeName {
+		return fmt.Errorf("argument validation failed: --wk-name %s: use only letters, digits, '-' and '_' (e.g. %s)",
+			opts.WorktreeName, sanitizeBranchName(opts.WorktreeName))
+	}
+	if opts.WorktreePath == "" {
+		return nil
+	}
+	expanded, err := expandSettingsDir(opts.WorktreePath)
+	if err != nil {
+		return fmt.Errorf("argument validation failed: --wk-path %s: %w", opts.WorktreePath, err)
+	}
+	abs, err := filepath.Abs(expanded)
+	if err != nil {
+		return fmt.Errorf("argument validation failed: --wk-path %s: %w", opts.WorktreePath, err)
+	}
+	if info, err := os.Stat(abs); err == nil && !info.IsDir() {
+		return fmt.Errorf("argument validation failed: --wk-path %s: not a directory", opts.WorktreePath)
+	}
+	opts.WorktreePath = abs
+	return nil
+}
+
+// finishWorktreeCleanup removes the launch's worktree after claude exits and reports it.
+// A worktree that cannot be removed is only a warning; claude's own result is returned.
+func finishWorktreeCleanup(wm *WorktreeManager, force bool, launchErr error) error {
+	path, err := wm.cleanupWorktree(force)
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	case path != "":
+		fmt.Fprintf(os.Stderr, "Removed worktree: %s\n", path)
+	case wm.wasReused():
+		fmt.Fprintf(os.Stderr, "Kept worktree %s: it was reused, not created by this launch\n", wm.getWorktreePath())
+	}
+	return launchErr
+}
+
 // runDefaultWithOverride handles the default behavior with optional API key env var override
 func runDefaultWithOverride(envName string, claudeArgs []string, keyVarOverride string, worktreeEnabled bool) error {
+	return runDefaultWithOptions(envName, claudeArgs, launchOptions{
+		KeyVarOverride:  keyVarOverride,
+		WorktreeEnabled: worktreeEnabled,
+	})
+}
+
+// resolveLaunchEnvironment validates launch flags, loads the config, selects the environment
+// and applies one-run overrides, producing exactly what would be launched
+func resolveLaunchEnvironment(envName string, opts launchOptions) (Environment, error) {
+	keyVarOverride := opts.KeyVarOverride
+
 	// Validate override early
 	if keyVarOverride != "" {
 		keyVarOverride = strings.ToUpper(keyVarOverride)
 		if err := validateAPIKeyEnv(keyVarOverride); err != nil {
-			return fmt.Errorf("argument validation failed: invalid --key-var: %w", err)
+			return Environment{}, fmt.Errorf("argument validation failed: invalid --key-var: %w", err)
 		}
 	}
+	if err := validateModel(opts.ModelOverride); err != nil {
+		return Environment{}, fmt.Errorf("argument validation failed: invalid --model: %w", err)
+	}
 
-	var worktreePath string
-	var worktreeWarning string
+	// Parse the env file before any selection so malformed files fail fast
+	var fileVars map[string]string
+	if opts.EnvFile != "" {
+		vars, err := parseEnvFile(workPath(opts.EnvFile))
+		if err != nil {
+			errorCtx := newErrorContext("env file loading", "main runner")
+			errorCtx.addContext("path", workPath(opts.EnvFile))
+			errorCtx.addSuggestion("Use KEY=value lines; quote values containing spaces or #")
+			return Environment{}, fmt.Errorf("argument validation failed: invalid --env-file: %w", errorCtx.formatError(err))
+		}
+		fileVars = vars
+	}
 
 	// Load configuration
 	config, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("configuration loading failed: %w", err)
+		return Environment{}, fmt.Errorf("configuration loading failed: %w", err)
+	}
+
+	// A bare cce with nothing configured gets guidance instead of an empty selector
+	if envName == "" && len(config.Environments) == 0 {
+		config, err = offerFirstEnvironment()
+		if err != nil {
+			return Environment{}, err
+		}
+	}
+	if envName == "" {
+		if config, err = configWithTag(config, opts.Tag); err != nil {
+			return Environment{}, err
+		}
 	}
 
 	var selectedEnv Environment
 
 	if envName != "" {
 		// Use specified environment
-		index, exists := findEnvironmentByName(config, envName)
-		if !exists {
-			return fmt.Errorf("environment '%s' not found", envName)
+		index, err := lookupEnvironment(config, envName)
+		if err != nil {
+			return Environment{}, err
 		}
 		selectedEnv = config.Environments[index]
+	} else if env, ok := launchDefault(config, opts); ok {
+		selectedEnv = env
 	} else {
 		// Interactive selection
-		selectedEnv, err = selectEnvironment(config)
+		if err := checkSelectionPrompt(config); err != nil {
+			return Environment{}, err
+		}
+		selectedEnv, err = environmentSelector(config)
 		if err != nil {
-			return fmt.Errorf("environment selection failed: %w", err)
+			return Environment{}, fmt.Errorf("environment selection failed: %w", err)
 		}
 	}
 
+	if warning := unvalidatedWarning(selectedEnv); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
 	// Apply one-run override if provided
 	if keyVarOverride != "" {
 		selectedEnv.APIKeyEnv = keyVarOverride
+		selectedEnv.AuthScheme = authSchemeForKeyVar(keyVarOverride)
+	}
+	if opts.ModelOverride != "" {
+		selectedEnv.Model = opts.ModelOverride
+	}
+
+	if fileVars != nil {
+		var ignored []string
+		selectedEnv, ignored = mergeEnvFileVars(selectedEnv, fileVars)
+		if len(ignored) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring CCE-managed variables from %s: %s\n", opts.EnvFile, strings.Join(ignored, ", "))
+		}
+	}
+
+	return selectedEnv, nil
+}
+
+// offerFirstEnvironment handles a bare cce with an empty config: on a terminal it offers
+// to run add right away and returns the reloaded config; otherwise it explains how to start.
+func offerFirstEnvironment() (Config, error) {
+	errorCtx := newErrorContext("environment selection", "main runner")
+	if path, err := getConfigPath(); err == nil {
+		errorCtx.addContext("config", path)
+	}
+	errorCtx.addSuggestion("Run 'cce add' to create an environment")
+	errorCtx.addSuggestion("Run 'cce init' for a guided first-time setup")
+	emptyErr := fmt.Errorf("configuration incomplete: %w", errorCtx.formatError(fmt.Errorf("no environments configured")))
+
+	if !stdinIsTerminal() || noPrompt {
+		return Config{}, emptyErr
+	}
+
+	fmt.Println("No environments configured yet.")
+	if assumeYes {
+		noteAutoConfirmed("adding a first environment")
+	} else {
+		answer, err := confirmationReader("Add one now? [Y/n]: ")
+		if err != nil {
+			return Config{}, emptyErr
+		}
+		if answer = strings.ToLower(answer); answer != "" && answer != "y" && answer != "yes" {
+			return Config{}, emptyErr
+		}
+	}
+
+	if err := runAdd(); err != nil {
+		return Config{}, err
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return Config{}, fmt.Errorf("configuration loading failed: %w", err)
+	}
+	return config, nil
+}
+
+// runDefaultWithOptions handles environment selection and launch using the collected launch options
+func runDefaultWithOptions(envName string, claudeArgs []string, opts launchOptions) (err error) {
+	if opts.Detach {
+		if err := validateDetach(claudeArgs, stdinIsTerminal()); err != nil {
+			return fmt.Errorf("argument validation failed: %w", err)
+		}
+	}
+	// Piping claude's output would take the terminal away from its TUI, so interactive
+	// sessions are launched as usual and only non-interactive output is logged
+	if opts.LogFile != "" && stdinIsTerminal() && !claudePrintMode(claudeArgs) {
+		fmt.Fprintf(os.Stderr, "Warning: --log-file is ignored for interactive sessions; pass -p/--print to log claude's output\n")
+		opts.LogFile, opts.LogOnly = "", false
+	}
+
+	selectedEnv, err := resolveLaunchEnvironment(envName, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := validateArgsForEnvironment(claudeArgs, selectedEnv, opts.StrictArgs); err != nil {
+		return fmt.Errorf("argument validation failed: %w", err)
 	}
 
-	if worktreeEnabled {
-		wm := NewWorktreeManager("")
+	// Spread launches across the environment's keys when it has more than one
+	selectedEnv, keyLabel := pickLaunchKey(selectedEnv)
+
+	// Stop before anything changes: no worktree, settings rewrite, pre-flight or claude
+	if opts.DryRun {
+		return renderLaunchPlan(os.Stdout, buildLaunchPlan(selectedEnv, claudeArgs).withModelOverride(opts.ModelOverride))
+	}
+
+	var worktreePath string
+	var worktreeWarning string
+
+	if opts.WorktreeEnabled {
+		wm := NewWorktreeManager(workDir)
+		wm.setFresh(opts.WorktreeFresh)
+		wm.setNameSuffix(opts.WorktreeName)
+		if opts.WorktreePath != "" {
+			wm.setBasePath(opts.WorktreePath)
+		} else {
+			wm.setBasePath(worktreeBasePath)
+		}
+		if opts.WorktreeCleanup {
+			defer func() { err = finishWorktreeCleanup(wm, opts.WorktreeForce, err) }()
+		}
 
 		branch, err := wm.getCurrentBranch()
 		if err != nil {
@@ -786,49 +2178,436 @@ func runDefaultWithOverride(envName string, claudeArgs []string, keyVarOverride
 
 		caps := detectTerminalCapabilities()
 		headless := isHeadlessMode()
-		if err := renderWorktreeSummary(os.Stdout, os.Stderr, worktreePath, worktreeWarning, caps, headless); err != nil {
+		if err := renderWorktreeSummaryWithStatus(os.Stdout, os.Stderr, worktreePath, worktreeWarning, caps, headless, wm.wasReused()); err != nil {
 			return fmt.Errorf("failed to display worktree summary: %w", err)
 		}
 	}
 
-	// Display selected environment
-	if _, err := fmt.Printf("Using environment: %s (%s)\n", selectedEnv.Name, selectedEnv.URL); err != nil {
+	// Display selected environment, including the resolved model when verbose
+	banner := fmt.Sprintf("Using environment: %s (%s)", selectedEnv.Name, selectedEnv.URL)
+	if keyLabel != "" {
+		banner += " [" + keyLabel + "]"
+	}
+	if isVerbose() {
+		banner += fmt.Sprintf(" [model: %s]", resolveModel(selectedEnv, opts.ModelOverride).describe())
+	}
+	if _, err := fmt.Println(banner); err != nil {
 		return fmt.Errorf("failed to display selected environment: %w", err)
 	}
 
+	if warning := unavailableModelWarning(selectedEnv); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
+	if opts.OverrideSettings {
+		path, backup, keys, err := overrideSettingsEnv(selectedEnv)
+		if err != nil {
+			return fmt.Errorf("failed to override claude settings: %w", err)
+		}
+		if len(keys) > 0 {
+			fmt.Fprintf(os.Stderr, "Wrote %s for '%s' into %s (backup at %s)\n", strings.Join(keys, ", "), selectedEnv.Name, path, backup)
+		}
+	} else if warning := settingsConflictWarning(selectedEnv); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+
+	if err := checkClaudeVersion(selectedEnv); err != nil {
+		return err
+	}
+
+	if !opts.SkipPreflight {
+		if err := runPreflight(selectedEnv, worktreePath); err != nil {
+			return err
+		}
+	}
+
+	// Record the launch first: claude usually replaces this process
+	if err := recordLastUsed(selectedEnv.Name); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record last use of '%s': %v\n", selectedEnv.Name, err)
+	}
+
 	// Launch Claude Code with arguments
+	if opts.Detach {
+		return detachedLauncher(selectedEnv, claudeArgs, worktreePath)
+	}
+	if opts.LogFile != "" {
+		return loggedLauncher(selectedEnv, claudeArgs, worktreePath, opts.LogFile, opts.LogOnly)
+	}
+	if opts.Watch {
+		return runWatch(selectedEnv, claudeArgs, worktreePath, opts.WatchMax)
+	}
+	if opts.WorktreeCleanup {
+		return runClaudeAttached(selectedEnv, claudeArgs, worktreePath)
+	}
 	return claudeLauncher(selectedEnv, claudeArgs, worktreePath)
 }
 
+// detachedLauncher allows tests to replace the background launcher used by --detach.
+var detachedLauncher = launchClaudeCodeDetached
+
+// loggedLauncher allows tests to replace the launcher used by --log-file.
+var loggedLauncher = launchClaudeCodeLogged
+
+// stdinIsTerminal allows tests to simulate an attached terminal
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// claudePrintMode reports whether claude runs non-interactively (-p/--print)
+func claudePrintMode(claudeArgs []string) bool {
+	for _, arg := range claudeArgs {
+		if arg == "-p" || arg == "--print" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateDetach rejects --detach for interactive claude sessions: a TUI started in the
+// background would fight the shell for the terminal. Print mode (-p) or no terminal is fine.
+func validateDetach(claudeArgs []string, attached bool) error {
+	if !attached || claudePrintMode(claudeArgs) {
+		return nil
+	}
+	return fmt.Errorf("--detach cannot start an interactive claude session from a terminal; pass -p/--print or run without a terminal")
+}
+
+// listOptions holds flags accepted by the list subcommand
+type listOptions struct {
+	NamesOnly bool   // Print bare, sorted names for scripting
+	Wide      bool   // Include notes and other secondary details
+	Check     bool   // Probe every environment's connectivity and auth
+	JSON      bool   // Print a JSON array with keys masked to their last four characters
+	Tag       string // Only show environments carrying this tag
+	Sort      string // Order by name or last-used instead of config order
+	// Format is a compiled --format text/template executed per environment
+	Format *template.Template
+}
+
+// parseListOptions parses flags following the list subcommand
+func parseListOptions(args []string) (listOptions, error) {
+	var opts listOptions
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--names" || arg == "--quiet" || arg == "-q":
+			opts.NamesOnly = true
+		case arg == "--wide" || arg == "-w":
+			opts.Wide = true
+		case arg == "--check":
+			opts.Check = true
+		case arg == "--json":
+			opts.JSON = true
+		case arg == "--format" || strings.HasPrefix(arg, "--format="):
+			text := strings.TrimPrefix(arg, "--format=")
+			if arg == "--format" {
+				if i+1 >= len(args) {
+					return listOptions{}, fmt.Errorf("--format flag requires a template, e.g. '{{.Name}} {{.URL}}'")
+				}
+				i++
+				text = args[i]
+			}
+			tmpl, err := parseListTemplate(text)
+			if err != nil {
+				return listOptions{}, fmt.Errorf("invalid --format template: %w", err)
+			}
+			opts.Format = tmpl
+		case arg == "--tag" || strings.HasPrefix(arg, "--tag="):
+			opts.Tag = strings.TrimPrefix(arg, "--tag=")
+			if arg == "--tag" {
+				if i+1 >= len(args) {
+					return listOptions{}, fmt.Errorf("--tag flag requires a tag")
+				}
+				i++
+				opts.Tag = args[i]
+			}
+			if opts.Tag == "" {
+				return listOptions{}, fmt.Errorf("--tag flag requires a tag")
+			}
+		case arg == "--sort" || strings.HasPrefix(arg, "--sort="):
+			opts.Sort = strings.TrimPrefix(arg, "--sort=")
+			if arg == "--sort" {
+				if i+1 >= len(args) {
+					return listOptions{}, fmt.Errorf("--sort flag requires %s or %s", sortByLastUsed, sortByName)
+				}
+				i++
+				opts.Sort = args[i]
+			}
+			if opts.Sort != sortByLastUsed && opts.Sort != sortByName {
+				return listOptions{}, fmt.Errorf("invalid --sort '%s': use %s or %s", opts.Sort, sortByLastUsed, sortByName)
+			}
+		default:
+			return listOptions{}, fmt.Errorf("unknown list flag: %s", arg)
+		}
+	}
+	if opts.Format != nil && (opts.NamesOnly || opts.Wide || opts.Check) {
+		return listOptions{}, fmt.Errorf("--format cannot be combined with --names, --wide or --check")
+	}
+	if opts.JSON && (opts.Format != nil || opts.NamesOnly || opts.Wide || opts.Check) {
+		return listOptions{}, fmt.Errorf("--json cannot be combined with --format, --names, --wide or --check")
+	}
+	return opts, nil
+}
+
 // runList displays all configured environments
 func runList() error {
+	return runListWithOptions(listOptions{})
+}
+
+// runListWithOptions displays configured environments honoring list flags
+func runListWithOptions(opts listOptions) error {
+	// Names alone come from the streaming reader, which stays fast for very large configs
+	if opts.NamesOnly && opts.Tag == "" && opts.Sort == "" {
+		names, err := loadEnvironmentNames()
+		if err != nil {
+			return fmt.Errorf("configuration loading failed: %w", err)
+		}
+		return printEnvironmentNames(names)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	if config, err = configWithTag(config, opts.Tag); err != nil {
+		return err
+	}
+	config.Environments = sortEnvironments(config.Environments, opts.Sort)
+
+	if opts.NamesOnly {
+		if opts.Sort != "" {
+			return printEnvironmentNamesInOrder(config.Environments)
+		}
+		return displayEnvironmentNames(config)
+	}
+
+	if opts.Format != nil {
+		return displayEnvironmentsFormatted(os.Stdout, config, opts.Format)
+	}
+
+	if opts.JSON {
+		return displayEnvironmentsJSON(os.Stdout, config)
+	}
+
+	if opts.Check {
+		return runListCheck(config)
+	}
+
+	return displayEnvironmentsWithOptions(config, opts)
+}
+
+// environmentSelector picks an environment interactively; tests replace it to avoid a terminal
+var environmentSelector = selectEnvironment
+
+// selectOptions holds flags accepted by the select subcommand
+type selectOptions struct {
+	Launch     bool     // Launch claude with the picked environment instead of printing its name
+	Check      bool     // Show each endpoint's measured latency in the picker
+	Tag        string   // Only offer environments carrying this tag
+	ClaudeArgs []string // Arguments after -- passed to claude when launching
+}
+
+// parseSelectOptions parses flags following the select subcommand
+func parseSelectOptions(args []string) (selectOptions, error) {
+	var opts selectOptions
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--launch", "-l":
+			opts.Launch = true
+		case "--check":
+			opts.Check = true
+		case "--tag":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return selectOptions{}, fmt.Errorf("--tag flag requires a tag")
+			}
+			i++
+			opts.Tag = args[i]
+		case "--":
+			opts.ClaudeArgs = append([]string{}, args[i+1:]...)
+			if !opts.Launch && len(opts.ClaudeArgs) > 0 {
+				return selectOptions{}, fmt.Errorf("claude arguments after -- require --launch")
+			}
+			return opts, nil
+		default:
+			return selectOptions{}, fmt.Errorf("unknown select flag: %s (use -- before claude arguments)", arg)
+		}
+	}
+	return opts, nil
+}
+
+// runSelect runs the picker and either prints the chosen name or launches claude with it
+func runSelect(opts selectOptions) error {
+	selectorCheck = opts.Check
+	defer func() { selectorCheck = false }()
+
+	if opts.Launch {
+		if err := rejectDangerousArgs(opts.ClaudeArgs); err != nil {
+			return fmt.Errorf("argument validation failed: %w", err)
+		}
+		return runDefaultWithOptions("", opts.ClaudeArgs, launchOptions{StrictArgs: strictArgsEnabled(ParseResult{}), Tag: opts.Tag, Pick: true})
+	}
+
 	config, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("configuration loading failed: %w", err)
 	}
+	if config, err = configWithTag(config, opts.Tag); err != nil {
+		return err
+	}
+
+	if err := checkSelectionPrompt(config); err != nil {
+		return err
+	}
+	env, err := environmentSelector(config)
+	if err != nil {
+		return fmt.Errorf("environment selection failed: %w", err)
+	}
+
+	if _, err := fmt.Println(env.Name); err != nil {
+		return fmt.Errorf("failed to display selected environment: %w", err)
+	}
+	return nil
+}
+
+// runListCheck probes all environments concurrently; Ctrl-C cancels in-flight probes and
+// prints the partial results instead of waiting for every timeout
+func runListCheck(config Config) error {
+	if len(config.Environments) == 0 {
+		return displayEnvironments(config)
+	}
 
-	return displayEnvironments(config)
+	return runNetworkChecks(newNetworkValidator(0), config.Environments)
 }
 
+// addOptions holds flags accepted by the add subcommand
+type addOptions struct {
+	Test     bool   // Require a passing network check before saving
+	CopyFrom string // Existing environment whose values pre-fill the prompts
+	CopyKey  bool   // Also offer the source environment's API key as the default
+	// NoValidate accepts URL, key and model values that fail strict validation
+	NoValidate bool
+	// RejectDupURL (--allow-dup-url=false) fails instead of warning when the URL is in use
+	RejectDupURL bool
+	// NormalizeURL strips trailing slashes from the entered URL before saving
+	NormalizeURL bool
+	// NoNetworkCheck skips the reachability probe made before saving
+	NoNetworkCheck bool
+}
+
+// parseAddOptions parses flags following the add subcommand
+func parseAddOptions(args []string) (addOptions, error) {
+	var opts addOptions
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--test":
+			opts.Test = true
+		case "--copy-env":
+			if i+1 >= len(args) {
+				return addOptions{}, fmt.Errorf("flag %s requires a value", arg)
+			}
+			opts.CopyFrom = args[i+1]
+			i++
+		case "--copy-key":
+			opts.CopyKey = true
+		case "--no-validate":
+			opts.NoValidate = true
+		case "--allow-dup-url", "--allow-dup-url=true":
+			opts.RejectDupURL = false
+		case "--allow-dup-url=false":
+			opts.RejectDupURL = true
+		case "--normalize-url":
+			opts.NormalizeURL = true
+		case "--no-network-check":
+			opts.NoNetworkCheck = true
+		default:
+			return addOptions{}, fmt.Errorf("unknown add flag: %s", arg)
+		}
+	}
+	if opts.CopyKey && opts.CopyFrom == "" {
+		return addOptions{}, fmt.Errorf("--copy-key requires --copy-env")
+	}
+	if opts.Test && opts.NoNetworkCheck {
+		return addOptions{}, fmt.Errorf("--test and --no-network-check cannot be combined")
+	}
+	return opts, nil
+}
+
+// environmentPrompter allows tests to replace the interactive add prompts.
+var environmentPrompter = promptForEnvironment
+
+// seededEnvironmentPrompter allows tests to replace the add prompts used with --copy-env.
+var seededEnvironmentPrompter = promptForEnvironmentWithDefaults
+
 // runAdd adds a new environment configuration
 func runAdd() error {
+	return runAddWithOptions(addOptions{})
+}
+
+// runAddWithOptions adds a new environment configuration honoring add flags
+func runAddWithOptions(opts addOptions) error {
+	if noPrompt {
+		return promptRefused("cce add", "create environments with 'cce import' instead")
+	}
+
 	// Load existing configuration
 	config, err := loadConfig()
 	if err != nil {
 		return fmt.Errorf("configuration loading failed: %w", err)
 	}
 
-	// Prompt for new environment details
-	env, err := promptForEnvironment(config)
+	// Prompt for new environment details, seeded from another environment if requested
+	var env Environment
+	if opts.CopyFrom != "" {
+		index, exists := findEnvironmentByName(config, opts.CopyFrom)
+		if !exists {
+			return fmt.Errorf("environment '%s' not found", opts.CopyFrom)
+		}
+		env, err = seededEnvironmentPrompter(config, promptDefaults{
+			Source:         opts.CopyFrom,
+			Env:            config.Environments[index],
+			ReuseKey:       opts.CopyKey,
+			SkipValidation: opts.NoValidate,
+		})
+	} else if opts.NoValidate {
+		env, err = seededEnvironmentPrompter(config, promptDefaults{SkipValidation: true})
+	} else {
+		env, err = environmentPrompter(config)
+	}
 	if err != nil {
 		return fmt.Errorf("environment input failed: %w", err)
 	}
+	if opts.NormalizeURL && env.URL != "" {
+		env.URL = normalizeURLSlash(env.URL)
+	}
+	if warning := unvalidatedWarning(env); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+	if warning := keyStyleWarning(env); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+	if warning := keyFormatWarning(env); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
 
-	// Add environment to configuration
-	if err := addEnvironmentToConfig(&config, env); err != nil {
+	// Add environment to configuration, which runs the duplicate and validation checks
+	if err := addEnvironmentToConfigWithOptions(&config, env, !opts.RejectDupURL); err != nil {
 		return fmt.Errorf("failed to add environment: %w", err)
 	}
 
+	// Probe only an environment that passed every local check; --test gates the save on it
+	if opts.Test {
+		result, err := newNetworkValidator(0).checkEnvironment(env)
+		if err != nil {
+			return fmt.Errorf("environment '%s' not saved: %w", env.Name, err)
+		}
+		if _, err := fmt.Printf("Network check passed: %s\n", result.describe()); err != nil {
+			return fmt.Errorf("failed to display network check result: %w", err)
+		}
+	} else if !opts.NoNetworkCheck {
+		if _, err := reportReachability(env); err != nil {
+			return err
+		}
+	}
+
 	// Save updated configuration
 	if err := saveConfig(config); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
@@ -841,6 +2620,287 @@ func runAdd() error {
 	return nil
 }
 
+// secretReader reads a replacement API key; tests replace it to avoid a terminal
+var secretReader = readSecret
+
+// readSecret reads a secret with hidden input on a terminal, or one line from piped stdin
+func readSecret(prompt string) (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return secureInput(prompt)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read secret from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// parseFieldUpdates parses `field=value` arguments. Only named fields change; an empty
+// value clears an optional field. Presence in the map, not the value, marks a change.
+func parseFieldUpdates(args []string) (map[string]string, []string, error) {
+	updates := make(map[string]string, len(args))
+	order := make([]string, 0, len(args))
+	for _, arg := range args {
+		eq := strings.Index(arg, "=")
+		if eq <= 0 {
+			return nil, nil, fmt.Errorf("expected field=value, got '%s'", arg)
+		}
+		field := strings.ToLower(arg[:eq])
+		if _, dup := updates[field]; dup {
+			return nil, nil, fmt.Errorf("field '%s' given more than once", field)
+		}
+		updates[field] = arg[eq+1:]
+		order = append(order, field)
+	}
+	return updates, order, nil
+}
+
+// applyFieldUpdates returns env with the named fields changed and everything else untouched
+func applyFieldUpdates(env Environment, updates map[string]string, order []string) (Environment, error) {
+	return applyFieldUpdatesWithOptions(env, updates, order, false)
+}
+
+// applyFieldUpdatesWithOptions is applyFieldUpdates; with noValidate, changed url, api_key
+// and model values that fail strict validation are kept and recorded as unvalidated.
+// A changed field always loses its earlier unvalidated mark unless it fails again.
+func applyFieldUpdatesWithOptions(env Environment, updates map[string]string, order []string, noValidate bool) (Environment, error) {
+	updated := env
+	updated.Unvalidated = append([]string(nil), env.Unvalidated...)
+	updated.EnvVars = make(map[string]string, len(env.EnvVars))
+	for key, value := range env.EnvVars {
+		updated.EnvVars[key] = value
+	}
+	updated.Headers = make(map[string]string, len(env.Headers))
+	for name, value := range env.Headers {
+		updated.Headers[name] = value
+	}
+
+	for _, field := range order {
+		value := updates[field]
+		switch {
+		case field == fieldURL && value == "":
+			// Only cloud providers may omit the URL; the final validation enforces that
+			updated.URL = ""
+			clearUnvalidated(&updated, fieldURL)
+		case field == fieldURL || field == fieldModel:
+			strict := validateURL
+			if field == fieldURL {
+				updated.URL = value
+			} else {
+				updated.Model = value
+				strict = validateModel
+			}
+			clearUnvalidated(&updated, field)
+			skipped, err := checkFieldEscapable(field, value, strict, noValidate)
+			if err != nil {
+				return Environment{}, fmt.Errorf("invalid %s: %w", field, err)
+			}
+			if skipped != nil {
+				markUnvalidated(&updated, field)
+			}
+		case field == "notes":
+			updated.Notes = value
+		case field == "settings_dir":
+			updated.SettingsDir = value
+		case field == "ca_cert":
+			updated.CACert = value
+		case field == "provider":
+			updated.Provider = strings.ToLower(value)
+		case field == "region":
+			updated.Region = strings.ToLower(value)
+		case field == "project":
+			updated.Project = value
+		case field == "check_timeout":
+			updated.CheckTimeout = value
+		case field == "min_claude_version":
+			updated.MinClaudeVersion = value
+		case field == "max_claude_version":
+			updated.MaxClaudeVersion = value
+		case field == "api_keys":
+			updated.APIKeys = nil
+			for _, key := range strings.Split(value, ",") {
+				if key = strings.TrimSpace(key); key != "" {
+					updated.APIKeys = append(updated.APIKeys, key)
+				}
+			}
+		case field == "key_strategy":
+			updated.KeyStrategy = strings.ToLower(value)
+		case field == "tags":
+			updated.Tags = parseTags(value)
+		case field == "trust_args":
+			trust := false
+			if value != "" {
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return Environment{}, fmt.Errorf("invalid trust_args: '%s' is not true or false", value)
+				}
+				trust = b
+			}
+			updated.TrustArgs = trust
+		case field == "check_retries":
+			retries := 0
+			if value != "" {
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					return Environment{}, fmt.Errorf("invalid check_retries: '%s' is not a number", value)
+				}
+				retries = n
+			}
+			updated.CheckRetries = retries
+		case field == "api_key":
+			if value == "" {
+				// Only cloud providers may run without a key; the final validation enforces that
+				updated.APIKey = ""
+				clearUnvalidated(&updated, fieldAPIKey)
+				continue
+			}
+			clearUnvalidated(&updated, fieldAPIKey)
+			skipped, err := checkFieldEscapable(fieldAPIKey, value, validateAPIKey, noValidate)
+			if err != nil {
+				return Environment{}, fmt.Errorf("invalid API key: %w", err)
+			}
+			if skipped != nil {
+				markUnvalidated(&updated, fieldAPIKey)
+			}
+			updated.APIKey = value
+		case field == "api_key_env" || field == "key_var":
+			updated.APIKeyEnv = strings.ToUpper(value)
+			updated.AuthScheme = ""
+			if value != "" {
+				updated.AuthScheme = authSchemeForKeyVar(updated.APIKeyEnv)
+			}
+		case field == "auth_scheme":
+			updated.AuthScheme = strings.ToLower(value)
+			updated.APIKeyEnv = authSchemeKeyVars[updated.AuthScheme]
+		case strings.HasPrefix(field, "env."):
+			name := strings.ToUpper(field[len("env."):])
+			if !isValidEnvVarName(name) {
+				return Environment{}, fmt.Errorf("invalid variable name '%s'", name)
+			}
+			if err := validateEnvVarValue(value); err != nil {
+				return Environment{}, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			if value == "" {
+				delete(updated.EnvVars, name)
+			} else {
+				updated.EnvVars[name] = value
+			}
+		case strings.HasPrefix(field, "header."):
+			// Header names are case-insensitive; field names arrive lowercased, so an
+			// existing header keeps its spelling
+			name := field[len("header."):]
+			for existing := range updated.Headers {
+				if strings.EqualFold(existing, name) {
+					name = existing
+				}
+			}
+			if err := validateHeaderName(name); err != nil {
+				return Environment{}, fmt.Errorf("invalid header: %w", err)
+			}
+			if value == "" {
+				delete(updated.Headers, name)
+				continue
+			}
+			if err := validateHeaderValue(value); err != nil {
+				return Environment{}, fmt.Errorf("invalid header %s: %w", name, err)
+			}
+			updated.Headers[name] = value
+		default:
+			return Environment{}, fmt.Errorf("unknown field '%s' (expected url, api_key, model, key_var, auth_scheme, notes, settings_dir, ca_cert, provider, region, project, check_timeout, check_retries, min_claude_version, max_claude_version, trust_args, api_keys, key_strategy, tags, env.NAME, or header.NAME)", field)
+		}
+	}
+
+	if len(updated.EnvVars) == 0 {
+		updated.EnvVars = nil
+	}
+	if len(updated.Headers) == 0 {
+		updated.Headers = nil
+	}
+
+	// Stored keys may predate the length minimum; only a new key is held to it (above)
+	if err := validateStoredEnvironment(updated); err != nil {
+		return Environment{}, err
+	}
+	return updated, nil
+}
+
+// runSet updates individual fields of an environment without re-entering the others.
+// api_key=- prompts for the key (or reads it from piped stdin) so it never hits shell history.
+func runSet(args []string) error {
+	noValidate, noNetworkCheck := false, false
+	for len(args) > 0 && (args[0] == "--no-validate" || args[0] == "--no-network-check") {
+		noValidate = noValidate || args[0] == "--no-validate"
+		noNetworkCheck = noNetworkCheck || args[0] == "--no-network-check"
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("argument parsing failed: set requires an environment name and at least one field=value")
+	}
+	name := args[0]
+
+	updates, order, err := parseFieldUpdates(args[1:])
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	if key, ok := updates["api_key"]; ok {
+		if key != "-" {
+			return fmt.Errorf("argument parsing failed: use api_key=- to enter the key without exposing it in shell history")
+		}
+		if noPrompt && stdinIsTerminal() {
+			return promptRefused("api_key=-", "pipe the key on stdin")
+		}
+		if updates["api_key"], err = secretReader(fmt.Sprintf("New API key for '%s' (hidden): ", name)); err != nil {
+			return fmt.Errorf("failed to read API key: %w", err)
+		}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	index, err := lookupEnvironment(config, name)
+	if err != nil {
+		return err
+	}
+
+	updated, err := applyFieldUpdatesWithOptions(config.Environments[index], updates, order, noValidate)
+	if err != nil {
+		return fmt.Errorf("failed to update environment '%s': %w", name, err)
+	}
+	config.Environments[index] = updated
+	if warning := unvalidatedWarning(updated); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+	if warning := keyStyleWarning(updated); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+	// The key shape depends on the endpoint, so a new URL is checked against the kept key too
+	if changesKeyShape(updates) {
+		if keyFormatMode == keyFormatStrict {
+			if err := validateAPIKeyForProvider(updated); err != nil {
+				return fmt.Errorf("failed to update environment '%s': invalid API key: %w", name, err)
+			}
+		} else if warning := keyFormatWarning(updated); warning != "" {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+	}
+	// A new URL is probed like one entered in add
+	if _, ok := updates["url"]; ok && updated.URL != "" && !noNetworkCheck {
+		if _, err := reportReachability(updated); err != nil {
+			return err
+		}
+	}
+
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if _, err := fmt.Printf("Environment '%s' updated: %s\n", name, strings.Join(order, ", ")); err != nil {
+		return fmt.Errorf("failed to display success message: %w", err)
+	}
+	return nil
+}
+
 // runRemove removes an environment configuration
 func runRemove(name string) error {
 	// Validate name parameter
@@ -870,3 +2930,279 @@ func runRemove(name string) error {
 
 	return nil
 }
+
+// assumeYes answers every confirmation with yes; set by the global --yes/-y flag or CCE_ASSUME_YES=1
+var assumeYes bool
+
+// assumeYesFromEnv reports whether CCE_ASSUME_YES asks for automatic confirmation
+func assumeYesFromEnv() bool {
+	value := os.Getenv("CCE_ASSUME_YES")
+	return value == "1" || strings.EqualFold(value, "true") || strings.EqualFold(value, "yes")
+}
+
+// noPrompt makes anything that would wait for typed input fail instead; set by the global
+// --no-prompt flag or CCE_NO_PROMPT=1, for scripts that must never block
+var noPrompt bool
+
+// noPromptFromEnv reports whether CCE_NO_PROMPT forbids interactive input
+func noPromptFromEnv() bool {
+	value := os.Getenv("CCE_NO_PROMPT")
+	return value == "1" || strings.EqualFold(value, "true") || strings.EqualFold(value, "yes")
+}
+
+// workDir is the directory set by --chdir: git and worktree operations and relative
+// project files (--env @file, --env-file) act from it instead of the process's own
+// directory. Empty means the current directory. claude itself is not moved.
+var workDir string
+
+// resolveChdir validates a --chdir value and returns it as an absolute path
+func resolveChdir(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	expanded, err := expandSettingsDir(path)
+	if err != nil {
+		return "", fmt.Errorf("argument validation failed: --chdir %s: %w", path, err)
+	}
+	abs, err := filepath.Abs(expanded)
+	if err != nil {
+		return "", fmt.Errorf("argument validation failed: --chdir %s: %w", path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("argument validation failed: --chdir %s: no such directory", path)
+		}
+		return "", fmt.Errorf("argument validation failed: --chdir %s: cannot access it", path)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("argument validation failed: --chdir %s: not a directory", path)
+	}
+	return abs, nil
+}
+
+// workPath resolves a relative path against workDir
+func workPath(path string) string {
+	if workDir == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(workDir, path)
+}
+
+// promptRefused is the error for input --no-prompt forbids; instead says what to pass
+func promptRefused(what, instead string) error {
+	return fmt.Errorf("argument validation failed: %s needs interactive input, which --no-prompt disables; %s", what, instead)
+}
+
+// checkSelectionPrompt refuses the interactive selector under --no-prompt. A single
+// environment is picked without asking, so it is still allowed.
+func checkSelectionPrompt(config Config) error {
+	if noPrompt && len(config.Environments) > 1 {
+		return promptRefused("choosing an environment", "pass --env <name>")
+	}
+	return nil
+}
+
+// noteAutoConfirmed records on stderr what --yes agreed to, so unattended runs leave a trail
+func noteAutoConfirmed(what string) {
+	fmt.Fprintln(os.Stderr, formatWarningMessage("Auto-confirmed (--yes): "+what, false))
+}
+
+// confirmationReader reads one typed confirmation line; tests replace it to avoid stdin
+var confirmationReader = func(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// runRemoveAll deletes every environment after a typed confirmation, or --yes when
+// there is no terminal to confirm on. The existing file is backed up before saving.
+func runRemoveAll(assumeYes bool) error {
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+
+	count := len(config.Environments)
+	if count == 0 {
+		fmt.Println("No environments configured; nothing to remove.")
+		return nil
+	}
+
+	fmt.Printf("This will remove all %d environment(s):\n", count)
+	for _, env := range config.Environments {
+		fmt.Printf("  %s (%s)\n", env.Name, env.URL)
+	}
+
+	if assumeYes {
+		noteAutoConfirmed(fmt.Sprintf("removing all %d environment(s)", count))
+	} else {
+		if noPrompt {
+			return promptRefused("remove --all", "pass --yes to confirm")
+		}
+		if !stdinIsTerminal() {
+			errorCtx := newErrorContext("remove all", "remove command")
+			errorCtx.addSuggestion("Re-run with --yes to confirm without a terminal")
+			return errorCtx.formatError(fmt.Errorf("argument validation failed: remove --all needs confirmation"))
+		}
+		answer, err := confirmationReader(fmt.Sprintf("Type 'yes' or %d to confirm: ", count))
+		if err != nil {
+			return err
+		}
+		if answer != "yes" && answer != strconv.Itoa(count) {
+			return fmt.Errorf("remove cancelled: confirmation did not match; nothing was removed")
+		}
+	}
+
+	// saveConfig only warns when its backup fails; a full wipe must not proceed without one
+	if err := configStore.Backup(); err != nil {
+		return fmt.Errorf("remove cancelled: could not back up configuration: %w", err)
+	}
+
+	config.Environments = []Environment{}
+	if err := configStore.Save(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if _, err := fmt.Printf("Removed %d environment(s).\n", count); err != nil {
+		return fmt.Errorf("failed to display success message: %w", err)
+	}
+	return nil
+}
+
+// runConfigCommand dispatches `cce config <action>` subcommands
+func runConfigCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("argument parsing failed: config command requires an action (migrate, set-setting, get-setting)")
+	}
+
+	switch args[0] {
+	case "migrate":
+		return runConfigMigrate(args[1:])
+	case "set-setting":
+		return runConfigSetSetting(args[1:])
+	case "get-setting":
+		return runConfigGetSetting(args[1:])
+	default:
+		return fmt.Errorf("argument parsing failed: unknown config action '%s'", args[0])
+	}
+}
+
+// runConfigMigrate converts a legacy configuration file into the canonical format
+func runConfigMigrate(args []string) error {
+	dryRun := false
+	for _, arg := range args {
+		switch arg {
+		case "--dry-run":
+			dryRun = true
+		default:
+			return fmt.Errorf("argument parsing failed: unknown config migrate flag: %s", arg)
+		}
+	}
+
+	format, config, err := migrateConfig(dryRun)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case format == configFormatCanonical:
+		_, err = fmt.Println("Configuration already uses the canonical format; nothing to migrate.")
+	case dryRun:
+		_, err = fmt.Printf("Would migrate %d environment(s) from %s format to canonical format.\n", len(config.Environments), format)
+	default:
+		_, err = fmt.Printf("Migrated %d environment(s) from %s format to canonical format.\n", len(config.Environments), format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to display migration result: %w", err)
+	}
+
+	return nil
+}
+
+// initOptions holds flags accepted by the init subcommand
+type initOptions struct {
+	Force    bool // Overwrite an existing configuration (after backing it up)
+	Template bool // Write an annotated template instead of prompting
+}
+
+// parseInitOptions parses flags following the init subcommand
+func parseInitOptions(args []string) (initOptions, error) {
+	var opts initOptions
+	for _, arg := range args {
+		switch arg {
+		case "--force", "-f":
+			opts.Force = true
+		case "--template":
+			opts.Template = true
+		default:
+			return initOptions{}, fmt.Errorf("unknown init flag: %s", arg)
+		}
+	}
+	return opts, nil
+}
+
+// runInit creates a first configuration, either interactively or from an annotated template
+func runInit(opts initOptions) error {
+	if noPrompt && !opts.Template {
+		return promptRefused("cce init", "write an annotated template with 'cce init --template'")
+	}
+
+	configPath, err := getConfigPath()
+	if err != nil {
+		return fmt.Errorf("configuration initialization failed: %w", err)
+	}
+
+	if info, statErr := os.Stat(configPath); statErr == nil && info.Size() > 0 {
+		if !opts.Force {
+			errorCtx := newErrorContext("configuration initialization", "init command")
+			errorCtx.addContext("path", configPath)
+			errorCtx.addSuggestion("Use 'cce add' to add another environment")
+			errorCtx.addSuggestion("Re-run with --force to overwrite (the existing file is backed up first)")
+			return errorCtx.formatError(fmt.Errorf("configuration already exists"))
+		}
+	}
+
+	if err := ensureConfigDir(); err != nil {
+		return fmt.Errorf("configuration initialization failed: %w", err)
+	}
+
+	if opts.Template {
+		// saveConfig backs up on its own; the template is written directly so back up here
+		if backupPath, backupErr := newConfigBackup(configPath).createBackup(); backupErr != nil {
+			return fmt.Errorf("configuration initialization failed: could not back up existing file: %w", backupErr)
+		} else if backupPath != "" {
+			fmt.Printf("Existing configuration backed up to: %s\n", backupPath)
+		}
+		if err := writeConfigTemplate(configPath); err != nil {
+			return fmt.Errorf("configuration initialization failed: %w", err)
+		}
+		fmt.Printf("Wrote configuration template to %s\n", configPath)
+		fmt.Println("Edit the file, or run 'cce add' to create your first environment.")
+		return nil
+	}
+
+	fmt.Println("Welcome to cce! Let's create your first environment.")
+	fmt.Println("  Name:     a short label such as 'prod' or 'work' (letters, digits, - and _)")
+	fmt.Println("  Base URL: the API endpoint, e.g. https://api.anthropic.com")
+	fmt.Println("  API key:  input is hidden and stored with 0600 permissions")
+	fmt.Println("  Model:    optional; leave blank to use Claude Code's default")
+	fmt.Println()
+
+	env, err := environmentPrompter(Config{Environments: []Environment{}})
+	if err != nil {
+		return fmt.Errorf("environment input failed: %w", err)
+	}
+
+	config := Config{Environments: []Environment{env}}
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	fmt.Printf("Configuration created at %s\n", configPath)
+	fmt.Printf("Run 'cce' or 'cce --env %s' to launch Claude Code.\n", env.Name)
+	return nil
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -48,3 +50,37 @@ func TestMainNonErrorPaths(t *testing.T) {
 	// main should exit normally without panicking or calling os.Exit on success path.
 	main()
 }
+
+func TestRunVersionJSON(t *testing.T) {
+	origCommit, origDate := BuildCommit, BuildDate
+	BuildCommit, BuildDate = "abc1234", "2024-01-02T03:04:05Z"
+	defer func() { BuildCommit, BuildDate = origCommit, origDate }()
+
+	var runErr error
+	out := captureStdout(t, func() { runErr = runVersion([]string{"--json"}) })
+	if runErr != nil {
+		t.Fatalf("runVersion(--json) failed: %v", runErr)
+	}
+
+	var info versionInfo
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatalf("version --json is not valid JSON: %v\n%s", err, out)
+	}
+	if info.Version != Version || info.Commit != "abc1234" || info.Date != "2024-01-02T03:04:05Z" {
+		t.Errorf("unexpected build metadata: %+v", info)
+	}
+	if info.GoVersion != runtime.Version() || info.OS != runtime.GOOS || info.Arch != runtime.GOARCH {
+		t.Errorf("unexpected runtime metadata: %+v", info)
+	}
+
+	if err := runVersion([]string{"--bogus"}); err == nil {
+		t.Error("expected unknown flag to be rejected")
+	}
+}
+
+func TestParseArgumentsVersionJSON(t *testing.T) {
+	result := parseArguments([]string{"version", "--json"})
+	if result.Subcommand != "version" || len(result.SubcommandArgs) != 1 || result.SubcommandArgs[0] != "--json" {
+		t.Errorf("unexpected parse result: %+v", result)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// withCachedModels enables the availability check and caches models for url
+func withCachedModels(t *testing.T, url string, models ...string) {
+	t.Helper()
+	withTempConfigPath(t)
+	original := modelAvailabilityCheck
+	t.Cleanup(func() { modelAvailabilityCheck = original })
+	modelAvailabilityCheck = true
+
+	cache := modelCache{Endpoints: map[string]modelCacheEntry{
+		modelCacheKey(url): {Models: models, FetchedAt: time.Now().Add(-time.Hour)},
+	}}
+	if err := saveModelCache(cache); err != nil {
+		t.Fatalf("saveModelCache() failed: %v", err)
+	}
+}
+
+func TestUnavailableModelWarningSuggestsClosest(t *testing.T) {
+	withCachedModels(t, "https://proxy.example.com", "claude-3-5-haiku-20241022", "claude-sonnet-4-20250514")
+	env := Environment{Name: "proxy", URL: "https://proxy.example.com/", Model: "claude-sonnet-4-20250515"}
+
+	warning := unavailableModelWarning(env)
+	for _, want := range []string{"'claude-sonnet-4-20250515' is not in the model list", "did you mean claude-sonnet-4-20250514?", "cce models --refresh proxy"} {
+		if !strings.Contains(warning, want) {
+			t.Errorf("warning should contain %q, got %q", want, warning)
+		}
+	}
+
+	env.Model = "claude-sonnet-4-20250514"
+	if warning := unavailableModelWarning(env); warning != "" {
+		t.Errorf("listed model should not warn, got %q", warning)
+	}
+}
+
+func TestUnavailableModelWarningListsModelsWhenNothingIsClose(t *testing.T) {
+	withCachedModels(t, "https://proxy.example.com", "a-model", "b-model", "c-model", "d-model")
+	env := Environment{Name: "proxy", URL: "https://proxy.example.com", Model: "claude-opus-4-20250514"}
+
+	if warning := unavailableModelWarning(env); !strings.Contains(warning, "it lists a-model, b-model, c-model and 1 more") {
+		t.Errorf("expected the available models to be listed, got %q", warning)
+	}
+}
+
+func TestUnavailableModelWarningSkips(t *testing.T) {
+	withCachedModels(t, "https://proxy.example.com", "claude-3-5-haiku-20241022")
+	env := Environment{Name: "proxy", URL: "https://proxy.example.com", Model: "claude-sonnet-4-20250514"}
+
+	modelAvailabilityCheck = false
+	if warning := unavailableModelWarning(env); warning != "" {
+		t.Errorf("check is off by default, got %q", warning)
+	}
+	modelAvailabilityCheck = true
+
+	if warning := unavailableModelWarning(Environment{Name: "other", URL: "https://uncached.example.com", Model: env.Model}); warning != "" {
+		t.Errorf("endpoints without a cache should not warn, got %q", warning)
+	}
+	if warning := unavailableModelWarning(Environment{Name: "proxy", URL: env.URL}); warning != "" {
+		t.Errorf("claude's default model should not be checked, got %q", warning)
+	}
+}
+
+func TestCheckModelAvailabilitySetting(t *testing.T) {
+	original := modelAvailabilityCheck
+	t.Cleanup(func() { modelAvailabilityCheck = original })
+
+	if err := applyModelSettings(&ConfigSettings{CheckModelAvailability: true}); err != nil || !modelAvailabilityCheck {
+		t.Errorf("setting should enable the check: %v", err)
+	}
+	if err := applyModelSettings(nil); err != nil || modelAvailabilityCheck {
+		t.Errorf("check should be off without settings: %v", err)
+	}
+}
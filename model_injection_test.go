@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestValidateModelRejectsEncodedAndInvisibleControls(t *testing.T) {
+	rejected := []string{
+		"claude-3%0a",
+		"claude%0D%0AINJECTED=1",
+		"claude%250a",       // double-encoded newline
+		"claude%25250a",     // triple-encoded newline
+		"claude\\nINJECTED", // backslash escape
+		"claude\u200b-3",    // zero-width space
+		"claude\u202e-3",    // right-to-left override
+		"claude\ufeff",      // byte order mark
+		"claude\u2028x",     // line separator
+		"claude\u0085x",     // C1 next line
+		"claude\xff",        // invalid UTF-8
+	}
+	for _, model := range rejected {
+		if err := validateModel(model); err == nil {
+			t.Errorf("validateModel(%q) should be rejected", model)
+		}
+	}
+
+	accepted := []string{
+		"claude-3-5-sonnet-20241022",
+		"anthropic/claude-3.5-sonnet",
+		"us.anthropic.claude-3-5-sonnet-20241022-v2:0",
+		"claude-opus-4@20250514",
+		"100%-model", // a stray percent that decodes to nothing harmful
+	}
+	for _, model := range accepted {
+		if err := validateModel(model); err != nil {
+			t.Errorf("validateModel(%q) should be accepted: %v", model, err)
+		}
+	}
+}
+
+func FuzzModelCannotInjectControlCharacters(f *testing.F) {
+	for _, seed := range []string{"claude-3-5-sonnet-20241022", "a%0ab", "x\u200by", "m\\r", "%25%30%61", "模型"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, model string) {
+		if validateModel(model) != nil {
+			return
+		}
+
+		env := Environment{Name: "fuzz", URL: "https://api.example.com", APIKey: "sk-ant-fuzz-key-123", Model: model}
+		vars, err := prepareEnvironment(env)
+		if err != nil {
+			return
+		}
+		for _, kv := range vars {
+			if !strings.HasPrefix(kv, "ANTHROPIC_MODEL=") {
+				continue
+			}
+			for _, r := range kv {
+				if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) || unicode.In(r, unicode.Zl, unicode.Zp) {
+					t.Fatalf("control character %U reached the environment via model %q", r, model)
+				}
+			}
+		}
+	})
+}
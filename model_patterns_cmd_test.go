@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModelPatternsListsSources(t *testing.T) {
+	withTempConfigPath(t)
+	file := filepath.Join(t.TempDir(), "patterns.txt")
+	if err := ioutil.WriteFile(file, []byte("# team models\n^acme-file-.*$\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CCE_MODEL_PATTERNS", "^acme-env-.*$")
+	t.Setenv("CCE_MODEL_PATTERNS_FILE", file)
+	t.Setenv("CCE_MODEL_STRICT", "")
+	if err := saveConfig(Config{Settings: &ConfigSettings{Validation: &ValidationSettings{
+		StrictValidation: true,
+		ModelPatterns:    []string{"^acme-config-.*$", "(unclosed"},
+	}}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := runModels([]string{"patterns"}); err != nil {
+			t.Errorf("models patterns failed: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"Strict mode: on",
+		"settings.validation.strict_validation",
+		"builtin  ^claude-3-5-sonnet-[0-9]{8}$",
+		"env      ^acme-env-.*$  (CCE_MODEL_PATTERNS)",
+		"file     ^acme-file-.*$  (" + file + ":2)",
+		"config   ^acme-config-.*$",
+		"(unclosed  (settings.validation.model_patterns)  [invalid, never matches",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestModelPatternsTest(t *testing.T) {
+	withTempConfigPath(t)
+	t.Setenv("CCE_MODEL_PATTERNS", "^acme-.*$")
+	t.Setenv("CCE_MODEL_PATTERNS_FILE", "")
+	t.Setenv("CCE_MODEL_STRICT", "")
+
+	out := captureStdout(t, func() {
+		if err := runModels([]string{"patterns", "--test", "acme-large-1"}); err != nil {
+			t.Errorf("expected acme-large-1 to be accepted: %v", err)
+		}
+	})
+	if !strings.Contains(out, "accepted, matches pattern") || !strings.Contains(out, "^acme-.*$ (env, CCE_MODEL_PATTERNS)") {
+		t.Errorf("expected the matching env pattern, got:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		err := runModels([]string{"patterns", "--test=claude-sonet-4-20250514"})
+		if err == nil || !strings.Contains(err.Error(), "rejected") {
+			t.Errorf("expected a rejection error, got %v", err)
+		}
+	})
+	if !strings.Contains(out, "strict mode is on") || !strings.Contains(out, "Did you mean claude-sonnet-4-20250514?") {
+		t.Errorf("expected a strict rejection with a suggestion, got:\n%s", out)
+	}
+
+	// Permissive mode lets unknown claude-* models through but still needs the prefix
+	t.Setenv("CCE_MODEL_STRICT", "false")
+	mv := newModelValidator()
+	var sb strings.Builder
+	if accepted, err := renderModelPatternTest(&sb, mv, "claude-custom"); err != nil || !accepted {
+		t.Errorf("expected claude-custom to be accepted in permissive mode: %v\n%s", err, sb.String())
+	}
+	if accepted, _ := renderModelPatternTest(&sb, mv, "gpt-4"); accepted {
+		t.Error("expected gpt-4 to be rejected even in permissive mode")
+	}
+	if !strings.Contains(sb.String(), "from CCE_MODEL_STRICT") {
+		t.Errorf("expected the strict mode source, got:\n%s", sb.String())
+	}
+
+	if _, err := parseModelPatternsOptions([]string{"--test"}); err == nil {
+		t.Error("expected --test without a model to fail")
+	}
+}
+
+func TestModelPatternsAddAndRemove(t *testing.T) {
+	withTempConfigPath(t)
+	t.Setenv("CCE_MODEL_PATTERNS", "^acme-env-.*$")
+	t.Setenv("CCE_MODEL_PATTERNS_FILE", "")
+	t.Setenv("CCE_MODEL_STRICT", "")
+
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"models", "add-pattern", "^acme-large-[0-9]+$"}); err != nil {
+			t.Fatalf("add-pattern failed: %v", err)
+		}
+		if err := handleCommand([]string{"models", "add-pattern", "acme"}); err != nil {
+			t.Fatalf("add-pattern failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "strict mode stays on") || !strings.Contains(out, "not anchored") {
+		t.Errorf("unexpected add output:\n%s", out)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	validation := config.Settings.Validation
+	if !validation.StrictValidation || strings.Join(validation.ModelPatterns, " ") != "^acme-large-[0-9]+$ acme" {
+		t.Fatalf("unexpected validation settings: %+v", validation)
+	}
+	if err := newModelValidatorWithConfig(config).validateModelAdaptive("acme-large-1"); err != nil {
+		t.Errorf("the added pattern should accept acme-large-1: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"models", "add-pattern", "(unclosed"},
+		{"models", "add-pattern", "^acme-large-[0-9]+$"},
+		{"models", "add-pattern", "^claude-3-5-sonnet-[0-9]{8}$"},
+		{"models", "add-pattern"},
+		{"models", "remove-pattern", "^claude-3-5-sonnet-[0-9]{8}$"},
+		{"models", "remove-pattern", "^acme-env-.*$"},
+		{"models", "remove-pattern", "^never-added$"},
+	} {
+		if err := handleCommand(args); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+
+	out = captureStdout(t, func() {
+		if err := handleCommand([]string{"models", "remove-pattern", "acme"}); err != nil {
+			t.Fatalf("remove-pattern failed: %v", err)
+		}
+		if err := handleCommand([]string{"models", "list-patterns"}); err != nil {
+			t.Fatalf("list-patterns failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Removed model pattern acme") || !strings.Contains(out, "config   ^acme-large-[0-9]+$") || strings.Contains(out, "config   acme\n") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func containsPattern(mv *modelValidator, pattern string) bool {
+	for _, p := range mv.patterns {
+		if p == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func TestModelPatternsFileFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.txt")
+	content := "# internal gateway models\n^gw-[a-z]+$\n\n  ^team-model-[0-9]+$  \n([unclosed\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CCE_MODEL_PATTERNS_FILE", path)
+
+	var mv *modelValidator
+	stderr := captureStderr(t, func() { mv = newModelValidator() })
+
+	for _, want := range []string{"^gw-[a-z]+$", "^team-model-[0-9]+$"} {
+		if !containsPattern(mv, want) {
+			t.Errorf("pattern %q not loaded", want)
+		}
+	}
+	if containsPattern(mv, "([unclosed") || containsPattern(mv, "# internal gateway models") {
+		t.Error("invalid lines and comments must be skipped")
+	}
+	if !strings.Contains(stderr, path+":5: skipping invalid model pattern") {
+		t.Errorf("expected a warning naming the bad line, got %q", stderr)
+	}
+}
+
+func TestModelPatternsFileMissingOnlyWarns(t *testing.T) {
+	t.Setenv("CCE_MODEL_PATTERNS_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+
+	var mv *modelValidator
+	stderr := captureStderr(t, func() { mv = newModelValidator() })
+	if mv == nil || len(mv.patterns) == 0 {
+		t.Fatal("built-in patterns should survive a missing file")
+	}
+	if !strings.Contains(stderr, "ignoring model patterns file") {
+		t.Errorf("expected warning, got %q", stderr)
+	}
+}
+
+func TestModelPatternsFileFromSettingsIsConfigRelative(t *testing.T) {
+	t.Setenv("CCE_MODEL_PATTERNS_FILE", "")
+	configPath := withTempConfigPath(t)
+	if err := ioutil.WriteFile(filepath.Join(filepath.Dir(configPath), "models.txt"), []byte("^relative-[0-9]+$\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := Config{Settings: &ConfigSettings{Validation: &ValidationSettings{StrictValidation: true, ModelPatternsFile: "models.txt"}}}
+	mv := newModelValidatorWithConfig(config)
+	if !containsPattern(mv, "^relative-[0-9]+$") {
+		t.Errorf("settings patterns file not loaded relative to the config dir: %v", mv.patterns)
+	}
+}
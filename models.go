@@ -0,0 +1,587 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxModelListBytes bounds how much of a models response is read
+const maxModelListBytes = 1 << 20
+
+// fetchModels lists the model IDs an environment's endpoint serves
+func (nv *networkValidator) fetchModels(ctx context.Context, env Environment) ([]string, networkCheckResult, error) {
+	result := networkCheckResult{URL: modelsEndpoint(env.URL)}
+
+	ctx, cancel := context.WithTimeout(ctx, nv.policyFor(env).Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.URL, nil)
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := applyAuthHeaders(req, env); err != nil {
+		return nil, result, err
+	}
+	client, err := nv.clientFor(env)
+	if err != nil {
+		return nil, result, fmt.Errorf("invalid ca_cert: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Latency = time.Since(start)
+	if err != nil {
+		return nil, result, nv.formatFailure(env, result, fmt.Errorf("endpoint unreachable: %w", err))
+	}
+	defer resp.Body.Close()
+
+	result.Reachable = true
+	result.StatusCode = resp.StatusCode
+	result.Authenticated = resp.StatusCode >= 200 && resp.StatusCode < 300
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, result, nv.formatFailure(env, result, fmt.Errorf("authentication rejected (HTTP %d)", resp.StatusCode))
+	case !result.Authenticated:
+		return nil, result, nv.formatFailure(env, result, fmt.Errorf("unexpected response (HTTP %d)", resp.StatusCode))
+	}
+
+	var listing struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxModelListBytes))
+	if err != nil {
+		return nil, result, fmt.Errorf("failed to read model list: %w", err)
+	}
+	if err := json.Unmarshal(body, &listing); err != nil {
+		return nil, result, fmt.Errorf("failed to parse model list: %w", err)
+	}
+
+	models := make([]string, 0, len(listing.Data))
+	for _, m := range listing.Data {
+		// Endpoint data ends up in ANTHROPIC_MODEL suggestions, so hold it to the same rules
+		if m.ID != "" && validateModel(m.ID) == nil {
+			models = append(models, m.ID)
+		}
+	}
+	sort.Strings(models)
+	return models, result, nil
+}
+
+// modelCacheEntry is one endpoint's model list as last fetched
+type modelCacheEntry struct {
+	Models    []string  `json:"models"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// modelCache holds model lists keyed by normalized endpoint URL
+type modelCache struct {
+	Endpoints map[string]modelCacheEntry `json:"endpoints"`
+}
+
+// modelCacheKey normalizes a base URL so trailing slashes share one entry
+func modelCacheKey(baseURL string) string {
+	return strings.TrimRight(baseURL, "/")
+}
+
+// getModelCachePath stores the cache next to the config file
+func getModelCachePath() (string, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "models-cache.json"), nil
+}
+
+// loadModelCache reads the cache; a missing file yields an empty cache
+func loadModelCache() (modelCache, error) {
+	cache := modelCache{Endpoints: map[string]modelCacheEntry{}}
+
+	path, err := getModelCachePath()
+	if err != nil {
+		return cache, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	} else if err != nil {
+		return cache, fmt.Errorf("failed to read model cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return modelCache{Endpoints: map[string]modelCacheEntry{}}, fmt.Errorf("failed to parse model cache: %w", err)
+	}
+	if cache.Endpoints == nil {
+		cache.Endpoints = map[string]modelCacheEntry{}
+	}
+	return cache, nil
+}
+
+// saveModelCache writes the cache with the same permissions as the config
+func saveModelCache(cache modelCache) error {
+	if err := ensureConfigDir(); err != nil {
+		return err
+	}
+	path, err := getModelCachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode model cache: %w", err)
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// cachedModels returns the cached model list for an endpoint, for offline validation
+func cachedModels(baseURL string) (modelCacheEntry, bool) {
+	cache, err := loadModelCache()
+	if err != nil {
+		return modelCacheEntry{}, false
+	}
+	entry, ok := cache.Endpoints[modelCacheKey(baseURL)]
+	return entry, ok
+}
+
+// modelAvailabilityCheck is the active settings.check_model_availability
+var modelAvailabilityCheck bool
+
+// unavailableModelWarning returns a warning when the model a launch would use is missing
+// from the endpoint's cached model list, naming the closest models it does list. It is
+// empty when the check is off, nothing is cached, or claude picks its own default.
+func unavailableModelWarning(env Environment) string {
+	if !modelAvailabilityCheck || isCloudProvider(env) {
+		return ""
+	}
+	model := resolveModel(env, "").Model
+	if model == "" {
+		return ""
+	}
+	entry, ok := cachedModels(env.URL)
+	if !ok || len(entry.Models) == 0 {
+		return ""
+	}
+	for _, available := range entry.Models {
+		if available == model {
+			return ""
+		}
+	}
+
+	suggestions := nearestModels(strings.ToLower(model), entry.Models)
+	hint := "did you mean " + strings.Join(suggestions, " or ") + "?"
+	if len(suggestions) == 0 {
+		shown := entry.Models
+		if len(shown) > maxModelSuggestions {
+			shown = shown[:maxModelSuggestions]
+		}
+		hint = "it lists " + strings.Join(shown, ", ")
+		if len(entry.Models) > len(shown) {
+			hint += fmt.Sprintf(" and %d more", len(entry.Models)-len(shown))
+		}
+	}
+	return fmt.Sprintf("Warning: model '%s' is not in the model list cached for %s %s ago; %s (run 'cce models --refresh %s' if the list is stale)",
+		model, env.URL, time.Since(entry.FetchedAt).Round(time.Minute), hint, env.Name)
+}
+
+// modelsOptions holds flags accepted by the models subcommand
+type modelsOptions struct {
+	Refresh bool
+	Names   []string // Limit to these environments; empty means all
+}
+
+// parseModelsOptions parses models subcommand flags
+func parseModelsOptions(args []string) (modelsOptions, error) {
+	var opts modelsOptions
+	for _, arg := range args {
+		switch {
+		case arg == "--refresh":
+			opts.Refresh = true
+		case strings.HasPrefix(arg, "-"):
+			return opts, fmt.Errorf("unknown models flag '%s'", arg)
+		default:
+			opts.Names = append(opts.Names, arg)
+		}
+	}
+	return opts, nil
+}
+
+// selectNamedEnvironments resolves the named environments, or all of them
+func selectNamedEnvironments(config Config, names []string) ([]Environment, error) {
+	if len(names) == 0 {
+		return config.Environments, nil
+	}
+	envs := make([]Environment, 0, len(names))
+	for _, name := range names {
+		index, err := lookupEnvironment(config, name)
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, config.Environments[index])
+	}
+	return envs, nil
+}
+
+// runModels shows cached model lists, or refreshes them with --refresh
+func runModels(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "patterns", "list-patterns":
+			return runModelPatterns(args[1:])
+		case "add-pattern":
+			return runAddModelPattern(args[1:])
+		case "remove-pattern":
+			return runRemoveModelPattern(args[1:])
+		}
+	}
+	opts, err := parseModelsOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	envs, err := selectNamedEnvironments(config, opts.Names)
+	if err != nil {
+		return err
+	}
+	if len(envs) == 0 {
+		fmt.Println("No environments configured.")
+		return nil
+	}
+
+	if opts.Refresh {
+		ctx, stop := signalContext(context.Background())
+		defer stop()
+		return refreshModelCache(ctx, os.Stdout, newNetworkValidator(0), envs)
+	}
+	return displayCachedModels(os.Stdout, envs)
+}
+
+// refreshModelCache fetches each environment's model list and stores what succeeded.
+// Environments sharing an endpoint are fetched once.
+func refreshModelCache(ctx context.Context, w io.Writer, nv *networkValidator, envs []Environment) error {
+	cache, err := loadModelCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; starting a new cache\n", err)
+	}
+
+	refreshed := 0
+	var authFailures, otherFailures []string
+	fetched := map[string]bool{}
+
+	for _, env := range envs {
+		key := modelCacheKey(env.URL)
+		if fetched[key] {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		models, result, err := nv.fetchModels(ctx, env)
+		if err != nil {
+			if result.StatusCode == http.StatusUnauthorized || result.StatusCode == http.StatusForbidden {
+				authFailures = append(authFailures, env.Name)
+			} else {
+				otherFailures = append(otherFailures, env.Name)
+			}
+			fmt.Fprintf(w, "  %s: FAILED  %s\n", env.Name, firstLine(err.Error()))
+			continue
+		}
+
+		fetched[key] = true
+		cache.Endpoints[key] = modelCacheEntry{Models: models, FetchedAt: time.Now().UTC()}
+		refreshed++
+		fmt.Fprintf(w, "  %s: %d model(s)\n", env.Name, len(models))
+	}
+
+	if refreshed > 0 {
+		if err := saveModelCache(cache); err != nil {
+			return fmt.Errorf("failed to save model cache: %w", err)
+		}
+	}
+
+	fmt.Fprintf(w, "Refreshed %d endpoint(s)", refreshed)
+	if len(authFailures) > 0 {
+		fmt.Fprintf(w, "; auth failed for %s", strings.Join(authFailures, ", "))
+	}
+	if len(otherFailures) > 0 {
+		fmt.Fprintf(w, "; unreachable or invalid: %s", strings.Join(otherFailures, ", "))
+	}
+	fmt.Fprintln(w)
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("network check interrupted: model refresh cancelled")
+	}
+	if len(authFailures)+len(otherFailures) > 0 {
+		return fmt.Errorf("network check failed for %d environment(s)", len(authFailures)+len(otherFailures))
+	}
+	return nil
+}
+
+// displayCachedModels prints each environment's cached model list and its age
+func displayCachedModels(w io.Writer, envs []Environment) error {
+	cache, err := loadModelCache()
+	if err != nil {
+		return err
+	}
+
+	for _, env := range envs {
+		entry, ok := cache.Endpoints[modelCacheKey(env.URL)]
+		if !ok {
+			fmt.Fprintf(w, "%s: not cached (run 'cce models --refresh')\n", env.Name)
+			continue
+		}
+		age := time.Since(entry.FetchedAt).Round(time.Minute)
+		fmt.Fprintf(w, "%s: %d model(s), fetched %s ago\n", env.Name, len(entry.Models), age)
+		for _, model := range entry.Models {
+			fmt.Fprintf(w, "  %s\n", model)
+		}
+	}
+	return nil
+}
+
+// modelPatternsOptions holds flags accepted by `models patterns`
+type modelPatternsOptions struct {
+	Test string // Model to run through the patterns instead of listing them
+}
+
+// parseModelPatternsOptions parses `models patterns [--test <model>]`
+func parseModelPatternsOptions(args []string) (modelPatternsOptions, error) {
+	var opts modelPatternsOptions
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--test":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return opts, fmt.Errorf("--test flag requires a model")
+			}
+			i++
+			opts.Test = args[i]
+		case strings.HasPrefix(arg, "--test="):
+			opts.Test = strings.TrimPrefix(arg, "--test=")
+			if opts.Test == "" {
+				return opts, fmt.Errorf("--test flag requires a model")
+			}
+		default:
+			return opts, fmt.Errorf("unknown models patterns argument '%s'", arg)
+		}
+	}
+	return opts, nil
+}
+
+// runModelPatterns lists the model validator's active patterns with their sources, or
+// with --test reports which pattern a model matches and what the validator decides
+func runModelPatterns(args []string) error {
+	opts, err := parseModelPatternsOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	mv := newModelValidatorWithConfig(config)
+
+	if opts.Test != "" {
+		accepted, err := renderModelPatternTest(os.Stdout, mv, opts.Test)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return fmt.Errorf("model validation failed: '%s' is rejected", opts.Test)
+		}
+		return nil
+	}
+	return renderModelPatterns(os.Stdout, mv)
+}
+
+// singlePatternArg returns the one pattern argument of add-pattern or remove-pattern
+func singlePatternArg(command string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("models %s takes exactly one pattern (quote it so the shell leaves it alone)", command)
+	}
+	pattern := strings.TrimSpace(args[0])
+	if pattern == "" {
+		return "", fmt.Errorf("models %s requires a non-empty pattern", command)
+	}
+	return pattern, nil
+}
+
+// runAddModelPattern appends a pattern to settings.validation.model_patterns after
+// checking it compiles and is not already active
+func runAddModelPattern(args []string) error {
+	pattern, err := singlePatternArg("add-pattern", args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	mv := newModelValidatorWithConfig(config)
+	if err := mv.validatePattern(pattern); err != nil {
+		return fmt.Errorf("invalid model pattern '%s': %w", pattern, err)
+	}
+	for i, active := range mv.patterns {
+		if active == pattern {
+			return fmt.Errorf("model pattern '%s' is already active (%s)", pattern, mv.sourceOf(i).Kind)
+		}
+	}
+
+	if config.Settings == nil {
+		config.Settings = &ConfigSettings{}
+	}
+	created := config.Settings.Validation == nil
+	if created {
+		// A validation block without strict_validation would turn strict mode off
+		config.Settings.Validation = &ValidationSettings{StrictValidation: true}
+	}
+	config.Settings.Validation.ModelPatterns = append(config.Settings.Validation.ModelPatterns, pattern)
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	lines := []string{fmt.Sprintf("Added model pattern %s to settings.validation.model_patterns.", pattern)}
+	if created {
+		lines = append(lines, "Created settings.validation with strict_validation=true, so strict mode stays on.")
+	}
+	if !strings.HasPrefix(pattern, "^") || !strings.HasSuffix(pattern, "$") {
+		lines = append(lines, "Note: the pattern is not anchored, so it also matches models that merely contain it; use ^...$ to match whole names.")
+	}
+	for _, line := range lines {
+		if _, err := fmt.Println(line); err != nil {
+			return fmt.Errorf("failed to display result: %w", err)
+		}
+	}
+	return nil
+}
+
+// runRemoveModelPattern deletes a pattern from settings.validation.model_patterns; patterns
+// from other sources are explained rather than removed
+func runRemoveModelPattern(args []string) error {
+	pattern, err := singlePatternArg("remove-pattern", args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+
+	var configured []string
+	if config.Settings != nil && config.Settings.Validation != nil {
+		configured = config.Settings.Validation.ModelPatterns
+	}
+	for i, existing := range configured {
+		if existing != pattern {
+			continue
+		}
+		config.Settings.Validation.ModelPatterns = append(configured[:i:i], configured[i+1:]...)
+		if err := saveConfig(config); err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+		if _, err := fmt.Printf("Removed model pattern %s from settings.validation.model_patterns.\n", pattern); err != nil {
+			return fmt.Errorf("failed to display result: %w", err)
+		}
+		return nil
+	}
+
+	mv := newModelValidatorWithConfig(config)
+	for i, active := range mv.patterns {
+		if active != pattern {
+			continue
+		}
+		switch source := mv.sourceOf(i); source.Kind {
+		case patternSourceBuiltin:
+			return fmt.Errorf("model pattern '%s' is built in and cannot be removed", pattern)
+		default:
+			return fmt.Errorf("model pattern '%s' comes from %s (%s); change it there", pattern, source.Kind, source.Detail)
+		}
+	}
+	return fmt.Errorf("no configured model pattern '%s'; see 'cce models list-patterns'", pattern)
+}
+
+// sourceOf describes where pattern i came from
+func (mv *modelValidator) sourceOf(i int) patternSource {
+	if i < len(mv.sources) {
+		return mv.sources[i]
+	}
+	return patternSource{Kind: "unknown"}
+}
+
+// describeStrictMode renders the strict mode setting and what decided it
+func (mv *modelValidator) describeStrictMode() string {
+	mode := "off (unknown claude-* models are accepted with a warning)"
+	if mv.strictMode {
+		mode = "on (models matching no pattern are rejected)"
+	}
+	return fmt.Sprintf("Strict mode: %s, from %s", mode, mv.strictSource)
+}
+
+// renderModelPatterns prints the strict mode and every active pattern in match order
+func renderModelPatterns(w io.Writer, mv *modelValidator) error {
+	if _, err := fmt.Fprintf(w, "%s\n%d pattern(s), tried in order:\n", mv.describeStrictMode(), len(mv.patterns)); err != nil {
+		return fmt.Errorf("failed to display model patterns: %w", err)
+	}
+	number := len(fmt.Sprint(len(mv.patterns)))
+	for i, pattern := range mv.patterns {
+		source := mv.sourceOf(i)
+		line := fmt.Sprintf("  %*d  %s  %s", number, i+1, padToWidth(source.Kind, len(patternSourceConfig)+1), pattern)
+		if source.Detail != "" {
+			line += "  (" + source.Detail + ")"
+		}
+		if err := mv.validatePattern(pattern); err != nil {
+			line += "  [invalid, never matches: " + err.Error() + "]"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to display model patterns: %w", err)
+		}
+	}
+	return nil
+}
+
+// renderModelPatternTest explains how the validator treats model, mirroring
+// validateModelAdaptive, and reports whether it is accepted
+func renderModelPatternTest(w io.Writer, mv *modelValidator, model string) (bool, error) {
+	lines := []string{mv.describeStrictMode()}
+	accepted := true
+	if i := mv.matchModel(model); i >= 0 {
+		source := mv.sourceOf(i)
+		from := source.Kind
+		if source.Detail != "" {
+			from += ", " + source.Detail
+		}
+		lines = append(lines, fmt.Sprintf("%s: accepted, matches pattern %d %s (%s)", model, i+1, mv.patterns[i], from))
+	} else {
+		switch {
+		case mv.strictMode:
+			accepted = false
+			lines = append(lines, fmt.Sprintf("%s: rejected, no pattern matches and strict mode is on", model))
+			if suggestions, _ := suggestModels(model); len(suggestions) > 0 {
+				lines = append(lines, fmt.Sprintf("Did you mean %s?", strings.Join(suggestions, " or ")))
+			}
+		case strings.HasPrefix(model, "claude-") && len(model) > len("claude-"):
+			lines = append(lines, fmt.Sprintf("%s: accepted with a warning, no pattern matches but strict mode is off", model))
+		default:
+			accepted = false
+			lines = append(lines, fmt.Sprintf("%s: rejected, no pattern matches and models must start with 'claude-' even with strict mode off", model))
+		}
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return false, fmt.Errorf("failed to display model test: %w", err)
+		}
+	}
+	return accepted, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newModelsServer serves a fixed /v1/models listing, or the given status when it is not 200
+func newModelsServer(t *testing.T, status int, ids ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		parts := make([]string, len(ids))
+		for i, id := range ids {
+			parts[i] = `{"id": "` + id + `"}`
+		}
+		w.Write([]byte(`{"data": [` + strings.Join(parts, ",") + `]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchModelsFiltersUnsafeIDs(t *testing.T) {
+	server := newModelsServer(t, http.StatusOK, "claude-3-haiku-20240307", "claude-3-5-sonnet-20241022", "bad%0aid")
+	env := Environment{Name: "ok", URL: server.URL, APIKey: "sk-ant-models-key"}
+
+	models, _, err := newNetworkValidator(time.Second).fetchModels(context.Background(), env)
+	if err != nil {
+		t.Fatalf("fetchModels() failed: %v", err)
+	}
+	if len(models) != 2 || models[0] != "claude-3-5-sonnet-20241022" || models[1] != "claude-3-haiku-20240307" {
+		t.Errorf("unexpected models: %v", models)
+	}
+}
+
+func TestRefreshModelCache(t *testing.T) {
+	withTempConfigPath(t)
+
+	good := newModelsServer(t, http.StatusOK, "claude-3-haiku-20240307")
+	denied := newModelsServer(t, http.StatusUnauthorized)
+	envs := []Environment{
+		{Name: "good", URL: good.URL, APIKey: "sk-ant-good-key"},
+		{Name: "mirror", URL: good.URL + "/", APIKey: "sk-ant-good-key"},
+		{Name: "denied", URL: denied.URL, APIKey: "sk-ant-denied-key"},
+	}
+
+	var out bytes.Buffer
+	err := refreshModelCache(context.Background(), &out, newNetworkValidator(time.Second), envs)
+	if err == nil || !strings.Contains(err.Error(), "network check failed") {
+		t.Errorf("expected network failure for denied environment, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Refreshed 1 endpoint(s); auth failed for denied") {
+		t.Errorf("unexpected summary:\n%s", out.String())
+	}
+
+	entry, ok := cachedModels(good.URL + "/")
+	if !ok {
+		t.Fatal("expected cache entry for the good endpoint")
+	}
+	if len(entry.Models) != 1 || entry.Models[0] != "claude-3-haiku-20240307" {
+		t.Errorf("unexpected cached models: %v", entry.Models)
+	}
+	if time.Since(entry.FetchedAt) > time.Minute {
+		t.Errorf("unexpected fetch timestamp: %v", entry.FetchedAt)
+	}
+	if _, ok := cachedModels(denied.URL); ok {
+		t.Error("failed endpoint should not be cached")
+	}
+
+	var shown bytes.Buffer
+	if err := displayCachedModels(&shown, envs); err != nil {
+		t.Fatalf("displayCachedModels() failed: %v", err)
+	}
+	if !strings.Contains(shown.String(), "good: 1 model(s)") || !strings.Contains(shown.String(), "denied: not cached") {
+		t.Errorf("unexpected cached model display:\n%s", shown.String())
+	}
+}
+
+func TestParseModelsOptions(t *testing.T) {
+	opts, err := parseModelsOptions([]string{"--refresh", "prod", "dev"})
+	if err != nil {
+		t.Fatalf("parseModelsOptions() failed: %v", err)
+	}
+	if !opts.Refresh || len(opts.Names) != 2 {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+	if _, err := parseModelsOptions([]string{"--bogus"}); err == nil {
+		t.Error("expected unknown flag to be rejected")
+	}
+}
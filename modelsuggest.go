@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// knownModelExamples are model IDs the built-in patterns accept, used to suggest fixes
+var knownModelExamples = []string{
+	"claude-sonnet-4-20250514",
+	"claude-opus-4-20250514",
+	"claude-3-7-sonnet-20250219",
+	"claude-3-5-sonnet-20241022",
+	"claude-3-5-haiku-20241022",
+	"claude-3-opus-20240229",
+	"claude-3-haiku-20240307",
+}
+
+// maxModelSuggestions caps the "did you mean" list and the fallback examples
+const maxModelSuggestions = 3
+
+// modelDateSuffix matches the -YYYYMMDD snapshot date model IDs end with
+var modelDateSuffix = regexp.MustCompile(`-[0-9]{8}$`)
+
+// editDistance is the Levenshtein distance between a and b, counted in runes
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// suggestModels returns the known model IDs model was most likely meant to be. A model
+// that is an example minus its date suffix reports missingDate; anything else is matched
+// by edit distance, ignoring case.
+func suggestModels(model string) (suggestions []string, missingDate bool) {
+	lower := strings.ToLower(strings.TrimSpace(model))
+	if lower == "" {
+		return nil, false
+	}
+
+	if !modelDateSuffix.MatchString(lower) {
+		for _, example := range knownModelExamples {
+			if modelDateSuffix.ReplaceAllString(example, "") == strings.TrimSuffix(lower, "-") {
+				suggestions = append(suggestions, example)
+			}
+		}
+		if len(suggestions) > 0 {
+			return suggestions, true
+		}
+	}
+
+	return nearestModels(lower, knownModelExamples), false
+}
+
+// nearestModels returns the candidates closest to model by edit distance, at most
+// maxModelSuggestions of them and only those tied for the smallest distance
+func nearestModels(model string, candidates []string) []string {
+	type candidate struct {
+		model    string
+		distance int
+	}
+	// Allow roughly one edit per four characters, so short garbage gets no suggestions
+	limit := len(model)/4 + 1
+	var matches []candidate
+	for _, c := range candidates {
+		distance := editDistance(model, strings.ToLower(c))
+		if !modelDateSuffix.MatchString(model) {
+			// Compare without the date too, so "claude-3-5-sonet" still finds sonnet
+			distance = min(distance, editDistance(model, modelDateSuffix.ReplaceAllString(strings.ToLower(c), "")))
+		}
+		if distance <= limit {
+			matches = append(matches, candidate{c, distance})
+		}
+	}
+	// Only the closest matches are worth showing; a farther one is rarely what was meant
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+	var nearest []string
+	for i := 0; i < len(matches) && i < maxModelSuggestions && matches[i].distance == matches[0].distance; i++ {
+		nearest = append(nearest, matches[i].model)
+	}
+	return nearest
+}
+
+// modelFormatError explains a model rejected by strict validation, suggesting the closest
+// known IDs and how to accept custom names
+func modelFormatError(model string) error {
+	errorCtx := newErrorContext("model validation", "model validator")
+	errorCtx.addContext("model", model)
+
+	suggestions, missingDate := suggestModels(model)
+	if missingDate {
+		errorCtx.addSuggestion("Model IDs end with a snapshot date (-YYYYMMDD)")
+	}
+	if len(suggestions) > 0 {
+		errorCtx.addSuggestion(fmt.Sprintf("Did you mean %s?", strings.Join(suggestions, " or ")))
+	} else {
+		errorCtx.addSuggestion(fmt.Sprintf("Examples: %s", strings.Join(knownModelExamples[:maxModelSuggestions], ", ")))
+	}
+	errorCtx.addSuggestion("For a custom model name, add a pattern to settings.validation.model_patterns or set CCE_MODEL_STRICT=false")
+	return errorCtx.formatError(fmt.Errorf("invalid model format"))
+}
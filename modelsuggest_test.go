@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"sonnet", "sonnet", 0},
+		{"sonet", "sonnet", 1},
+		{"snonet", "sonnet", 2},
+		{"kitten", "sitting", 3},
+		{"", "opus", 4},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestModels(t *testing.T) {
+	tests := []struct {
+		model       string
+		want        string
+		missingDate bool
+	}{
+		{"claude-3-5-sonnet", "claude-3-5-sonnet-20241022", true},
+		{"claude-sonnet-4-", "claude-sonnet-4-20250514", true},
+		{"claude-3-5-sonet-20241022", "claude-3-5-sonnet-20241022", false},
+		{"Claude-3-Opus-20240229", "claude-3-opus-20240229", false},
+		{"claude-3-haiku-2024037", "claude-3-haiku-20240307", false},
+		{"claude-3-5-sonet", "claude-3-5-sonnet-20241022", false},
+	}
+	for _, tt := range tests {
+		got, missingDate := suggestModels(tt.model)
+		if len(got) == 0 || got[0] != tt.want || missingDate != tt.missingDate {
+			t.Errorf("suggestModels(%q) = %v, %v; want %s first, missingDate %v", tt.model, got, missingDate, tt.want, tt.missingDate)
+		}
+	}
+	if got, _ := suggestModels("gpt-4"); len(got) != 0 {
+		t.Errorf("unrelated models should get no suggestions, got %v", got)
+	}
+}
+
+func TestValidateModelAdaptiveSuggestsFix(t *testing.T) {
+	mv := newModelValidator()
+	mv.strictMode = true
+
+	err := mv.validateModelAdaptive("claude-sonet-4-20250514")
+	if err == nil {
+		t.Fatal("expected strict validation to reject a misspelled model")
+	}
+	for _, want := range []string{"invalid model format", "Did you mean claude-sonnet-4-20250514?", "model_patterns"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+
+	err = mv.validateModelAdaptive("claude-3-opus")
+	if err == nil || !strings.Contains(err.Error(), "snapshot date") || !strings.Contains(err.Error(), "claude-3-opus-20240229") {
+		t.Errorf("expected missing date guidance, got %v", err)
+	}
+
+	err = mv.validateModelAdaptive("gpt-4")
+	if err == nil || !strings.Contains(err.Error(), "Examples:") {
+		t.Errorf("expected examples when nothing is close, got %v", err)
+	}
+}
+
+func TestKnownModelExamplesPassStrictValidation(t *testing.T) {
+	mv := newModelValidator()
+	mv.strictMode = true
+	for _, model := range knownModelExamples {
+		if err := mv.validateModelAdaptive(model); err != nil {
+			t.Errorf("suggested model %s is rejected by the validator: %v", model, err)
+		}
+	}
+}
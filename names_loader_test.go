@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestLoadEnvironmentNamesStreamsFileConfig(t *testing.T) {
+	path := withTempConfigPath(t)
+
+	if names, err := loadEnvironmentNames(); err != nil || len(names) != 0 {
+		t.Fatalf("missing config should list nothing, got %v, %v", names, err)
+	}
+
+	config := `{"settings": {"strict_args": true}, "environments": [
+		{"name": "b", "url": "https://b.example.com", "api_key": "sk-ant-api03-bbbbbbbbbb", "env_vars": {"X": "1"}},
+		{"name": "a", "url": "https://a.example.com", "api_key": "sk-ant-api03-aaaaaaaaaa", "tags": ["x"]}
+	]}`
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	names, err := loadEnvironmentNames()
+	if err != nil || strings.Join(names, ",") != "b,a" {
+		t.Errorf("expected names in file order, got %v, %v", names, err)
+	}
+}
+
+func TestLoadEnvironmentNamesFallsBackToFullLoad(t *testing.T) {
+	path := withTempConfigPath(t)
+	for name, content := range map[string]string{
+		"invalid JSON":         `{"environments": [`,
+		"legacy list":          `[{"name": "a", "url": "https://a.example.com", "api_key": "sk-ant-api03-aaaaaaaaaa"}]`,
+		"missing environments": `{"settings": {}}`,
+	} {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		_, fastErr := loadEnvironmentNames()
+		_, fullErr := loadConfig()
+		if fastErr == nil || fullErr == nil || fastErr.Error() != fullErr.Error() {
+			t.Errorf("%s: expected the full load's error, got %v (full load: %v)", name, fastErr, fullErr)
+		}
+	}
+
+	if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if names, err := loadEnvironmentNames(); err != nil || len(names) != 0 {
+		t.Errorf("empty file should list nothing, got %v, %v", names, err)
+	}
+
+	// Stores other than the file use the full load
+	withMemoryStore(t, &memoryStore{config: &Config{Environments: []Environment{{Name: "mem", URL: "https://m.example.com", APIKey: "sk-ant-api03-mmmmmmmmmm"}}}})
+	if names, err := loadEnvironmentNames(); err != nil || strings.Join(names, ",") != "mem" {
+		t.Errorf("expected names from the memory store, got %v, %v", names, err)
+	}
+}
+
+// writeLargeConfig saves n environments with the fields a real config carries
+func writeLargeConfig(tb testing.TB, n int) {
+	tb.Helper()
+	config := Config{Environments: make([]Environment, n)}
+	for i := range config.Environments {
+		config.Environments[i] = Environment{
+			Name:    fmt.Sprintf("env-%04d", i),
+			URL:     fmt.Sprintf("https://gateway-%d.example.com/v1", i),
+			APIKey:  fmt.Sprintf("sk-ant-api03-%040d", i),
+			Model:   "claude-sonnet-4-20250514",
+			EnvVars: map[string]string{"ANTHROPIC_TIMEOUT": "30s", "HTTP_PROXY": "http://proxy.example.com:8080"},
+			Notes:   "team gateway",
+			Tags:    []string{"team", "gateway"},
+		}
+	}
+	if err := saveConfig(config); err != nil {
+		tb.Fatalf("saveConfig() failed: %v", err)
+	}
+}
+
+func BenchmarkLoadConfigLarge(b *testing.B) {
+	withTempConfigPath(b)
+	writeLargeConfig(b, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := loadConfig(); err != nil {
+			b.Fatalf("loadConfig() failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkLoadEnvironmentNamesLarge(b *testing.B) {
+	withTempConfigPath(b)
+	writeLargeConfig(b, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := loadEnvironmentNames(); err != nil {
+			b.Fatalf("loadEnvironmentNames() failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,574 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultNetworkTimeout bounds a single connectivity probe
+const defaultNetworkTimeout = 10 * time.Second
+
+// maxCheckTimeout and maxCheckRetries keep a misconfigured check from hanging a command
+const (
+	maxCheckTimeout = 5 * time.Minute
+	maxCheckRetries = 10
+)
+
+// checkRetryDelay is the pause before the first retry; it grows linearly per attempt
+var checkRetryDelay = 500 * time.Millisecond
+
+// checkPolicy is how long one probe attempt may take and how many times a transient failure is retried
+type checkPolicy struct {
+	Timeout time.Duration
+	Retries int
+}
+
+// networkDefaults is the policy for environments without their own; settings.network overrides it
+var networkDefaults = checkPolicy{Timeout: defaultNetworkTimeout}
+
+// parseCheckTimeout parses a check_timeout value; empty means "not set" and yields 0
+func parseCheckTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a duration (e.g. 15s, 1m)", value)
+	}
+	if timeout <= 0 || timeout > maxCheckTimeout {
+		return 0, fmt.Errorf("'%s' must be positive and at most %s", value, maxCheckTimeout)
+	}
+	return timeout, nil
+}
+
+// validateCheckRetries bounds check_retries
+func validateCheckRetries(retries int) error {
+	if retries < 0 || retries > maxCheckRetries {
+		return fmt.Errorf("%d must be between 0 and %d", retries, maxCheckRetries)
+	}
+	return nil
+}
+
+// applyNetworkSettings validates and activates settings.network
+func applyNetworkSettings(settings *ConfigSettings) error {
+	networkDefaults = checkPolicy{Timeout: defaultNetworkTimeout}
+	if settings == nil || settings.Network == nil {
+		return nil
+	}
+	timeout, err := parseCheckTimeout(settings.Network.CheckTimeout)
+	if err != nil {
+		return fmt.Errorf("check_timeout: %w", err)
+	}
+	if err := validateCheckRetries(settings.Network.CheckRetries); err != nil {
+		return fmt.Errorf("check_retries: %w", err)
+	}
+	if timeout > 0 {
+		networkDefaults.Timeout = timeout
+	}
+	networkDefaults.Retries = settings.Network.CheckRetries
+	return nil
+}
+
+// anthropicAPIVersion is sent with probes so Anthropic-compatible endpoints accept the request
+const anthropicAPIVersion = "2023-06-01"
+
+// networkCheckResult captures the outcome of a connectivity and auth probe
+type networkCheckResult struct {
+	URL           string
+	StatusCode    int
+	Latency       time.Duration
+	Reachable     bool
+	Authenticated bool
+	Attempts      int // Probes made, including retries
+}
+
+// networkValidator performs connectivity and authentication probes against environment endpoints
+type networkValidator struct {
+	timeout time.Duration // Overrides every environment's timeout when set
+	retries int           // Overrides every environment's retries when >= 0
+	client  *http.Client
+	// insecure skips TLS certificate verification; only checks set it, never launches
+	insecure bool
+
+	caMu      sync.Mutex
+	caClients map[string]*http.Client // client per ca_cert path, built on first use
+}
+
+// newNetworkValidator creates a validator. A positive timeout applies to every probe;
+// zero uses each environment's check_timeout, then settings, then the default.
+func newNetworkValidator(timeout time.Duration) *networkValidator {
+	// Attempts are bounded by a per-probe context so each environment can have its own timeout
+	return &networkValidator{timeout: timeout, retries: -1, client: &http.Client{}}
+}
+
+// withRetries overrides every environment's retry count
+func (nv *networkValidator) withRetries(retries int) *networkValidator {
+	nv.retries = retries
+	return nv
+}
+
+// policyFor resolves the check policy for env: validator overrides, then the
+// environment's check_timeout/check_retries, then settings defaults
+func (nv *networkValidator) policyFor(env Environment) checkPolicy {
+	policy := networkDefaults
+	if timeout, err := parseCheckTimeout(env.CheckTimeout); err == nil && timeout > 0 {
+		policy.Timeout = timeout
+	}
+	if env.CheckRetries > 0 {
+		policy.Retries = env.CheckRetries
+	}
+	if nv.timeout > 0 {
+		policy.Timeout = nv.timeout
+	}
+	if nv.retries >= 0 {
+		policy.Retries = nv.retries
+	}
+	return policy
+}
+
+// withInsecure makes every probe skip TLS certificate verification, for telling a TLS
+// trust problem apart from an auth one. Launches never do this.
+func (nv *networkValidator) withInsecure() *networkValidator {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	client := *nv.client
+	client.Transport = transport
+	nv.client = &client
+	nv.insecure = true
+	return nv
+}
+
+// insecureWarning is printed to stderr whenever checks run with --insecure
+const insecureWarning = "WARNING: --insecure: TLS certificates are NOT verified. A passing check only shows the endpoint is reachable and accepts the key; claude will still verify certificates."
+
+// clientFor returns the client for probing env: the shared one, or for an environment
+// with ca_cert one that also trusts its CA bundle, as claude will
+func (nv *networkValidator) clientFor(env Environment) (*http.Client, error) {
+	if env.CACert == "" || nv.insecure {
+		return nv.client, nil
+	}
+	nv.caMu.Lock()
+	defer nv.caMu.Unlock()
+	if client, ok := nv.caClients[env.CACert]; ok {
+		return client, nil
+	}
+	transport, err := caCertTransport(nv.client.Transport, env)
+	if err != nil {
+		return nil, err
+	}
+	client := *nv.client
+	client.Transport = transport
+	if nv.caClients == nil {
+		nv.caClients = make(map[string]*http.Client)
+	}
+	nv.caClients[env.CACert] = &client
+	return &client, nil
+}
+
+// modelsEndpoint returns the models listing URL for an environment base URL
+func modelsEndpoint(baseURL string) string {
+	return strings.TrimRight(baseURL, "/") + "/v1/models"
+}
+
+// applyAuthHeaders sets the key header matching the environment's auth scheme, reading
+// a keychain-stored key first
+func applyAuthHeaders(req *http.Request, env Environment) error {
+	key, err := resolveSecret(env.APIKey)
+	if err != nil {
+		return err
+	}
+	if resolveAPIKeyVar(env) == "ANTHROPIC_AUTH_TOKEN" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	} else {
+		req.Header.Set("x-api-key", key)
+	}
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+	// Gateways that require custom headers would otherwise reject the probe
+	for name, value := range env.Headers {
+		req.Header.Set(name, value)
+	}
+	return nil
+}
+
+// probe issues an authenticated GET against the environment's models endpoint
+func (nv *networkValidator) probe(ctx context.Context, env Environment) (networkCheckResult, error) {
+	result := networkCheckResult{URL: modelsEndpoint(env.URL)}
+
+	ctx, cancel := context.WithTimeout(ctx, nv.policyFor(env).Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.URL, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to build request: %w", err)
+	}
+	if err := applyAuthHeaders(req, env); err != nil {
+		return result, err
+	}
+	client, err := nv.clientFor(env)
+	if err != nil {
+		return result, fmt.Errorf("invalid ca_cert: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Latency = time.Since(start)
+	if err != nil {
+		return result, fmt.Errorf("endpoint unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result.Reachable = true
+	result.StatusCode = resp.StatusCode
+	result.Authenticated = resp.StatusCode >= 200 && resp.StatusCode < 300
+
+	return result, nil
+}
+
+// checkEnvironment probes the environment and fails when it is unreachable or rejects the key
+func (nv *networkValidator) checkEnvironment(env Environment) (networkCheckResult, error) {
+	return nv.checkEnvironmentContext(context.Background(), env)
+}
+
+// checkEnvironmentContext is checkEnvironment bounded by ctx, so callers can cancel in-flight probes.
+// Unreachable endpoints, 5xx and 429 responses are retried per the environment's policy;
+// a rejected key is not.
+func (nv *networkValidator) checkEnvironmentContext(ctx context.Context, env Environment) (networkCheckResult, error) {
+	if isCloudProvider(env) {
+		// Bedrock and Vertex sign requests with cloud credentials CCE never sees
+		return networkCheckResult{URL: env.URL}, fmt.Errorf("%s environments cannot be probed by cce; check them with the %s CLI", env.Provider, cloudCLI(env))
+	}
+	// A bad CA bundle fails the same way on every attempt, so it is not retried
+	if err := checkCACert(env); err != nil && !nv.insecure {
+		return networkCheckResult{URL: modelsEndpoint(env.URL)}, fmt.Errorf("network check failed: invalid ca_cert: %w", err)
+	}
+	policy := nv.policyFor(env)
+
+	var result networkCheckResult
+	var err error
+	for attempt := 0; ; attempt++ {
+		result, err = nv.checkOnce(ctx, env)
+		result.Attempts = attempt + 1
+		// A 429 that persists is still reported as reachable, as before retries existed
+		if !retryableResult(result) || attempt >= policy.Retries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(checkRetryDelay * time.Duration(attempt+1)):
+		}
+	}
+	return result, err
+}
+
+// checkOnce makes a single probe and classifies its outcome
+func (nv *networkValidator) checkOnce(ctx context.Context, env Environment) (networkCheckResult, error) {
+	result, err := nv.probe(ctx, env)
+	if err != nil {
+		return result, nv.formatFailure(env, result, err)
+	}
+
+	switch {
+	case result.StatusCode == http.StatusUnauthorized || result.StatusCode == http.StatusForbidden:
+		return result, nv.formatFailure(env, result, fmt.Errorf("authentication rejected (HTTP %d)", result.StatusCode))
+	case result.StatusCode == http.StatusNotFound:
+		// Every Anthropic-compatible endpoint serves /v1/models, so a 404 means a wrong base URL
+		return result, nv.formatFailure(env, result, fmt.Errorf("endpoint not found (HTTP 404)"))
+	case result.StatusCode >= 500:
+		return result, nv.formatFailure(env, result, fmt.Errorf("server error (HTTP %d)", result.StatusCode))
+	}
+
+	return result, nil
+}
+
+// retryableResult reports whether a probe may do better on another attempt
+func retryableResult(result networkCheckResult) bool {
+	return !result.Reachable || result.StatusCode >= 500 || result.StatusCode == http.StatusTooManyRequests
+}
+
+// formatFailure wraps a probe failure in a network-categorized error with guidance
+func (nv *networkValidator) formatFailure(env Environment, result networkCheckResult, baseErr error) error {
+	errorCtx := newErrorContext("network check", "network validator")
+	errorCtx.addContext("url", result.URL)
+	if result.StatusCode != 0 {
+		errorCtx.addContext("status", fmt.Sprintf("%d", result.StatusCode))
+	}
+	switch {
+	case result.StatusCode == http.StatusUnauthorized || result.StatusCode == http.StatusForbidden:
+		errorCtx.addSuggestion("Verify the API key is correct and active")
+		errorCtx.addSuggestion("Check whether the endpoint expects the other auth scheme (x-api-key vs bearer)")
+	case result.StatusCode == http.StatusNotFound:
+		errorCtx.addSuggestion("Verify the base URL: it should be the API root, without /v1 (cce appends /v1/models)")
+	case result.StatusCode >= 500:
+		errorCtx.addSuggestion("The endpoint is reachable but failing; try again later or check the provider's status page")
+	default:
+		errorCtx.addSuggestion("Verify the base URL is correct and the endpoint is online")
+		errorCtx.addSuggestion("Check proxy, VPN, or firewall settings")
+		if strings.Contains(baseErr.Error(), "x509:") && env.CACert == "" {
+			errorCtx.addSuggestion(fmt.Sprintf("If a proxy uses an internal CA, trust it with 'cce set %s ca_cert=<path>'", env.Name))
+		}
+	}
+	return errorCtx.formatError(baseErr)
+}
+
+// describe renders a one-line summary of a probe result
+func (r networkCheckResult) describe() string {
+	if !r.Reachable {
+		return fmt.Sprintf("unreachable after %s", r.Latency.Round(time.Millisecond))
+	}
+	auth := "auth ok"
+	if !r.Authenticated {
+		auth = "auth not verified"
+	}
+	if r.Attempts > 1 {
+		auth += fmt.Sprintf(", attempt %d", r.Attempts)
+	}
+	return fmt.Sprintf("HTTP %d in %s (%s)", r.StatusCode, r.Latency.Round(time.Millisecond), auth)
+}
+
+// reachabilityTimeout bounds the probe add and set make, so an offline endpoint costs
+// seconds rather than the full check timeout
+const reachabilityTimeout = 5 * time.Second
+
+// slowEndpointLatency is the latency above which that probe calls an endpoint slow
+const slowEndpointLatency = 2 * time.Second
+
+// reachabilityCheck makes a single probe without retries; tests replace it
+var reachabilityCheck = func(env Environment) (networkCheckResult, error) {
+	return newNetworkValidator(reachabilityTimeout).withRetries(0).checkEnvironment(env)
+}
+
+// reportReachability probes env after add or set and prints how it answered, returning
+// the result for its timing. A failed probe is only a warning, since the endpoint may just
+// be down right now; the error reports a failure to print. Bedrock and Vertex
+// environments are not probed.
+func reportReachability(env Environment) (networkCheckResult, error) {
+	if isCloudProvider(env) {
+		return networkCheckResult{URL: env.URL}, nil
+	}
+	result, err := reachabilityCheck(env)
+	if err != nil && result.StatusCode >= 500 {
+		fmt.Fprintf(os.Stderr, "Warning: %s answered with a server error (HTTP %d); the URL looks right but the endpoint is failing.\nSaving anyway; check again with 'cce test %s' once it recovers.\n", result.URL, result.StatusCode, env.Name)
+		return result, nil
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\nSaving anyway; check again with 'cce test %s' (skip this probe with --no-network-check).\n", err, env.Name)
+		return result, nil
+	}
+	if _, printErr := fmt.Printf("Network check: %s\n", result.describe()); printErr != nil {
+		return result, fmt.Errorf("failed to display network check result: %w", printErr)
+	}
+	if result.Latency > slowEndpointLatency {
+		fmt.Fprintf(os.Stderr, "Warning: %s took %s to answer; claude may be slow against it.\n", result.URL, result.Latency.Round(time.Millisecond))
+	}
+	return result, nil
+}
+
+// defaultCheckConcurrency bounds how many environments are probed at once
+const defaultCheckConcurrency = 4
+
+// environmentCheck is the outcome of probing one environment in a batch
+type environmentCheck struct {
+	Name      string
+	Result    networkCheckResult
+	Err       error
+	Cancelled bool // The batch was cancelled before this probe finished
+}
+
+// checkAll probes environments with at most concurrency workers. Cancelling ctx aborts
+// in-flight probes and skips queued ones; results keep the input order either way.
+func (nv *networkValidator) checkAll(ctx context.Context, envs []Environment, concurrency int) []environmentCheck {
+	results := make([]environmentCheck, len(envs))
+	dispatched := runBounded(ctx, len(envs), concurrency, func(i int) {
+		check := environmentCheck{Name: envs[i].Name}
+		check.Result, check.Err = nv.checkEnvironmentContext(ctx, envs[i])
+		check.Cancelled = check.Err != nil && ctx.Err() != nil
+		results[i] = check
+	})
+	for j := dispatched; j < len(envs); j++ {
+		results[j] = environmentCheck{Name: envs[j].Name, Cancelled: true, Err: ctx.Err()}
+	}
+	return results
+}
+
+// runBounded calls work(i) for i in [0, count) on at most concurrency goroutines and waits
+// for them. Once ctx is cancelled no further indexes are handed out; it returns how many were.
+func runBounded(ctx context.Context, count, concurrency int, work func(i int)) int {
+	if concurrency <= 0 {
+		concurrency = defaultCheckConcurrency
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < count; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				work(i)
+			}
+		}()
+	}
+
+	dispatched := 0
+dispatch:
+	for ; dispatched < count; dispatched++ {
+		select {
+		case indexes <- dispatched:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return dispatched
+}
+
+// signalContext returns a context cancelled by Ctrl-C or SIGTERM, for interruptible
+// network work such as list --check. Call stop to release the signal handler.
+func signalContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
+
+// renderEnvironmentChecks writes one status line per environment and returns the failure count
+func renderEnvironmentChecks(w io.Writer, checks []environmentCheck) (failed, cancelled int, err error) {
+	width := 0
+	for _, check := range checks {
+		if w := displayWidth(check.Name); w > width {
+			width = w
+		}
+	}
+
+	for _, check := range checks {
+		var status string
+		switch {
+		case check.Cancelled:
+			status = "cancelled"
+			cancelled++
+		case check.Err != nil:
+			status = "FAILED  " + firstLine(check.Err.Error())
+			failed++
+		default:
+			status = "ok      " + check.Result.describe()
+		}
+		if _, err := fmt.Fprintf(w, "  %s  %s\n", padToWidth(check.Name, width), status); err != nil {
+			return failed, cancelled, fmt.Errorf("failed to display check result: %w", err)
+		}
+	}
+	return failed, cancelled, nil
+}
+
+// runNetworkChecks checks envs concurrently, prints one line per environment and
+// fails when any check failed or was interrupted
+func runNetworkChecks(nv *networkValidator, envs []Environment) error {
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	if _, err := fmt.Printf("Checking %d environment(s) (Ctrl-C to cancel)...\n", len(envs)); err != nil {
+		return fmt.Errorf("failed to display header: %w", err)
+	}
+	checks := nv.checkAll(ctx, envs, defaultCheckConcurrency)
+
+	failed, cancelled, err := renderEnvironmentChecks(os.Stdout, checks)
+	if err != nil {
+		return err
+	}
+	if cancelled > 0 {
+		return fmt.Errorf("network check interrupted: %d check(s) cancelled", cancelled)
+	}
+	if failed > 0 {
+		return fmt.Errorf("network check failed for %d environment(s)", failed)
+	}
+	return nil
+}
+
+// testOptions holds flags accepted by the test subcommand
+type testOptions struct {
+	Names   []string      // Environments to check; empty means all
+	Timeout time.Duration // Overrides every check_timeout when set
+	Retries int           // Overrides every check_retries when >= 0
+	// Insecure skips TLS certificate verification in the probes
+	Insecure bool
+}
+
+// parseTestOptions parses test subcommand flags
+func parseTestOptions(args []string) (testOptions, error) {
+	opts := testOptions{Retries: -1}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--timeout":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--timeout flag requires a duration (e.g. 30s)")
+			}
+			i++
+			timeout, err := parseCheckTimeout(args[i])
+			if err != nil || timeout == 0 {
+				return opts, fmt.Errorf("invalid --timeout: '%s' must be a positive duration up to %s", args[i], maxCheckTimeout)
+			}
+			opts.Timeout = timeout
+		case arg == "--retries":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--retries flag requires a number")
+			}
+			i++
+			retries, err := strconv.Atoi(args[i])
+			if err != nil || validateCheckRetries(retries) != nil {
+				return opts, fmt.Errorf("invalid --retries: '%s' must be between 0 and %d", args[i], maxCheckRetries)
+			}
+			opts.Retries = retries
+		case arg == "--insecure":
+			opts.Insecure = true
+		case strings.HasPrefix(arg, "-"):
+			return opts, fmt.Errorf("unknown test flag '%s'", arg)
+		default:
+			opts.Names = append(opts.Names, arg)
+		}
+	}
+	return opts, nil
+}
+
+// runTest checks connectivity and authentication for all or the named environments
+func runTest(args []string) error {
+	opts, err := parseTestOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	envs, err := selectNamedEnvironments(config, opts.Names)
+	if err != nil {
+		return err
+	}
+	if len(envs) == 0 {
+		fmt.Println("No environments configured.")
+		return nil
+	}
+
+	nv := newNetworkValidator(opts.Timeout).withRetries(opts.Retries)
+	if opts.Insecure {
+		fmt.Fprintln(os.Stderr, insecureWarning)
+		nv.withInsecure()
+	}
+	return runNetworkChecks(nv, expandKeyPools(envs))
+}
+
+// firstLine trims multi-line errorContext output to its headline
+func firstLine(s string) string {
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
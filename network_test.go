@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newProbeServer returns a test server that answers /v1/models with the given status
+func newProbeServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// withReachabilityCheck replaces the add/set probe with one returning result and err,
+// and returns the environments it was asked to probe
+func withReachabilityCheck(t *testing.T, result networkCheckResult, err error) *[]Environment {
+	t.Helper()
+	original := reachabilityCheck
+	var probed []Environment
+	reachabilityCheck = func(env Environment) (networkCheckResult, error) {
+		probed = append(probed, env)
+		return result, err
+	}
+	t.Cleanup(func() { reachabilityCheck = original })
+	return &probed
+}
+
+func TestNetworkValidatorProbe(t *testing.T) {
+	t.Run("sends x-api-key by default", func(t *testing.T) {
+		var gotKey, gotAuth string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotKey = r.Header.Get("x-api-key")
+			gotAuth = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		env := Environment{Name: "probe", URL: server.URL + "/", APIKey: "sk-ant-probe-key"}
+		result, err := newNetworkValidator(time.Second).checkEnvironment(env)
+		if err != nil {
+			t.Fatalf("checkEnvironment() failed: %v", err)
+		}
+		if !result.Authenticated || result.StatusCode != http.StatusOK {
+			t.Errorf("unexpected result: %+v", result)
+		}
+		if gotKey != "sk-ant-probe-key" || gotAuth != "" {
+			t.Errorf("unexpected auth headers: x-api-key=%q Authorization=%q", gotKey, gotAuth)
+		}
+		if !strings.HasSuffix(result.URL, "/v1/models") || strings.Contains(result.URL, "//v1") {
+			t.Errorf("unexpected probe URL: %s", result.URL)
+		}
+	})
+
+	t.Run("sends bearer token for auth token environments", func(t *testing.T) {
+		var gotAuth string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAuth = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		env := Environment{Name: "probe", URL: server.URL, APIKey: "token-123", APIKeyEnv: "ANTHROPIC_AUTH_TOKEN"}
+		if _, err := newNetworkValidator(time.Second).checkEnvironment(env); err != nil {
+			t.Fatalf("checkEnvironment() failed: %v", err)
+		}
+		if gotAuth != "Bearer token-123" {
+			t.Errorf("Authorization header = %q, want bearer token", gotAuth)
+		}
+	})
+
+	t.Run("rejected key is a network error", func(t *testing.T) {
+		server := newProbeServer(t, http.StatusUnauthorized)
+		env := Environment{Name: "probe", URL: server.URL, APIKey: "bad-key"}
+
+		_, err := newNetworkValidator(time.Second).checkEnvironment(env)
+		if err == nil {
+			t.Fatal("expected authentication failure")
+		}
+		if categorizeError(err) != "network" {
+			t.Errorf("categorizeError() = %q, want network", categorizeError(err))
+		}
+	})
+
+	t.Run("unreachable endpoint is a network error", func(t *testing.T) {
+		server := newProbeServer(t, http.StatusOK)
+		url := server.URL
+		server.Close()
+
+		env := Environment{Name: "probe", URL: url, APIKey: "sk-ant-key"}
+		result, err := newNetworkValidator(time.Second).checkEnvironment(env)
+		if err == nil {
+			t.Fatal("expected unreachable endpoint to fail")
+		}
+		if result.Reachable {
+			t.Error("expected result to report unreachable")
+		}
+		if !strings.Contains(err.Error(), "network check") {
+			t.Errorf("expected network check error, got %v", err)
+		}
+	})
+
+	t.Run("non-auth client errors still pass", func(t *testing.T) {
+		server := newProbeServer(t, http.StatusBadRequest)
+		env := Environment{Name: "probe", URL: server.URL, APIKey: "sk-ant-key"}
+
+		result, err := newNetworkValidator(time.Second).checkEnvironment(env)
+		if err != nil {
+			t.Fatalf("expected 400 to be treated as reachable, got %v", err)
+		}
+		if result.Authenticated {
+			t.Error("400 should not be reported as authenticated")
+		}
+	})
+
+	t.Run("404 means a wrong base URL", func(t *testing.T) {
+		server := newProbeServer(t, http.StatusOK)
+		env := Environment{Name: "probe", URL: server.URL + "/proxy", APIKey: "sk-ant-key"}
+
+		_, err := newNetworkValidator(time.Second).checkEnvironment(env)
+		if err == nil {
+			t.Fatal("expected 404 to fail the check")
+		}
+		if !strings.Contains(err.Error(), "endpoint not found (HTTP 404)") || !strings.Contains(err.Error(), "Verify the base URL") {
+			t.Errorf("expected a wrong-URL error, got %v", err)
+		}
+	})
+}
+
+func TestAddTestGate(t *testing.T) {
+	tempDir := t.TempDir()
+	originalConfigPath := configPathOverride
+	configPathOverride = filepath.Join(tempDir, ".claude-code-env", "config.json")
+	defer func() { configPathOverride = originalConfigPath }()
+
+	originalPrompter := environmentPrompter
+	defer func() { environmentPrompter = originalPrompter }()
+
+	t.Run("refuses to save unreachable environment", func(t *testing.T) {
+		server := newProbeServer(t, http.StatusForbidden)
+		environmentPrompter = func(Config) (Environment, error) {
+			return Environment{Name: "gated", URL: server.URL, APIKey: "sk-ant-bad"}, nil
+		}
+
+		err := runAddWithOptions(addOptions{Test: true})
+		if err == nil {
+			t.Fatal("expected add --test to fail")
+		}
+		if categorizeError(err) != "network" {
+			t.Errorf("categorizeError() = %q, want network", categorizeError(err))
+		}
+
+		config, loadErr := loadConfig()
+		if loadErr != nil {
+			t.Fatalf("loadConfig() failed: %v", loadErr)
+		}
+		if _, exists := findEnvironmentByName(config, "gated"); exists {
+			t.Error("environment should not be saved when the network check fails")
+		}
+	})
+
+	t.Run("refuses to save a wrong base URL", func(t *testing.T) {
+		server := newProbeServer(t, http.StatusOK)
+		environmentPrompter = func(Config) (Environment, error) {
+			return Environment{Name: "misrouted", URL: server.URL + "/v1", APIKey: "sk-ant-good"}, nil
+		}
+
+		err := runAddWithOptions(addOptions{Test: true})
+		if err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+			t.Fatalf("expected add --test to fail on 404, got %v", err)
+		}
+		config, loadErr := loadConfig()
+		if loadErr != nil {
+			t.Fatalf("loadConfig() failed: %v", loadErr)
+		}
+		if _, exists := findEnvironmentByName(config, "misrouted"); exists {
+			t.Error("environment should not be saved when its URL answers 404")
+		}
+	})
+
+	t.Run("saves reachable environment", func(t *testing.T) {
+		server := newProbeServer(t, http.StatusOK)
+		environmentPrompter = func(Config) (Environment, error) {
+			return Environment{Name: "reachable", URL: server.URL, APIKey: "sk-ant-good"}, nil
+		}
+
+		output := captureStdout(t, func() {
+			if err := runAddWithOptions(addOptions{Test: true}); err != nil {
+				t.Fatalf("runAddWithOptions() failed: %v", err)
+			}
+		})
+		if !strings.Contains(output, "Network check passed") {
+			t.Errorf("expected network check summary, got %q", output)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			t.Fatalf("loadConfig() failed: %v", err)
+		}
+		if _, exists := findEnvironmentByName(config, "reachable"); !exists {
+			t.Error("expected environment to be saved")
+		}
+	})
+}
+
+func TestParseAddOptions(t *testing.T) {
+	opts, err := parseAddOptions([]string{"--test"})
+	if err != nil || !opts.Test {
+		t.Fatalf("parseAddOptions(--test) = %+v, %v", opts, err)
+	}
+
+	if _, err := parseAddOptions([]string{"--bogus"}); err == nil {
+		t.Error("expected unknown flag to be rejected")
+	}
+	if _, err := parseAddOptions([]string{"--test", "--no-network-check"}); err == nil {
+		t.Error("expected --test and --no-network-check to be rejected together")
+	}
+
+	result := parseArguments([]string{"add", "--test"})
+	if result.Subcommand != "add" || len(result.SubcommandArgs) != 1 || result.SubcommandArgs[0] != "--test" {
+		t.Errorf("unexpected parse result: %+v", result)
+	}
+}
+
+func TestAddWarnsButSavesUnreachableEnvironment(t *testing.T) {
+	withTempConfigPath(t)
+	originalPrompter := environmentPrompter
+	defer func() { environmentPrompter = originalPrompter }()
+	environmentPrompter = func(Config) (Environment, error) {
+		return Environment{Name: "down", URL: "https://down.example.com", APIKey: "sk-ant-REDACTED"}, nil
+	}
+
+	probed := withReachabilityCheck(t, networkCheckResult{Latency: 5 * time.Second}, fmt.Errorf("network check failed: endpoint unreachable"))
+	_, stderr, err := captureStdoutAndStderr(t, func() error { return runAddWithOptions(addOptions{}) })
+	if err != nil {
+		t.Fatalf("runAddWithOptions() failed: %v", err)
+	}
+	if len(*probed) != 1 || !strings.Contains(stderr, "endpoint unreachable") || !strings.Contains(stderr, "Saving anyway") {
+		t.Errorf("expected one probe and a warning, got %d probes, stderr %q", len(*probed), stderr)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	if _, exists := findEnvironmentByName(config, "down"); !exists {
+		t.Error("an unreachable environment should still be saved")
+	}
+
+	environmentPrompter = func(Config) (Environment, error) {
+		return Environment{Name: "offline", URL: "https://offline.example.com", APIKey: "sk-ant-REDACTED"}, nil
+	}
+	captureStdout(t, func() {
+		if err := handleCommand([]string{"add", "--no-network-check"}); err != nil {
+			t.Fatalf("add --no-network-check failed: %v", err)
+		}
+	})
+	if len(*probed) != 1 {
+		t.Error("--no-network-check should skip the probe")
+	}
+}
+
+func TestAddChecksLocallyBeforeProbing(t *testing.T) {
+	withTempConfigPath(t)
+	existing := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}
+	if err := saveConfig(Config{Environments: []Environment{existing}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	originalPrompter := environmentPrompter
+	defer func() { environmentPrompter = originalPrompter }()
+	environmentPrompter = func(Config) (Environment, error) {
+		return Environment{Name: "prod", URL: "https://other.example.com", APIKey: "sk-ant-REDACTED"}, nil
+	}
+
+	probed := withReachabilityCheck(t, networkCheckResult{Reachable: true, StatusCode: 200}, nil)
+	_, _, err := captureStdoutAndStderr(t, func() error { return runAddWithOptions(addOptions{}) })
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected a duplicate name error, got %v", err)
+	}
+	if len(*probed) != 0 {
+		t.Errorf("a duplicate name should fail before the probe, got %d probes", len(*probed))
+	}
+}
+
+func TestReportReachability(t *testing.T) {
+	env := Environment{Name: "slow", URL: "https://slow.example.com", APIKey: "sk-ant-REDACTED"}
+	withReachabilityCheck(t, networkCheckResult{URL: modelsEndpoint(env.URL), Reachable: true, Authenticated: true, StatusCode: 200, Latency: 3 * time.Second, Attempts: 1}, nil)
+
+	var result networkCheckResult
+	stdout, stderr, err := captureStdoutAndStderr(t, func() error {
+		var reportErr error
+		result, reportErr = reportReachability(env)
+		return reportErr
+	})
+	if err != nil || result.Latency != 3*time.Second {
+		t.Fatalf("reportReachability() = %+v, %v", result, err)
+	}
+	if !strings.Contains(stdout, "Network check: HTTP 200 in 3s (auth ok)") || !strings.Contains(stderr, "claude may be slow") {
+		t.Errorf("unexpected output: stdout %q stderr %q", stdout, stderr)
+	}
+
+	withReachabilityCheck(t, networkCheckResult{URL: modelsEndpoint(env.URL), Reachable: true, StatusCode: 503}, fmt.Errorf("network check failed: server error (HTTP 503)"))
+	_, stderr, err = captureStdoutAndStderr(t, func() error {
+		_, reportErr := reportReachability(env)
+		return reportErr
+	})
+	if err != nil || !strings.Contains(stderr, "server error (HTTP 503); the URL looks right") {
+		t.Errorf("a 5xx should get its own warning, got %v, stderr %q", err, stderr)
+	}
+
+	probed := withReachabilityCheck(t, networkCheckResult{}, nil)
+	if _, err := reportReachability(Environment{Name: "aws", Provider: "bedrock", Region: "us-east-1"}); err != nil || len(*probed) != 0 {
+		t.Errorf("cloud environments should not be probed (err %v, %d probes)", err, len(*probed))
+	}
+}
+
+func TestSetProbesNewURL(t *testing.T) {
+	withTempConfigPath(t)
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	probed := withReachabilityCheck(t, networkCheckResult{Reachable: true, StatusCode: 200}, nil)
+
+	captureStdout(t, func() {
+		for _, args := range [][]string{
+			{"set", "prod", "model=claude-sonnet-4-5-20250929"},
+			{"set", "--no-network-check", "prod", "url=https://offline.example.com"},
+			{"set", "prod", "url=https://new.example.com"},
+		} {
+			if err := handleCommand(args); err != nil {
+				t.Fatalf("%v failed: %v", args, err)
+			}
+		}
+	})
+	if len(*probed) != 1 || (*probed)[0].URL != "https://new.example.com" {
+		t.Errorf("expected only the unflagged url change to be probed, got %+v", *probed)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// withNoPrompt turns --no-prompt on for one test, failing it if any prompt is still reached
+func withNoPrompt(t *testing.T) {
+	t.Helper()
+	origSelector, origConfirm, origSecret, origPrompter, origTerminal := environmentSelector, confirmationReader, secretReader, environmentPrompter, stdinIsTerminal
+	t.Cleanup(func() {
+		environmentSelector, confirmationReader, secretReader, environmentPrompter, stdinIsTerminal = origSelector, origConfirm, origSecret, origPrompter, origTerminal
+		noPrompt = false
+	})
+	environmentSelector = func(config Config) (Environment, error) {
+		if len(config.Environments) == 1 {
+			return config.Environments[0], nil // Like the real selector, which does not ask
+		}
+		t.Error("selector should not be shown with --no-prompt")
+		return Environment{}, nil
+	}
+	confirmationReader = func(string) (string, error) {
+		t.Error("confirmation should not be asked with --no-prompt")
+		return "", nil
+	}
+	secretReader = func(string) (string, error) {
+		t.Error("secret should not be read with --no-prompt")
+		return "", nil
+	}
+	environmentPrompter = func(Config) (Environment, error) {
+		t.Error("environment prompts should not run with --no-prompt")
+		return Environment{}, nil
+	}
+	stdinIsTerminal = func() bool { return true }
+	t.Setenv("CCE_NO_PROMPT", "")
+}
+
+func TestNoPromptFailsInsteadOfPrompting(t *testing.T) {
+	withTempConfigPath(t)
+	withNoPrompt(t)
+	if err := saveConfig(Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+		{Name: "dev", URL: "https://dev.example.com", APIKey: "sk-ant-REDACTED"},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--no-prompt"},
+		{"--no-prompt", "-p", "hi"},
+		{"--env-file", "/dev/null", "--no-prompt", "--", "-p", "hi"},
+		{"--no-prompt", "select"},
+		{"--no-prompt", "add"},
+		{"--no-prompt", "init", "--force"},
+		{"--no-prompt", "set", "prod", "api_key=-"},
+		{"--no-prompt", "rotate", "prod"},
+		{"--no-prompt", "remove", "--all"},
+		{"--no-prompt", "doctor", "--fix"},
+	} {
+		err := handleCommand(args)
+		if err == nil || !strings.Contains(err.Error(), "--no-prompt") || exitCodeFor(err) != 7 {
+			t.Errorf("%v: expected a --no-prompt argument validation error, got %v", args, err)
+		}
+	}
+}
+
+func TestNoPromptAllowsWhatNeedsNoInput(t *testing.T) {
+	withTempConfigPath(t)
+	withNoPrompt(t)
+	if err := saveConfig(Config{Environments: []Environment{
+		{Name: "only", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	// One environment is picked without asking
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"--no-prompt", "select"}); err != nil {
+			t.Fatalf("select failed: %v", err)
+		}
+	})
+	if strings.TrimSpace(out) != "only" {
+		t.Errorf("expected the single environment, got %q", out)
+	}
+
+	// --yes answers the confirmation, so nothing is left to prompt for
+	captureStdout(t, func() {
+		if err := handleCommand([]string{"--no-prompt", "--yes", "remove", "--all"}); err != nil {
+			t.Errorf("remove --all --yes should not need a prompt: %v", err)
+		}
+	})
+
+	// An empty config reports the problem instead of offering to add one
+	t.Setenv("CCE_NO_PROMPT", "1")
+	err := handleCommand([]string{"-p", "hi"})
+	if err == nil || !strings.Contains(err.Error(), "no environments configured") {
+		t.Errorf("expected the empty-config error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckFieldEscapable(t *testing.T) {
+	if _, err := checkFieldEscapable(fieldAPIKey, "short", validateAPIKey, false); err == nil {
+		t.Error("short key should fail without --no-validate")
+	}
+	skipped, err := checkFieldEscapable(fieldAPIKey, "short", validateAPIKey, true)
+	if err != nil || skipped == nil {
+		t.Errorf("short key should be accepted with --no-validate, got skipped=%v err=%v", skipped, err)
+	}
+	// Control characters are never accepted
+	if _, err := checkFieldEscapable(fieldURL, "gw.internal\n:8443", validateURL, true); err == nil {
+		t.Error("control characters in URL should be rejected even with --no-validate")
+	}
+	if skipped, err := checkFieldEscapable(fieldURL, "https://api.anthropic.com", validateURL, true); skipped != nil || err != nil {
+		t.Errorf("valid URL should pass strict validation, got skipped=%v err=%v", skipped, err)
+	}
+}
+
+func TestAddNoValidateRecordsSkippedFields(t *testing.T) {
+	withTempConfigPath(t)
+
+	original := seededEnvironmentPrompter
+	defer func() { seededEnvironmentPrompter = original }()
+
+	var got promptDefaults
+	seededEnvironmentPrompter = func(config Config, defaults promptDefaults) (Environment, error) {
+		got = defaults
+		env := Environment{Name: "lab", URL: "gw.internal:8443", APIKey: "abc123"}
+		markUnvalidated(&env, fieldURL)
+		markUnvalidated(&env, fieldAPIKey)
+		return env, nil
+	}
+
+	withReachabilityCheck(t, networkCheckResult{Reachable: true, StatusCode: 200}, nil)
+	captureStdout(t, func() {
+		if err := handleCommand([]string{"add", "--no-validate"}); err != nil {
+			t.Fatalf("add --no-validate failed: %v", err)
+		}
+	})
+	if !got.SkipValidation {
+		t.Error("expected prompts to run with SkipValidation")
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() should accept unvalidated environments: %v", err)
+	}
+	env := config.Environments[0]
+	if strings.Join(env.Unvalidated, ",") != "api_key,url" {
+		t.Errorf("expected api_key and url to be recorded, got %v", env.Unvalidated)
+	}
+	if warning := unvalidatedWarning(env); !strings.Contains(warning, "unchecked fields: api_key, url") {
+		t.Errorf("unexpected launch warning: %q", warning)
+	}
+}
+
+func TestSetNoValidate(t *testing.T) {
+	env := Environment{Name: "lab", URL: "https://api.anthropic.com", APIKey: "sk-ant-lab-key-123"}
+
+	if _, err := applyFieldUpdatesWithOptions(env, map[string]string{"url": "gw.internal:8443"}, []string{"url"}, false); err == nil {
+		t.Error("invalid URL should be rejected without --no-validate")
+	}
+
+	updated, err := applyFieldUpdatesWithOptions(env, map[string]string{"url": "gw.internal:8443"}, []string{"url"}, true)
+	if err != nil {
+		t.Fatalf("--no-validate update failed: %v", err)
+	}
+	if !isUnvalidated(updated, fieldURL) {
+		t.Errorf("expected url to be marked unvalidated: %v", updated.Unvalidated)
+	}
+
+	// Fixing the field without --no-validate clears the mark
+	fixed, err := applyFieldUpdates(updated, map[string]string{"url": "https://gw.example.com"}, []string{"url"})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if len(fixed.Unvalidated) != 0 {
+		t.Errorf("expected unvalidated list to be cleared, got %v", fixed.Unvalidated)
+	}
+
+	// Changing a marked field to another bad value requires --no-validate again
+	if _, err := applyFieldUpdates(updated, map[string]string{"url": "other.internal:1"}, []string{"url"}); err == nil {
+		t.Error("changing an unvalidated field should re-apply strict checks")
+	}
+}
+
+func TestValidateRejectsUnknownUnvalidatedEntry(t *testing.T) {
+	env := Environment{Name: "lab", URL: "https://api.anthropic.com", APIKey: "sk-ant-lab-key-123", Unvalidated: []string{"name"}}
+	if err := validateStoredEnvironment(env); err == nil {
+		t.Error("unknown unvalidated entry should be rejected")
+	}
+}
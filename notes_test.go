@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateNotes(t *testing.T) {
+	if err := validateNotes(""); err != nil {
+		t.Errorf("empty notes should be valid: %v", err)
+	}
+	if err := validateNotes("uses Bedrock proxy, rotate key monthly"); err != nil {
+		t.Errorf("plain notes should be valid: %v", err)
+	}
+	if err := validateNotes(strings.Repeat("n", maxNotesLength+1)); err == nil {
+		t.Error("expected overlong notes to be rejected")
+	}
+	if err := validateNotes("line\nbreak"); err == nil {
+		t.Error("expected control characters to be rejected")
+	}
+
+	env := Environment{Name: "n", URL: "https://api.anthropic.com", APIKey: "sk-ant-notes-key", Notes: "bad\x1b[31m"}
+	if err := validateEnvironment(env); err == nil {
+		t.Error("expected validateEnvironment to reject invalid notes")
+	}
+}
+
+func TestNotesPersistAndDisplay(t *testing.T) {
+	withTempConfigPath(t)
+	env := Environment{Name: "bedrock", URL: "https://proxy.example.com", APIKey: "sk-ant-bedrock-key", Notes: "uses Bedrock proxy"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil || config.Environments[0].Notes != env.Notes {
+		t.Fatalf("notes not preserved: %+v (%v)", config.Environments, err)
+	}
+
+	plain := captureStdout(t, func() {
+		if err := handleCommand([]string{"list"}); err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+	})
+	if strings.Contains(plain, "uses Bedrock proxy") {
+		t.Error("notes should only appear in wide output")
+	}
+
+	wide := captureStdout(t, func() {
+		if err := handleCommand([]string{"list", "--wide"}); err != nil {
+			t.Fatalf("list --wide failed: %v", err)
+		}
+	})
+	if !strings.Contains(wide, "Notes: uses Bedrock proxy") {
+		t.Errorf("expected notes in wide output:\n%s", wide)
+	}
+}
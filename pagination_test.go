@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMenuPageSize(t *testing.T) {
+	cases := map[int]int{0: defaultMenuPageSize, -1: defaultMenuPageSize, 24: 20, 6: 3, 2: 3}
+	for height, want := range cases {
+		if got := menuPageSize(height); got != want {
+			t.Errorf("menuPageSize(%d) = %d, want %d", height, got, want)
+		}
+	}
+}
+
+func TestMenuViewport(t *testing.T) {
+	cases := []struct {
+		total, selected, pageSize int
+		start, end                int
+	}{
+		{5, 3, 10, 0, 5},     // fits on one page
+		{57, 0, 10, 0, 10},   // first page
+		{57, 9, 10, 0, 10},   // last entry of first page
+		{57, 10, 10, 10, 20}, // first entry of second page
+		{57, 56, 10, 50, 57}, // short last page
+		{57, 99, 10, 50, 57}, // out of range clamps to last page
+		{57, -3, 10, 0, 10},  // negative clamps to first page
+	}
+	for _, c := range cases {
+		start, end := menuViewport(c.total, c.selected, c.pageSize)
+		if start != c.start || end != c.end {
+			t.Errorf("menuViewport(%d, %d, %d) = [%d, %d), want [%d, %d)", c.total, c.selected, c.pageSize, start, end, c.start, c.end)
+		}
+		if c.selected >= 0 && c.selected < c.total && (c.selected < start || c.selected >= end) {
+			t.Errorf("selection %d not inside viewport [%d, %d)", c.selected, start, end)
+		}
+	}
+}
+
+func TestPageKeysMoveSelection(t *testing.T) {
+	envs := make([]Environment, 25)
+	for i := range envs {
+		envs[i] = Environment{Name: fmt.Sprintf("env-%02d", i)}
+	}
+	state := newSelectionState(envs)
+	state.pageSize = 10
+	bindings := defaultKeyBindings()
+
+	press := func(seq string) {
+		if _, done, err := state.handleKey(bindings, []byte(seq)); done || err != nil {
+			t.Fatalf("key %q ended selection: %v", seq, err)
+		}
+	}
+
+	press("\x1b[6~")
+	if state.index != 10 {
+		t.Errorf("PgDn: expected index 10, got %d", state.index)
+	}
+	press("\x1b[6~")
+	press("\x1b[6~")
+	if state.index != 24 {
+		t.Errorf("PgDn past the end should clamp to 24, got %d", state.index)
+	}
+	press("\x1b[5~")
+	if state.index != 14 {
+		t.Errorf("PgUp: expected index 14, got %d", state.index)
+	}
+	press("\x1b[5~")
+	press("\x1b[5~")
+	if state.index != 0 {
+		t.Errorf("PgUp past the start should clamp to 0, got %d", state.index)
+	}
+
+	// The rendered page always contains the selection
+	state.index = 17
+	if start, end := menuViewport(len(state.visible), state.index, state.pageSize); start != 10 || end != 20 {
+		t.Errorf("expected viewport [10, 20), got [%d, %d)", start, end)
+	}
+}
+
+func TestScrollIndicator(t *testing.T) {
+	if got := scrollIndicator(10, 20, 57); got != "  [11-20 of 57] PgUp/PgDn for more" {
+		t.Errorf("unexpected indicator: %q", got)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// pingAuthStatus says what a probe showed about the key
+func pingAuthStatus(result networkCheckResult) string {
+	switch {
+	case !result.Reachable:
+		return "unknown (endpoint unreachable)"
+	case result.StatusCode == http.StatusUnauthorized || result.StatusCode == http.StatusForbidden:
+		return "rejected"
+	case result.Authenticated:
+		return "ok"
+	default:
+		return fmt.Sprintf("not verified (HTTP %d)", result.StatusCode)
+	}
+}
+
+// pingKeyLine describes the key a probe sent without revealing it
+func pingKeyLine(env Environment) string {
+	header := "x-api-key"
+	if resolveAPIKeyVar(env) == "ANTHROPIC_AUTH_TOKEN" {
+		header = "Authorization: Bearer"
+	}
+	if isSecretRef(env.APIKey) {
+		return fmt.Sprintf("%s (stored outside the config), sent as %s", env.APIKey, header)
+	}
+	return fmt.Sprintf("%s (fingerprint %s), sent as %s", maskAPIKey(env.APIKey), keyFingerprint(env.APIKey), header)
+}
+
+// renderPingReport writes one probe's outcome; the key only ever appears masked
+func renderPingReport(w io.Writer, env Environment, result networkCheckResult) error {
+	status := "no response"
+	if result.Reachable {
+		status = fmt.Sprintf("HTTP %d", result.StatusCode)
+	}
+	if result.Attempts > 1 {
+		status += fmt.Sprintf(" (attempt %d)", result.Attempts)
+	}
+	lines := []string{
+		fmt.Sprintf("Ping %s", env.Name),
+		fmt.Sprintf("  Endpoint: %s", result.URL),
+		fmt.Sprintf("  Key:      %s", pingKeyLine(env)),
+		fmt.Sprintf("  Status:   %s", status),
+		fmt.Sprintf("  Time:     %s", result.Latency.Round(time.Millisecond)),
+		fmt.Sprintf("  Auth:     %s", pingAuthStatus(result)),
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to display ping result: %w", err)
+		}
+	}
+	return nil
+}
+
+// runPing probes one environment's endpoint with its key and reports status, response
+// time and whether the key was accepted. It takes the test command's flags; an
+// environment with api_keys is probed once per key.
+func runPing(args []string) error {
+	opts, err := parseTestOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	if len(opts.Names) != 1 {
+		return fmt.Errorf("argument parsing failed: ping command requires exactly one environment name")
+	}
+	if err := validateName(opts.Names[0]); err != nil {
+		return fmt.Errorf("invalid environment name: %w", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	index, err := lookupEnvironment(config, opts.Names[0])
+	if err != nil {
+		return err
+	}
+	env := config.Environments[index]
+	if isCloudProvider(env) {
+		return fmt.Errorf("%s environments cannot be probed by cce; check them with the %s CLI", env.Provider, cloudCLI(env))
+	}
+
+	nv := newNetworkValidator(opts.Timeout).withRetries(opts.Retries)
+	if opts.Insecure {
+		fmt.Fprintln(os.Stderr, insecureWarning)
+		nv.withInsecure()
+	}
+
+	// Every key is probed and reported; the first failure decides the exit code
+	var firstErr error
+	for i, single := range expandKeyPools([]Environment{env}) {
+		result, checkErr := nv.checkEnvironment(single)
+		if i > 0 {
+			if _, err := fmt.Println(); err != nil {
+				return fmt.Errorf("failed to display ping result: %w", err)
+			}
+		}
+		if err := renderPingReport(os.Stdout, single, result); err != nil {
+			return err
+		}
+		if checkErr != nil && firstErr == nil {
+			firstErr = checkErr
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRunPing(t *testing.T) {
+	withTempConfigPath(t)
+	ok := newProbeServer(t, http.StatusOK)
+	rejected := newProbeServer(t, http.StatusUnauthorized)
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: ok.URL, APIKey: "sk-ant-REDACTED"},
+		{Name: "stale", URL: rejected.URL, APIKey: "sk-ant-REDACTED"},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := handleCommand([]string{"ping", "prod", "--timeout", "5s"}); err != nil {
+			t.Fatalf("ping prod failed: %v", err)
+		}
+	})
+	for _, want := range []string{"Endpoint: " + ok.URL + "/v1/models", "Status:   HTTP 200", "Time:", "Auth:     ok", "sk-a", "sent as x-api-key"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "sk-ant-REDACTED") {
+		t.Errorf("the key must be masked:\n%s", output)
+	}
+
+	var pingErr error
+	output = captureStdout(t, func() { pingErr = handleCommand([]string{"ping", "stale"}) })
+	if pingErr == nil || categorizeError(pingErr) != "network" {
+		t.Errorf("expected a network error for a rejected key, got %v", pingErr)
+	}
+	if !strings.Contains(output, "Status:   HTTP 401") || !strings.Contains(output, "Auth:     rejected") {
+		t.Errorf("expected the rejection to be reported:\n%s", output)
+	}
+
+	for _, args := range [][]string{{"ping"}, {"ping", "prod", "stale"}, {"ping", "missing"}} {
+		if err := handleCommand(args); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
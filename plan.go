@@ -0,0 +1,433 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// claudeSettingsPathOverride allows tests to point at a fake ~/.claude/settings.json
+var claudeSettingsPathOverride string
+
+// claudeSettingsPath returns the path of Claude Code's user settings file
+func claudeSettingsPath() (string, error) {
+	if claudeSettingsPathOverride != "" {
+		return claudeSettingsPathOverride, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude", "settings.json"), nil
+}
+
+// claudeSettingsPathFor returns the settings.json claude will read for env, honoring settings_dir
+func claudeSettingsPathFor(env Environment) (string, error) {
+	if env.SettingsDir == "" {
+		return claudeSettingsPath()
+	}
+	dir, err := expandSettingsDir(env.SettingsDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+// loadClaudeSettingsEnv returns the "env" block of Claude Code's settings.json.
+// A missing file is not an error and yields no variables.
+func loadClaudeSettingsEnv() (map[string]string, string, error) {
+	path, err := claudeSettingsPath()
+	if err != nil {
+		return nil, "", err
+	}
+	return loadSettingsEnvFile(path)
+}
+
+// loadSettingsEnvFile reads the "env" block of the settings.json at path
+func loadSettingsEnvFile(path string) (map[string]string, string, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, path, nil
+	} else if err != nil {
+		return nil, path, fmt.Errorf("failed to read claude settings: %w", err)
+	}
+
+	var settings struct {
+		Env map[string]string `json:"env"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, path, fmt.Errorf("failed to parse claude settings: %w", err)
+	}
+	return settings.Env, path, nil
+}
+
+// detectSettingsConflicts lists settings.json env keys that override what CCE sets.
+// Claude applies settings.json env after the process environment, so any ANTHROPIC_*
+// key there (or any key CCE injects) silently defeats environment switching.
+func detectSettingsConflicts(assignments []envAssignment, settingsEnv map[string]string) []string {
+	injected := make(map[string]bool, len(assignments))
+	for _, a := range assignments {
+		injected[a.Key] = true
+	}
+
+	var conflicts []string
+	for key := range settingsEnv {
+		if injected[key] || strings.HasPrefix(key, "ANTHROPIC_") {
+			conflicts = append(conflicts, key)
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// planVariable is one variable in a launch plan, with secrets masked
+type planVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// launchPlan describes everything a launch would do, without doing it
+type launchPlan struct {
+	Environment       string         `json:"environment"`
+	URL               string         `json:"url"`
+	Model             string         `json:"model,omitempty"`
+	ModelSource       string         `json:"model_source"`
+	KeyVar            string         `json:"key_var"`
+	Variables         []planVariable `json:"variables"`
+	ClearedFromShell  []string       `json:"cleared_from_shell"`
+	SettingsPath      string         `json:"settings_path,omitempty"`
+	SettingsConflicts []string       `json:"settings_conflicts"`
+	SettingsError     string         `json:"settings_error,omitempty"`
+	ClaudePath        string         `json:"claude_path"`
+	Argv              []string       `json:"argv"`
+}
+
+// isSecretVar reports whether a variable's value should be masked in plan output
+func isSecretVar(key, keyVar string) bool {
+	if key == keyVar {
+		return true
+	}
+	upper := strings.ToUpper(key)
+	for _, marker := range []string{"KEY", "TOKEN", "SECRET", "PASSWORD"} {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskLaunchValue hides secrets in a launch variable for display: secret-looking variables
+// entirely, ANTHROPIC_CUSTOM_HEADERS header by header
+func maskLaunchValue(key, value, keyVar string) string {
+	if isSecretVar(key, keyVar) {
+		return maskAPIKey(value)
+	}
+	if key == customHeadersVar {
+		masked, _ := maskCustomHeaders(value)
+		return masked
+	}
+	return value
+}
+
+// buildLaunchPlan assembles the plan for an already-resolved environment
+func buildLaunchPlan(env Environment, claudeArgs []string) launchPlan {
+	resolved := resolveModel(env, "")
+	keyVar := resolveAPIKeyVar(env)
+	assignments := launchVariables(env)
+
+	plan := launchPlan{
+		Environment:       env.Name,
+		URL:               env.URL,
+		Model:             resolved.Model,
+		ModelSource:       resolved.Source,
+		KeyVar:            keyVar,
+		Variables:         []planVariable{},
+		ClearedFromShell:  []string{},
+		SettingsConflicts: []string{},
+		Argv:              append([]string{"claude"}, claudeArgs...),
+	}
+
+	for _, a := range assignments {
+		plan.Variables = append(plan.Variables, planVariable{Key: a.Key, Value: maskLaunchValue(a.Key, a.Value, keyVar)})
+	}
+
+	// prepareEnvironment drops every inherited ANTHROPIC* variable
+	for _, kv := range os.Environ() {
+		if name := strings.SplitN(kv, "=", 2)[0]; strings.HasPrefix(name, "ANTHROPIC") {
+			plan.ClearedFromShell = append(plan.ClearedFromShell, name)
+		}
+	}
+	sort.Strings(plan.ClearedFromShell)
+
+	settingsEnv, settingsPath, err := loadClaudeSettingsEnv()
+	if env.SettingsDir != "" {
+		var path string
+		if path, err = claudeSettingsPathFor(env); err == nil {
+			settingsEnv, settingsPath, err = loadSettingsEnvFile(path)
+		}
+	}
+	plan.SettingsPath = settingsPath
+	if err != nil {
+		plan.SettingsError = err.Error()
+	} else {
+		plan.SettingsConflicts = append(plan.SettingsConflicts, detectSettingsConflicts(assignments, settingsEnv)...)
+	}
+
+	if path, err := exec.LookPath("claude"); err == nil {
+		plan.ClaudePath = path
+	}
+
+	return plan
+}
+
+// withModelOverride credits the plan's model to --model; the environment passed to
+// buildLaunchPlan already carries the override, so only the source changes
+func (p launchPlan) withModelOverride(override string) launchPlan {
+	if override != "" {
+		p.ModelSource = modelSourceOverride
+	}
+	return p
+}
+
+// quoteArgv renders argv for display so it can be pasted into a shell unchanged
+func quoteArgv(argv []string) string {
+	return joinArgs(argv)
+}
+
+// renderLaunchPlan writes the human-readable plan
+func renderLaunchPlan(w io.Writer, plan launchPlan) error {
+	claudePath := plan.ClaudePath
+	if claudePath == "" {
+		claudePath = "(not found in PATH)"
+	}
+	model := plan.ModelSource
+	if plan.Model != "" {
+		model = fmt.Sprintf("%s (from %s)", plan.Model, plan.ModelSource)
+	}
+
+	lines := []string{
+		"Launch plan:",
+		fmt.Sprintf("  Environment:  %s (%s)", plan.Environment, plan.URL),
+		fmt.Sprintf("  Model:        %s", model),
+		fmt.Sprintf("  Key variable: %s", plan.KeyVar),
+		fmt.Sprintf("  Claude:       %s", claudePath),
+		fmt.Sprintf("  Argv:         %s", quoteArgv(plan.Argv)),
+		"",
+		"Variables set by CCE:",
+	}
+	for _, v := range plan.Variables {
+		lines = append(lines, fmt.Sprintf("  %s=%s", v.Key, v.Value))
+	}
+	if len(plan.ClearedFromShell) > 0 {
+		lines = append(lines, "", "Cleared from the current shell:", "  "+strings.Join(plan.ClearedFromShell, ", "))
+	}
+
+	lines = append(lines, "")
+	switch {
+	case plan.SettingsError != "":
+		lines = append(lines, fmt.Sprintf("Claude settings: %s", plan.SettingsError))
+	case len(plan.SettingsConflicts) > 0:
+		lines = append(lines,
+			fmt.Sprintf("Conflicts: %s sets these in \"env\", overriding CCE:", plan.SettingsPath),
+			"  "+strings.Join(plan.SettingsConflicts, ", "),
+			"  Remove them from settings.json for environment switching to take effect.")
+	default:
+		lines = append(lines, "Conflicts: none")
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to display launch plan: %w", err)
+		}
+	}
+	return nil
+}
+
+// planOptions holds flags accepted by the plan subcommand
+type planOptions struct {
+	JSON       bool
+	LaunchArgs []string // Launch flags and claude args, parsed exactly like a normal launch
+}
+
+// parsePlanOptions separates --json from the launch arguments being planned
+func parsePlanOptions(args []string) planOptions {
+	var opts planOptions
+	for i, arg := range args {
+		if arg == "--" {
+			opts.LaunchArgs = append(opts.LaunchArgs, args[i:]...)
+			break
+		}
+		if arg == "--json" {
+			opts.JSON = true
+			continue
+		}
+		opts.LaunchArgs = append(opts.LaunchArgs, arg)
+	}
+	return opts
+}
+
+// runPlan prints what `cce <launch args>` would do without launching claude
+func runPlan(args []string) error {
+	opts := parsePlanOptions(args)
+
+	parsed := parseArguments(opts.LaunchArgs)
+	if parsed.Error != nil {
+		return fmt.Errorf("argument parsing failed: %w", parsed.Error)
+	}
+	if parsed.Subcommand != "" {
+		return fmt.Errorf("argument parsing failed: plan accepts launch options only, got '%s'", parsed.Subcommand)
+	}
+	if err := checkUnknownFlag(parsed); err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+	if err := rejectDangerousArgs(parsed.ClaudeArgs); err != nil {
+		return fmt.Errorf("argument validation failed: %w", err)
+	}
+	if dir := parsed.CCEFlags["chdir"]; dir != "" {
+		resolved, err := resolveChdir(dir)
+		if err != nil {
+			return err
+		}
+		workDir = resolved
+	}
+
+	env, err := resolveLaunchEnvironment(parsed.CCEFlags["env"], launchOptions{
+		KeyVarOverride: parsed.CCEFlags["key_var"],
+		ModelOverride:  parsed.CCEFlags["model"],
+		EnvFile:        parsed.CCEFlags["env_file"],
+	})
+	if err != nil {
+		return err
+	}
+	if err := validateArgsForEnvironment(parsed.ClaudeArgs, env, strictArgsEnabled(parsed)); err != nil {
+		return fmt.Errorf("argument validation failed: %w", err)
+	}
+
+	plan := buildLaunchPlan(env, parsed.ClaudeArgs).withModelOverride(parsed.CCEFlags["model"])
+	if opts.JSON {
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode launch plan: %w", err)
+		}
+		if _, err := fmt.Println(string(data)); err != nil {
+			return fmt.Errorf("failed to display launch plan: %w", err)
+		}
+		return nil
+	}
+	return renderLaunchPlan(os.Stdout, plan)
+}
+
+// Kinds of change in an environment diff
+const (
+	envDiffAdded   = "+" // Not set in the current environment
+	envDiffChanged = "~" // Set, but CCE overrides the value
+	envDiffCleared = "-" // Set, but CCE drops it (inherited ANTHROPIC_*)
+)
+
+// envDiffEntry is one variable that differs between the current and the launch environment
+type envDiffEntry struct {
+	Key  string
+	Kind string
+	Old  string
+	New  string
+}
+
+// environMap turns KEY=value pairs into a map; later duplicates win
+func environMap(environ []string) map[string]string {
+	vars := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			vars[parts[0]] = parts[1]
+		}
+	}
+	return vars
+}
+
+// diffEnvironments compares the current environment with the one claude would get.
+// Secret-looking values are masked. It also returns how many variables already match.
+func diffEnvironments(current, next []string, keyVar string) ([]envDiffEntry, int) {
+	before, after := environMap(current), environMap(next)
+	mask := func(key, value string) string {
+		return maskLaunchValue(key, value, keyVar)
+	}
+
+	var entries []envDiffEntry
+	unchanged := 0
+	for key, value := range after {
+		old, existed := before[key]
+		switch {
+		case !existed:
+			entries = append(entries, envDiffEntry{Key: key, Kind: envDiffAdded, New: mask(key, value)})
+		case old != value:
+			entries = append(entries, envDiffEntry{Key: key, Kind: envDiffChanged, Old: mask(key, old), New: mask(key, value)})
+		default:
+			unchanged++
+		}
+	}
+	for key, value := range before {
+		if _, kept := after[key]; !kept {
+			entries = append(entries, envDiffEntry{Key: key, Kind: envDiffCleared, Old: mask(key, value)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, unchanged
+}
+
+// renderEnvDiff writes the diff, then any settings.json keys that would shadow CCE's values
+func renderEnvDiff(w io.Writer, envName string, entries []envDiffEntry, unchanged int, plan launchPlan) error {
+	lines := []string{fmt.Sprintf("Environment changes for '%s' (+ added, ~ overridden, - cleared):", envName)}
+	for _, e := range entries {
+		switch e.Kind {
+		case envDiffAdded:
+			lines = append(lines, fmt.Sprintf("  + %s=%s", e.Key, e.New))
+		case envDiffChanged:
+			lines = append(lines, fmt.Sprintf("  ~ %s: %s -> %s", e.Key, e.Old, e.New))
+		case envDiffCleared:
+			lines = append(lines, fmt.Sprintf("  - %s (was %s)", e.Key, e.Old))
+		}
+	}
+	if len(entries) == 0 {
+		lines = append(lines, "  (no changes)")
+	}
+	lines = append(lines, fmt.Sprintf("  %d other variable(s) passed through unchanged", unchanged), "")
+
+	switch {
+	case plan.SettingsError != "":
+		lines = append(lines, fmt.Sprintf("Claude settings: %s", plan.SettingsError))
+	case len(plan.SettingsConflicts) > 0:
+		lines = append(lines,
+			fmt.Sprintf("Collisions: %s sets these in \"env\", overriding the values above:", plan.SettingsPath),
+			"  "+strings.Join(plan.SettingsConflicts, ", "))
+	default:
+		lines = append(lines, "Collisions: none")
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to display environment diff: %w", err)
+		}
+	}
+	return nil
+}
+
+// runEnvDiff prints how launching envName would change the process environment, without launching
+func runEnvDiff(envName string, opts launchOptions) error {
+	env, err := resolveLaunchEnvironment(envName, opts)
+	if err != nil {
+		return err
+	}
+	next, err := prepareEnvironment(env)
+	if err != nil {
+		return fmt.Errorf("environment preparation failed: %w", err)
+	}
+
+	entries, unchanged := diffEnvironments(os.Environ(), next, resolveAPIKeyVar(env))
+	return renderEnvDiff(os.Stdout, env.Name, entries, unchanged, buildLaunchPlan(env, nil))
+}
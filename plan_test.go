@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withClaudeSettings points the claude settings path at a temp file with the given content
+func withClaudeSettings(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if content != "" {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write settings: %v", err)
+		}
+	}
+	original := claudeSettingsPathOverride
+	claudeSettingsPathOverride = path
+	t.Cleanup(func() { claudeSettingsPathOverride = original })
+}
+
+func TestPlanCommand(t *testing.T) {
+	withTempConfigPath(t)
+	env := Environment{
+		Name:    "prod",
+		URL:     "https://api.anthropic.com",
+		APIKey:  "sk-ant-REDACTED",
+		Model:   "claude-3-5-sonnet-20241022",
+		EnvVars: map[string]string{"ANTHROPIC_TIMEOUT": "30", "PROXY_TOKEN": "tok-very-secret-value"},
+	}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	withClaudeSettings(t, `{"env": {"ANTHROPIC_BASE_URL": "https://other.example.com", "DISABLE_TELEMETRY": "1"}}`)
+	t.Setenv("ANTHROPIC_LEFTOVER", "x")
+
+	t.Run("human output", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			if err := handleCommand([]string{"plan", "--env", "prod", "--", "chat", "--verbose"}); err != nil {
+				t.Fatalf("plan failed: %v", err)
+			}
+		})
+		for _, snippet := range []string{
+			"Environment:  prod (https://api.anthropic.com)",
+			"claude-3-5-sonnet-20241022 (from environment model)",
+			"Argv:         claude chat --verbose",
+			"ANTHROPIC_TIMEOUT=30",
+			"ANTHROPIC_LEFTOVER",
+			"ANTHROPIC_BASE_URL",
+		} {
+			if !strings.Contains(output, snippet) {
+				t.Errorf("plan output missing %q:\n%s", snippet, output)
+			}
+		}
+		if strings.Contains(output, env.APIKey) || strings.Contains(output, "tok-very-secret-value") {
+			t.Errorf("plan leaked a secret:\n%s", output)
+		}
+		if strings.Contains(output, "DISABLE_TELEMETRY") {
+			t.Error("non-conflicting settings keys should not be reported")
+		}
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		output := captureStdout(t, func() {
+			if err := handleCommand([]string{"plan", "--json", "-e", "prod", "--key-var", "ANTHROPIC_AUTH_TOKEN"}); err != nil {
+				t.Fatalf("plan --json failed: %v", err)
+			}
+		})
+		var plan launchPlan
+		if err := json.Unmarshal([]byte(output), &plan); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, output)
+		}
+		if plan.KeyVar != "ANTHROPIC_AUTH_TOKEN" || plan.Environment != "prod" {
+			t.Errorf("unexpected plan: %+v", plan)
+		}
+		if len(plan.SettingsConflicts) != 1 || plan.SettingsConflicts[0] != "ANTHROPIC_BASE_URL" {
+			t.Errorf("unexpected conflicts: %v", plan.SettingsConflicts)
+		}
+		if len(plan.Argv) != 1 || plan.Argv[0] != "claude" {
+			t.Errorf("unexpected argv: %v", plan.Argv)
+		}
+	})
+
+	t.Run("rejects subcommands and unknown environments", func(t *testing.T) {
+		if err := runPlan([]string{"list"}); err == nil {
+			t.Error("expected plan of a subcommand to fail")
+		}
+		if err := runPlan([]string{"--env", "missing"}); err == nil {
+			t.Error("expected unknown environment to fail")
+		}
+	})
+}
+
+func TestDetectSettingsConflicts(t *testing.T) {
+	assignments := []envAssignment{{Key: "ANTHROPIC_BASE_URL"}, {Key: "CUSTOM_VAR"}}
+	settings := map[string]string{"CUSTOM_VAR": "a", "ANTHROPIC_MODEL": "b", "UNRELATED": "c"}
+	got := detectSettingsConflicts(assignments, settings)
+	if strings.Join(got, ",") != "ANTHROPIC_MODEL,CUSTOM_VAR" {
+		t.Errorf("detectSettingsConflicts() = %v", got)
+	}
+
+	withClaudeSettings(t, "")
+	if env, _, err := loadClaudeSettingsEnv(); err != nil || env != nil {
+		t.Errorf("missing settings file should be ignored, got %v %v", env, err)
+	}
+}
+
+func TestDryRunPrintsPlanWithoutLaunching(t *testing.T) {
+	withTempConfigPath(t)
+	withClaudeSettings(t, "")
+	env := Environment{
+		Name:    "prod",
+		URL:     "https://api.anthropic.com",
+		APIKey:  "sk-ant-REDACTED",
+		EnvVars: map[string]string{"HTTPS_PROXY": "http://proxy:3128"},
+	}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	launched := false
+	original := claudeLauncher
+	claudeLauncher = func(Environment, []string, string) error { launched = true; return nil }
+	t.Cleanup(func() { claudeLauncher = original })
+
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"--env", "prod", "-k", "ANTHROPIC_AUTH_TOKEN", "--dry-run", "--yolo", "-p", "hi there"}); err != nil {
+			t.Fatalf("--dry-run failed: %v", err)
+		}
+	})
+	if launched {
+		t.Error("--dry-run must not launch claude")
+	}
+	for _, want := range []string{
+		"Environment:  prod (https://api.anthropic.com)",
+		"Key variable: ANTHROPIC_AUTH_TOKEN",
+		"Argv:         claude --dangerously-skip-permissions -p 'hi there'",
+		"HTTPS_PROXY=http://proxy:3128",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry run output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, env.APIKey) {
+		t.Errorf("dry run leaked the API key:\n%s", out)
+	}
+}
+
+func TestDryRunKeepsClaudeFlagsAfterItsArguments(t *testing.T) {
+	withTempConfigPath(t)
+	withClaudeSettings(t, "")
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"--env", "prod", "--dry-run", "chat", "--force", "--watch", "--wait", "--config", "x", "--dry-run"}); err != nil {
+			t.Fatalf("--dry-run failed: %v", err)
+		}
+	})
+	if want := "Argv:         claude chat --force --watch --wait --config x --dry-run"; !strings.Contains(out, want) {
+		t.Errorf("dry run output missing %q:\n%s", want, out)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+const (
+	defaultPreflightTimeout = 60 * time.Second
+	maxPreflightTimeout     = 10 * time.Minute
+)
+
+// PreFlightSettings configures the org-wide command run before every launch
+type PreFlightSettings struct {
+	Command string `json:"command"`           // Run through the shell, e.g. "vpn-up --quiet"
+	Timeout string `json:"timeout,omitempty"` // Kill the command after this long, e.g. "30s"
+}
+
+// Active pre-flight settings, set from the config by applyPreflightSettings
+var (
+	preflightCommand string
+	preflightTimeout = defaultPreflightTimeout
+)
+
+// applyPreflightSettings validates and activates settings.preflight
+func applyPreflightSettings(settings *ConfigSettings) error {
+	preflightCommand = ""
+	preflightTimeout = defaultPreflightTimeout
+	if settings == nil || settings.PreFlight == nil {
+		return nil
+	}
+	if settings.PreFlight.Timeout != "" {
+		timeout, err := time.ParseDuration(settings.PreFlight.Timeout)
+		if err != nil || timeout <= 0 || timeout > maxPreflightTimeout {
+			return fmt.Errorf("timeout '%s' must be a positive duration up to %s", settings.PreFlight.Timeout, maxPreflightTimeout)
+		}
+		preflightTimeout = timeout
+	}
+	preflightCommand = settings.PreFlight.Command
+	return nil
+}
+
+// shellCommand builds the platform shell invocation for a command line
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// runPreflight runs the configured pre-flight command once with the environment claude
+// will get, plus CCE_ENV_NAME. Its output goes to stderr so print-mode stdout stays clean;
+// a non-zero exit or timeout aborts the launch.
+func runPreflight(env Environment, workdir string) error {
+	if preflightCommand == "" {
+		return nil
+	}
+
+	envVars, err := prepareEnvironment(env)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, preflightCommand)
+	cmd.Env = append(envVars, "CCE_ENV_NAME="+env.Name)
+	cmd.Dir = workdir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", preflightTimeout)
+	}
+
+	errorCtx := newErrorContext("pre-flight command", "main runner")
+	errorCtx.addContext("command", preflightCommand)
+	errorCtx.addContext("environment", env.Name)
+	errorCtx.addSuggestion("Fix the command or its timeout in settings.preflight")
+	errorCtx.addSuggestion("Pass --skip-preflight to launch without it")
+	return errorCtx.formatError(err)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// withPreflightConfig writes a one-environment config whose settings run command before launch
+func withPreflightConfig(t *testing.T, command, timeout string) {
+	t.Helper()
+	path := withTempConfigPath(t)
+	t.Cleanup(func() { applyPreflightSettings(nil) })
+	content := fmt.Sprintf(`{
+  "environments": [{"name": "prod", "url": "https://api.anthropic.com", "api_key": "sk-ant-api03-test-key-123"}],
+  "settings": {"preflight": {"command": %q, "timeout": %q}}
+}`, command, timeout)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+// stubLauncher records whether claude would have been launched
+func stubLauncher(t *testing.T) *bool {
+	t.Helper()
+	launched := false
+	original := claudeLauncher
+	claudeLauncher = func(env Environment, args []string, workdir string) error {
+		launched = true
+		return nil
+	}
+	t.Cleanup(func() { claudeLauncher = original })
+	return &launched
+}
+
+func TestApplyPreflightSettings(t *testing.T) {
+	t.Cleanup(func() { applyPreflightSettings(nil) })
+
+	if err := applyPreflightSettings(&ConfigSettings{PreFlight: &PreFlightSettings{Command: "true"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preflightCommand != "true" || preflightTimeout != defaultPreflightTimeout {
+		t.Errorf("got command %q timeout %s", preflightCommand, preflightTimeout)
+	}
+	for _, bad := range []string{"soon", "-1s", "0s", "11m"} {
+		if err := applyPreflightSettings(&ConfigSettings{PreFlight: &PreFlightSettings{Command: "true", Timeout: bad}}); err == nil {
+			t.Errorf("timeout %q should be rejected", bad)
+		}
+	}
+	if err := applyPreflightSettings(nil); err != nil || preflightCommand != "" {
+		t.Errorf("nil settings should clear the command, got %q, %v", preflightCommand, err)
+	}
+}
+
+func TestPreflightRunsWithPreparedEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	out := filepath.Join(t.TempDir(), "preflight.out")
+	withPreflightConfig(t, `echo "$CCE_ENV_NAME $ANTHROPIC_BASE_URL" > `+out, "5s")
+	launched := stubLauncher(t)
+
+	captureStdout(t, func() {
+		if err := runDefaultWithOptions("prod", nil, launchOptions{}); err != nil {
+			t.Fatalf("launch failed: %v", err)
+		}
+	})
+	if !*launched {
+		t.Error("claude should launch after a successful pre-flight")
+	}
+	data, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatalf("pre-flight did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "prod https://api.anthropic.com" {
+		t.Errorf("pre-flight saw %q", got)
+	}
+}
+
+func TestPreflightFailureAbortsLaunch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	withPreflightConfig(t, "exit 3", "5s")
+	launched := stubLauncher(t)
+
+	var err error
+	captureStdout(t, func() {
+		err = runDefaultWithOptions("prod", nil, launchOptions{})
+	})
+	if err == nil || !strings.Contains(err.Error(), "pre-flight command failed") || !strings.Contains(err.Error(), "--skip-preflight") {
+		t.Fatalf("expected pre-flight failure with hint, got %v", err)
+	}
+	if *launched {
+		t.Error("claude must not launch when pre-flight fails")
+	}
+
+	captureStdout(t, func() {
+		if err := runDefaultWithOptions("prod", nil, launchOptions{SkipPreflight: true}); err != nil {
+			t.Fatalf("--skip-preflight launch failed: %v", err)
+		}
+	})
+	if !*launched {
+		t.Error("--skip-preflight should launch without running the command")
+	}
+}
+
+func TestPreflightTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	withPreflightConfig(t, "exec sleep 5", "100ms")
+	launched := stubLauncher(t)
+
+	var err error
+	captureStdout(t, func() {
+		err = runDefaultWithOptions("prod", nil, launchOptions{})
+	})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if *launched {
+		t.Error("claude must not launch when pre-flight times out")
+	}
+}
+
+func TestParseSkipPreflight(t *testing.T) {
+	parsed := parseArguments([]string{"--skip-preflight", "--env", "prod", "chat"})
+	if parsed.CCEFlags["skip_preflight"] != "true" || strings.Join(parsed.ClaudeArgs, " ") != "chat" {
+		t.Errorf("unexpected parse result %+v", parsed)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateProviderFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     Environment
+		wantErr string
+	}{
+		{"default", Environment{}, ""},
+		{"anthropic with region", Environment{Provider: "anthropic", Region: "us-east-1"}, "only apply"},
+		{"bedrock", Environment{Provider: "bedrock", Region: "us-east-1"}, ""},
+		{"bedrock without region", Environment{Provider: "bedrock"}, "requires region"},
+		{"bedrock with project", Environment{Provider: "bedrock", Region: "us-east-1", Project: "my-project"}, "only applies to the vertex"},
+		{"vertex", Environment{Provider: "vertex", Region: "us-east5", Project: "my-project"}, ""},
+		{"vertex without project", Environment{Provider: "vertex", Region: "us-east5"}, "requires project"},
+		{"vertex bad project", Environment{Provider: "vertex", Region: "us-east5", Project: "My_Project"}, "invalid project"},
+		{"bad region", Environment{Provider: "bedrock", Region: "us east"}, "invalid region"},
+		{"unknown", Environment{Provider: "azure"}, "unknown provider"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateProviderFields(tt.env)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCloudProviderNeedsNoURLOrKey(t *testing.T) {
+	env := Environment{Name: "aws", Provider: "bedrock", Region: "us-east-1"}
+	if err := validateEnvironment(env); err != nil {
+		t.Fatalf("bedrock without url/key should validate: %v", err)
+	}
+	env.Provider = ""
+	env.Region = ""
+	if err := validateEnvironment(env); err == nil {
+		t.Fatal("anthropic environment without url should fail validation")
+	}
+}
+
+func TestPrepareEnvironmentInjectsProviderBundle(t *testing.T) {
+	t.Setenv("CLAUDE_CODE_USE_VERTEX", "1")
+	t.Setenv("ANTHROPIC_BASE_URL", "https://stale.example.com")
+
+	env := Environment{Name: "aws", Provider: "bedrock", Region: "us-west-2", URL: "https://gateway.example.com"}
+	vars, err := prepareEnvironment(env)
+	if err != nil {
+		t.Fatalf("prepareEnvironment() failed: %v", err)
+	}
+	got := map[string]string{}
+	for _, kv := range vars {
+		parts := strings.SplitN(kv, "=", 2)
+		got[parts[0]] = parts[1]
+	}
+	want := map[string]string{
+		"CLAUDE_CODE_USE_BEDROCK":    "1",
+		"AWS_REGION":                 "us-west-2",
+		"ANTHROPIC_BEDROCK_BASE_URL": "https://gateway.example.com",
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+	for _, key := range []string{"CLAUDE_CODE_USE_VERTEX", "ANTHROPIC_BASE_URL", "ANTHROPIC_API_KEY"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("%s should not be set for a keyless bedrock environment", key)
+		}
+	}
+
+	vertex := Environment{Name: "gcp", Provider: "vertex", Region: "us-east5", Project: "my-project"}
+	assignments := launchVariables(vertex)
+	keys := []string{}
+	for _, a := range assignments {
+		keys = append(keys, a.Key+"="+a.Value)
+	}
+	if strings.Join(keys, " ") != "CLAUDE_CODE_USE_VERTEX=1 CLOUD_ML_REGION=us-east5 ANTHROPIC_VERTEX_PROJECT_ID=my-project" {
+		t.Errorf("unexpected vertex bundle: %v", keys)
+	}
+}
+
+func TestPrepareEnvironmentClearsInheritedProviderSwitch(t *testing.T) {
+	t.Setenv("CLAUDE_CODE_USE_BEDROCK", "1")
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-test-key-123"}
+	vars, err := prepareEnvironment(env)
+	if err != nil {
+		t.Fatalf("prepareEnvironment() failed: %v", err)
+	}
+	for _, kv := range vars {
+		if strings.HasPrefix(kv, "CLAUDE_CODE_USE_BEDROCK=") {
+			t.Errorf("inherited provider switch leaked into anthropic launch: %s", kv)
+		}
+	}
+}
+
+func TestSetSwitchesEnvironmentToProvider(t *testing.T) {
+	env := Environment{Name: "aws", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-test-key-123"}
+	updates := map[string]string{"provider": "Bedrock", "region": "US-EAST-1", "url": "", "api_key": ""}
+	order := []string{"provider", "region", "url", "api_key"}
+	updated, err := applyFieldUpdatesWithOptions(env, updates, order, false)
+	if err != nil {
+		t.Fatalf("applyFieldUpdatesWithOptions() failed: %v", err)
+	}
+	if updated.Provider != "bedrock" || updated.Region != "us-east-1" || updated.URL != "" || updated.APIKey != "" {
+		t.Errorf("unexpected environment after set: %+v", updated)
+	}
+
+	if _, err := applyFieldUpdatesWithOptions(env, map[string]string{"url": ""}, []string{"url"}, false); err == nil {
+		t.Error("clearing the url of an anthropic environment should fail")
+	}
+}
+
+func TestListShowsProvider(t *testing.T) {
+	config := Config{Environments: []Environment{
+		{Name: "gcp", Provider: "vertex", Region: "us-east5", Project: "my-project"},
+	}}
+	out := captureStdout(t, func() {
+		if err := displayEnvironments(config); err != nil {
+			t.Fatalf("displayEnvironments() failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Provider: vertex (us-east5, my-project)") {
+		t.Errorf("expected provider line, got:\n%s", out)
+	}
+	if !strings.Contains(out, "(provider default)") {
+		t.Errorf("expected provider default URL, got:\n%s", out)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Providers an environment can target
+const (
+	providerAnthropic = "anthropic" // Anthropic API or a compatible gateway (the default)
+	providerBedrock   = "bedrock"   // Amazon Bedrock, authenticated by the AWS credential chain
+	providerVertex    = "vertex"    // Google Vertex AI, authenticated by gcloud credentials
+)
+
+// providerSwitchVars are the variables that route claude to a cloud provider. An inherited
+// one would silently redirect every other environment, so launches always clear them.
+var providerSwitchVars = []string{"CLAUDE_CODE_USE_BEDROCK", "CLAUDE_CODE_USE_VERTEX"}
+
+var (
+	regionPattern     = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+	gcpProjectPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+)
+
+// isProviderSwitch reports whether an inherited KEY=VALUE entry is a provider switch
+func isProviderSwitch(envVar string) bool {
+	for _, key := range providerSwitchVars {
+		if strings.HasPrefix(envVar, key+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// environmentProvider returns env's provider, defaulting to anthropic
+func environmentProvider(env Environment) string {
+	if env.Provider == "" {
+		return providerAnthropic
+	}
+	return env.Provider
+}
+
+// isCloudProvider reports whether env talks to Bedrock or Vertex rather than a URL+key endpoint
+func isCloudProvider(env Environment) bool {
+	provider := environmentProvider(env)
+	return provider == providerBedrock || provider == providerVertex
+}
+
+// validateProviderFields checks the provider name and the structured fields it requires
+func validateProviderFields(env Environment) error {
+	switch environmentProvider(env) {
+	case providerAnthropic:
+		if env.Region != "" || env.Project != "" {
+			return fmt.Errorf("region and project only apply to the bedrock and vertex providers")
+		}
+		return nil
+	case providerBedrock:
+		if env.Project != "" {
+			return fmt.Errorf("project only applies to the vertex provider")
+		}
+	case providerVertex:
+		if env.Project == "" {
+			return fmt.Errorf("vertex requires project (the Google Cloud project ID)")
+		}
+		if !gcpProjectPattern.MatchString(env.Project) {
+			return fmt.Errorf("invalid project '%s' (expected a Google Cloud project ID)", env.Project)
+		}
+	default:
+		return fmt.Errorf("unknown provider '%s' (expected %s, %s or %s)", env.Provider, providerAnthropic, providerBedrock, providerVertex)
+	}
+
+	if env.Region == "" {
+		return fmt.Errorf("%s requires region (e.g. us-east-1)", env.Provider)
+	}
+	if !regionPattern.MatchString(env.Region) {
+		return fmt.Errorf("invalid region '%s'", env.Region)
+	}
+	return nil
+}
+
+// providerVariables is the bundle of variables a cloud provider needs. An optional URL
+// becomes the provider's base URL override (e.g. an LLM gateway in front of Bedrock).
+func providerVariables(env Environment) []envAssignment {
+	switch environmentProvider(env) {
+	case providerBedrock:
+		vars := []envAssignment{
+			{Key: "CLAUDE_CODE_USE_BEDROCK", Value: "1"},
+			{Key: "AWS_REGION", Value: env.Region},
+		}
+		if env.URL != "" {
+			vars = append(vars, envAssignment{Key: "ANTHROPIC_BEDROCK_BASE_URL", Value: env.URL})
+		}
+		return vars
+	case providerVertex:
+		vars := []envAssignment{
+			{Key: "CLAUDE_CODE_USE_VERTEX", Value: "1"},
+			{Key: "CLOUD_ML_REGION", Value: env.Region},
+			{Key: "ANTHROPIC_VERTEX_PROJECT_ID", Value: env.Project},
+		}
+		if env.URL != "" {
+			vars = append(vars, envAssignment{Key: "ANTHROPIC_VERTEX_BASE_URL", Value: env.URL})
+		}
+		return vars
+	}
+	return nil
+}
+
+// cloudCLI names the vendor tool that can verify a cloud provider's credentials
+func cloudCLI(env Environment) string {
+	if environmentProvider(env) == providerVertex {
+		return "gcloud"
+	}
+	return "aws"
+}
+
+// describeProvider renders the provider for listings, e.g. "vertex (us-east5, my-project)"
+func describeProvider(env Environment) string {
+	provider := environmentProvider(env)
+	details := []string{}
+	if env.Region != "" {
+		details = append(details, env.Region)
+	}
+	if env.Project != "" {
+		details = append(details, env.Project)
+	}
+	if len(details) == 0 {
+		return provider
+	}
+	return fmt.Sprintf("%s (%s)", provider, strings.Join(details, ", "))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// stubRawMode replaces the raw-mode hooks and counts restorations
+func stubRawMode(t *testing.T) (restores *int, exits chan int) {
+	t.Helper()
+	originalEnter, originalRestore, originalExit := rawModeEnter, rawModeRestore, signalExit
+	t.Cleanup(func() {
+		rawModeEnter, rawModeRestore, signalExit = originalEnter, originalRestore, originalExit
+	})
+
+	count := 0
+	exits = make(chan int, 1)
+	rawModeEnter = func(fd int) (*term.State, error) { return &term.State{}, nil }
+	rawModeRestore = func(fd int, state *term.State) error {
+		count++
+		return nil
+	}
+	signalExit = func(code int) { exits <- code }
+	return &count, exits
+}
+
+func TestRawModeRestoredOnSignal(t *testing.T) {
+	restores, exits := stubRawMode(t)
+
+	ts, err := enterRawMode(0)
+	if err != nil {
+		t.Fatalf("enterRawMode() failed: %v", err)
+	}
+
+	// Simulate Ctrl-C / kill arriving mid-selection
+	ts.signals <- syscall.SIGTERM
+
+	select {
+	case code := <-exits:
+		if code != 128+int(syscall.SIGTERM) {
+			t.Errorf("exit code = %d, want %d", code, 128+int(syscall.SIGTERM))
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("signal watcher did not exit")
+	}
+
+	if *restores != 1 {
+		t.Errorf("expected terminal restored once, got %d", *restores)
+	}
+
+	// The caller's deferred restore must not restore twice
+	ts.ensureRestore()
+	if *restores != 1 {
+		t.Errorf("expected restore to be idempotent, got %d", *restores)
+	}
+}
+
+func TestRawModeRestoredOnPanic(t *testing.T) {
+	restores, _ := stubRawMode(t)
+
+	func() {
+		defer func() { _ = recover() }()
+		ts, err := enterRawMode(0)
+		if err != nil {
+			t.Fatalf("enterRawMode() failed: %v", err)
+		}
+		defer ts.ensureRestore()
+		panic("selection crashed")
+	}()
+
+	if *restores != 1 {
+		t.Errorf("expected terminal restored after panic, got %d", *restores)
+	}
+}
+
+func TestRawModeNormalExitStopsWatcher(t *testing.T) {
+	restores, exits := stubRawMode(t)
+
+	ts, err := enterRawMode(0)
+	if err != nil {
+		t.Fatalf("enterRawMode() failed: %v", err)
+	}
+	ts.ensureRestore()
+
+	if *restores != 1 || ts.signals != nil {
+		t.Errorf("expected restore and watcher shutdown, restores=%d", *restores)
+	}
+	select {
+	case code := <-exits:
+		t.Errorf("unexpected exit %d after normal restore", code)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func seedRemoveAllConfig(t *testing.T) string {
+	t.Helper()
+	path := withTempConfigPath(t)
+	config := Config{Environments: []Environment{
+		{Name: "one", URL: "https://api.anthropic.com", APIKey: "sk-ant-remove-one"},
+		{Name: "two", URL: "https://api.example.com", APIKey: "sk-ant-remove-two"},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	return path
+}
+
+func stubTerminal(t *testing.T, attached bool, answer string) {
+	t.Helper()
+	origTerminal, origReader := stdinIsTerminal, confirmationReader
+	stdinIsTerminal = func() bool { return attached }
+	confirmationReader = func(string) (string, error) { return answer, nil }
+	t.Cleanup(func() {
+		stdinIsTerminal = origTerminal
+		confirmationReader = origReader
+	})
+}
+
+func TestParseRemoveAll(t *testing.T) {
+	tests := []struct {
+		args    []string
+		flags   map[string]string
+		wantErr string
+	}{
+		{[]string{"remove", "--all"}, map[string]string{"remove_all": "true"}, ""},
+		{[]string{"remove", "--all", "--yes"}, map[string]string{"remove_all": "true", "yes": "true"}, ""},
+		{[]string{"remove", "-y", "--all"}, map[string]string{"remove_all": "true", "yes": "true"}, ""},
+		{[]string{"remove", "--all", "prod"}, nil, "does not take an environment name"},
+		{[]string{"remove", "--force"}, nil, "unknown remove flag"},
+		{[]string{"remove", "a", "b"}, nil, "single environment name"},
+	}
+	for _, tt := range tests {
+		result := parseArguments(tt.args)
+		if tt.wantErr != "" {
+			if result.Error == nil || !strings.Contains(result.Error.Error(), tt.wantErr) {
+				t.Errorf("%v: error = %v, want %q", tt.args, result.Error, tt.wantErr)
+			}
+			continue
+		}
+		if result.Error != nil || result.Subcommand != "remove" {
+			t.Fatalf("%v: unexpected result %+v", tt.args, result)
+		}
+		for key, want := range tt.flags {
+			if result.CCEFlags[key] != want {
+				t.Errorf("%v: CCEFlags[%s] = %q, want %q", tt.args, key, result.CCEFlags[key], want)
+			}
+		}
+	}
+}
+
+func TestRemoveAllConfirmation(t *testing.T) {
+	for _, answer := range []string{"yes", "2"} {
+		t.Run(answer, func(t *testing.T) {
+			path := seedRemoveAllConfig(t)
+			stubTerminal(t, true, answer)
+
+			out := captureStdout(t, func() {
+				if err := handleCommand([]string{"remove", "--all"}); err != nil {
+					t.Fatalf("remove --all failed: %v", err)
+				}
+			})
+			if !strings.Contains(out, "one (https://api.anthropic.com)") || !strings.Contains(out, "Removed 2 environment(s)") {
+				t.Errorf("unexpected output:\n%s", out)
+			}
+
+			config, err := loadConfig()
+			if err != nil {
+				t.Fatalf("loadConfig() failed: %v", err)
+			}
+			if len(config.Environments) != 0 {
+				t.Errorf("expected no environments, got %d", len(config.Environments))
+			}
+
+			backups, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "backups", "config-*.json"))
+			if len(backups) == 0 {
+				t.Fatal("expected a backup of the wiped configuration")
+			}
+			data, _ := ioutil.ReadFile(backups[len(backups)-1])
+			if !strings.Contains(string(data), "sk-ant-remove-two") {
+				t.Errorf("backup should hold the removed environments:\n%s", data)
+			}
+		})
+	}
+}
+
+func TestRemoveAllRejectsWrongConfirmation(t *testing.T) {
+	seedRemoveAllConfig(t)
+	stubTerminal(t, true, "y")
+
+	var err error
+	captureStdout(t, func() { err = handleCommand([]string{"remove", "--all"}) })
+	if err == nil || !strings.Contains(err.Error(), "remove cancelled") {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	config, _ := loadConfig()
+	if len(config.Environments) != 2 {
+		t.Errorf("environments should be untouched, got %d", len(config.Environments))
+	}
+}
+
+func TestRemoveAllNonInteractiveRequiresYes(t *testing.T) {
+	seedRemoveAllConfig(t)
+	stubTerminal(t, false, "")
+
+	var err error
+	captureStdout(t, func() { err = handleCommand([]string{"remove", "--all"}) })
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Fatalf("expected --yes requirement, got %v", err)
+	}
+
+	captureStdout(t, func() { err = handleCommand([]string{"remove", "--all", "--yes"}) })
+	if err != nil {
+		t.Fatalf("remove --all --yes failed: %v", err)
+	}
+	config, _ := loadConfig()
+	if len(config.Environments) != 0 {
+		t.Errorf("expected no environments, got %d", len(config.Environments))
+	}
+}
+
+func TestRemoveAllEmptyConfig(t *testing.T) {
+	path := withTempConfigPath(t)
+	if err := ioutil.WriteFile(path, []byte(`{"environments": []}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stubTerminal(t, false, "")
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"remove", "--all"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "nothing to remove") {
+		t.Errorf("unexpected output: %s", out)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "backups")); err == nil {
+		t.Error("no backup should be made when nothing is removed")
+	}
+}
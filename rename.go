@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runRename renames an environment in place, so its position in the list and every
+// other field stay as they are
+func runRename(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("argument parsing failed: rename requires the current and the new environment name")
+	}
+	oldName, newName := args[0], args[1]
+	if err := validateName(newName); err != nil {
+		return fmt.Errorf("argument validation failed: invalid name '%s': %w", newName, err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	index, err := lookupEnvironment(config, oldName)
+	if err != nil {
+		return err
+	}
+	oldName = config.Environments[index].Name
+	if oldName == newName {
+		if _, err := fmt.Printf("Environment '%s' already has that name; nothing to change.\n", oldName); err != nil {
+			return fmt.Errorf("failed to display message: %w", err)
+		}
+		return nil
+	}
+	// A case-only rename collides with nothing but the environment itself
+	if existing, taken := environmentNameTaken(config, newName); taken && existing != oldName {
+		return fmt.Errorf("argument validation failed: environment '%s' already exists", existing)
+	}
+
+	config.Environments[index].Name = newName
+	if config.DefaultEnv == oldName {
+		config.DefaultEnv = newName
+	}
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	// Keep the round-robin position with the environment
+	state := loadKeyState()
+	if next, ok := state.Next[oldName]; ok {
+		delete(state.Next, oldName)
+		state.Next[newName] = next
+		if err := saveKeyState(state); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to move key rotation state: %v\n", err)
+		}
+	}
+
+	if _, err := fmt.Printf("Environment '%s' renamed to '%s'.\n", oldName, newName); err != nil {
+		return fmt.Errorf("failed to display success message: %w", err)
+	}
+	return nil
+}
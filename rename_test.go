@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func saveRenameConfig(t *testing.T) {
+	t.Helper()
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+		{Name: "staging", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED", Model: "claude-sonnet-4-20250514"},
+		{Name: "dev", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+}
+
+func TestRenameKeepsPositionAndKeyState(t *testing.T) {
+	withTempConfigPath(t)
+	saveRenameConfig(t)
+	if err := saveKeyState(keyState{Next: map[string]int{"staging": 2}}); err != nil {
+		t.Fatalf("saveKeyState() failed: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := handleCommand([]string{"rename", "staging", "qa"}); err != nil {
+			t.Fatalf("rename failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Environment 'staging' renamed to 'qa'.") {
+		t.Errorf("unexpected output: %q", out)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	var names []string
+	for _, env := range config.Environments {
+		names = append(names, env.Name)
+	}
+	if strings.Join(names, ",") != "prod,qa,dev" {
+		t.Errorf("expected the order to be kept, got %v", names)
+	}
+	if config.Environments[1].Model != "claude-sonnet-4-20250514" {
+		t.Errorf("rename lost fields: %+v", config.Environments[1])
+	}
+	state := loadKeyState()
+	if _, ok := state.Next["staging"]; ok || state.Next["qa"] != 2 {
+		t.Errorf("expected the key state to follow the rename, got %v", state.Next)
+	}
+}
+
+func TestRenameSameNameIsNoOp(t *testing.T) {
+	withTempConfigPath(t)
+	saveRenameConfig(t)
+	out := captureStdout(t, func() {
+		if err := runRename([]string{"dev", "dev"}); err != nil {
+			t.Fatalf("rename failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "nothing to change") {
+		t.Errorf("expected a no-op message, got %q", out)
+	}
+}
+
+func TestRenameRejectsBadTargets(t *testing.T) {
+	withTempConfigPath(t)
+	saveRenameConfig(t)
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"staging", "prod"}, "already exists"},
+		{[]string{"missing", "qa"}, "not found"},
+		{[]string{"staging", "bad name"}, "invalid name"},
+		{[]string{"staging"}, "requires the current"},
+	} {
+		if err := runRename(tc.args); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected %q, got %v", tc.args, tc.want, err)
+		}
+	}
+	config, _ := loadConfig()
+	if config.Environments[1].Name != "staging" {
+		t.Errorf("failed renames must not change the config: %+v", config.Environments)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// rotateNow allows tests to pin the rotation timestamp
+var rotateNow = time.Now
+
+// rotateOptions holds flags accepted by the rotate subcommand
+type rotateOptions struct {
+	Name     string
+	Rollback bool // Swap the previous key back in instead of entering a new one
+	Test     bool // Require a passing network check with the new key before saving
+}
+
+// parseRotateOptions parses `rotate [--test] <name>` and `rotate --rollback <name>`
+func parseRotateOptions(args []string) (rotateOptions, error) {
+	var opts rotateOptions
+	for _, arg := range args {
+		switch arg {
+		case "--rollback":
+			opts.Rollback = true
+		case "--test":
+			opts.Test = true
+		default:
+			if len(arg) > 0 && arg[0] == '-' {
+				return rotateOptions{}, fmt.Errorf("unknown rotate flag: %s", arg)
+			}
+			if opts.Name != "" {
+				return rotateOptions{}, fmt.Errorf("rotate takes a single environment name")
+			}
+			opts.Name = arg
+		}
+	}
+	if opts.Name == "" {
+		return rotateOptions{}, fmt.Errorf("rotate requires an environment name")
+	}
+	if opts.Rollback && opts.Test {
+		return rotateOptions{}, fmt.Errorf("--test cannot be combined with --rollback")
+	}
+	return opts, nil
+}
+
+// rotateKey installs newKey and keeps the current key as the timestamped previous key
+func rotateKey(env Environment, newKey string, now time.Time) Environment {
+	rotated := env
+	rotated.PreviousAPIKey = env.APIKey
+	rotated.KeyRotatedAt = now.UTC().Format(time.RFC3339)
+	rotated.APIKey = newKey
+	rotated.Unvalidated = append([]string(nil), env.Unvalidated...)
+	clearUnvalidated(&rotated, fieldAPIKey)
+	return rotated
+}
+
+// rollbackKey swaps the previous key back in; the replaced key becomes the previous one,
+// so a second rollback undoes the first
+func rollbackKey(env Environment, now time.Time) (Environment, error) {
+	if env.PreviousAPIKey == "" {
+		return Environment{}, fmt.Errorf("environment '%s' has no previous key to roll back to", env.Name)
+	}
+	return rotateKey(env, env.PreviousAPIKey, now), nil
+}
+
+// runRotate replaces an environment's API key (or rolls it back) and saves
+func runRotate(args []string) error {
+	opts, err := parseRotateOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	index, err := lookupEnvironment(config, opts.Name)
+	if err != nil {
+		return err
+	}
+	current := config.Environments[index]
+
+	var updated Environment
+	if opts.Rollback {
+		if updated, err = rollbackKey(current, rotateNow()); err != nil {
+			return err
+		}
+	} else {
+		if noPrompt && stdinIsTerminal() {
+			return promptRefused("rotate", "pipe the new key on stdin")
+		}
+		newKey, err := secretReader(fmt.Sprintf("New API key for '%s' (hidden): ", current.Name))
+		if err != nil {
+			return fmt.Errorf("failed to read API key: %w", err)
+		}
+		if err := validateAPIKey(newKey); err != nil {
+			return fmt.Errorf("invalid API key: %w", err)
+		}
+		if newKey == current.APIKey {
+			return fmt.Errorf("the new key is the same as the current key for '%s'", current.Name)
+		}
+		updated = rotateKey(current, newKey, rotateNow())
+
+		if opts.Test {
+			result, err := newNetworkValidator(0).checkEnvironment(updated)
+			if err != nil {
+				return fmt.Errorf("key for '%s' not rotated: %w", current.Name, err)
+			}
+			if _, err := fmt.Printf("Network check passed: %s\n", result.describe()); err != nil {
+				return fmt.Errorf("failed to display network check result: %w", err)
+			}
+		}
+	}
+
+	config.Environments[index] = updated
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	verb, hint := "Rotated", fmt.Sprintf("Roll back with 'cce rotate --rollback %s'.", current.Name)
+	if opts.Rollback {
+		verb, hint = "Rolled back", "Run the rollback again to undo it."
+	}
+	if _, err := fmt.Printf("%s key for '%s' (fingerprint %s → %s). %s\n", verb, current.Name,
+		keyFingerprint(current.APIKey), keyFingerprint(updated.APIKey), hint); err != nil {
+		return fmt.Errorf("failed to display success message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func withRotateConfig(t *testing.T, url string) {
+	t.Helper()
+	path := withTempConfigPath(t)
+	content := `{"environments": [{"name": "prod", "url": "` + url + `", "api_key": "sk-ant-api03-old-key-1234"}]}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	original, originalNow := secretReader, rotateNow
+	secretReader = func(string) (string, error) { return "sk-ant-api03-new-key-5678", nil }
+	rotateNow = func() time.Time { return time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC) }
+	t.Cleanup(func() {
+		secretReader = original
+		rotateNow = originalNow
+	})
+}
+
+func TestParseRotateOptions(t *testing.T) {
+	if opts, err := parseRotateOptions([]string{"--test", "prod"}); err != nil || opts.Name != "prod" || !opts.Test {
+		t.Errorf("parseRotateOptions(--test prod) = %+v, %v", opts, err)
+	}
+	if opts, err := parseRotateOptions([]string{"--rollback", "prod"}); err != nil || !opts.Rollback {
+		t.Errorf("parseRotateOptions(--rollback prod) = %+v, %v", opts, err)
+	}
+	for _, args := range [][]string{{}, {"a", "b"}, {"--rollback", "--test", "prod"}, {"--bogus", "prod"}} {
+		if _, err := parseRotateOptions(args); err == nil {
+			t.Errorf("parseRotateOptions(%q) should fail", args)
+		}
+	}
+}
+
+func TestRotateAndRollback(t *testing.T) {
+	withRotateConfig(t, "https://api.anthropic.com")
+
+	out := captureStdout(t, func() {
+		if err := runRotate([]string{"prod"}); err != nil {
+			t.Fatalf("runRotate() failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Rotated key for 'prod'") || !strings.Contains(out, "cce rotate --rollback prod") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	env := config.Environments[0]
+	if env.APIKey != "sk-ant-api03-new-key-5678" || env.PreviousAPIKey != "sk-ant-api03-old-key-1234" || env.KeyRotatedAt != "2026-10-16T09:30:00Z" {
+		t.Fatalf("unexpected environment after rotate: %+v", env)
+	}
+
+	listing := captureStdout(t, func() {
+		if err := displayEnvironments(config); err != nil {
+			t.Fatalf("displayEnvironments() failed: %v", err)
+		}
+	})
+	if !strings.Contains(listing, "Rotated: 2026-10-16T09:30:00Z (previous key "+keyFingerprint("sk-ant-api03-old-key-1234")) {
+		t.Errorf("expected rotation line in list:\n%s", listing)
+	}
+
+	captureStdout(t, func() {
+		if err := runRotate([]string{"--rollback", "prod"}); err != nil {
+			t.Fatalf("rollback failed: %v", err)
+		}
+	})
+	config, _ = loadConfig()
+	env = config.Environments[0]
+	if env.APIKey != "sk-ant-api03-old-key-1234" || env.PreviousAPIKey != "sk-ant-api03-new-key-5678" {
+		t.Errorf("rollback should swap the keys, got %+v", env)
+	}
+}
+
+func TestRotateRejectsSameOrInvalidKey(t *testing.T) {
+	withRotateConfig(t, "https://api.anthropic.com")
+
+	secretReader = func(string) (string, error) { return "sk-ant-api03-old-key-1234", nil }
+	if err := runRotate([]string{"prod"}); err == nil || !strings.Contains(err.Error(), "same as the current key") {
+		t.Errorf("expected same-key error, got %v", err)
+	}
+	secretReader = func(string) (string, error) { return "short", nil }
+	if err := runRotate([]string{"prod"}); err == nil || !strings.Contains(err.Error(), "invalid API key") {
+		t.Errorf("expected invalid-key error, got %v", err)
+	}
+	if err := runRotate([]string{"--rollback", "prod"}); err == nil || !strings.Contains(err.Error(), "no previous key") {
+		t.Errorf("expected rollback without history to fail, got %v", err)
+	}
+}
+
+func TestRotateTestRefusesRejectedKey(t *testing.T) {
+	server := newProbeServer(t, http.StatusUnauthorized)
+	withRotateConfig(t, server.URL)
+
+	if err := runRotate([]string{"--test", "prod"}); err == nil || !strings.Contains(err.Error(), "not rotated") {
+		t.Fatalf("expected rotation to be refused, got %v", err)
+	}
+	config, _ := loadConfig()
+	if env := config.Environments[0]; env.APIKey != "sk-ant-api03-old-key-1234" || env.PreviousAPIKey != "" {
+		t.Errorf("a failed --test must not change the key, got %+v", env)
+	}
+}
+
+func TestExportRedactStripsPreviousKey(t *testing.T) {
+	config := Config{Environments: []Environment{{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-new", PreviousAPIKey: "sk-old"}}}
+	doc, err := buildExportDocument(config, exportOptions{Redact: true})
+	if err != nil {
+		t.Fatalf("buildExportDocument() failed: %v", err)
+	}
+	if doc.Environments[0].PreviousAPIKey != "" {
+		t.Error("redacted export leaked the previous key")
+	}
+}
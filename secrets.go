@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// secretRefPrefix marks an api_key held in a SecretStore: keychain:<service>/<account>
+const secretRefPrefix = "keychain:"
+
+// secretService is the service name cce stores its keys under
+const secretService = "cce"
+
+// SecretStore keeps API keys outside the config file. Keys are addressed by a service
+// and an account, which cce sets to "cce" and a per-secret account from newSecretAccount.
+type SecretStore interface {
+	Name() string // Shown in messages, e.g. "macOS Keychain"
+	Get(service, account string) (string, error)
+	Set(service, account, secret string) error
+}
+
+// secretStore is the active store; nil means choose one with newSecretStore. Tests replace it.
+var secretStore SecretStore
+
+// newSecretStore picks the OS keychain when its command-line tool is available, and the
+// file fallback otherwise or when CCE_SECRET_STORE=file
+func newSecretStore() SecretStore {
+	if os.Getenv("CCE_SECRET_STORE") != "file" {
+		switch runtime.GOOS {
+		case "darwin":
+			if _, err := exec.LookPath("security"); err == nil {
+				return macKeychain{}
+			}
+		case "linux":
+			if _, err := exec.LookPath("secret-tool"); err == nil {
+				return secretServiceStore{}
+			}
+		}
+	}
+	return fileSecretStore{}
+}
+
+// activeSecretStore returns the store in use, choosing it on first use
+func activeSecretStore() SecretStore {
+	if secretStore == nil {
+		secretStore = newSecretStore()
+	}
+	return secretStore
+}
+
+// secretRef returns the api_key reference for a key stored under account
+func secretRef(account string) string {
+	return secretRefPrefix + secretService + "/" + account
+}
+
+// newSecretAccount names the store entry for one key: a readable label (the environment
+// name, plus ".1" or ".previous" for pool and rotated keys) and a random suffix. The
+// account is recorded in the reference, so it never has to follow later renames, and
+// each migrated key gets an entry of its own that no other key can overwrite.
+func newSecretAccount(label string) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate secret id: %w", err)
+	}
+	return label + "-" + hex.EncodeToString(suffix), nil
+}
+
+// parseSecretRef splits keychain:<service>/<account>; ok is false for plain keys
+func parseSecretRef(value string) (service, account string, ok bool) {
+	if !strings.HasPrefix(value, secretRefPrefix) {
+		return "", "", false
+	}
+	service, account, found := strings.Cut(strings.TrimPrefix(value, secretRefPrefix), "/")
+	if !found || service == "" || account == "" {
+		return "", "", false
+	}
+	return service, account, true
+}
+
+// isSecretRef reports whether an api_key value refers to a SecretStore entry
+func isSecretRef(value string) bool {
+	_, _, ok := parseSecretRef(value)
+	return ok
+}
+
+// resolveSecret returns the key a value stands for: references are looked up in the
+// active store, anything else is returned as is
+func resolveSecret(value string) (string, error) {
+	service, account, ok := parseSecretRef(value)
+	if !ok {
+		return value, nil
+	}
+	secret, err := activeSecretStore().Get(service, account)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from %s: %w", value, activeSecretStore().Name(), err)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("%s is empty in %s", value, activeSecretStore().Name())
+	}
+	return secret, nil
+}
+
+// resolveSecretRefs replaces key references in env with the keys themselves, just
+// before they are needed; the config keeps the references
+func resolveSecretRefs(env Environment) (Environment, error) {
+	key, err := resolveSecret(env.APIKey)
+	if err != nil {
+		return env, err
+	}
+	env.APIKey = key
+	if len(env.APIKeys) > 0 {
+		keys := make([]string, len(env.APIKeys))
+		for i, value := range env.APIKeys {
+			if keys[i], err = resolveSecret(value); err != nil {
+				return env, err
+			}
+		}
+		env.APIKeys = keys
+	}
+	return env, nil
+}
+
+// macKeychain stores keys as generic passwords in the login keychain via security(1)
+type macKeychain struct{}
+
+func (macKeychain) Name() string { return "macOS Keychain" }
+
+func (macKeychain) Get(service, account string) (string, error) {
+	out, err := runSecretTool(nil, "security", "find-generic-password", "-s", service, "-a", account, "-w")
+	return strings.TrimRight(out, "\n"), err
+}
+
+func (macKeychain) Set(service, account, secret string) error {
+	// A trailing -w with no value makes security prompt for the password, so the secret goes
+	// in on stdin instead of the process list. It asks twice (entry and retype); -U updates
+	// an existing item.
+	input := strings.NewReader(secret + "\n" + secret + "\n")
+	_, err := runSecretTool(input, "security", "add-generic-password", "-U", "-s", service, "-a", account, "-l", service+"/"+account, "-w")
+	return err
+}
+
+// secretServiceStore stores keys through libsecret's secret-tool (GNOME Keyring, KWallet)
+type secretServiceStore struct{}
+
+func (secretServiceStore) Name() string { return "Secret Service" }
+
+func (secretServiceStore) Get(service, account string) (string, error) {
+	out, err := runSecretTool(nil, "secret-tool", "lookup", "service", service, "account", account)
+	return strings.TrimRight(out, "\n"), err
+}
+
+func (secretServiceStore) Set(service, account, secret string) error {
+	// The secret goes in on stdin so it never appears in the process list
+	_, err := runSecretTool(strings.NewReader(secret), "secret-tool", "store", "--label", service+"/"+account, "service", service, "account", account)
+	return err
+}
+
+// runSecretTool runs a keychain command, folding its stderr into the error
+func runSecretTool(stdin *strings.Reader, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %s", name, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// fileSecretStore keeps keys in secrets.json (0600) next to the config, for systems
+// without a keychain. It only separates keys from the shareable config.
+type fileSecretStore struct{}
+
+func (fileSecretStore) Name() string { return "secrets file" }
+
+// getSecretsPath stores the secrets next to the config file
+func getSecretsPath() (string, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "secrets.json"), nil
+}
+
+func (fileSecretStore) load() (map[string]string, error) {
+	secrets := map[string]string{}
+	path, err := getSecretsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+func (s fileSecretStore) Get(service, account string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := secrets[service+"/"+account]
+	if !ok {
+		return "", fmt.Errorf("no such secret")
+	}
+	return secret, nil
+}
+
+func (s fileSecretStore) Set(service, account, secret string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[service+"/"+account] = secret
+	if err := ensureConfigDir(); err != nil {
+		return err
+	}
+	path, err := getSecretsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// runSecrets dispatches `cce secrets <action>`
+func runSecrets(args []string) error {
+	if len(args) == 0 || args[0] != "migrate" {
+		return fmt.Errorf("argument parsing failed: secrets requires an action: migrate [<name>...]")
+	}
+	return runSecretsMigrate(args[1:])
+}
+
+// runSecretsMigrate moves plaintext keys of the named environments (default all) into
+// the secret store and leaves references in the config. Each key is read back before the
+// config changes, so a failed store never loses a key.
+func runSecretsMigrate(names []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	indexes := make([]int, 0, len(config.Environments))
+	if len(names) == 0 {
+		for i := range config.Environments {
+			indexes = append(indexes, i)
+		}
+	}
+	for _, name := range names {
+		index, err := lookupEnvironment(config, name)
+		if err != nil {
+			return err
+		}
+		indexes = append(indexes, index)
+	}
+
+	store := activeSecretStore()
+	changed := false
+	// move stores one key in a new entry labelled label and returns its reference
+	move := func(label, key string) (string, error) {
+		if key == "" || isSecretRef(key) {
+			return key, nil
+		}
+		changed = true
+		account, err := newSecretAccount(label)
+		if err != nil {
+			return "", err
+		}
+		if err := store.Set(secretService, account, key); err != nil {
+			return "", fmt.Errorf("failed to store key for '%s' in %s: %w", label, store.Name(), err)
+		}
+		if stored, err := store.Get(secretService, account); err != nil || stored != key {
+			return "", fmt.Errorf("key for '%s' did not read back from %s", label, store.Name())
+		}
+		return secretRef(account), nil
+	}
+
+	var moved []string
+	for _, index := range indexes {
+		env := cloneEnvironment(config.Environments[index])
+		changed = false
+		if env.APIKey, err = move(env.Name, env.APIKey); err != nil {
+			return err
+		}
+		for i, key := range env.APIKeys {
+			if env.APIKeys[i], err = move(fmt.Sprintf("%s.%d", env.Name, i+1), key); err != nil {
+				return err
+			}
+		}
+		if env.PreviousAPIKey, err = move(env.Name+".previous", env.PreviousAPIKey); err != nil {
+			return err
+		}
+		if changed {
+			config.Environments[index] = env
+			moved = append(moved, env.Name)
+		}
+	}
+
+	if len(moved) == 0 {
+		if _, err := fmt.Println("No plaintext keys to migrate."); err != nil {
+			return fmt.Errorf("failed to display message: %w", err)
+		}
+		return nil
+	}
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	if _, err := fmt.Printf("Moved the keys of %s into the %s; the config now holds %s references.\n",
+		strings.Join(moved, ", "), store.Name(), secretRefPrefix); err != nil {
+		return fmt.Errorf("failed to display success message: %w", err)
+	}
+	if configPath, err := getConfigPath(); err == nil {
+		fmt.Fprintf(os.Stderr, "Note: earlier backups in %s still hold the plaintext keys; delete them once launching works.\n",
+			filepath.Join(filepath.Dir(configPath), "backups"))
+	}
+	return nil
+}
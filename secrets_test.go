@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memorySecretStore is an in-memory SecretStore for tests
+type memorySecretStore map[string]string
+
+func (memorySecretStore) Name() string { return "test store" }
+
+func (m memorySecretStore) Get(service, account string) (string, error) {
+	secret, ok := m[service+"/"+account]
+	if !ok {
+		return "", fmt.Errorf("no such secret")
+	}
+	return secret, nil
+}
+
+func (m memorySecretStore) Set(service, account, secret string) error {
+	m[service+"/"+account] = secret
+	return nil
+}
+
+func withSecretStore(t *testing.T, store SecretStore) {
+	t.Helper()
+	original := secretStore
+	secretStore = store
+	t.Cleanup(func() { secretStore = original })
+}
+
+func TestSecretsMigrateMovesKeysAndLaunchResolvesThem(t *testing.T) {
+	withTempConfigPath(t)
+	store := memorySecretStore{}
+	withSecretStore(t, store)
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED", APIKeys: []string{"sk-ant-REDACTED"}},
+		{Name: "dev", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	_, _, err := captureStdoutAndStderr(t, func() error {
+		return handleCommand([]string{"secrets", "migrate", "prod"})
+	})
+	if err != nil {
+		t.Fatalf("secrets migrate failed: %v", err)
+	}
+	config, _ = loadConfig()
+	prod, dev := config.Environments[0], config.Environments[1]
+	if !strings.HasPrefix(prod.APIKey, "keychain:cce/prod-") || !strings.HasPrefix(prod.APIKeys[0], "keychain:cce/prod.1-") {
+		t.Errorf("expected references in the config, got %+v", prod)
+	}
+	if dev.APIKey != "sk-ant-REDACTED" {
+		t.Errorf("dev was not named and should keep its key, got %q", dev.APIKey)
+	}
+	if store[strings.TrimPrefix(prod.APIKey, secretRefPrefix)] != "sk-ant-REDACTED" {
+		t.Errorf("key not stored: %v", store)
+	}
+
+	envVars, err := prepareEnvironment(prod)
+	if err != nil {
+		t.Fatalf("prepareEnvironment() failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(envVars, "\n"), "ANTHROPIC_API_KEY=sk-ant-REDACTED") {
+		t.Error("expected the launch to receive the stored key, not the reference")
+	}
+
+	out := captureStdout(t, func() {
+		if err := runSecretsMigrate([]string{"prod"}); err != nil {
+			t.Fatalf("second migrate failed: %v", err)
+		}
+	})
+	if !strings.Contains(out, "No plaintext keys") {
+		t.Errorf("expected nothing left to migrate, got %q", out)
+	}
+}
+
+func TestMissingSecretFailsLaunch(t *testing.T) {
+	withSecretStore(t, memorySecretStore{})
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "keychain:cce/prod"}
+	if _, err := prepareEnvironment(env); err == nil || !strings.Contains(err.Error(), "keychain:cce/prod") {
+		t.Errorf("expected a missing secret to stop the launch, got %v", err)
+	}
+	if _, _, ok := parseSecretRef("keychain:nothing"); ok {
+		t.Error("a reference needs a service and an account")
+	}
+}
+
+func TestFileSecretStore(t *testing.T) {
+	withTempConfigPath(t)
+	store := fileSecretStore{}
+	if err := store.Set("cce", "prod", "sk-ant-REDACTED"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if secret, err := store.Get("cce", "prod"); err != nil || secret != "sk-ant-REDACTED" {
+		t.Errorf("Get() = %q, %v", secret, err)
+	}
+	if _, err := store.Get("cce", "dev"); err == nil {
+		t.Error("expected a missing secret to fail")
+	}
+}
+
+func TestUseAndExportResolveMigratedKeys(t *testing.T) {
+	withTempConfigPath(t)
+	withSecretStore(t, memorySecretStore{"cce/prod": "sk-ant-REDACTED"})
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "keychain:cce/prod"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	for _, args := range [][]string{{"use", "prod"}, {"shell", "prod"}, {"export", "prod", "--format", "env"}} {
+		output := captureStdout(t, func() {
+			if err := handleCommand(args); err != nil {
+				t.Fatalf("%v failed: %v", args, err)
+			}
+		})
+		if !strings.Contains(output, "ANTHROPIC_API_KEY='sk-ant-REDACTED'") || strings.Contains(output, "keychain:") {
+			t.Errorf("%v should export the stored key, not the reference:\n%s", args, output)
+		}
+	}
+
+	withSecretStore(t, memorySecretStore{})
+	if err := handleCommand([]string{"use", "prod"}); err == nil || !strings.Contains(err.Error(), "keychain:cce/prod") {
+		t.Errorf("expected a missing secret to fail, got %v", err)
+	}
+}
+
+func TestMacKeychainSetKeepsSecretOffCommandLine(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a stand-in for security(1)")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" > \"$0.args\"\ncat > \"$0.stdin\"\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "security"), []byte(script), 0700); err != nil {
+		t.Fatalf("failed to write fake security: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	if err := (macKeychain{}).Set("cce", "prod", "sk-ant-REDACTED"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	args, _ := ioutil.ReadFile(filepath.Join(dir, "security.args"))
+	stdin, _ := ioutil.ReadFile(filepath.Join(dir, "security.stdin"))
+	if strings.Contains(string(args), "secret") || !strings.HasSuffix(strings.TrimSpace(string(args)), "-w") {
+		t.Errorf("the secret must not be an argument, got %q", args)
+	}
+	if !strings.HasPrefix(string(stdin), "sk-ant-REDACTED\n") {
+		t.Errorf("expected the secret on stdin, got %q", stdin)
+	}
+}
+
+func TestSecretsMigrateAfterRotationKeepsPreviousEntry(t *testing.T) {
+	withTempConfigPath(t)
+	store := memorySecretStore{}
+	withSecretStore(t, store)
+	if err := saveConfig(Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	migrate := func() Environment {
+		t.Helper()
+		if _, _, err := captureStdoutAndStderr(t, func() error { return runSecretsMigrate(nil) }); err != nil {
+			t.Fatalf("secrets migrate failed: %v", err)
+		}
+		config, err := loadConfig()
+		if err != nil {
+			t.Fatalf("loadConfig() failed: %v", err)
+		}
+		return config.Environments[0]
+	}
+
+	first := migrate()
+	rotated := rotateKey(first, "sk-ant-REDACTED", time.Now())
+	if err := saveConfig(Config{Environments: []Environment{rotated}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	second := migrate()
+
+	if second.APIKey == first.APIKey || second.PreviousAPIKey != first.APIKey {
+		t.Fatalf("the new key needs its own entry: first %q, now %q (previous %q)", first.APIKey, second.APIKey, second.PreviousAPIKey)
+	}
+	for ref, want := range map[string]string{second.APIKey: "sk-ant-REDACTED", second.PreviousAPIKey: "sk-ant-REDACTED"} {
+		if got, err := resolveSecret(ref); err != nil || got != want {
+			t.Errorf("resolveSecret(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+}
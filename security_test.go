@@ -741,7 +741,7 @@ func TestSecurityAndPermissions(t *testing.T) {
 		}{
 			{`{"environments": [{"name": "", "url": "https://api.anthropic.com", "api_key": "sk-ant-test"}]}`, "invalid name"},
 			{`{"environments": [{"name": "test", "url": "invalid-url", "api_key": "sk-ant-test"}]}`, "invalid URL"},
-			{`{"environments": [{"name": "test", "url": "https://api.anthropic.com", "api_key": "short"}]}`, "invalid API key"},
+			{`{"environments": [{"name": "test", "url": "https://api.anthropic.com", "api_key": "sk-ant-\u0007test"}]}`, "invalid API key"},
 			{`{malformed json}`, "malformed JSON"},
 		}
 
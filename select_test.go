@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// stubSelection saves two environments and replaces the picker and launcher for a test
+func stubSelection(t *testing.T, pick string) (*[]string, *string) {
+	t.Helper()
+	withTempConfigPath(t)
+	config := Config{Environments: []Environment{
+		{Name: "dev", URL: "https://dev.example.com", APIKey: "sk-ant-dev-1234567890"},
+		{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-prod-1234567890"},
+	}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	originalSelector := environmentSelector
+	originalLauncher := claudeLauncher
+	t.Cleanup(func() {
+		environmentSelector = originalSelector
+		claudeLauncher = originalLauncher
+	})
+
+	environmentSelector = func(c Config) (Environment, error) {
+		if index, ok := findEnvironmentByName(c, pick); ok {
+			return c.Environments[index], nil
+		}
+		return Environment{}, fmt.Errorf("selection cancelled")
+	}
+
+	var launchedArgs []string
+	var launchedEnv string
+	claudeLauncher = func(e Environment, args []string, workdir string) error {
+		launchedEnv = e.Name
+		launchedArgs = append([]string{}, args...)
+		return nil
+	}
+	return &launchedArgs, &launchedEnv
+}
+
+func TestSeparatorOnlyRunsPickerThenLaunches(t *testing.T) {
+	result := parseArguments([]string{"--", "chat", "--interactive"})
+	if result.CCEFlags["env"] != "" || strings.Join(result.ClaudeArgs, " ") != "chat --interactive" {
+		t.Fatalf("unexpected parse result: %+v", result)
+	}
+
+	args, env := stubSelection(t, "prod")
+	captureStdout(t, func() {
+		if err := handleCommand([]string{"--", "chat", "--interactive"}); err != nil {
+			t.Fatalf("handleCommand failed: %v", err)
+		}
+	})
+
+	if *env != "prod" {
+		t.Errorf("expected picked environment 'prod' to launch, got %q", *env)
+	}
+	if strings.Join(*args, " ") != "chat --interactive" {
+		t.Errorf("expected claude args 'chat --interactive', got %v", *args)
+	}
+}
+
+func TestSelectCommand(t *testing.T) {
+	t.Run("prints picked name", func(t *testing.T) {
+		_, env := stubSelection(t, "dev")
+		output := captureStdout(t, func() {
+			if err := handleCommand([]string{"select"}); err != nil {
+				t.Fatalf("select failed: %v", err)
+			}
+		})
+		if output != "dev\n" || *env != "" {
+			t.Errorf("expected bare name and no launch, got %q (launched %q)", output, *env)
+		}
+	})
+
+	t.Run("launch passes args after separator", func(t *testing.T) {
+		args, env := stubSelection(t, "prod")
+		captureStdout(t, func() {
+			if err := handleCommand([]string{"select", "--launch", "--", "chat", "--interactive"}); err != nil {
+				t.Fatalf("select --launch failed: %v", err)
+			}
+		})
+		if *env != "prod" || strings.Join(*args, " ") != "chat --interactive" {
+			t.Errorf("unexpected launch: env=%q args=%v", *env, *args)
+		}
+	})
+
+	t.Run("cancelled selection does not launch", func(t *testing.T) {
+		_, env := stubSelection(t, "missing")
+		err := handleCommand([]string{"select", "--launch"})
+		if err == nil || *env != "" {
+			t.Errorf("expected cancellation error without launch, got %v (launched %q)", err, *env)
+		}
+	})
+
+	t.Run("flag validation", func(t *testing.T) {
+		if _, err := parseSelectOptions([]string{"--", "chat"}); err == nil {
+			t.Error("expected claude args without --launch to be rejected")
+		}
+		if _, err := parseSelectOptions([]string{"chat"}); err == nil {
+			t.Error("expected bare argument to be rejected")
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func stubLatencyProbe(t *testing.T, probe func(ctx context.Context, env Environment) latencyEntry) {
+	t.Helper()
+	original := latencyProbe
+	t.Cleanup(func() { latencyProbe = original })
+	latencyProbe = probe
+}
+
+func TestLatencyEntryLabel(t *testing.T) {
+	if got := (latencyEntry{Latency: 182400 * time.Microsecond, Reachable: true}).label(); got != "182ms" {
+		t.Errorf("expected 182ms, got %q", got)
+	}
+	if got := (latencyEntry{Latency: time.Second}).label(); got != "unreachable" {
+		t.Errorf("expected unreachable, got %q", got)
+	}
+}
+
+func TestAppendLatencyKeepsWidth(t *testing.T) {
+	line := "► production (https://api.example.com/some/long/path) [claude-sonnet-4-20250514]"
+	got := appendLatency(line, "95ms", 40)
+	if !strings.HasSuffix(got, "  95ms") {
+		t.Errorf("expected latency suffix, got %q", got)
+	}
+	if displayWidth(got) > 40 {
+		t.Errorf("line is %d columns wide, want at most 40: %q", displayWidth(got), got)
+	}
+
+	if got := appendLatency("► dev", "", 80); got != "► dev" {
+		t.Errorf("empty label should leave the line alone, got %q", got)
+	}
+	if got := appendLatency("► dev", "unreachable", 12); got != "► dev" {
+		t.Errorf("narrow terminals should drop the label, got %q", got)
+	}
+}
+
+func TestLatencyTrackerProbesInBackgroundAndCaches(t *testing.T) {
+	withTempConfigPath(t)
+	release := make(chan struct{})
+	var probes int32
+	stubLatencyProbe(t, func(ctx context.Context, env Environment) latencyEntry {
+		atomic.AddInt32(&probes, 1)
+		<-release
+		return latencyEntry{Latency: 50 * time.Millisecond, Reachable: true, CheckedAt: time.Now()}
+	})
+
+	envs := []Environment{
+		{Name: "a", URL: "https://a.example.com"},
+		{Name: "a-slash", URL: "https://a.example.com/"},
+	}
+	tracker := startLatencyTracker(envs)
+	if got := tracker.label(envs[0]); got != "" {
+		t.Fatalf("label should be empty before the probe finishes, got %q", got)
+	}
+
+	close(release)
+	select {
+	case <-tracker.updates:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update after the probe finished")
+	}
+	if got := tracker.label(envs[1]); got != "50ms" {
+		t.Errorf("environments sharing a URL should share the result, got %q", got)
+	}
+	tracker.stop()
+	if n := atomic.LoadInt32(&probes); n != 1 {
+		t.Errorf("expected one probe per endpoint, got %d", n)
+	}
+
+	// A second selector within the TTL reuses the cache without probing
+	again := startLatencyTracker(envs)
+	if got := again.label(envs[0]); got != "50ms" {
+		t.Errorf("expected cached latency, got %q", got)
+	}
+	again.stop()
+	if n := atomic.LoadInt32(&probes); n != 1 {
+		t.Errorf("cached endpoint was probed again (%d probes)", n)
+	}
+}
+
+func TestLatencyCacheExpires(t *testing.T) {
+	withTempConfigPath(t)
+	stale := latencyCache{Endpoints: map[string]latencyEntry{
+		"https://a.example.com": {Latency: time.Millisecond, Reachable: true, CheckedAt: time.Now().Add(-2 * latencyCacheTTL)},
+	}}
+	if err := saveLatencyCache(stale); err != nil {
+		t.Fatalf("saveLatencyCache() failed: %v", err)
+	}
+
+	var probes int32
+	stubLatencyProbe(t, func(ctx context.Context, env Environment) latencyEntry {
+		atomic.AddInt32(&probes, 1)
+		return latencyEntry{Reachable: false, CheckedAt: time.Now()}
+	})
+	tracker := startLatencyTracker([]Environment{{Name: "a", URL: "https://a.example.com"}})
+	select {
+	case <-tracker.updates:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update after the probe finished")
+	}
+	tracker.stop()
+	if atomic.LoadInt32(&probes) != 1 {
+		t.Error("stale cache entry should be probed again")
+	}
+	if got := loadLatencyCache().Endpoints["https://a.example.com"].label(); got != "unreachable" {
+		t.Errorf("expected refreshed entry in cache, got %q", got)
+	}
+}
+
+func TestSelectorLatencyOnlyWithCheck(t *testing.T) {
+	if inputs, stop := startSelectorLatency([]Environment{{Name: "a", URL: "https://a.example.com"}}); inputs != nil || selectorLatency != nil {
+		t.Error("probes should not start without --check")
+	} else {
+		stop()
+	}
+	if got := selectorLatency.label(Environment{URL: "https://a.example.com"}); got != "" {
+		t.Errorf("nil tracker should render no latency, got %q", got)
+	}
+
+	opts, err := parseSelectOptions([]string{"--check", "--launch"})
+	if err != nil || !opts.Check || !opts.Launch {
+		t.Fatalf("parseSelectOptions() = %+v, %v", opts, err)
+	}
+}
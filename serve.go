@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Methods accepted by serve --stdio
+const (
+	serveMethodList       = "list"
+	serveMethodGet        = "get"
+	serveMethodAdd        = "add"
+	serveMethodRemove     = "remove"
+	serveMethodPrepareEnv = "prepare-env"
+)
+
+// serveRequest is one line of input: {"id": 1, "method": "get", "params": {"name": "prod"}}
+type serveRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`     // Echoed back unchanged; any JSON value
+	Method string          `json:"method"`           // One of the serveMethod constants
+	Params json.RawMessage `json:"params,omitempty"` // Method-specific, see serveParams
+}
+
+// serveParams holds the parameters every method draws from
+type serveParams struct {
+	Name        string       `json:"name,omitempty"`        // get, remove, prepare-env
+	Environment *Environment `json:"environment,omitempty"` // add; same fields as the config file
+}
+
+// serveResponse is one line of output; exactly one of Result and Error is set
+type serveResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  *serveError     `json:"error,omitempty"`
+}
+
+// serveError carries the category and exit code the CLI would have reported
+type serveError struct {
+	Category string `json:"category"`  // categorizeError value, e.g. "cce_config"
+	ExitCode int    `json:"exit_code"` // Exit code of the equivalent cce command
+	Message  string `json:"message"`
+}
+
+// servePreparedEnv is the prepare-env result: what a launch would change in claude's environment
+type servePreparedEnv struct {
+	Environment string            `json:"environment"`
+	Variables   map[string]string `json:"variables"` // Set for claude, secrets included
+	Unset       []string          `json:"unset"`     // Inherited variables the launch clears
+}
+
+// parseServeOptions accepts the only transport there is, so later ones can be added
+func parseServeOptions(args []string) error {
+	if len(args) != 1 || args[0] != "--stdio" {
+		return fmt.Errorf("serve requires --stdio")
+	}
+	return nil
+}
+
+// runServe answers newline-delimited JSON requests on stdin until it is closed
+func runServe(args []string) error {
+	if err := parseServeOptions(args); err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+
+	// Config helpers print notices (backups, recovery) to stdout; keep those off the protocol stream
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = out }()
+
+	return serveStdio(os.Stdin, out)
+}
+
+// serveStdio handles one request per line, writing one response line each, in order
+func serveStdio(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	encoder := json.NewEncoder(out)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := encoder.Encode(handleServeLine(line)); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// handleServeLine decodes and dispatches one request, turning failures into error objects
+func handleServeLine(line []byte) serveResponse {
+	var req serveRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return serveResponse{Error: newServeError(fmt.Errorf("argument parsing failed: invalid request: %w", err))}
+	}
+	var params serveParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return serveResponse{ID: req.ID, Error: newServeError(fmt.Errorf("argument parsing failed: invalid params: %w", err))}
+		}
+	}
+
+	result, err := dispatchServeRequest(req.Method, params)
+	if err != nil {
+		return serveResponse{ID: req.ID, Error: newServeError(err)}
+	}
+	return serveResponse{ID: req.ID, Result: result}
+}
+
+// newServeError classifies err the same way main does for the CLI
+func newServeError(err error) *serveError {
+	return &serveError{Category: categorizeError(err), ExitCode: exitCodeFor(err), Message: err.Error()}
+}
+
+// dispatchServeRequest runs one method against a freshly loaded config, so changes
+// made by the CLI in the meantime are always seen
+func dispatchServeRequest(method string, params serveParams) (interface{}, error) {
+	switch method {
+	case serveMethodList, serveMethodGet, serveMethodAdd, serveMethodRemove, serveMethodPrepareEnv:
+	default:
+		return nil, fmt.Errorf("argument parsing failed: unknown method '%s' (expected list, get, add, remove or prepare-env)", method)
+	}
+	if method != serveMethodList && method != serveMethodAdd && params.Name == "" {
+		return nil, fmt.Errorf("argument validation failed: %s requires params.name", method)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("configuration loading failed: %w", err)
+	}
+
+	switch method {
+	case serveMethodList, serveMethodGet:
+		// Keys never leave cce through the listing methods; prepare-env is the one that hands them out
+		doc, err := buildExportDocument(config, exportOptions{Env: params.Name, Redact: true})
+		if err != nil {
+			return nil, fmt.Errorf("configuration lookup failed: %w", err)
+		}
+		if method == serveMethodGet {
+			return doc.Environments[0], nil
+		}
+		return doc.Environments, nil
+
+	case serveMethodAdd:
+		if params.Environment == nil {
+			return nil, fmt.Errorf("argument validation failed: add requires params.environment")
+		}
+		if err := addEnvironmentToConfig(&config, *params.Environment); err != nil {
+			return nil, fmt.Errorf("failed to add environment: %w", err)
+		}
+		if err := saveConfig(config); err != nil {
+			return nil, fmt.Errorf("failed to save configuration: %w", err)
+		}
+		return map[string]string{"added": params.Environment.Name}, nil
+
+	case serveMethodRemove:
+		index, err := lookupEnvironment(config, params.Name)
+		if err != nil {
+			return nil, fmt.Errorf("configuration lookup failed: %w", err)
+		}
+		name := config.Environments[index].Name
+		if err := removeEnvironmentFromConfig(&config, name); err != nil {
+			return nil, fmt.Errorf("failed to remove environment: %w", err)
+		}
+		if err := saveConfig(config); err != nil {
+			return nil, fmt.Errorf("failed to save configuration: %w", err)
+		}
+		return map[string]string{"removed": name}, nil
+
+	default: // serveMethodPrepareEnv
+		index, err := lookupEnvironment(config, params.Name)
+		if err != nil {
+			return nil, fmt.Errorf("configuration lookup failed: %w", err)
+		}
+		return prepareServeEnvironment(config.Environments[index])
+	}
+}
+
+// prepareServeEnvironment reports the variables a launch of env sets and the inherited
+// ones it drops, computed by the same code that builds claude's environment
+func prepareServeEnvironment(env Environment) (servePreparedEnv, error) {
+	prepared, err := prepareEnvironment(env)
+	if err != nil {
+		return servePreparedEnv{}, err
+	}
+	resolved, err := resolveSecretRefs(env)
+	if err != nil {
+		return servePreparedEnv{}, err
+	}
+	result := servePreparedEnv{Environment: env.Name, Variables: map[string]string{}, Unset: []string{}}
+	for _, a := range launchVariables(resolved) {
+		result.Variables[a.Key] = a.Value
+	}
+	kept := environMap(prepared)
+	for name := range environMap(os.Environ()) {
+		if _, ok := kept[name]; !ok {
+			result.Unset = append(result.Unset, name)
+		}
+	}
+	sort.Strings(result.Unset)
+	return result, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// serveLines runs serveStdio over the given request lines and decodes each response
+func serveLines(t *testing.T, lines ...string) []map[string]interface{} {
+	t.Helper()
+	var out bytes.Buffer
+	if err := serveStdio(strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("serveStdio() failed: %v", err)
+	}
+	var responses []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var resp map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("response is not JSON: %q", line)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func TestServeRoundTrip(t *testing.T) {
+	withTempConfigPath(t)
+	responses := serveLines(t,
+		`{"id":1,"method":"add","params":{"environment":{"name":"dev","url":"https://api.anthropic.com","api_key":"sk-ant-REDACTED"}}}`,
+		`{"id":"two","method":"list"}`,
+		`{"id":3,"method":"get","params":{"name":"dev"}}`,
+		`{"id":4,"method":"prepare-env","params":{"name":"dev"}}`,
+		`{"id":5,"method":"remove","params":{"name":"dev"}}`,
+		`{"id":6,"method":"list"}`,
+	)
+	if len(responses) != 6 {
+		t.Fatalf("expected 6 responses, got %d", len(responses))
+	}
+	for i, resp := range responses {
+		if resp["error"] != nil {
+			t.Fatalf("request %d failed: %v", i+1, resp["error"])
+		}
+	}
+	if responses[1]["id"] != "two" {
+		t.Errorf("id should be echoed unchanged, got %v", responses[1]["id"])
+	}
+
+	listed := responses[1]["result"].([]interface{})
+	if len(listed) != 1 || listed[0].(map[string]interface{})["name"] != "dev" {
+		t.Errorf("unexpected list result: %v", listed)
+	}
+	if key := listed[0].(map[string]interface{})["api_key"]; key != nil && key != "" {
+		t.Error("list should not return API keys")
+	}
+	if key := responses[2]["result"].(map[string]interface{})["api_key"]; key != nil && key != "" {
+		t.Error("get should not return API keys")
+	}
+
+	prepared := responses[3]["result"].(map[string]interface{})
+	vars := prepared["variables"].(map[string]interface{})
+	if vars["ANTHROPIC_BASE_URL"] != "https://api.anthropic.com" || vars["ANTHROPIC_API_KEY"] != "sk-ant-REDACTED" {
+		t.Errorf("unexpected prepare-env variables: %v", vars)
+	}
+
+	if got := responses[5]["result"].([]interface{}); len(got) != 0 {
+		t.Errorf("expected empty list after remove, got %v", got)
+	}
+}
+
+func TestServeErrorsCarryExitCodes(t *testing.T) {
+	withTempConfigPath(t)
+	responses := serveLines(t,
+		`not json`,
+		`{"id":1,"method":"launch"}`,
+		`{"id":2,"method":"get","params":{"name":"missing"}}`,
+		`{"id":3,"method":"remove"}`,
+	)
+
+	want := []struct {
+		category string
+		code     float64
+	}{
+		{"cce_argument", 6},
+		{"cce_argument", 6},
+		{"cce_config", 2},
+		{"cce_argument", 7},
+	}
+	for i, w := range want {
+		errObj, ok := responses[i]["error"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("response %d should be an error: %v", i, responses[i])
+		}
+		if errObj["category"] != w.category || errObj["exit_code"] != w.code {
+			t.Errorf("response %d: got %v/%v, want %s/%v (%v)", i, errObj["category"], errObj["exit_code"], w.category, w.code, errObj["message"])
+		}
+		if responses[i]["result"] != nil {
+			t.Errorf("response %d should not carry a result", i)
+		}
+	}
+	if responses[2]["id"].(float64) != 2 {
+		t.Errorf("error responses should echo the id, got %v", responses[2]["id"])
+	}
+}
+
+func TestServePrepareEnvUnsetsInheritedAnthropicVars(t *testing.T) {
+	withTempConfigPath(t)
+	os.Setenv("ANTHROPIC_SMALL_FAST_MODEL", "stale")
+	defer os.Unsetenv("ANTHROPIC_SMALL_FAST_MODEL")
+
+	if err := saveConfig(Config{Environments: []Environment{
+		{Name: "dev", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	prepared, err := prepareServeEnvironment(Environment{Name: "dev", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"})
+	if err != nil {
+		t.Fatalf("prepareServeEnvironment() failed: %v", err)
+	}
+	if !strings.Contains(","+strings.Join(prepared.Unset, ",")+",", ",ANTHROPIC_SMALL_FAST_MODEL,") {
+		t.Errorf("expected the inherited variable to be unset, got %v", prepared.Unset)
+	}
+}
+
+func TestServeRequiresStdio(t *testing.T) {
+	if err := runServe(nil); err == nil || !strings.Contains(err.Error(), "--stdio") {
+		t.Errorf("expected --stdio to be required, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetCommand(t *testing.T) {
+	setup := func(t *testing.T) Environment {
+		withTempConfigPath(t)
+		env := Environment{
+			Name:    "prod",
+			URL:     "https://api.anthropic.com",
+			APIKey:  "sk-ant-original-key",
+			Model:   "claude-3-opus-20240229",
+			EnvVars: map[string]string{"ANTHROPIC_TIMEOUT": "30"},
+		}
+		if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+			t.Fatalf("saveConfig() failed: %v", err)
+		}
+		return env
+	}
+	load := func(t *testing.T) Environment {
+		config, err := loadConfig()
+		if err != nil {
+			t.Fatalf("loadConfig() failed: %v", err)
+		}
+		return config.Environments[0]
+	}
+
+	t.Run("url only leaves the key untouched", func(t *testing.T) {
+		original := setup(t)
+		withReachabilityCheck(t, networkCheckResult{Reachable: true, StatusCode: 200}, nil)
+		captureStdout(t, func() {
+			if err := handleCommand([]string{"set", "prod", "url=https://new.example.com"}); err != nil {
+				t.Fatalf("set failed: %v", err)
+			}
+		})
+		got := load(t)
+		if got.URL != "https://new.example.com" || got.APIKey != original.APIKey || got.Model != original.Model {
+			t.Errorf("unexpected environment after url update: %+v", got)
+		}
+	})
+
+	t.Run("empty value clears optional fields", func(t *testing.T) {
+		setup(t)
+		captureStdout(t, func() {
+			if err := runSet([]string{"prod", "model=", "env.ANTHROPIC_TIMEOUT="}); err != nil {
+				t.Fatalf("set failed: %v", err)
+			}
+		})
+		got := load(t)
+		if got.Model != "" || len(got.EnvVars) != 0 {
+			t.Errorf("expected model and env var cleared: %+v", got)
+		}
+	})
+
+	t.Run("key starting with *** is stored verbatim", func(t *testing.T) {
+		setup(t)
+		original := secretReader
+		defer func() { secretReader = original }()
+		secretReader = func(string) (string, error) { return "***real-key-that-looks-masked", nil }
+
+		output := captureStdout(t, func() {
+			if err := runSet([]string{"prod", "api_key=-"}); err != nil {
+				t.Fatalf("set api_key failed: %v", err)
+			}
+		})
+		if got := load(t); got.APIKey != "***real-key-that-looks-masked" {
+			t.Errorf("key misinterpreted as unchanged: %q", got.APIKey)
+		}
+		if strings.Contains(output, "real-key") {
+			t.Error("set must not echo the key")
+		}
+	})
+
+	t.Run("rejects unsafe and invalid updates", func(t *testing.T) {
+		setup(t)
+		cases := [][]string{
+			{"prod"},
+			{"prod", "api_key=sk-in-shell-history"},
+			{"prod", "url"},
+			{"prod", "color=blue"},
+			{"prod", "url=not-a-url"},
+			{"prod", "url=https://a.example.com", "url=https://b.example.com"},
+			{"missing", "url=https://a.example.com"},
+		}
+		for _, args := range cases {
+			if err := runSet(args); err == nil {
+				t.Errorf("expected error for %v", args)
+			}
+		}
+		if got := load(t); got.URL != "https://api.anthropic.com" {
+			t.Errorf("failed updates modified the config: %+v", got)
+		}
+	})
+
+	t.Run("key var keeps auth scheme consistent", func(t *testing.T) {
+		env := Environment{Name: "x", URL: "https://api.anthropic.com", APIKey: "sk-ant-consistent"}
+		updates, order, _ := parseFieldUpdates([]string{"key_var=anthropic_auth_token"})
+		got, err := applyFieldUpdates(env, updates, order)
+		if err != nil || got.APIKeyEnv != "ANTHROPIC_AUTH_TOKEN" || got.AuthScheme != authSchemeBearer {
+			t.Errorf("unexpected result: %+v, %v", got, err)
+		}
+	})
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// settingRef is what a dotted settings key addresses: a field, or one entry of a map
+// such as key_bindings.keys.select
+type settingRef struct {
+	value reflect.Value // The field, or the map holding the entry
+	key   string        // Entry key when entry is set
+	entry bool
+}
+
+// settingJSONName returns the json name of a struct field, or "" for untagged fields
+func settingJSONName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// settingNames lists the keys available in a settings section, for error messages
+func settingNames(t reflect.Type) string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := settingJSONName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// resolveSetting walks settings along a dotted key of json field names. With create,
+// unset sections on the way are allocated so the leaf can be assigned; without it they
+// read as empty, so unset settings report their zero value.
+func resolveSetting(settings *ConfigSettings, key string, create bool) (settingRef, error) {
+	path := strings.Split(key, ".")
+	cur := reflect.ValueOf(settings).Elem()
+	for i, name := range path {
+		for cur.Kind() == reflect.Ptr && cur.Type().Elem().Kind() == reflect.Struct {
+			switch {
+			case !cur.IsNil():
+				cur = cur.Elem()
+			case create:
+				cur.Set(reflect.New(cur.Type().Elem()))
+				cur = cur.Elem()
+			default:
+				cur = reflect.New(cur.Type().Elem()).Elem()
+			}
+		}
+
+		switch cur.Kind() {
+		case reflect.Struct:
+			next, ok := reflect.Value{}, false
+			for f := 0; f < cur.NumField(); f++ {
+				if name != "" && settingJSONName(cur.Type().Field(f)) == name {
+					next, ok = cur.Field(f), true
+					break
+				}
+			}
+			if !ok {
+				section := strings.Join(path[:i], ".")
+				if section == "" {
+					section = "settings"
+				}
+				return settingRef{}, fmt.Errorf("unknown setting '%s' (%s has: %s)", key, section, settingNames(cur.Type()))
+			}
+			cur = next
+		case reflect.Map:
+			if i != len(path)-1 || name == "" {
+				return settingRef{}, fmt.Errorf("unknown setting '%s': %s entries have no fields", key, strings.Join(path[:i], "."))
+			}
+			if cur.IsNil() && create {
+				cur.Set(reflect.MakeMap(cur.Type()))
+			}
+			return settingRef{value: cur, key: name, entry: true}, nil
+		default:
+			return settingRef{}, fmt.Errorf("unknown setting '%s': %s is not a section", key, strings.Join(path[:i], "."))
+		}
+	}
+	return settingRef{value: cur}, nil
+}
+
+// parseSettingValue converts raw to a value of type t; an empty raw clears the setting
+func parseSettingValue(key string, t reflect.Type, raw string) (reflect.Value, error) {
+	if raw == "" {
+		return reflect.Zero(t), nil
+	}
+	switch {
+	case t.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s expects true or false, got '%s'", key, raw)
+		}
+		return reflect.ValueOf(b), nil
+	case t.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s expects a whole number, got '%s'", key, raw)
+		}
+		return reflect.ValueOf(n), nil
+	case t.Kind() == reflect.String:
+		return reflect.ValueOf(raw), nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return reflect.ValueOf(items), nil
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Bool:
+		b, err := parseSettingValue(key, t.Elem(), raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		ptr := reflect.New(t.Elem())
+		ptr.Elem().Set(b)
+		return ptr, nil
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct:
+		return reflect.Value{}, fmt.Errorf("%s is a section; set one of its fields (%s)", key, settingNames(t.Elem()))
+	default:
+		return reflect.Value{}, fmt.Errorf("%s is a section; set one of its entries, e.g. %s.<name>", key, key)
+	}
+}
+
+// setSetting assigns raw (comma-separated for lists, "" to clear) to the dotted key
+func setSetting(settings *ConfigSettings, key, raw string) error {
+	ref, err := resolveSetting(settings, key, true)
+	if err != nil {
+		return err
+	}
+	if ref.entry {
+		if raw == "" {
+			ref.value.SetMapIndex(reflect.ValueOf(ref.key), reflect.Value{})
+			return nil
+		}
+		value, err := parseSettingValue(key, ref.value.Type().Elem(), raw)
+		if err != nil {
+			return err
+		}
+		ref.value.SetMapIndex(reflect.ValueOf(ref.key), value)
+		return nil
+	}
+	value, err := parseSettingValue(key, ref.value.Type(), raw)
+	if err != nil {
+		return err
+	}
+	ref.value.Set(value)
+	return nil
+}
+
+// getSetting renders the dotted key's value: scalars as text, lists comma-separated,
+// sections as JSON, and "" for anything unset
+func getSetting(settings *ConfigSettings, key string) (string, error) {
+	ref, err := resolveSetting(settings, key, false)
+	if err != nil {
+		return "", err
+	}
+	value := ref.value
+	if ref.entry {
+		value = value.MapIndex(reflect.ValueOf(ref.key)) // Invalid for a missing entry
+	}
+	return formatSettingValue(value)
+}
+
+// formatSettingValue renders one value for get-setting
+func formatSettingValue(value reflect.Value) (string, error) {
+	if !value.IsValid() || (value.Kind() == reflect.Ptr && value.IsNil()) {
+		return "", nil
+	}
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Slice:
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = value.Index(i).String()
+		}
+		return strings.Join(items, ","), nil
+	case reflect.Struct, reflect.Map:
+		data, err := json.MarshalIndent(value.Interface(), "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode setting: %w", err)
+		}
+		return string(data), nil
+	default:
+		return fmt.Sprint(value.Interface()), nil
+	}
+}
+
+// pruneSettings drops sections left empty by clearing their last field, so the saved
+// config does not fill up with "terminal": {} and the like; empty maps go the same way
+func pruneSettings(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() == reflect.Map && !field.IsNil() && field.Len() == 0 {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+		if field.Kind() != reflect.Ptr || field.IsNil() || field.Type().Elem().Kind() != reflect.Struct {
+			continue
+		}
+		pruneSettings(field.Elem())
+		if field.Elem().IsZero() {
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+}
+
+// runConfigSetSetting changes one setting by dotted key, validating it as loading would
+func runConfigSetSetting(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("argument parsing failed: config set-setting requires a key and a value, e.g. validation.strict_validation false")
+	}
+	key, raw := args[0], args[1]
+
+	// Read without loadConfig's checks so a bad setting can still be corrected here
+	config, err := configStore.Load()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	settings := config.Settings
+	if settings == nil {
+		settings = &ConfigSettings{}
+	}
+	if err := setSetting(settings, key, raw); err != nil {
+		return fmt.Errorf("argument validation failed: %w", err)
+	}
+	pruneSettings(reflect.ValueOf(settings).Elem())
+	if reflect.ValueOf(settings).Elem().IsZero() {
+		settings = nil
+	}
+	if err := validateConfigSettings(settings); err != nil {
+		return fmt.Errorf("argument validation failed: %w", err)
+	}
+	config.Settings = settings
+	if err := saveConfig(config); err != nil {
+		return fmt.Errorf("configuration save failed: %w", err)
+	}
+
+	message := fmt.Sprintf("Set %s = %s", key, raw)
+	if raw == "" {
+		message = fmt.Sprintf("Cleared %s", key)
+	}
+	if _, err := fmt.Println(message); err != nil {
+		return fmt.Errorf("failed to display setting: %w", err)
+	}
+	return nil
+}
+
+// runConfigGetSetting prints one setting by dotted key; unset settings print an empty line
+func runConfigGetSetting(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("argument parsing failed: config get-setting requires a key, e.g. validation.strict_validation")
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	settings := config.Settings
+	if settings == nil {
+		settings = &ConfigSettings{}
+	}
+	value, err := getSetting(settings, args[0])
+	if err != nil {
+		return fmt.Errorf("argument validation failed: %w", err)
+	}
+	if _, err := fmt.Println(value); err != nil {
+		return fmt.Errorf("failed to display setting: %w", err)
+	}
+	return nil
+}
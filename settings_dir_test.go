@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateSettingsDir(t *testing.T) {
+	for _, dir := range []string{"", "/tmp/claude-work", "~/.claude-work", "~"} {
+		if err := validateSettingsDir(dir); err != nil {
+			t.Errorf("validateSettingsDir(%q) should pass: %v", dir, err)
+		}
+	}
+	for _, dir := range []string{"relative/dir", "~other/dir", "/tmp/bad\ndir"} {
+		if err := validateSettingsDir(dir); err == nil {
+			t.Errorf("validateSettingsDir(%q) should fail", dir)
+		}
+	}
+}
+
+func TestSettingsDirInjectsClaudeConfigDir(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %v", err)
+	}
+	os.Setenv(claudeConfigDirVar, "/inherited/dir")
+	defer os.Unsetenv(claudeConfigDirVar)
+
+	env := Environment{
+		Name:        "work",
+		URL:         "https://api.anthropic.com",
+		APIKey:      "sk-ant-work-key-123",
+		SettingsDir: "~/.claude-work",
+		EnvVars:     map[string]string{claudeConfigDirVar: "/from/env_vars"},
+	}
+	vars, err := prepareEnvironment(env)
+	if err != nil {
+		t.Fatalf("prepareEnvironment() failed: %v", err)
+	}
+
+	var found []string
+	for _, kv := range vars {
+		if strings.HasPrefix(kv, claudeConfigDirVar+"=") {
+			found = append(found, kv)
+		}
+	}
+	want := claudeConfigDirVar + "=" + filepath.Join(home, ".claude-work")
+	if len(found) != 1 || found[0] != want {
+		t.Errorf("expected only %s, got %v", want, found)
+	}
+
+	// Without settings_dir the inherited value passes through untouched
+	env.SettingsDir = ""
+	env.EnvVars = nil
+	vars, err = prepareEnvironment(env)
+	if err != nil {
+		t.Fatalf("prepareEnvironment() failed: %v", err)
+	}
+	inherited := false
+	for _, kv := range vars {
+		if kv == claudeConfigDirVar+"=/inherited/dir" {
+			inherited = true
+		}
+	}
+	if !inherited {
+		t.Error("expected inherited CLAUDE_CONFIG_DIR to be kept when settings_dir is unset")
+	}
+}
+
+func TestEnsureSettingsDirCreatesPrivateDirectory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "claude")
+	if err := ensureSettingsDir(Environment{SettingsDir: dir}); err != nil {
+		t.Fatalf("ensureSettingsDir() failed: %v", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("expected directory to exist: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("expected mode 0700, got %o", perm)
+	}
+}
+
+func TestPlanUsesSettingsDirForConflicts(t *testing.T) {
+	withClaudeSettings(t, `{"env": {"ANTHROPIC_BASE_URL": "https://global.example.com"}}`)
+
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "settings.json"), []byte(`{"env": {}}`), 0600); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+
+	env := Environment{Name: "work", URL: "https://api.anthropic.com", APIKey: "sk-ant-work-key-123", SettingsDir: dir}
+	plan := buildLaunchPlan(env, nil)
+	if plan.SettingsPath != filepath.Join(dir, "settings.json") {
+		t.Errorf("expected settings path inside settings_dir, got %s", plan.SettingsPath)
+	}
+	if len(plan.SettingsConflicts) != 0 {
+		t.Errorf("global settings should not conflict with an isolated environment: %v", plan.SettingsConflicts)
+	}
+}
+
+func TestSetSettingsDirField(t *testing.T) {
+	env := Environment{Name: "work", URL: "https://api.anthropic.com", APIKey: "sk-ant-work-key-123"}
+	updated, err := applyFieldUpdates(env, map[string]string{"settings_dir": "~/.claude-work"}, []string{"settings_dir"})
+	if err != nil {
+		t.Fatalf("applyFieldUpdates() failed: %v", err)
+	}
+	if updated.SettingsDir != "~/.claude-work" {
+		t.Errorf("expected settings_dir to be set, got %q", updated.SettingsDir)
+	}
+	if _, err := applyFieldUpdates(env, map[string]string{"settings_dir": "relative"}, []string{"settings_dir"}); err == nil {
+		t.Error("expected relative settings_dir to be rejected")
+	}
+}
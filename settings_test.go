@@ -0,0 +1,111 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestSetAndGetSettingByPath(t *testing.T) {
+	settings := &ConfigSettings{}
+	for key, value := range map[string]string{
+		"validation.strict_validation": "true",
+		"validation.min_key_length":    "20",
+		"validation.model_patterns":    "^a$, ^b$",
+		"auto_backup":                  "false",
+		"key_bindings.keys.select":     "l,o",
+		"network.check_timeout":        "15s",
+	} {
+		if err := setSetting(settings, key, value); err != nil {
+			t.Fatalf("setSetting(%s) failed: %v", key, err)
+		}
+	}
+	if !settings.Validation.StrictValidation || settings.Validation.MinKeyLength != 20 || *settings.AutoBackup {
+		t.Errorf("values not assigned: %+v %+v", settings.Validation, settings.AutoBackup)
+	}
+	want := map[string]string{
+		"validation.model_patterns":    "^a$,^b$",
+		"key_bindings.keys.select":     "l,o",
+		"network.check_timeout":        "15s",
+		"auto_backup":                  "false",
+		"terminal.force_fallback":      "false",
+		"preflight.command":            "",
+		"key_bindings.keys.cancel":     "",
+		"validation.min_key_length":    "20",
+		"strict_flags":                 "false",
+		"claude_version_policy":        "",
+		"validation.strict_validation": "true",
+	}
+	for key, value := range want {
+		if got, err := getSetting(settings, key); err != nil || got != value {
+			t.Errorf("getSetting(%s) = %q, %v; want %q", key, got, err, value)
+		}
+	}
+	if got, _ := getSetting(settings, "network"); !strings.Contains(got, `"check_timeout": "15s"`) {
+		t.Errorf("sections should print as JSON, got %q", got)
+	}
+}
+
+func TestSetSettingRejectsBadKeysAndValues(t *testing.T) {
+	settings := &ConfigSettings{}
+	for key, value := range map[string]string{
+		"validation.strict_validation": "maybe",
+		"validation.min_key_length":    "ten",
+		"validation.nope":              "1",
+		"validation":                   "x",
+		"strict_args.deeper":           "true",
+	} {
+		if err := setSetting(settings, key, value); err == nil {
+			t.Errorf("setSetting(%s, %s) should fail", key, value)
+		}
+	}
+	err := setSetting(settings, "validaton.strict_validation", "true")
+	if err == nil || !strings.Contains(err.Error(), "validation") {
+		t.Errorf("unknown key should list the valid names, got %v", err)
+	}
+}
+
+func TestConfigSetSettingValidatesAndSaves(t *testing.T) {
+	path := withTempConfigPath(t)
+	if err := saveConfig(Config{Environments: []Environment{}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	captureStdout(t, func() {
+		if err := runConfigCommand([]string{"set-setting", "network.check_retries", "2"}); err != nil {
+			t.Fatalf("set-setting failed: %v", err)
+		}
+	})
+	config, err := loadConfig()
+	if err != nil || config.Settings == nil || config.Settings.Network.CheckRetries != 2 {
+		t.Fatalf("setting not saved: %+v, %v", config.Settings, err)
+	}
+
+	// Values the loader would refuse are rejected before saving
+	if err := runConfigCommand([]string{"set-setting", "claude_version_policy", "sometimes"}); err == nil || !strings.Contains(err.Error(), "argument validation failed") {
+		t.Errorf("expected an argument validation error, got %v", err)
+	}
+
+	// Clearing the last field drops the empty section
+	captureStdout(t, func() {
+		if err := runConfigCommand([]string{"set-setting", "network.check_retries", ""}); err != nil {
+			t.Fatalf("clearing failed: %v", err)
+		}
+	})
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if strings.Contains(string(data), "network") || strings.Contains(string(data), "settings") {
+		t.Errorf("empty sections should be pruned:\n%s", data)
+	}
+
+	out := captureStdout(t, func() {
+		if err := runConfigCommand([]string{"get-setting", "network.check_retries"}); err != nil {
+			t.Fatalf("get-setting failed: %v", err)
+		}
+	})
+	if out != "0\n" {
+		t.Errorf("get-setting printed %q", out)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// settingsConflictWarning explains which env keys in the settings.json claude will read for
+// env override what cce sets. Keys already holding env's value (e.g. after
+// --override-settings) are not reported. It is empty when nothing conflicts or the file
+// cannot be read; cce doctor reports unreadable settings.
+func settingsConflictWarning(env Environment) string {
+	path, err := claudeSettingsPathFor(env)
+	if err != nil {
+		return ""
+	}
+	settingsEnv, _, err := loadSettingsEnvFile(path)
+	if err != nil || settingsEnv == nil {
+		return ""
+	}
+	if resolved, err := resolveSecretRefs(env); err == nil {
+		env = resolved
+	}
+	assignments := launchVariables(env)
+	values := make(map[string]string, len(assignments))
+	for _, a := range assignments {
+		values[a.Key] = a.Value
+	}
+	var conflicts []string
+	for _, key := range detectSettingsConflicts(assignments, settingsEnv) {
+		if value, ok := values[key]; !ok || settingsEnv[key] != value {
+			conflicts = append(conflicts, key)
+		}
+	}
+	if len(conflicts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Warning: %s sets %s in its env block, and claude applies it after cce's variables, so '%s' may not take effect.\n"+
+		"Remove them with 'cce doctor --fix', or launch with --override-settings to write this environment's values there.",
+		path, strings.Join(conflicts, ", "), env.Name)
+}
+
+// overrideSettingsEnv makes the settings.json claude will read for env agree with env:
+// conflicting keys cce sets get env's values and other ANTHROPIC_* keys are removed. It
+// returns the settings path, the backup made and the keys changed; nothing is written when
+// there is no conflict.
+func overrideSettingsEnv(env Environment) (string, string, []string, error) {
+	path, err := claudeSettingsPathFor(env)
+	if err != nil {
+		return "", "", nil, err
+	}
+	settingsEnv, _, err := loadSettingsEnvFile(path)
+	if err != nil || settingsEnv == nil {
+		return path, "", nil, err
+	}
+
+	// The settings file gets real keys, never keychain references
+	resolved, err := resolveSecretRefs(env)
+	if err != nil {
+		return path, "", nil, err
+	}
+	assignments := launchVariables(resolved)
+	conflicts := detectSettingsConflicts(assignments, settingsEnv)
+	if len(conflicts) == 0 {
+		return path, "", nil, nil
+	}
+
+	values := make(map[string]string, len(assignments))
+	for _, a := range assignments {
+		values[a.Key] = a.Value
+	}
+	set := map[string]string{}
+	var remove []string
+	for _, key := range conflicts {
+		if value, ok := values[key]; ok {
+			set[key] = value
+		} else {
+			remove = append(remove, key)
+		}
+	}
+	backup, err := updateSettingsEnv(path, set, remove)
+	if err != nil {
+		return path, "", nil, err
+	}
+	return path, backup, conflicts, nil
+}
+
+// updateSettingsEnv sets and deletes keys in the env block of the settings.json at path,
+// leaving every other setting untouched, and returns the path of the .bak copy it made
+// first. A file that ends up holding an API key is restricted to 0600.
+func updateSettingsEnv(path string, set map[string]string, remove []string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read claude settings: %w", err)
+	}
+
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return "", fmt.Errorf("failed to parse claude settings: %w", err)
+	}
+	env := map[string]json.RawMessage{}
+	if raw, ok := settings["env"]; ok {
+		if err := json.Unmarshal(raw, &env); err != nil {
+			return "", fmt.Errorf("failed to parse claude settings env block: %w", err)
+		}
+	}
+	for _, key := range remove {
+		delete(env, key)
+	}
+	for key, value := range set {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode claude settings: %w", err)
+		}
+		env[key] = encoded
+	}
+
+	encodedEnv, err := json.Marshal(env)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claude settings: %w", err)
+	}
+	settings["env"] = encodedEnv
+	updated, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claude settings: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat claude settings: %w", err)
+	}
+	mode := info.Mode().Perm()
+	for key := range set {
+		if isSecretVar(key, "") {
+			mode &= 0600
+		}
+	}
+	backup := path + ".bak"
+	if err := ioutil.WriteFile(backup, data, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to back up claude settings: %w", err)
+	}
+	if err := ioutil.WriteFile(path, append(updated, '\n'), mode); err != nil {
+		return "", fmt.Errorf("failed to write claude settings: %w", err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return "", fmt.Errorf("failed to restrict claude settings: %w", err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSettingsConflictWarning(t *testing.T) {
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}
+
+	withClaudeSettings(t, `{"env": {"DISABLE_TELEMETRY": "1"}}`)
+	if warning := settingsConflictWarning(env); warning != "" {
+		t.Errorf("expected no warning without conflicts, got %q", warning)
+	}
+
+	withClaudeSettings(t, `{"env": {"ANTHROPIC_BASE_URL": "https://stale.example.com"}}`)
+	path, _ := claudeSettingsPath()
+	warning := settingsConflictWarning(env)
+	for _, want := range []string{path, "ANTHROPIC_BASE_URL", "'prod'", "--override-settings"} {
+		if !strings.Contains(warning, want) {
+			t.Errorf("warning missing %q: %s", want, warning)
+		}
+	}
+}
+
+func TestOverrideSettingsEnv(t *testing.T) {
+	withTempConfigPath(t)
+	withClaudeSettings(t, `{"model": "opus", "env": {"ANTHROPIC_BASE_URL": "https://stale.example.com", "ANTHROPIC_API_KEY": "sk-ant-api03-old", "ANTHROPIC_AUTH_TOKEN": "old", "DISABLE_TELEMETRY": "1"}}`)
+	path, _ := claudeSettingsPath()
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatalf("chmod failed: %v", err)
+	}
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}
+
+	gotPath, backup, keys, err := overrideSettingsEnv(env)
+	if err != nil {
+		t.Fatalf("overrideSettingsEnv() failed: %v", err)
+	}
+	if gotPath != path || backup != path+".bak" || strings.Join(keys, ",") != "ANTHROPIC_API_KEY,ANTHROPIC_AUTH_TOKEN,ANTHROPIC_BASE_URL" {
+		t.Fatalf("unexpected result: %s %s %v", gotPath, backup, keys)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	var settings struct {
+		Model string            `json:"model"`
+		Env   map[string]string `json:"env"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("settings no longer parse: %v", err)
+	}
+	if settings.Model != "opus" || settings.Env["DISABLE_TELEMETRY"] != "1" {
+		t.Errorf("unrelated settings changed: %+v", settings)
+	}
+	if settings.Env["ANTHROPIC_BASE_URL"] != env.URL || settings.Env["ANTHROPIC_API_KEY"] != env.APIKey {
+		t.Errorf("expected the environment's URL and key, got %v", settings.Env)
+	}
+	if _, ok := settings.Env["ANTHROPIC_AUTH_TOKEN"]; ok {
+		t.Error("a key variable cce does not set should be removed")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("settings holding a key should be 0600, got %04o", info.Mode().Perm())
+	}
+	if old, _ := ioutil.ReadFile(backup); !strings.Contains(string(old), "stale.example.com") {
+		t.Errorf("backup does not hold the original settings: %s", old)
+	}
+	if warning := settingsConflictWarning(env); warning != "" {
+		t.Errorf("expected no conflict after overriding, got %q", warning)
+	}
+}
+
+func TestOverrideSettingsFlag(t *testing.T) {
+	result := parseArguments([]string{"--env", "prod", "--override-settings", "chat"})
+	if result.Error != nil || result.CCEFlags["override_settings"] != "true" || strings.Join(result.ClaudeArgs, " ") != "chat" {
+		t.Fatalf("unexpected parse: %+v", result)
+	}
+	result = parseArguments([]string{"--group", "team", "--override-settings", "--", "-p", "hi"})
+	if err := validateGroupLaunch(result); err == nil {
+		t.Error("expected --override-settings to be rejected with --group")
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Shell dialects understood by cce shell
+const (
+	shellPosix      = "posix" // bash, zsh, sh
+	shellFish       = "fish"
+	shellPowerShell = "powershell"
+)
+
+// shellDialects maps accepted --shell values to a dialect
+var shellDialects = map[string]string{
+	"posix": shellPosix, "sh": shellPosix, "bash": shellPosix, "zsh": shellPosix,
+	"fish":       shellFish,
+	"powershell": shellPowerShell, "pwsh": shellPowerShell,
+}
+
+// shellOptions holds flags accepted by the shell subcommand
+type shellOptions struct {
+	Name  string // Environment to load; optional with --unset
+	Shell string // Dialect, one of the shell* constants
+	Unset bool   // Print unset statements instead of exports
+}
+
+// parseShellOptions parses shell subcommand flags
+func parseShellOptions(args []string) (shellOptions, error) {
+	opts := shellOptions{Shell: shellPosix}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--shell":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--shell flag requires a value (posix, fish or powershell)")
+			}
+			i++
+			dialect, ok := shellDialects[strings.ToLower(args[i])]
+			if !ok {
+				return opts, fmt.Errorf("unknown shell '%s' (expected posix, fish or powershell)", args[i])
+			}
+			opts.Shell = dialect
+		case arg == "--unset":
+			opts.Unset = true
+		case strings.HasPrefix(arg, "-"):
+			return opts, fmt.Errorf("unknown shell flag '%s'", arg)
+		case opts.Name != "":
+			return opts, fmt.Errorf("shell command takes a single environment name")
+		default:
+			opts.Name = arg
+		}
+	}
+	if opts.Name == "" && !opts.Unset {
+		return opts, fmt.Errorf("shell command requires environment name")
+	}
+	return opts, nil
+}
+
+// shellExport renders one assignment for the dialect
+func shellExport(dialect, key, value string) string {
+	switch dialect {
+	case shellFish:
+		// Inside fish single quotes only \ and ' are special
+		quoted := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+		return fmt.Sprintf("set -gx %s '%s'", key, quoted)
+	case shellPowerShell:
+		return fmt.Sprintf("$env:%s = '%s'", key, strings.ReplaceAll(value, "'", "''"))
+	default:
+		return fmt.Sprintf("export %s=%s", key, shellQuote(value))
+	}
+}
+
+// shellUnset renders the removal of one variable for the dialect
+func shellUnset(dialect, key string) string {
+	switch dialect {
+	case shellFish:
+		return fmt.Sprintf("set -e %s", key)
+	case shellPowerShell:
+		return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", key)
+	default:
+		return fmt.Sprintf("unset %s", key)
+	}
+}
+
+// inheritedAnthropicVars lists ANTHROPIC_* names set in the given environ, sorted
+func inheritedAnthropicVars(environ []string) []string {
+	var names []string
+	for _, kv := range environ {
+		if key := strings.SplitN(kv, "=", 2)[0]; strings.HasPrefix(key, "ANTHROPIC") {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeShellScript prints the statements that load env into the calling shell. Like a
+// launch, keychain references are replaced by the keys they stand for, and inherited
+// ANTHROPIC_* variables the environment does not set are unset first so a stale token
+// from another endpoint cannot leak through.
+func writeShellScript(w io.Writer, dialect string, env Environment, environ []string) error {
+	env, err := resolveSecretRefs(env)
+	if err != nil {
+		return err
+	}
+	assignments := launchVariables(env)
+	managed := make(map[string]bool, len(assignments))
+	for _, a := range assignments {
+		managed[a.Key] = true
+	}
+
+	lines := []string{fmt.Sprintf("# cce environment: %s", env.Name)}
+	for _, key := range inheritedAnthropicVars(environ) {
+		if !managed[key] {
+			lines = append(lines, shellUnset(dialect, key))
+		}
+	}
+	for _, a := range assignments {
+		lines = append(lines, shellExport(dialect, a.Key, a.Value))
+	}
+
+	if _, err := fmt.Fprintln(w, strings.Join(lines, "\n")); err != nil {
+		return fmt.Errorf("failed to write shell statements: %w", err)
+	}
+	return nil
+}
+
+// writeShellUnset prints statements removing env's variables, or every inherited
+// ANTHROPIC_* variable when env is nil
+func writeShellUnset(w io.Writer, dialect string, env *Environment, environ []string) error {
+	var keys []string
+	if env != nil {
+		for _, a := range launchVariables(*env) {
+			keys = append(keys, a.Key)
+		}
+	} else {
+		keys = inheritedAnthropicVars(environ)
+	}
+
+	for _, key := range keys {
+		if _, err := fmt.Fprintln(w, shellUnset(dialect, key)); err != nil {
+			return fmt.Errorf("failed to write shell statements: %w", err)
+		}
+	}
+	return nil
+}
+
+// runShell prints eval-able statements that configure the current shell for an environment
+func runShell(args []string) error {
+	opts, err := parseShellOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+
+	if opts.Name == "" {
+		return writeShellUnset(os.Stdout, opts.Shell, nil, os.Environ())
+	}
+
+	if err := validateName(opts.Name); err != nil {
+		return fmt.Errorf("invalid environment name: %w", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	index, err := lookupEnvironment(config, opts.Name)
+	if err != nil {
+		return err
+	}
+	env := config.Environments[index]
+
+	if opts.Unset {
+		return writeShellUnset(os.Stdout, opts.Shell, &env, os.Environ())
+	}
+
+	if err := validateStoredEnvironment(env); err != nil {
+		return fmt.Errorf("environment preparation failed: %w", err)
+	}
+	if err := ensureSettingsDir(env); err != nil {
+		return fmt.Errorf("environment preparation failed: %w", err)
+	}
+	return writeShellScript(os.Stdout, opts.Shell, env, os.Environ())
+}
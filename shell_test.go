@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseShellOptions(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    shellOptions
+		wantErr string
+	}{
+		{[]string{"prod"}, shellOptions{Name: "prod", Shell: shellPosix}, ""},
+		{[]string{"prod", "--shell", "FISH"}, shellOptions{Name: "prod", Shell: shellFish}, ""},
+		{[]string{"--shell", "pwsh", "prod"}, shellOptions{Name: "prod", Shell: shellPowerShell}, ""},
+		{[]string{"--unset"}, shellOptions{Shell: shellPosix, Unset: true}, ""},
+		{[]string{"--unset", "prod", "--shell", "zsh"}, shellOptions{Name: "prod", Shell: shellPosix, Unset: true}, ""},
+		{nil, shellOptions{}, "requires environment name"},
+		{[]string{"prod", "--shell", "tcsh"}, shellOptions{}, "unknown shell"},
+		{[]string{"prod", "--shell"}, shellOptions{}, "requires a value"},
+		{[]string{"prod", "dev"}, shellOptions{}, "single environment name"},
+		{[]string{"prod", "--mask"}, shellOptions{}, "unknown shell flag"},
+	}
+	for _, tt := range tests {
+		got, err := parseShellOptions(tt.args)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%v: error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%v: got %+v, %v; want %+v", tt.args, got, err, tt.want)
+		}
+	}
+}
+
+func TestShellDialectQuoting(t *testing.T) {
+	value := `it's $HOME \n`
+	tests := []struct {
+		dialect, export, unset string
+	}{
+		{shellPosix, `export K='it'\''s $HOME \n'`, "unset K"},
+		{shellFish, `set -gx K 'it\'s $HOME \\n'`, "set -e K"},
+		{shellPowerShell, `$env:K = 'it''s $HOME \n'`, "Remove-Item Env:K -ErrorAction SilentlyContinue"},
+	}
+	for _, tt := range tests {
+		if got := shellExport(tt.dialect, "K", value); got != tt.export {
+			t.Errorf("%s export = %s, want %s", tt.dialect, got, tt.export)
+		}
+		if got := shellUnset(tt.dialect, "K"); got != tt.unset {
+			t.Errorf("%s unset = %s, want %s", tt.dialect, got, tt.unset)
+		}
+	}
+}
+
+func TestWriteShellScriptUnsetsStaleAnthropicVars(t *testing.T) {
+	env := Environment{
+		Name:    "prod",
+		URL:     "https://api.anthropic.com",
+		APIKey:  "sk-ant-shell-'quoted'",
+		EnvVars: map[string]string{"HTTPS_PROXY": "http://proxy:8080"},
+	}
+	environ := []string{"ANTHROPIC_AUTH_TOKEN=old", "ANTHROPIC_BASE_URL=https://old", "PATH=/bin"}
+
+	var buf bytes.Buffer
+	if err := writeShellScript(&buf, shellPosix, env, environ); err != nil {
+		t.Fatalf("writeShellScript() failed: %v", err)
+	}
+	want := strings.Join([]string{
+		"# cce environment: prod",
+		"unset ANTHROPIC_AUTH_TOKEN",
+		"export ANTHROPIC_BASE_URL='https://api.anthropic.com'",
+		`export ANTHROPIC_API_KEY='sk-ant-shell-'\''quoted'\'''`,
+		"export HTTPS_PROXY='http://proxy:8080'",
+	}, "\n") + "\n"
+	if buf.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// The key is loaded verbatim when a POSIX shell evaluates the output
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	out, err := exec.Command(sh, "-c", buf.String()+`printf %s "$ANTHROPIC_API_KEY"`).Output()
+	if err != nil {
+		t.Fatalf("eval failed: %v", err)
+	}
+	if string(out) != env.APIKey {
+		t.Errorf("evaluated key = %q, want %q", out, env.APIKey)
+	}
+}
+
+func TestWriteShellUnset(t *testing.T) {
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-shell-key", APIKeyEnv: "ANTHROPIC_AUTH_TOKEN"}
+
+	var buf bytes.Buffer
+	if err := writeShellUnset(&buf, shellFish, &env, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "set -e ANTHROPIC_BASE_URL\nset -e ANTHROPIC_AUTH_TOKEN\n" {
+		t.Errorf("unexpected unset output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeShellUnset(&buf, shellPosix, nil, []string{"PATH=/bin", "ANTHROPIC_MODEL=x", "ANTHROPIC_API_KEY=y"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "unset ANTHROPIC_API_KEY\nunset ANTHROPIC_MODEL\n" {
+		t.Errorf("unexpected unset output:\n%s", buf.String())
+	}
+}
+
+func TestRunShellUnknownEnvironment(t *testing.T) {
+	withTempConfigPath(t)
+	if err := handleCommand([]string{"shell", "missing"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestUseExportsEnvironment(t *testing.T) {
+	withTempConfigPath(t)
+	env := Environment{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED", Model: "claude-sonnet-4-5-20250929"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	output := captureStdout(t, func() {
+		if err := handleCommand([]string{"use", "prod", "--shell", "fish"}); err != nil {
+			t.Fatalf("cce use failed: %v", err)
+		}
+	})
+	for _, want := range []string{"set -gx ANTHROPIC_BASE_URL", "set -gx ANTHROPIC_API_KEY 'sk-ant-REDACTED'", "set -gx ANTHROPIC_MODEL"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+
+	if err := handleCommand([]string{"use", "../prod"}); err == nil || !strings.Contains(err.Error(), "invalid environment name") {
+		t.Errorf("expected the name to be validated, got %v", err)
+	}
+}
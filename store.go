@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// errUnsafeConfigPath marks a config path refused because a symlink leads somewhere it
+// should not; categorizeError reports it as a permission problem
+var errUnsafeConfigPath = errors.New("insecure configuration path (permission check failed)")
+
+// checkConfigPathSafety refuses a config dir or file that is a symlink leading outside
+// where it is expected to live: the dir must resolve under its parent (so a dotfiles
+// checkout in the home directory still works) and the file under the config dir.
+// Missing paths are fine; they will be created as regular files.
+func checkConfigPathSafety(configPath string) error {
+	dir := filepath.Dir(configPath)
+	if err := checkSymlinkWithin(dir, filepath.Dir(dir)); err != nil {
+		return err
+	}
+	return checkSymlinkWithin(configPath, dir)
+}
+
+// looseModeWarned keeps the permission warning to once per run; the config is loaded repeatedly
+var looseModeWarned bool
+
+// warnLooseConfigPermissions prints a warning when the config dir or file can be read by
+// other users, e.g. because the directory was created by hand. Loading still proceeds.
+// Windows does not use POSIX modes, so nothing is checked there.
+func warnLooseConfigPermissions(configPath string) {
+	if looseModeWarned || runtime.GOOS == "windows" {
+		return
+	}
+	problems, err := configPermissionProblems(configPath)
+	if err != nil {
+		return
+	}
+	for _, p := range problems {
+		if !p.loose() {
+			continue
+		}
+		looseModeWarned = true
+		fmt.Fprintf(os.Stderr, "Warning: %s has permissions %04o; other users may be able to read your API keys (want %04o)\n", p.path, p.have, p.want)
+	}
+	if looseModeWarned {
+		fmt.Fprintln(os.Stderr, "Run 'cce doctor --fix' to restrict access to your user only.")
+	}
+}
+
+// checkSymlinkWithin returns errUnsafeConfigPath if path is a symlink whose target does
+// not resolve inside root
+func checkSymlinkWithin(path, root string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to inspect %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("%w: %s is a symlink that cannot be resolved: %v", errUnsafeConfigPath, path, err)
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", root, err)
+	}
+	if rel, err := filepath.Rel(resolvedRoot, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%w: %s is a symlink to %s, outside %s", errUnsafeConfigPath, path, target, resolvedRoot)
+	}
+	return nil
+}
+
+// Store persists the configuration. loadConfig and saveConfig go through the active
+// store, so a keyring or remote backend can replace the file without touching commands.
+type Store interface {
+	Load() (Config, error) // Missing configuration yields an empty Config, not an error
+	Save(config Config) error
+	Path() string // Where the configuration lives, for messages; may be empty
+	Backup() error
+}
+
+// configStore is the active store; tests may replace it
+var configStore Store = fileStore{}
+
+// fileStore keeps the configuration in ~/.claude-code-env/config.json (or the override path)
+type fileStore struct{}
+
+// Path returns the configuration file path, or "" when it cannot be determined
+func (fileStore) Path() string {
+	path, err := getConfigPath()
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// Load reads and parses the configuration file
+func (fileStore) Load() (Config, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return Config{}, fmt.Errorf("configuration loading failed: %w", err)
+	}
+	if err := checkConfigPathSafety(configPath); err != nil {
+		return Config{}, fmt.Errorf("configuration loading failed: %w", err)
+	}
+	warnLooseConfigPermissions(configPath)
+
+	// Check if file exists
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		// Return empty configuration if file doesn't exist (not an error)
+		return Config{Environments: []Environment{}}, nil
+	} else if err != nil {
+		return Config{}, fmt.Errorf("configuration file access failed: %w", err)
+	}
+
+	// Read file contents
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return Config{}, fmt.Errorf("configuration file read failed: %w", err)
+	}
+
+	// Handle empty file
+	if len(data) == 0 {
+		return Config{Environments: []Environment{}}, nil
+	}
+
+	// Parse JSON
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		if format := detectConfigFormat(data); format == configFormatLegacyList || format == configFormatLegacyMap {
+			return Config{}, fmt.Errorf("configuration file parsing failed (%s format detected, run 'cce config migrate'): %w", format, err)
+		}
+		return Config{}, fmt.Errorf("configuration file parsing failed (invalid JSON): %w", err)
+	}
+
+	// Validate structure includes environments key when file isn't empty
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err == nil {
+		if _, ok := raw["environments"]; !ok {
+			return Config{}, fmt.Errorf("configuration validation failed: missing environments field")
+		}
+	}
+
+	return config, nil
+}
+
+// Backup copies the current file into the backups directory; nothing to back up is not an error
+func (fileStore) Backup() error {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := checkConfigPathSafety(configPath); err != nil {
+		return err
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return nil
+	}
+
+	backup := newConfigBackup(configPath)
+	backupPath, err := backup.createBackup()
+	if err != nil {
+		return err
+	}
+	if backupPath != "" {
+		fmt.Printf("Configuration backed up to: %s\n", backupPath)
+	}
+	return backup.pruneBackups(maxConfigBackups)
+}
+
+// Save writes the configuration with atomic operations and proper permissions
+func (fileStore) Save(config Config) error {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return fmt.Errorf("configuration save failed: %w", err)
+	}
+	if err := checkConfigPathSafety(configPath); err != nil {
+		return fmt.Errorf("configuration save failed: %w", err)
+	}
+
+	// Ensure configuration directory exists
+	if err := ensureConfigDir(); err != nil {
+		return fmt.Errorf("configuration save failed: %w", err)
+	}
+
+	// Marshal to JSON with proper formatting
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("configuration serialization failed: %w", err)
+	}
+
+	// Use atomic write pattern (temp file + rename). The temp file gets a random name and
+	// is created with O_EXCL and 0600, so nobody can pre-plant it as a symlink or read it.
+	tempFile, err := ioutil.TempFile(filepath.Dir(configPath), ".config-*.tmp")
+	if err != nil {
+		return fmt.Errorf("configuration temporary file creation failed: %w", err)
+	}
+	tempPath := tempFile.Name()
+	if _, err := tempFile.Write(data); err != nil {
+		tempFile.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("configuration temporary file write failed: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("configuration temporary file write failed: %w", err)
+	}
+
+	// Verify temporary file permissions
+	if info, err := os.Stat(tempPath); err != nil {
+		// Clean up temp file
+		os.Remove(tempPath)
+		return fmt.Errorf("configuration temporary file verification failed: %w", err)
+	} else if info.Mode().Perm() != 0600 {
+		// Try to fix permissions
+		if err := os.Chmod(tempPath, 0600); err != nil {
+			os.Remove(tempPath)
+			return fmt.Errorf("configuration temporary file permission setting failed: %w", err)
+		}
+	}
+
+	// If destination exists, verify writability to surface permission issues
+	if _, err := os.Stat(configPath); err == nil {
+		f, openErr := os.OpenFile(configPath, os.O_WRONLY, 0)
+		if openErr != nil {
+			// Clean up temp file
+			os.Remove(tempPath)
+			return fmt.Errorf("configuration file save failed (permission denied): %w", openErr)
+		}
+		f.Close()
+	}
+
+	// Atomic move (rename) from temp to final location
+	if err := os.Rename(tempPath, configPath); err != nil {
+		// Clean up temp file on error
+		os.Remove(tempPath)
+		return fmt.Errorf("configuration file save failed (atomic move): %w", err)
+	}
+	// Earlier versions wrote through a fixed config.json.tmp; drop one a crash left behind.
+	// Remove unlinks a symlink rather than following it.
+	os.Remove(configPath + ".tmp")
+
+	// Verify final file permissions
+	if info, err := os.Stat(configPath); err != nil {
+		return fmt.Errorf("configuration file verification failed: %w", err)
+	} else if info.Mode().Perm() != 0600 {
+		// Try to fix permissions
+		if err := os.Chmod(configPath, 0600); err != nil {
+			return fmt.Errorf("configuration file permission setting failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// errNamesFastPath marks a config the names-only reader does not handle; the caller falls
+// back to the full load, which reports any real problem with the usual message
+var errNamesFastPath = errors.New("config not readable by the names-only path")
+
+// loadEnvironmentNames returns the environment names for read-only listings such as
+// list --names. For the file store it streams through the file and decodes only each
+// environment's name, skipping the per-environment validation loadConfig does, which
+// dominates startup with hundreds of environments. Other stores, and files the stream
+// cannot follow (legacy formats, malformed JSON), use loadConfig, so errors read the same.
+// Commands that change the config or use more than names always use loadConfig.
+func loadEnvironmentNames() ([]string, error) {
+	if _, ok := configStore.(fileStore); ok {
+		names, err := readEnvironmentNames()
+		if !errors.Is(err, errNamesFastPath) {
+			return names, err
+		}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(config.Environments))
+	for _, env := range config.Environments {
+		names = append(names, env.Name)
+	}
+	return names, nil
+}
+
+// readEnvironmentNames is the streaming half of loadEnvironmentNames
+func readEnvironmentNames() ([]string, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("configuration loading failed: %w", err)
+	}
+	if err := checkConfigPathSafety(configPath); err != nil {
+		return nil, fmt.Errorf("configuration loading failed: %w", err)
+	}
+	warnLooseConfigPermissions(configPath)
+
+	file, err := os.Open(configPath)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("configuration file read failed: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, errNamesFastPath // Also an empty file, which the full load accepts
+	}
+	var names []string
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, errNamesFastPath
+		}
+		if key != "environments" {
+			var skip json.RawMessage
+			if err := decoder.Decode(&skip); err != nil {
+				return nil, errNamesFastPath
+			}
+			continue
+		}
+		if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+			return nil, errNamesFastPath
+		}
+		names = []string{}
+		for decoder.More() {
+			var env struct {
+				Name string `json:"name"`
+			}
+			if err := decoder.Decode(&env); err != nil {
+				return nil, errNamesFastPath
+			}
+			names = append(names, env.Name)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, errNamesFastPath
+		}
+	}
+	if names == nil {
+		return nil, errNamesFastPath // No environments key: let the full load reject it
+	}
+	return names, nil
+}
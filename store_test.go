@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// memoryStore keeps the configuration in memory and records backups
+type memoryStore struct {
+	config  *Config
+	saves   int
+	backups []Config
+	loadErr error
+}
+
+func (m *memoryStore) Load() (Config, error) {
+	if m.loadErr != nil {
+		return Config{}, m.loadErr
+	}
+	if m.config == nil {
+		return Config{Environments: []Environment{}}, nil
+	}
+	// Copy the slice so callers editing in place don't rewrite stored state
+	config := *m.config
+	config.Environments = append([]Environment(nil), m.config.Environments...)
+	return config, nil
+}
+
+func (m *memoryStore) Save(config Config) error {
+	m.config = &config
+	m.saves++
+	return nil
+}
+
+func (m *memoryStore) Path() string { return "memory" }
+
+func (m *memoryStore) Backup() error {
+	if m.config != nil {
+		m.backups = append(m.backups, *m.config)
+	}
+	return nil
+}
+
+func withMemoryStore(t *testing.T, store *memoryStore) {
+	t.Helper()
+	original := configStore
+	configStore = store
+	t.Cleanup(func() { configStore = original })
+}
+
+func TestMemoryStoreBacksCommands(t *testing.T) {
+	store := &memoryStore{}
+	withMemoryStore(t, store)
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	if len(config.Environments) != 0 {
+		t.Fatalf("expected empty config, got %+v", config)
+	}
+
+	config.Environments = append(config.Environments, Environment{Name: "mem", URL: "https://api.anthropic.com", APIKey: "sk-ant-memory-store"})
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	if store.saves != 1 || len(store.backups) != 0 {
+		t.Errorf("first save: saves=%d backups=%d", store.saves, len(store.backups))
+	}
+
+	// Command handlers work against the active store without knowing it
+	captureStdout(t, func() {
+		if err := runSet([]string{"mem", "model=claude-3-haiku-20240307"}); err != nil {
+			t.Fatalf("runSet() failed: %v", err)
+		}
+	})
+	if store.saves != 2 || len(store.backups) != 1 {
+		t.Errorf("second save: saves=%d backups=%d", store.saves, len(store.backups))
+	}
+	if store.backups[0].Environments[0].Model != "" {
+		t.Errorf("backup should hold the previous config, got %+v", store.backups[0])
+	}
+	if store.config.Environments[0].Model != "claude-3-haiku-20240307" {
+		t.Errorf("store not updated: %+v", store.config)
+	}
+
+	captureStdout(t, func() {
+		if err := runRemove("mem"); err != nil {
+			t.Fatalf("runRemove() failed: %v", err)
+		}
+	})
+	if len(store.config.Environments) != 0 {
+		t.Errorf("expected environment removed, got %+v", store.config.Environments)
+	}
+}
+
+func TestStoreValidationIsBackendIndependent(t *testing.T) {
+	store := &memoryStore{config: &Config{Environments: []Environment{
+		{Name: "bad name!", URL: "https://api.anthropic.com", APIKey: "sk-ant-memory-store"},
+	}}}
+	withMemoryStore(t, store)
+
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "configuration validation failed") {
+		t.Errorf("expected validation error from loaded config, got %v", err)
+	}
+
+	err := saveConfig(Config{Environments: []Environment{{Name: "x", URL: "not a url", APIKey: "sk-ant-memory-store"}}})
+	if err == nil {
+		t.Error("expected invalid environment to be rejected before Save")
+	}
+	if store.saves != 0 {
+		t.Errorf("invalid config must not reach the store, saves=%d", store.saves)
+	}
+
+	store.loadErr = fmt.Errorf("backend unavailable")
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "backend unavailable") {
+		t.Errorf("expected store error to surface, got %v", err)
+	}
+}
+
+func TestFileStorePath(t *testing.T) {
+	path := withTempConfigPath(t)
+	if got := (fileStore{}).Path(); got != path {
+		t.Errorf("Path() = %q, want %q", got, path)
+	}
+	if err := (fileStore{}).Backup(); err != nil {
+		t.Errorf("Backup() with no file should succeed, got %v", err)
+	}
+}
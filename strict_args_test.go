@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+var metacharacterArgs = []string{"chat", "a;b", "x|y", "`id`", "$(whoami)", "one&two"}
+
+func TestValidatePassthroughArgsModes(t *testing.T) {
+	if err := validatePassthroughArgsMode(metacharacterArgs, false); err != nil {
+		t.Errorf("default mode should only warn, got %v", err)
+	}
+	for _, arg := range metacharacterArgs[1:] {
+		if err := validatePassthroughArgsMode([]string{arg}, true); err == nil {
+			t.Errorf("strict mode should reject %q", arg)
+		}
+	}
+	if err := validatePassthroughArgsMode([]string{"chat", "--model", "claude-3-5-sonnet-20241022"}, true); err != nil {
+		t.Errorf("strict mode should accept plain arguments: %v", err)
+	}
+	// Dangerous arguments stay blocked in both modes
+	if err := validatePassthroughArgsMode([]string{"sudo"}, false); err == nil {
+		t.Error("dangerous argument should be rejected in default mode")
+	}
+}
+
+func TestStrictArgsSources(t *testing.T) {
+	path := withTempConfigPath(t)
+	os.Unsetenv("CCE_STRICT_ARGS")
+
+	result := parseArguments([]string{"--strict-args", "--env", "prod", "chat"})
+	if result.CCEFlags["strict_args"] != "true" {
+		t.Fatalf("expected strict_args flag, got %+v", result.CCEFlags)
+	}
+	if len(result.ClaudeArgs) != 1 || result.ClaudeArgs[0] != "chat" {
+		t.Errorf("--strict-args should not be passed to claude: %v", result.ClaudeArgs)
+	}
+	if !strictArgsEnabled(result) {
+		t.Error("flag should enable strict mode")
+	}
+
+	plain := parseArguments([]string{"chat"})
+	if strictArgsEnabled(plain) {
+		t.Error("strict mode should be off by default")
+	}
+
+	os.Setenv("CCE_STRICT_ARGS", "1")
+	if !strictArgsEnabled(plain) {
+		t.Error("CCE_STRICT_ARGS should enable strict mode")
+	}
+	os.Unsetenv("CCE_STRICT_ARGS")
+
+	if err := ioutil.WriteFile(path, []byte(`{"environments": [], "settings": {"strict_args": true}}`), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if !strictArgsEnabled(plain) {
+		t.Error("strict_args setting should enable strict mode")
+	}
+}
+
+func TestHandleCommandStrictArgsRejects(t *testing.T) {
+	withTempConfigPath(t)
+	err := handleCommand([]string{"--strict-args", "--", "a;b"})
+	if err == nil || !strings.Contains(err.Error(), "argument validation failed") {
+		t.Errorf("expected argument validation error, got %v", err)
+	}
+}
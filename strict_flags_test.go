@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSuggestFlag(t *testing.T) {
+	tests := map[string]string{
+		"--ene":             "--env",
+		"--ene=prod":        "--env",
+		"--key-vra":         "--key-var",
+		"--strict-arg":      "--strict-args",
+		"--skip-prefli":     "",
+		"--permission-mode": "",
+		"-x":                "",
+	}
+	for flag, want := range tests {
+		if got := suggestFlag(flag); got != want {
+			t.Errorf("suggestFlag(%q) = %q, want %q", flag, got, want)
+		}
+	}
+}
+
+func TestParseArgumentsRecordsUnknownFlag(t *testing.T) {
+	result := parseArguments([]string{"--ene", "prod", "chat"})
+	if result.Error != nil {
+		t.Fatalf("unexpected error: %v", result.Error)
+	}
+	if result.UnknownFlag != "--ene" {
+		t.Errorf("UnknownFlag = %q, want --ene", result.UnknownFlag)
+	}
+	if got := strings.Join(result.ClaudeArgs, " "); got != "--ene prod chat" {
+		t.Errorf("permissive mode should forward the flag, got %q", got)
+	}
+
+	for _, args := range [][]string{{"-e", "prod", "chat", "--ene"}, {"--", "--ene"}, {"--strict-flags", "-e", "prod"}} {
+		if got := parseArguments(args).UnknownFlag; got != "" {
+			t.Errorf("%v: flags after the claude arguments start are claude's, got %q", args, got)
+		}
+	}
+}
+
+func TestStrictFlagsSources(t *testing.T) {
+	path := withTempConfigPath(t)
+	os.Unsetenv("CCE_STRICT_FLAGS")
+
+	plain := parseArguments([]string{"--ene", "prod"})
+	if err := checkUnknownFlag(plain); err != nil {
+		t.Errorf("unknown flags should be forwarded by default, got %v", err)
+	}
+
+	strict := parseArguments([]string{"--strict-flags", "--ene", "prod"})
+	err := checkUnknownFlag(strict)
+	if err == nil || !strings.Contains(err.Error(), "unknown flag --ene, did you mean --env?") {
+		t.Errorf("expected a suggestion, got %v", err)
+	}
+
+	os.Setenv("CCE_STRICT_FLAGS", "1")
+	if err := checkUnknownFlag(parseArguments([]string{"--permission-mode", "plan"})); err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("expected an unknown flag error without a suggestion, got %v", err)
+	}
+	os.Unsetenv("CCE_STRICT_FLAGS")
+
+	if err := ioutil.WriteFile(path, []byte(`{"environments": [], "settings": {"strict_flags": true}}`), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if checkUnknownFlag(plain) == nil {
+		t.Error("strict_flags setting should enable strict mode")
+	}
+	if err := checkUnknownFlag(parseArguments([]string{"-e", "prod", "--", "--permission-mode", "plan"})); err != nil {
+		t.Errorf("claude flags after -- are fine in strict mode, got %v", err)
+	}
+}
+
+func TestHandleCommandStrictFlagsRejects(t *testing.T) {
+	withTempConfigPath(t)
+	err := handleCommand([]string{"--strict-flags", "--ene", "prod"})
+	if err == nil || !strings.Contains(err.Error(), "argument parsing failed") {
+		t.Errorf("expected argument parsing error, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const symlinkTestConfig = `{"environments": [{"name": "prod", "url": "https://api.anthropic.com", "api_key": "sk-ant-api03-test-key-123"}]}`
+
+func TestLoadRefusesConfigSymlinkedOutsideDir(t *testing.T) {
+	path := withTempConfigPath(t)
+	attacker := filepath.Join(t.TempDir(), "attacker.json")
+	if err := ioutil.WriteFile(attacker, []byte(symlinkTestConfig), 0644); err != nil {
+		t.Fatalf("failed to write attacker file: %v", err)
+	}
+	if err := os.Symlink(attacker, path); err != nil {
+		t.Fatalf("failed to plant symlink: %v", err)
+	}
+
+	_, err := loadConfig()
+	if err == nil || !strings.Contains(err.Error(), "outside") {
+		t.Fatalf("expected symlink refusal, got %v", err)
+	}
+	if got := categorizeError(err); got != "permission" {
+		t.Errorf("categorizeError() = %q, want permission", got)
+	}
+
+	if err := saveConfig(Config{Environments: []Environment{{Name: "x", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-test-key-123"}}}); err == nil {
+		t.Fatal("save through a planted symlink should be refused")
+	}
+	data, _ := ioutil.ReadFile(attacker)
+	if string(data) != symlinkTestConfig {
+		t.Errorf("attacker file was modified: %s", data)
+	}
+}
+
+func TestLoadRefusesConfigDirSymlinkedOutsideParent(t *testing.T) {
+	home := t.TempDir()
+	elsewhere := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(elsewhere, "config.json"), []byte(symlinkTestConfig), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	dir := filepath.Join(home, ".claude-code-env")
+	if err := os.Symlink(elsewhere, dir); err != nil {
+		t.Fatalf("failed to plant symlink: %v", err)
+	}
+	original := configPathOverride
+	configPathOverride = filepath.Join(dir, "config.json")
+	t.Cleanup(func() { configPathOverride = original })
+
+	if _, err := loadConfig(); err == nil || !strings.Contains(err.Error(), "insecure configuration path") {
+		t.Fatalf("expected symlinked dir to be refused, got %v", err)
+	}
+}
+
+func TestSymlinksInsideExpectedDirAreAllowed(t *testing.T) {
+	// A dotfiles checkout under the same parent, and a file link inside the config dir
+	home := t.TempDir()
+	dotfiles := filepath.Join(home, "dotfiles", "cce")
+	if err := os.MkdirAll(dotfiles, 0700); err != nil {
+		t.Fatalf("failed to create dotfiles dir: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dotfiles, "config.v2.json"), []byte(symlinkTestConfig), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := os.Symlink("config.v2.json", filepath.Join(dotfiles, "config.json")); err != nil {
+		t.Fatalf("failed to link config file: %v", err)
+	}
+	dir := filepath.Join(home, ".claude-code-env")
+	if err := os.Symlink(dotfiles, dir); err != nil {
+		t.Fatalf("failed to link config dir: %v", err)
+	}
+	original := configPathOverride
+	configPathOverride = filepath.Join(dir, "config.json")
+	t.Cleanup(func() { configPathOverride = original })
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	if len(config.Environments) != 1 {
+		t.Errorf("expected 1 environment, got %d", len(config.Environments))
+	}
+}
+
+func TestSaveUsesUniqueTempFile(t *testing.T) {
+	path := withTempConfigPath(t)
+	// A symlink planted at the old predictable temp name must not be followed
+	victim := filepath.Join(t.TempDir(), "victim")
+	if err := ioutil.WriteFile(victim, []byte("untouched"), 0644); err != nil {
+		t.Fatalf("failed to write victim: %v", err)
+	}
+	if err := os.Symlink(victim, path+".tmp"); err != nil {
+		t.Fatalf("failed to plant symlink: %v", err)
+	}
+
+	config := Config{Environments: []Environment{{Name: "prod", URL: "https://api.anthropic.com", APIKey: "sk-ant-api03-test-key-123"}}}
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	if data, _ := ioutil.ReadFile(victim); string(data) != "untouched" {
+		t.Errorf("save followed the planted temp symlink: %s", data)
+	}
+	entries, _ := ioutil.ReadDir(filepath.Dir(path))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".config-") {
+			t.Errorf("temp file %s left behind", entry.Name())
+		}
+	}
+}
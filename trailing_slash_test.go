@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeURLSlash(t *testing.T) {
+	tests := map[string]string{
+		"https://api.example.com/v1/":       "https://api.example.com/v1",
+		"https://api.example.com/v1":        "https://api.example.com/v1",
+		"https://api.example.com/":          "https://api.example.com",
+		"https://api.example.com//":         "https://api.example.com",
+		"https://api.example.com/v1/?x=a/":  "https://api.example.com/v1?x=a/",
+		"https://api.example.com/v1/#frag/": "https://api.example.com/v1#frag/",
+	}
+	for input, want := range tests {
+		if got := normalizeURLSlash(input); got != want {
+			t.Errorf("normalizeURLSlash(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestTrailingSlashWarning(t *testing.T) {
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.example.com/v1"},
+		{Name: "canary", URL: "https://api.example.com/v2"},
+		{Name: "slashed", URL: "https://other.example.com/v1/"},
+	}}
+
+	warning := trailingSlashWarning(config, "https://API.example.com/v1/")
+	if !strings.Contains(warning, "ends with '/', unlike prod, canary on the same host") || !strings.Contains(warning, "--normalize-url") {
+		t.Errorf("slashed URL should be flagged, got %q", warning)
+	}
+	if warning := trailingSlashWarning(config, "https://other.example.com/v2"); !strings.Contains(warning, "has no trailing '/', unlike slashed") {
+		t.Errorf("unslashed URL should be flagged, got %q", warning)
+	}
+	for _, consistent := range []string{"https://api.example.com/v3", "https://other.example.com/v2/", "https://new.example.com/"} {
+		if warning := trailingSlashWarning(config, consistent); warning != "" {
+			t.Errorf("%s: unexpected warning %q", consistent, warning)
+		}
+	}
+}
+
+func TestAddKeepsURLAsEnteredAndWarns(t *testing.T) {
+	config := Config{Environments: []Environment{
+		{Name: "prod", URL: "https://api.example.com/v1", APIKey: "sk-ant-slash-one-1234"},
+	}}
+	env := Environment{Name: "beta", URL: "https://api.example.com/v2/", APIKey: "sk-ant-slash-two-5678"}
+	stderr := captureStderr(t, func() {
+		if err := addEnvironmentToConfig(&config, env); err != nil {
+			t.Fatalf("addEnvironmentToConfig() failed: %v", err)
+		}
+	})
+	if !strings.Contains(stderr, "ends with '/', unlike prod") {
+		t.Errorf("expected trailing slash warning, got %q", stderr)
+	}
+	if config.Environments[1].URL != "https://api.example.com/v2/" {
+		t.Errorf("URL should be stored as entered, got %q", config.Environments[1].URL)
+	}
+}
+
+func TestParseAddOptionsNormalizeURL(t *testing.T) {
+	opts, err := parseAddOptions([]string{"--normalize-url"})
+	if err != nil || !opts.NormalizeURL {
+		t.Errorf("parseAddOptions(--normalize-url) = %+v, %v", opts, err)
+	}
+}
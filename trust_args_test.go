@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateArgsForEnvironmentTrust(t *testing.T) {
+	trusted := Environment{Name: "scratch", TrustArgs: true}
+
+	stderr := captureStderr(t, func() {
+		if err := validateArgsForEnvironment(metacharacterArgs, trusted, false); err != nil {
+			t.Errorf("trusted environment should accept metacharacters, got %v", err)
+		}
+	})
+	if strings.Contains(stderr, "metacharacters") {
+		t.Errorf("trusted environment should not warn, got %q", stderr)
+	}
+
+	stderr = captureStderr(t, func() {
+		if err := validateArgsForEnvironment(metacharacterArgs, Environment{Name: "prod"}, false); err != nil {
+			t.Errorf("default mode should only warn, got %v", err)
+		}
+	})
+	if !strings.Contains(stderr, "metacharacters") {
+		t.Errorf("untrusted environment should warn, got %q", stderr)
+	}
+
+	if err := validateArgsForEnvironment([]string{"a|b"}, trusted, true); err == nil {
+		t.Error("strict mode should override trust_args")
+	}
+	if err := validateArgsForEnvironment([]string{"sudo"}, trusted, false); err == nil {
+		t.Error("dangerous arguments should be rejected for trusted environments")
+	}
+}
+
+func TestTrustArgsLaunchAndSet(t *testing.T) {
+	args, _ := stubSelection(t, "dev")
+	os.Unsetenv("CCE_STRICT_ARGS")
+
+	config, _ := loadConfig()
+	updated, err := applyFieldUpdates(config.Environments[0], map[string]string{"trust_args": "true"}, []string{"trust_args"})
+	if err != nil || !updated.TrustArgs {
+		t.Fatalf("set trust_args=true: %+v, %v", updated, err)
+	}
+	config.Environments[0] = updated
+	if err := saveConfig(config); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	var stderr string
+	captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			if err := handleCommand([]string{"--env", "dev", "--", "-p", "cost is $(price) | total"}); err != nil {
+				t.Fatalf("handleCommand failed: %v", err)
+			}
+		})
+	})
+	if strings.Contains(stderr, "metacharacters") {
+		t.Errorf("trusted launch should not warn, got %q", stderr)
+	}
+	if len(*args) != 2 {
+		t.Errorf("expected claude to receive the prompt, got %v", *args)
+	}
+
+	err = handleCommand([]string{"--strict-args", "--env", "dev", "--", "a;b"})
+	if err == nil || !strings.Contains(err.Error(), "argument validation failed") {
+		t.Errorf("--strict-args should still reject for a trusted environment, got %v", err)
+	}
+
+	if _, err := applyFieldUpdates(updated, map[string]string{"trust_args": "maybe"}, []string{"trust_args"}); err == nil {
+		t.Error("expected an error for a non-boolean trust_args")
+	}
+}
@@ -2,11 +2,16 @@ package main
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 
 	"golang.org/x/term"
@@ -83,8 +88,19 @@ type terminalState struct {
 	fd       int
 	oldState *term.State
 	restored bool
+
+	mu      sync.Mutex
+	signals chan os.Signal // Delivered while raw mode is active; nil when not watching
+	stop    chan struct{}  // Closed on restore to end the signal watcher
 }
 
+// Raw-mode hooks, replaced in tests to simulate a terminal and process exit
+var (
+	rawModeEnter   = term.MakeRaw
+	rawModeRestore = term.Restore
+	signalExit     = os.Exit
+)
+
 // initializeDisplayState creates a new DisplayState with terminal dimensions
 func initializeDisplayState() *DisplayState {
 	caps := detectTerminalCapabilities()
@@ -188,12 +204,10 @@ func (tp *TextPositioner) ClearLine() string {
 // OverwriteLine creates a string that overwrites a line with new content
 func (tp *TextPositioner) OverwriteLine(content string) string {
 	// Ensure content doesn't exceed terminal width
-	if len(content) > tp.width {
-		content = content[:tp.width-3] + "..."
-	}
+	content, _ = fitWidth(content, tp.width)
 
 	// Pad content to full width to clear any remaining characters
-	paddedContent := content + strings.Repeat(" ", tp.width-len(content))
+	paddedContent := padToWidth(content, tp.width)
 
 	return tp.MoveToStartOfLine() + paddedContent + tp.MoveToStartOfLine()
 }
@@ -214,6 +228,61 @@ func newLineRenderer(state *DisplayState, useANSI bool) *LineRenderer {
 	}
 }
 
+// defaultMenuPageSize is used when the terminal height is unknown
+const defaultMenuPageSize = 10
+
+// menuReservedLines covers the header, note detail, scroll indicator and prompt line
+const menuReservedLines = 4
+
+// menuPageSize returns how many environments fit on screen for the given terminal height
+func menuPageSize(height int) int {
+	if height <= 0 {
+		return defaultMenuPageSize
+	}
+	if size := height - menuReservedLines; size > 3 {
+		return size
+	}
+	return 3
+}
+
+// menuViewport returns the [start, end) window of a page-aligned viewport containing selected
+func menuViewport(total, selected, pageSize int) (int, int) {
+	if pageSize <= 0 || total <= pageSize {
+		return 0, total
+	}
+	if selected < 0 {
+		selected = 0
+	} else if selected >= total {
+		selected = total - 1
+	}
+	start := (selected / pageSize) * pageSize
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// pageStep moves an index by delta, clamping to the list instead of wrapping
+func pageStep(index, delta, total int) int {
+	if total == 0 {
+		return 0
+	}
+	index += delta
+	if index < 0 {
+		return 0
+	}
+	if index >= total {
+		return total - 1
+	}
+	return index
+}
+
+// scrollIndicator describes the visible window, e.g. "  [11-20 of 57] PgUp/PgDn for more"
+func scrollIndicator(start, end, total int) string {
+	return fmt.Sprintf("  [%d-%d of %d] PgUp/PgDn for more", start+1, end, total)
+}
+
 // RenderMenu renders the complete environment menu using stateful display
 func (lr *LineRenderer) RenderMenu(environments []Environment, selectedIndex int, header string) {
 	if !lr.state.initialized {
@@ -230,7 +299,10 @@ func (lr *LineRenderer) RenderMenu(environments []Environment, selectedIndex int
 		newLines = append(newLines, header)
 	}
 
-	for i, env := range environments {
+	// Only the page containing the selection is drawn so long lists never scroll off-screen
+	start, end := menuViewport(len(environments), selectedIndex, menuPageSize(layout.Height))
+	for i := start; i < end; i++ {
+		env := environments[i]
 		prefix := "  "
 		if i == selectedIndex {
 			if lr.useANSI {
@@ -242,8 +314,21 @@ func (lr *LineRenderer) RenderMenu(environments []Environment, selectedIndex int
 
 		// Format complete line to fit within terminal width
 		line := formatter.formatSingleLine(prefix, env)
+		line = appendLatency(line, selectorLatency.label(env), layout.Width)
 		newLines = append(newLines, line)
 	}
+	if end-start < len(environments) {
+		newLines = append(newLines, scrollIndicator(start, end, len(environments)))
+	}
+
+	// Detail line for the highlighted environment's notes
+	if selectedIndex >= 0 && selectedIndex < len(environments) && environments[selectedIndex].Notes != "" {
+		note := "  Note: " + environments[selectedIndex].Notes
+		if layout.Width > 3 {
+			note, _ = fitWidth(note, layout.Width)
+		}
+		newLines = append(newLines, note)
+	}
 
 	// Update display state
 	lr.state.UpdateContent(newLines, selectedIndex)
@@ -298,13 +383,54 @@ func (lr *LineRenderer) OverwriteLine(lineNum int, content string) {
 	}
 }
 
-// restore terminal state safely
+// enterRawMode is the single entry point into raw mode. It records the previous state and
+// restores it if SIGINT, SIGTERM or SIGHUP arrives before the caller's deferred ensureRestore
+// runs; deferred restores also run while a panic unwinds.
+func enterRawMode(fd int) (*terminalState, error) {
+	oldState, err := rawModeEnter(fd)
+	if err != nil {
+		return nil, err
+	}
+	ts := &terminalState{fd: fd, oldState: oldState}
+	ts.watchSignals()
+	return ts, nil
+}
+
+// watchSignals restores the terminal and exits with 128+signal if the process is signalled
+func (ts *terminalState) watchSignals() {
+	ts.signals = make(chan os.Signal, 1)
+	ts.stop = make(chan struct{})
+	signal.Notify(ts.signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	go func(signals <-chan os.Signal, stop <-chan struct{}) {
+		select {
+		case sig := <-signals:
+			ts.ensureRestore()
+			code := 1
+			if s, ok := sig.(syscall.Signal); ok {
+				code = 128 + int(s)
+			}
+			signalExit(code)
+		case <-stop:
+		}
+	}(ts.signals, ts.stop)
+}
+
+// restore terminal state safely; safe to call more than once and from the signal watcher
 func (ts *terminalState) restore() error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.signals != nil {
+		signal.Stop(ts.signals)
+		close(ts.stop)
+		ts.signals = nil
+	}
 	if ts.restored || ts.oldState == nil {
 		return nil
 	}
 	ts.restored = true
-	return term.Restore(ts.fd, ts.oldState)
+	return rawModeRestore(ts.fd, ts.oldState)
 }
 
 // ensureRestore guarantees terminal restoration via defer
@@ -407,24 +533,24 @@ func newDisplayFormatter(layout TerminalLayout) *DisplayFormatter {
 
 // smartTruncateName implements intelligent name truncation
 func (df *DisplayFormatter) smartTruncateName(name string) (string, bool) {
-	if len(name) <= df.nameWidth {
+	if displayWidth(name) <= df.nameWidth {
 		return name, false
 	}
 
 	// Keep beginning and end, ellipsis in middle
 	if df.nameWidth < 8 {
-		return name[:df.nameWidth-3] + "...", true
+		return fitWidth(name, df.nameWidth)
 	}
 
-	prefixLen := (df.nameWidth - 3) / 2
-	suffixLen := df.nameWidth - 3 - prefixLen
+	prefixWidth := (df.nameWidth - 3) / 2
+	suffixWidth := df.nameWidth - 3 - prefixWidth
 
-	return name[:prefixLen] + "..." + name[len(name)-suffixLen:], true
+	return truncateToWidth(name, prefixWidth) + "..." + tailToWidth(name, suffixWidth), true
 }
 
 // smartTruncateURL implements intelligent URL truncation
 func (df *DisplayFormatter) smartTruncateURL(url string) (string, bool) {
-	if len(url) <= df.urlWidth {
+	if displayWidth(url) <= df.urlWidth {
 		return url, false
 	}
 
@@ -442,16 +568,16 @@ func (df *DisplayFormatter) smartTruncateURL(url string) (string, bool) {
 			}
 
 			domain := remaining[:domainEndIdx]
-			protocolDomainLen := len(protocol) + len(domain)
+			protocolDomainWidth := displayWidth(protocol) + displayWidth(domain)
 
-			if protocolDomainLen <= df.urlWidth-3 {
+			if protocolDomainWidth <= df.urlWidth-3 {
 				return protocol + domain + "...", true
 			}
 		}
 	}
 
 	// Fallback: simple truncation
-	return url[:df.urlWidth-3] + "...", true
+	return fitWidth(url, df.urlWidth)
 }
 
 // smartTruncateModel implements intelligent model truncation
@@ -460,20 +586,22 @@ func (df *DisplayFormatter) smartTruncateModel(model string) (string, bool) {
 		return "default", false
 	}
 
-	if len(model) <= df.modelWidth {
+	return shortModelName(model, df.modelWidth)
+}
+
+// shortModelName fits model into width columns. The release date and then the "claude-"
+// prefix are dropped first, so "sonnet-4-5" and "opus-4-1" stay distinguishable in narrow
+// menus; only what still does not fit is cut with "...".
+func shortModelName(model string, width int) (string, bool) {
+	if displayWidth(model) <= width {
 		return model, false
 	}
-
-	// Preserve model family identifier if possible
-	if strings.HasPrefix(model, "claude-") {
-		if df.modelWidth >= 10 {
-			// Try to keep "claude-" prefix and truncate end
-			return model[:df.modelWidth-3] + "...", true
-		}
+	short := modelDateSuffix.ReplaceAllString(model, "")
+	if displayWidth(short) > width {
+		short = strings.TrimPrefix(short, "claude-")
 	}
-
-	// Simple truncation
-	return model[:df.modelWidth-3] + "...", true
+	fitted, _ := fitWidth(short, width)
+	return fitted, true
 }
 
 // formatEnvironmentForDisplay creates responsive display formatting for an environment
@@ -511,25 +639,38 @@ func (df *DisplayFormatter) formatEnvironmentForDisplay(env Environment) Environ
 func (df *DisplayFormatter) formatSingleLine(prefix string, env Environment) string {
 	// Calculate available space for content
 	// Format will be: "prefix name (url) [model]"
-	prefixLen := len(prefix)
+	prefixWidth := displayWidth(prefix)
 
 	// Static characters: " (" + ") [" + "]" = 6 characters
 	staticOverhead := 6
-	maxContentLen := df.layout.Width - prefixLen - staticOverhead
+	maxContentLen := df.layout.Width - prefixWidth - staticOverhead
+
+	model := env.Model
+	if model == "" {
+		model = "default"
+	}
 
-	// If we don't have enough space, use minimal format
+	// If we don't have enough space, use minimal format, keeping the model when it fits
 	if maxContentLen < 20 {
 		name := env.Name
-		if len(name) > 10 {
-			name = name[:7] + "..."
+		if displayWidth(name) > 10 {
+			name = truncateToWidth(name, 7) + "..."
+		}
+		line := prefix + name
+		if room := df.layout.Width - displayWidth(line) - 3; room >= 6 {
+			short, _ := shortModelName(model, room)
+			line += " [" + short + "]"
 		}
-		return fmt.Sprintf("%s%s", prefix, name)
+		return line
 	}
 
-	// Distribute space: name (40%), url (45%), model (15%)
-	nameSpace := int(float64(maxContentLen) * 0.40)
-	urlSpace := int(float64(maxContentLen) * 0.45)
-	modelSpace := maxContentLen - nameSpace - urlSpace
+	// The model gets the columns it needs, up to a third; name and URL share the rest 40:45
+	modelSpace := displayWidth(model)
+	if limit := maxContentLen / 3; modelSpace > limit {
+		modelSpace = limit
+	}
+	nameSpace := (maxContentLen - modelSpace) * 40 / 85
+	urlSpace := maxContentLen - modelSpace - nameSpace
 
 	// Ensure minimum sizes
 	if nameSpace < 8 {
@@ -542,49 +683,16 @@ func (df *DisplayFormatter) formatSingleLine(prefix string, env Environment) str
 		modelSpace = 6
 	}
 
-	// Truncate fields to fit allocated space
-	name := env.Name
-	if len(name) > nameSpace {
-		if nameSpace > 3 {
-			name = name[:nameSpace-3] + "..."
-		} else {
-			name = name[:nameSpace]
-		}
-	}
-
-	url := env.URL
-	if len(url) > urlSpace {
-		if urlSpace > 3 {
-			url = url[:urlSpace-3] + "..."
-		} else {
-			url = url[:urlSpace]
-		}
-	}
-
-	model := env.Model
-	if model == "" {
-		model = "default"
-	}
-	if len(model) > modelSpace {
-		if modelSpace > 3 {
-			model = model[:modelSpace-3] + "..."
-		} else {
-			model = model[:modelSpace]
-		}
-	}
+	// Truncate fields to fit allocated space, measured in terminal columns
+	name, _ := fitWidth(env.Name, nameSpace)
+	url, _ := fitWidth(env.URL, urlSpace)
+	model, _ = shortModelName(model, modelSpace)
 
 	// Create the formatted line
 	line := fmt.Sprintf("%s%s (%s) [%s]", prefix, name, url, model)
 
 	// Final safety check - truncate if still too long
-	if len(line) > df.layout.Width {
-		maxLen := df.layout.Width - 3
-		if maxLen > 0 {
-			line = line[:maxLen] + "..."
-		} else {
-			line = line[:df.layout.Width]
-		}
-	}
+	line, _ = fitWidth(line, df.layout.Width)
 
 	return line
 }
@@ -742,91 +850,68 @@ func selectEnvironmentWithArrows(config Config) (Environment, error) {
 
 // fullInteractiveSelection implements Tier 1: full featured arrow navigation with ANSI
 func fullInteractiveSelection(config Config, caps terminalCapabilities) (Environment, error) {
-	fd := int(syscall.Stdin)
-	termState := &terminalState{fd: fd}
-
 	// Set up raw mode with guaranteed cleanup
-	var err error
-	termState.oldState, err = term.MakeRaw(fd)
+	termState, err := enterRawMode(int(syscall.Stdin))
 	if err != nil {
 		return basicInteractiveSelection(config, caps)
 	}
 	defer termState.ensureRestore()
 	defer cleanupDisplayState() // Clean up display state on exit
 
-	selectedIndex := 0
+	bindings := selectorKeyBindings(config)
+	state := newSelectionState(config.Environments)
+	state.pageSize = menuPageSize(caps.Height)
 	buffer := make([]byte, 10)
+	inputs, stopLatency := startSelectorLatency(config.Environments)
+	defer stopLatency()
 
 	for {
-		displayEnvironmentMenu(config.Environments, selectedIndex)
+		renderMenuStatefully(state.visible, state.index, state.header("Select environment (use ↑↓ arrows, Enter to confirm, Esc to cancel):"), true)
 
-		n, err := os.Stdin.Read(buffer)
+		key, redraw, err := nextSelectorKey(buffer, inputs)
 		if err != nil {
 			return fallbackToNumberedSelection(config)
 		}
-
-		arrow, char, err := parseKeyInput(buffer[:n])
-		if err != nil {
+		if redraw {
 			continue
 		}
 
-		switch arrow {
-		case ArrowUp:
-			selectedIndex = (selectedIndex - 1 + len(config.Environments)) % len(config.Environments)
-		case ArrowDown:
-			selectedIndex = (selectedIndex + 1) % len(config.Environments)
-		case ArrowNone:
-			switch char {
-			case '\n', '\r':
-				return config.Environments[selectedIndex], nil
-			case '\x1b', '\x03':
-				return Environment{}, fmt.Errorf("selection cancelled")
-			}
+		if env, done, err := state.handleKey(bindings, key); done {
+			return env, err
 		}
 	}
 }
 
 // basicInteractiveSelection implements Tier 2: arrow navigation without ANSI styling
 func basicInteractiveSelection(config Config, caps terminalCapabilities) (Environment, error) {
-	fd := int(syscall.Stdin)
-	termState := &terminalState{fd: fd}
-
-	var err error
-	termState.oldState, err = term.MakeRaw(fd)
+	// Set up raw mode with guaranteed cleanup
+	termState, err := enterRawMode(int(syscall.Stdin))
 	if err != nil {
 		return fallbackToNumberedSelection(config)
 	}
 	defer termState.ensureRestore()
 	defer cleanupDisplayState() // Clean up display state on exit
 
-	selectedIndex := 0
+	bindings := selectorKeyBindings(config)
+	state := newSelectionState(config.Environments)
+	state.pageSize = menuPageSize(caps.Height)
 	buffer := make([]byte, 10)
+	inputs, stopLatency := startSelectorLatency(config.Environments)
+	defer stopLatency()
 
 	for {
-		displayBasicEnvironmentMenu(config.Environments, selectedIndex)
+		renderMenuStatefully(state.visible, state.index, state.header("Select environment (use arrows, Enter to confirm, Esc to cancel):"), false)
 
-		n, err := os.Stdin.Read(buffer)
+		key, redraw, err := nextSelectorKey(buffer, inputs)
 		if err != nil {
 			return fallbackToNumberedSelection(config)
 		}
-
-		arrow, char, err := parseKeyInput(buffer[:n])
-		if err != nil {
+		if redraw {
 			continue
 		}
 
-		switch arrow {
-		case ArrowUp:
-			selectedIndex = (selectedIndex - 1 + len(config.Environments)) % len(config.Environments)
-		case ArrowDown:
-			selectedIndex = (selectedIndex + 1) % len(config.Environments)
-		case ArrowNone:
-			switch char {
-			case '\n', '\r':
-				return config.Environments[selectedIndex], nil
-			case '\x1b', '\x03':
-				return Environment{}, fmt.Errorf("selection cancelled")
-			}
+		if env, done, err := state.handleKey(bindings, key); done {
+			return env, err
 		}
 	}
 }
@@ -878,6 +963,11 @@ func formatWarningMessage(message string, enableANSI bool) string {
 
 // renderWorktreeSummary emits concise, ANSI-safe worktree details to the provided writers.
 func renderWorktreeSummary(out io.Writer, errOut io.Writer, worktreePath string, dirtyWarning string, caps terminalCapabilities, headless bool) error {
+	return renderWorktreeSummaryWithStatus(out, errOut, worktreePath, dirtyWarning, caps, headless, false)
+}
+
+// renderWorktreeSummaryWithStatus emits worktree details, labelling reused worktrees distinctly.
+func renderWorktreeSummaryWithStatus(out io.Writer, errOut io.Writer, worktreePath string, dirtyWarning string, caps terminalCapabilities, headless bool, reused bool) error {
 	path := strings.TrimSpace(worktreePath)
 	if path == "" {
 		return fmt.Errorf("worktree path cannot be empty")
@@ -888,7 +978,11 @@ func renderWorktreeSummary(out io.Writer, errOut io.Writer, worktreePath string,
 	useANSI := caps.SupportsANSI && caps.IsTerminal && !headless
 
 	if headless {
-		if _, err := fmt.Fprintf(out, "Worktree: %s\n", path); err != nil {
+		label := "Worktree"
+		if reused {
+			label = "Worktree (reused)"
+		}
+		if _, err := fmt.Fprintf(out, "%s: %s\n", label, path); err != nil {
 			return fmt.Errorf("failed to display worktree path: %w", err)
 		}
 		if dirtyWarning != "" {
@@ -907,7 +1001,11 @@ func renderWorktreeSummary(out io.Writer, errOut io.Writer, worktreePath string,
 		return nil
 	}
 
-	if _, err := fmt.Fprintf(out, "Worktree created at: %s\n", path); err != nil {
+	headline := "Worktree created at: %s\n"
+	if reused {
+		headline = "Reusing worktree: %s\n"
+	}
+	if _, err := fmt.Fprintf(out, headline, path); err != nil {
 		return fmt.Errorf("failed to display worktree path: %w", err)
 	}
 
@@ -951,17 +1049,13 @@ func secureInput(prompt string) (string, error) {
 	}
 
 	// Save original terminal state
-	oldState, err := term.MakeRaw(fd)
+	termState, err := enterRawMode(fd)
 	if err != nil {
 		return "", fmt.Errorf("failed to set terminal raw mode: %w", err)
 	}
 
 	// Ensure terminal state is restored on exit
-	defer func() {
-		if err := term.Restore(fd, oldState); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to restore terminal state: %v\n", err)
-		}
-	}()
+	defer termState.ensureRestore()
 
 	var input []byte
 	buffer := make([]byte, 1)
@@ -1052,21 +1146,47 @@ func selectEnvironmentOriginal(config Config) (Environment, error) {
 	// Detect terminal layout and create formatter
 	layout := detectTerminalLayout()
 	formatter := newDisplayFormatter(layout)
+	total := len(config.Environments)
+	pageSize := menuPageSize(layout.Height)
+
+	// Long lists are shown a page at a time; numbers stay absolute across pages
+	var input string
+	for page := 0; ; {
+		start, end := menuViewport(total, page*pageSize, pageSize)
+		for i := start; i < end; i++ {
+			// Format complete line to fit within terminal width
+			prefix := fmt.Sprintf("%d. ", i+1)
+			line := formatter.formatSingleLine(prefix, config.Environments[i])
+
+			if _, err := fmt.Println(line); err != nil {
+				return Environment{}, fmt.Errorf("failed to display environment option: %w", err)
+			}
+		}
 
-	for i, env := range config.Environments {
-		// Format complete line to fit within terminal width
-		prefix := fmt.Sprintf("%d. ", i+1)
-		line := formatter.formatSingleLine(prefix, env)
-
-		if _, err := fmt.Println(line); err != nil {
-			return Environment{}, fmt.Errorf("failed to display environment option: %w", err)
+		prompt := fmt.Sprintf("Enter number (1-%d): ", total)
+		if end-start < total {
+			prompt = fmt.Sprintf("Showing %d-%d of %d. Enter number (1-%d), n for next page, p for previous: ", start+1, end, total, total)
 		}
-	}
 
-	// Get user selection
-	input, err := regularInput(fmt.Sprintf("Enter number (1-%d): ", len(config.Environments)))
-	if err != nil {
-		return Environment{}, fmt.Errorf("environment selection failed: %w", err)
+		// Get user selection
+		var err error
+		input, err = regularInput(prompt)
+		if err != nil {
+			return Environment{}, fmt.Errorf("environment selection failed: %w", err)
+		}
+
+		if end-start < total {
+			pages := (total + pageSize - 1) / pageSize
+			switch strings.ToLower(input) {
+			case "n":
+				page = (page + 1) % pages
+				continue
+			case "p":
+				page = (page - 1 + pages) % pages
+				continue
+			}
+		}
+		break
 	}
 
 	// Validate selection
@@ -1084,28 +1204,221 @@ func selectEnvironmentOriginal(config Config) (Environment, error) {
 
 // promptForEnvironment collects new environment details with validation
 func promptForEnvironment(config Config) (Environment, error) {
+	return promptForEnvironmentWithDefaults(config, promptDefaults{})
+}
+
+// promptDefaults pre-fills the add prompts from an existing environment (cce add --copy-env)
+type promptDefaults struct {
+	Source   string      // Name of the environment the defaults came from; empty for none
+	Env      Environment // Values offered as defaults; the name is never copied
+	ReuseKey bool        // Offer the source API key as the default (off unless --copy-key)
+	// SkipValidation accepts URL, key and model values that fail strict checks (add --no-validate)
+	SkipValidation bool
+}
+
+// reportSkippedValidation warns that a field failed strict checks but was accepted anyway
+func reportSkippedValidation(env *Environment, field string, skipped error) error {
+	markUnvalidated(env, field)
+	if _, err := fmt.Printf("Warning: %s accepted without validation (--no-validate): %v\n", field, skipped); err != nil {
+		return fmt.Errorf("failed to display warning: %w", err)
+	}
+	return nil
+}
+
+// withDefault renders a prompt label, showing the default value in brackets when present
+func withDefault(label, value string) string {
+	if value == "" {
+		return label + ": "
+	}
+	return fmt.Sprintf("%s [%s]: ", label, value)
+}
+
+// environmentField is one step of the add prompts; the review screen can re-run it
+type environmentField struct {
+	Key    string // What the user types on the review screen to edit this field
+	prompt func(config Config, defaults promptDefaults, env *Environment) error
+}
+
+// environmentFields are the add prompts in order
+var environmentFields = []environmentField{
+	{"name", promptEnvironmentName},
+	{"url", promptEnvironmentURL},
+	{"key", promptEnvironmentKey},
+	{"key_var", promptEnvironmentKeyVar},
+	{"model", promptEnvironmentModel},
+	{"env", promptEnvironmentEnvVars},
+	{"headers", promptEnvironmentHeaders},
+	{"notes", promptEnvironmentNotes},
+	{"tags", promptEnvironmentTags},
+}
+
+// reviewInput reads answers on the review screen; tests replace it
+var reviewInput = regularInput
+
+// renderEnvironmentSummary prints env as it would be saved, with the key and secret-looking variables masked
+func renderEnvironmentSummary(w io.Writer, env Environment) error {
+	keyVar := resolveAPIKeyVar(env)
+	model := env.Model
+	if model == "" {
+		model = "default"
+	}
+
+	lines := []string{
+		fmt.Sprintf("  Name:    %s", env.Name),
+		fmt.Sprintf("  URL:     %s", env.URL),
+		fmt.Sprintf("  Model:   %s", model),
+		fmt.Sprintf("  Key Var: %s (%s)", keyVar, authSchemeForKeyVar(keyVar)),
+		fmt.Sprintf("  Key:     %s", maskAPIKey(env.APIKey)),
+	}
+	if len(env.EnvVars) > 0 {
+		lines = append(lines, "  Env Variables:")
+		lines = append(lines, envVarLines(env, "    ")...)
+	}
+	if len(env.Headers) > 0 {
+		lines = append(lines, "  Headers:")
+		lines = append(lines, headerLines(env, "    ")...)
+	}
+	if env.Notes != "" {
+		lines = append(lines, fmt.Sprintf("  Notes:   %s", env.Notes))
+	}
+	if len(env.Tags) > 0 {
+		lines = append(lines, fmt.Sprintf("  Tags:    %s", strings.Join(env.Tags, ", ")))
+	}
+
+	if _, err := fmt.Fprintf(w, "\nReview:\n%s\n", strings.Join(lines, "\n")); err != nil {
+		return fmt.Errorf("failed to display summary: %w", err)
+	}
+	return nil
+}
+
+// reviewEnvironment shows env and asks to save it, edit one of fields, or cancel. edit
+// re-prompts the chosen field in place. Without a terminal the review is skipped.
+func reviewEnvironment(env Environment, fields []environmentField, edit func(environmentField, *Environment) error) (Environment, error) {
+	if !stdinIsTerminal() {
+		return env, nil
+	}
+	if assumeYes {
+		noteAutoConfirmed(fmt.Sprintf("saving environment '%s'", env.Name))
+		return env, nil
+	}
+
+	keys := make([]string, len(fields))
+	for i, field := range fields {
+		keys[i] = field.Key
+	}
+
+	for {
+		if err := renderEnvironmentSummary(os.Stdout, env); err != nil {
+			return Environment{}, err
+		}
+		answer, err := reviewInput("Save? [Y]es, [e]dit a field, [c]ancel: ")
+		if err != nil {
+			return Environment{}, fmt.Errorf("failed to read confirmation: %w", err)
+		}
+
+		// "e url" picks the field directly
+		choice, target := strings.ToLower(answer), ""
+		if parts := strings.Fields(choice); len(parts) == 2 {
+			choice, target = parts[0], parts[1]
+		}
+
+		switch choice {
+		case "", "y", "yes":
+			return env, nil
+		case "c", "cancel", "n", "no":
+			return Environment{}, fmt.Errorf("cancelled; nothing was saved")
+		case "e", "edit":
+			if target == "" {
+				if target, err = reviewInput(fmt.Sprintf("Field to edit (%s): ", strings.Join(keys, ", "))); err != nil {
+					return Environment{}, fmt.Errorf("failed to read field: %w", err)
+				}
+			}
+			field, ok := findEnvironmentField(fields, strings.ToLower(target))
+			if !ok {
+				if _, printErr := fmt.Printf("Unknown field '%s' (expected %s)\n", target, strings.Join(keys, ", ")); printErr != nil {
+					return Environment{}, fmt.Errorf("failed to display error: %w", printErr)
+				}
+				continue
+			}
+			if err := edit(field, &env); err != nil {
+				return Environment{}, err
+			}
+		default:
+			if _, printErr := fmt.Println("Please answer y, e or c."); printErr != nil {
+				return Environment{}, fmt.Errorf("failed to display error: %w", printErr)
+			}
+		}
+	}
+}
+
+// findEnvironmentField looks up a review-screen field by key
+func findEnvironmentField(fields []environmentField, key string) (environmentField, bool) {
+	for _, field := range fields {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return environmentField{}, false
+}
+
+// editEnvironmentField re-runs one add prompt with the current values as defaults
+func editEnvironmentField(config Config, skipValidation bool, field environmentField, env *Environment) error {
+	// A re-entered value is validated afresh
+	switch field.Key {
+	case "url":
+		clearUnvalidated(env, fieldURL)
+	case "key":
+		clearUnvalidated(env, fieldAPIKey)
+	case "model":
+		clearUnvalidated(env, fieldModel)
+	}
+	defaults := promptDefaults{Source: env.Name, Env: *env, ReuseKey: true, SkipValidation: skipValidation}
+	return field.prompt(config, defaults, env)
+}
+
+// promptForEnvironmentWithDefaults collects a new environment, offering defaults for every field but the name
+func promptForEnvironmentWithDefaults(config Config, defaults promptDefaults) (Environment, error) {
 	var env Environment
-	var err error
 
-	// Get environment name
+	if defaults.Source != "" {
+		if _, printErr := fmt.Printf("Copying settings from '%s'; press Enter to keep a value in brackets.\n", defaults.Source); printErr != nil {
+			return Environment{}, fmt.Errorf("failed to display prompt: %w", printErr)
+		}
+	}
+
+	for _, field := range environmentFields {
+		if err := field.prompt(config, defaults, &env); err != nil {
+			return Environment{}, err
+		}
+	}
+
+	// Show what will be saved and allow fixing a field before anything is written
+	return reviewEnvironment(env, environmentFields, func(field environmentField, env *Environment) error {
+		return editEnvironmentField(config, defaults.SkipValidation, field, env)
+	})
+}
+
+// promptEnvironmentName asks for a valid name not already taken
+func promptEnvironmentName(config Config, defaults promptDefaults, env *Environment) error {
+	var err error
 	for {
 		env.Name, err = regularInput("Environment name: ")
 		if err != nil {
-			return Environment{}, fmt.Errorf("failed to get environment name: %w", err)
+			return fmt.Errorf("failed to get environment name: %w", err)
 		}
 
 		// Validate name
 		if err := validateName(env.Name); err != nil {
 			if _, printErr := fmt.Printf("Invalid name: %v\n", err); printErr != nil {
-				return Environment{}, fmt.Errorf("failed to display error: %w", printErr)
+				return fmt.Errorf("failed to display error: %w", printErr)
 			}
 			continue
 		}
 
 		// Check for duplicate
-		if _, exists := findEnvironmentByName(config, env.Name); exists {
-			if _, printErr := fmt.Printf("Environment '%s' already exists\n", env.Name); printErr != nil {
-				return Environment{}, fmt.Errorf("failed to display error: %w", printErr)
+		if existing, taken := environmentNameTaken(config, env.Name); taken {
+			if _, printErr := fmt.Printf("Environment '%s' already exists\n", existing); printErr != nil {
+				return fmt.Errorf("failed to display error: %w", printErr)
 			}
 			continue
 		}
@@ -1113,147 +1426,343 @@ func promptForEnvironment(config Config) (Environment, error) {
 		break
 	}
 
-	// Get base URL
+	return nil
+}
+
+// promptEnvironmentURL asks for the base URL, defaulting to the copied one
+func promptEnvironmentURL(config Config, defaults promptDefaults, env *Environment) error {
+	var err error
 	for {
-		env.URL, err = regularInput("Base URL: ")
+		env.URL, err = regularInput(withDefault("Base URL", defaults.Env.URL))
 		if err != nil {
-			return Environment{}, fmt.Errorf("failed to get base URL: %w", err)
+			return fmt.Errorf("failed to get base URL: %w", err)
+		}
+		if env.URL == "" {
+			env.URL = defaults.Env.URL
 		}
 
 		// Validate URL
-		if err := validateURL(env.URL); err != nil {
+		skipped, err := checkFieldEscapable(fieldURL, env.URL, validateURL, defaults.SkipValidation)
+		if err != nil {
 			if _, printErr := fmt.Printf("Invalid URL: %v\n", err); printErr != nil {
-				return Environment{}, fmt.Errorf("failed to display error: %w", printErr)
+				return fmt.Errorf("failed to display error: %w", printErr)
 			}
 			continue
 		}
+		if skipped != nil {
+			if err := reportSkippedValidation(env, fieldURL, skipped); err != nil {
+				return err
+			}
+		}
 
 		break
 	}
 
-	// Get API key (secure input)
+	return nil
+}
+
+// promptEnvironmentKey asks for the API key with hidden input
+func promptEnvironmentKey(config Config, defaults promptDefaults, env *Environment) error {
+	var err error
 	for {
-		env.APIKey, err = secureInput("API Key (hidden): ")
+		keyPrompt := "API Key (hidden): "
+		if defaults.ReuseKey && defaults.Env.APIKey != "" {
+			keyPrompt = fmt.Sprintf("API Key (hidden, Enter to reuse '%s' key): ", defaults.Source)
+		}
+		env.APIKey, err = secureInput(keyPrompt)
 		if err != nil {
-			return Environment{}, fmt.Errorf("failed to get API key: %w", err)
+			return fmt.Errorf("failed to get API key: %w", err)
+		}
+		if env.APIKey == "" && defaults.ReuseKey {
+			env.APIKey = defaults.Env.APIKey
 		}
 
 		// Validate API key
-		if err := validateAPIKey(env.APIKey); err != nil {
+		skipped, err := checkFieldEscapable(fieldAPIKey, env.APIKey, validateAPIKey, defaults.SkipValidation)
+		if err != nil {
 			if _, printErr := fmt.Printf("Invalid API key: %v\n", err); printErr != nil {
-				return Environment{}, fmt.Errorf("failed to display error: %w", printErr)
+				return fmt.Errorf("failed to display error: %w", printErr)
 			}
 			continue
 		}
+		if skipped != nil {
+			if err := reportSkippedValidation(env, fieldAPIKey, skipped); err != nil {
+				return err
+			}
+		}
 
 		break
 	}
 
-	// Choose API key environment variable name
+	return nil
+}
+
+// promptEnvironmentKeyVar asks which auth scheme, and so which key variable, to use
+func promptEnvironmentKeyVar(config Config, defaults promptDefaults, env *Environment) error {
 	for {
-		if _, printErr := fmt.Println("Select API key environment variable:"); printErr != nil {
-			return Environment{}, fmt.Errorf("failed to display prompt: %w", printErr)
+		if _, printErr := fmt.Println("Select auth scheme / API key environment variable:"); printErr != nil {
+			return fmt.Errorf("failed to display prompt: %w", printErr)
+		}
+		if _, printErr := fmt.Println("  1) x-api-key header -> ANTHROPIC_API_KEY (default)"); printErr != nil {
+			return fmt.Errorf("failed to display option: %w", printErr)
 		}
-		if _, printErr := fmt.Println("  1) ANTHROPIC_API_KEY (default)"); printErr != nil {
-			return Environment{}, fmt.Errorf("failed to display option: %w", printErr)
+		if _, printErr := fmt.Println("  2) Authorization: Bearer -> ANTHROPIC_AUTH_TOKEN"); printErr != nil {
+			return fmt.Errorf("failed to display option: %w", printErr)
 		}
-		if _, printErr := fmt.Println("  2) ANTHROPIC_AUTH_TOKEN"); printErr != nil {
-			return Environment{}, fmt.Errorf("failed to display option: %w", printErr)
+		defaultChoice := "1"
+		if resolveAPIKeyVar(defaults.Env) == "ANTHROPIC_AUTH_TOKEN" {
+			defaultChoice = "2"
 		}
-		choice, err := regularInput("Enter choice [1/2] (default 1): ")
+		choice, err := regularInput(fmt.Sprintf("Enter choice [1/2] (default %s): ", defaultChoice))
 		if err != nil {
-			return Environment{}, fmt.Errorf("failed to get selection: %w", err)
+			return fmt.Errorf("failed to get selection: %w", err)
 		}
 		choice = strings.TrimSpace(choice)
-		if choice == "" || choice == "1" {
-			env.APIKeyEnv = "ANTHROPIC_API_KEY"
+		if choice == "" {
+			choice = defaultChoice
+		}
+		if choice == "1" {
+			env.AuthScheme = authSchemeAPIKey
 		} else if choice == "2" {
-			env.APIKeyEnv = "ANTHROPIC_AUTH_TOKEN"
+			env.AuthScheme = authSchemeBearer
 		} else {
 			if _, printErr := fmt.Println("Invalid choice. Please enter 1 or 2."); printErr != nil {
-				return Environment{}, fmt.Errorf("failed to display error: %w", printErr)
+				return fmt.Errorf("failed to display error: %w", printErr)
 			}
 			continue
 		}
+		env.APIKeyEnv = authSchemeKeyVars[env.AuthScheme]
 		break
 	}
 
-	// Get model (optional)
+	return nil
+}
+
+// promptEnvironmentModel asks for an optional model
+func promptEnvironmentModel(config Config, defaults promptDefaults, env *Environment) error {
+	var err error
 	for {
-		env.Model, err = regularInput("Model (optional, press Enter for default): ")
+		modelPrompt := "Model (optional, press Enter for default): "
+		if defaults.Env.Model != "" {
+			modelPrompt = fmt.Sprintf("Model [%s] (Enter to keep, '-' for claude default): ", defaults.Env.Model)
+		}
+		env.Model, err = regularInput(modelPrompt)
 		if err != nil {
-			return Environment{}, fmt.Errorf("failed to get model: %w", err)
+			return fmt.Errorf("failed to get model: %w", err)
+		}
+		switch env.Model {
+		case "":
+			env.Model = defaults.Env.Model
+		case "-":
+			env.Model = ""
 		}
 
 		// Validate model
-		if err := validateModel(env.Model); err != nil {
+		skipped, err := checkFieldEscapable(fieldModel, env.Model, validateModel, defaults.SkipValidation)
+		if err != nil {
 			if _, printErr := fmt.Printf("Invalid model: %v\n", err); printErr != nil {
-				return Environment{}, fmt.Errorf("failed to display error: %w", printErr)
+				return fmt.Errorf("failed to display error: %w", printErr)
 			}
 			continue
 		}
+		if skipped != nil {
+			if err := reportSkippedValidation(env, fieldModel, skipped); err != nil {
+				return err
+			}
+		}
 
 		break
 	}
 
-	// Get additional environment variables (optional)
-	env.EnvVars = make(map[string]string)
-	if _, printErr := fmt.Println("Additional environment variables (optional):"); printErr != nil {
-		return Environment{}, fmt.Errorf("failed to display prompt: %w", printErr)
+	return nil
+}
+
+// envVarInput reads commands in the environment variable editor; tests replace it
+var envVarInput = regularInput
+
+// envVarLines renders env's additional variables sorted by name, masking secret-looking values
+func envVarLines(env Environment, indent string) []string {
+	keyVar := resolveAPIKeyVar(env)
+	keys := make([]string, 0, len(env.EnvVars))
+	for key := range env.EnvVars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := env.EnvVars[key]
+		if isSecretVar(key, keyVar) {
+			value = maskAPIKey(value)
+		}
+		lines = append(lines, fmt.Sprintf("%s%s=%s", indent, key, value))
 	}
-	if _, printErr := fmt.Println("Examples: ANTHROPIC_SMALL_FAST_MODEL, ANTHROPIC_TIMEOUT, etc."); printErr != nil {
-		return Environment{}, fmt.Errorf("failed to display examples: %w", printErr)
+	return lines
+}
+
+// promptEnvironmentEnvVars edits additional environment variables in a loop: NAME=value
+// adds or replaces, a bare NAME asks for the value, -NAME removes, and Enter finishes.
+// The current variables are shown before every command.
+func promptEnvironmentEnvVars(config Config, defaults promptDefaults, env *Environment) error {
+	env.EnvVars = make(map[string]string, len(defaults.Env.EnvVars))
+	for key, value := range defaults.Env.EnvVars {
+		env.EnvVars[key] = value
 	}
-	if _, printErr := fmt.Println("Enter variable name (press Enter when done):"); printErr != nil {
-		return Environment{}, fmt.Errorf("failed to display prompt: %w", printErr)
+	if _, printErr := fmt.Println("Additional environment variables (optional), e.g. ANTHROPIC_SMALL_FAST_MODEL, ANTHROPIC_TIMEOUT:"); printErr != nil {
+		return fmt.Errorf("failed to display prompt: %w", printErr)
 	}
 
 	for {
-		var varName string
-		varName, err = regularInput("Variable name: ")
-		if err != nil {
-			return Environment{}, fmt.Errorf("failed to get variable name: %w", err)
+		lines := envVarLines(*env, "  ")
+		if len(lines) == 0 {
+			lines = []string{"  (none)"}
+		}
+		if _, printErr := fmt.Println(strings.Join(lines, "\n")); printErr != nil {
+			return fmt.Errorf("failed to display variables: %w", printErr)
 		}
 
-		// If empty, we're done
-		if varName == "" {
+		command, err := envVarInput("NAME=value to set, NAME to enter a value, -NAME to remove, Enter when done: ")
+		if err != nil {
+			return fmt.Errorf("failed to get variable: %w", err)
+		}
+		command = strings.TrimSpace(command)
+		if command == "" {
 			break
 		}
 
-		// Validate variable name using proper environment variable naming conventions
-		if !isValidEnvVarName(varName) {
-			if _, printErr := fmt.Printf("Invalid variable name '%s'. Must start with letter/underscore and contain only letters, numbers, and underscores.\n", varName); printErr != nil {
-				return Environment{}, fmt.Errorf("failed to display error: %w", printErr)
-			}
-			continue
+		message, err := applyEnvVarCommand(env, command)
+		if err != nil {
+			message = "Not applied: " + err.Error()
+		}
+		if _, printErr := fmt.Println(message); printErr != nil {
+			return fmt.Errorf("failed to display result: %w", printErr)
+		}
+	}
+
+	if len(env.EnvVars) == 0 {
+		env.EnvVars = nil
+	}
+	return nil
+}
+
+// applyEnvVarCommand applies one editor command to env and describes the change; an
+// error explains why the command was rejected and leaves env untouched
+func applyEnvVarCommand(env *Environment, command string) (string, error) {
+	if strings.HasPrefix(command, "-") {
+		name := command[1:]
+		if _, ok := env.EnvVars[name]; !ok {
+			return "", fmt.Errorf("no variable named '%s'", name)
 		}
+		delete(env.EnvVars, name)
+		return fmt.Sprintf("Removed %s", name), nil
+	}
 
-		// Warn about potential conflicts with common system variables
-		if isCommonSystemVar(varName) {
-			if _, printErr := fmt.Printf("Warning: '%s' is a common system variable. This may override existing system settings.\n", varName); printErr != nil {
-				return Environment{}, fmt.Errorf("failed to display warning: %w", printErr)
+	name, value, hasValue := strings.Cut(command, "=")
+	if !isValidEnvVarName(name) {
+		return "", fmt.Errorf("invalid variable name '%s' (must start with a letter or underscore and contain only letters, numbers, and underscores)", name)
+	}
+	current, exists := env.EnvVars[name]
+	if !hasValue {
+		var err error
+		if value, err = envVarInput(withDefault(fmt.Sprintf("Value for %s", name), current)); err != nil {
+			return "", fmt.Errorf("failed to read value: %w", err)
+		}
+		if value == "" {
+			value = current
+		}
+	}
+	if value == "" {
+		return "", fmt.Errorf("empty value for %s; use -%s to remove a variable", name, name)
+	}
+	if err := validateEnvVarValue(value); err != nil {
+		return "", fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+
+	env.EnvVars[name] = value
+	message := fmt.Sprintf("Set %s", name)
+	if exists {
+		message = fmt.Sprintf("Updated %s", name)
+	}
+	// Warn about potential conflicts with common system variables
+	if isCommonSystemVar(name) {
+		message += fmt.Sprintf(" (warning: '%s' is a common system variable and overrides the system setting)", name)
+	}
+	return message, nil
+}
+
+// promptEnvironmentNotes asks for optional notes
+func promptEnvironmentNotes(config Config, defaults promptDefaults, env *Environment) error {
+	var err error
+	for {
+		notesPrompt := "Notes (optional, e.g. 'uses Bedrock proxy'): "
+		if defaults.Env.Notes != "" {
+			notesPrompt = fmt.Sprintf("Notes [%s] (Enter to keep, '-' to clear): ", defaults.Env.Notes)
+		}
+		env.Notes, err = regularInput(notesPrompt)
+		if err != nil {
+			return fmt.Errorf("failed to get notes: %w", err)
+		}
+		switch env.Notes {
+		case "":
+			env.Notes = defaults.Env.Notes
+		case "-":
+			env.Notes = ""
+		}
+
+		if err := validateNotes(env.Notes); err != nil {
+			if _, printErr := fmt.Printf("Invalid notes: %v\n", err); printErr != nil {
+				return fmt.Errorf("failed to display error: %w", printErr)
 			}
+			continue
 		}
 
-		// Get variable value
-		var varValue string
-		varValue, err = regularInput(fmt.Sprintf("Value for %s: ", varName))
+		break
+	}
+
+	return nil
+}
+
+// promptEnvironmentTags asks for optional comma-separated tags that group environments
+// for list --tag, select --tag and --group
+func promptEnvironmentTags(config Config, defaults promptDefaults, env *Environment) error {
+	for {
+		tagsPrompt := "Tags (optional, comma-separated, e.g. 'work,experiments'): "
+		if len(defaults.Env.Tags) > 0 {
+			tagsPrompt = fmt.Sprintf("Tags [%s] (Enter to keep, '-' to clear): ", strings.Join(defaults.Env.Tags, ","))
+		}
+		input, err := regularInput(tagsPrompt)
 		if err != nil {
-			return Environment{}, fmt.Errorf("failed to get variable value: %w", err)
+			return fmt.Errorf("failed to get tags: %w", err)
+		}
+		switch input {
+		case "":
+			env.Tags = append([]string(nil), defaults.Env.Tags...)
+		case "-":
+			env.Tags = nil
+		default:
+			env.Tags = parseTags(input)
 		}
 
-		// Store the variable
-		env.EnvVars[varName] = varValue
-		if _, printErr := fmt.Printf("Added %s=%s\n", varName, varValue); printErr != nil {
-			return Environment{}, fmt.Errorf("failed to display confirmation: %w", printErr)
+		if err := validateTags(env.Tags); err != nil {
+			if _, printErr := fmt.Printf("Invalid tags: %v\n", err); printErr != nil {
+				return fmt.Errorf("failed to display error: %w", printErr)
+			}
+			continue
 		}
+
+		break
 	}
 
-	return env, nil
+	return nil
 }
 
 // displayEnvironments formats and shows the environment list with responsive layout and API key masking
 func displayEnvironments(config Config) error {
+	return displayEnvironmentsWithOptions(config, listOptions{})
+}
+
+// displayEnvironmentsWithOptions shows the environment list, adding notes in wide mode
+func displayEnvironmentsWithOptions(config Config, opts listOptions) error {
 	if len(config.Environments) == 0 {
 		if _, err := fmt.Println("No environments configured."); err != nil {
 			return fmt.Errorf("failed to display message: %w", err)
@@ -1271,6 +1780,7 @@ func displayEnvironments(config Config) error {
 	// Detect terminal layout for responsive formatting
 	layout := detectTerminalLayout()
 	formatter := newDisplayFormatter(layout)
+	now := usageNow()
 
 	for _, env := range config.Environments {
 		// Mask API key (show only first 4 and last 4 characters)
@@ -1279,40 +1789,103 @@ func displayEnvironments(config Config) error {
 		// Format environment with responsive layout
 		display := formatter.formatEnvironmentForDisplay(env)
 
-		if _, err := fmt.Printf("\n  Name:  %s\n", display.DisplayName); err != nil {
+		name := display.DisplayName
+		if config.DefaultEnv != "" && env.Name == config.DefaultEnv {
+			name += " *"
+		}
+		if _, err := fmt.Printf("\n  Name:  %s\n", name); err != nil {
 			return fmt.Errorf("failed to display environment name: %w", err)
 		}
-		if _, err := fmt.Printf("  URL:   %s\n", display.DisplayURL); err != nil {
+		if env.Provider != "" {
+			if _, err := fmt.Printf("  Provider: %s\n", describeProvider(env)); err != nil {
+				return fmt.Errorf("failed to display provider: %w", err)
+			}
+		}
+		if len(env.Tags) > 0 {
+			if _, err := fmt.Printf("  Tags:  %s\n", strings.Join(env.Tags, ", ")); err != nil {
+				return fmt.Errorf("failed to display tags: %w", err)
+			}
+		}
+		urlLine := display.DisplayURL
+		if urlLine == "" && isCloudProvider(env) {
+			urlLine = "(provider default)"
+		}
+		if _, err := fmt.Printf("  URL:   %s\n", urlLine); err != nil {
 			return fmt.Errorf("failed to display environment URL: %w", err)
 		}
-		if _, err := fmt.Printf("  Model: %s\n", display.DisplayModel); err != nil {
+		modelLine := display.DisplayModel
+		if resolved := resolveModel(env, ""); resolved.Source == modelSourceConfig {
+			modelLine = resolved.Model + " (inherits default)"
+		}
+		if _, err := fmt.Printf("  Model: %s\n", modelLine); err != nil {
 			return fmt.Errorf("failed to display model: %w", err)
 		}
-		if _, err := fmt.Printf("  Key:   %s\n", maskedKey); err != nil {
+		keyLine := fmt.Sprintf("%s (fingerprint %s)", maskedKey, keyFingerprint(env.APIKey))
+		if isSecretRef(env.APIKey) {
+			keyLine = env.APIKey + " (stored outside the config)"
+		}
+		if _, err := fmt.Printf("  Key:   %s\n", keyLine); err != nil {
 			return fmt.Errorf("failed to display masked API key: %w", err)
 		}
+		if len(environmentKeys(env)) > 1 {
+			if _, err := fmt.Printf("  Keys:  %s\n", describeKeyPool(env)); err != nil {
+				return fmt.Errorf("failed to display key pool: %w", err)
+			}
+		}
 
 		// Show selected API key env var name
-		keyVar := env.APIKeyEnv
-		if keyVar == "" {
-			keyVar = "ANTHROPIC_API_KEY"
-		}
-		if _, err := fmt.Printf("  Key Var: %s\n", keyVar); err != nil {
+		keyVar := resolveAPIKeyVar(env)
+		if _, err := fmt.Printf("  Key Var: %s (%s)\n", keyVar, authSchemeForKeyVar(keyVar)); err != nil {
 			return fmt.Errorf("failed to display api key env var: %w", err)
 		}
+		if pin := describeClaudeVersionRange(env); pin != "" {
+			if _, err := fmt.Printf("  Claude: %s\n", pin); err != nil {
+				return fmt.Errorf("failed to display claude version range: %w", err)
+			}
+		}
+		if env.CACert != "" {
+			if _, err := fmt.Printf("  CA Cert: %s (custom)\n", env.CACert); err != nil {
+				return fmt.Errorf("failed to display CA certificate: %w", err)
+			}
+		}
+		if env.TrustArgs {
+			if _, err := fmt.Println("  Args: trusted (no metacharacter warnings)"); err != nil {
+				return fmt.Errorf("failed to display argument trust: %w", err)
+			}
+		}
+		if env.PreviousAPIKey != "" {
+			if _, err := fmt.Printf("  Rotated: %s (previous key %s kept for rollback)\n", env.KeyRotatedAt, keyFingerprint(env.PreviousAPIKey)); err != nil {
+				return fmt.Errorf("failed to display key rotation: %w", err)
+			}
+		}
+
+		if len(env.Headers) > 0 {
+			if _, err := fmt.Printf("  Headers: %d (%s)\n", len(env.Headers), strings.Join(headerNames(env.Headers), ", ")); err != nil {
+				return fmt.Errorf("failed to display headers: %w", err)
+			}
+		}
 
 		// Display additional environment variables if any
 		if len(env.EnvVars) > 0 {
 			if _, err := fmt.Printf("  Env Variables:\n"); err != nil {
 				return fmt.Errorf("failed to display env vars header: %w", err)
 			}
-			for key, value := range env.EnvVars {
-				if _, err := fmt.Printf("    %s=%s\n", key, value); err != nil {
+			for _, line := range envVarLines(env, "    ") {
+				if _, err := fmt.Println(line); err != nil {
 					return fmt.Errorf("failed to display env var: %w", err)
 				}
 			}
 		}
 
+		if opts.Wide && env.Notes != "" {
+			if _, err := fmt.Printf("  Notes: %s\n", env.Notes); err != nil {
+				return fmt.Errorf("failed to display notes: %w", err)
+			}
+		}
+		if _, err := fmt.Printf("  Last Used: %s\n", describeLastUsed(env.LastUsed, now)); err != nil {
+			return fmt.Errorf("failed to display last use: %w", err)
+		}
+
 		// Show truncation warning if any fields were truncated
 		if len(display.TruncatedFields) > 0 {
 			if _, err := fmt.Printf("  (Truncated: %s)\n", strings.Join(display.TruncatedFields, ", ")); err != nil {
@@ -1324,6 +1897,38 @@ func displayEnvironments(config Config) error {
 	return nil
 }
 
+// displayEnvironmentNames prints one sorted environment name per line with no decoration
+func displayEnvironmentNames(config Config) error {
+	names := make([]string, 0, len(config.Environments))
+	for _, env := range config.Environments {
+		names = append(names, env.Name)
+	}
+	return printEnvironmentNames(names)
+}
+
+// printEnvironmentNames prints names sorted, one per line; names is left in its order
+func printEnvironmentNames(names []string) error {
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := fmt.Println(name); err != nil {
+			return fmt.Errorf("failed to display environment name: %w", err)
+		}
+	}
+	return nil
+}
+
+// printEnvironmentNamesInOrder prints envs' names one per line in the order given (list --names --sort)
+func printEnvironmentNamesInOrder(envs []Environment) error {
+	for _, env := range envs {
+		if _, err := fmt.Println(env.Name); err != nil {
+			return fmt.Errorf("failed to display environment name: %w", err)
+		}
+	}
+	return nil
+}
+
 // isValidEnvVarName validates environment variable names using proper naming conventions
 func isValidEnvVarName(name string) bool {
 	// Environment variable names should:
@@ -1378,3 +1983,15 @@ func maskAPIKey(apiKey string) string {
 
 	return apiKey[:4] + strings.Repeat("*", len(apiKey)-8) + apiKey[len(apiKey)-4:]
 }
+
+// keyFingerprintLength is the number of hex characters shown for a key fingerprint
+const keyFingerprintLength = 6
+
+// keyFingerprint returns a short, non-reversible SHA-256 prefix that distinguishes keys without revealing them
+func keyFingerprint(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])[:keyFingerprintLength]
+}
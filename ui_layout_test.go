@@ -140,3 +140,52 @@ func minInt(a, b int) int {
 	}
 	return b
 }
+
+// TestFormatSingleLineKeepsModelFamily tests that environments differing only by model stay distinguishable
+func TestFormatSingleLineKeepsModelFamily(t *testing.T) {
+	for _, width := range []int{40, 60, 80, 120} {
+		formatter := newDisplayFormatter(TerminalLayout{Width: width, Height: 24, ContentWidth: width - 8})
+		for model, want := range map[string]string{
+			"claude-sonnet-4-5-20250929": "sonnet-4-5",
+			"claude-opus-4-1-20250805":   "opus-4-1",
+			"":                           "[default]",
+		} {
+			env := Environment{Name: "gateway", URL: "https://gateway.example.com/v1", Model: model}
+			line := formatter.formatSingleLine("► ", env)
+			if !strings.Contains(line, want) {
+				t.Errorf("width %d: expected %q in %q", width, want, line)
+			}
+			if w := displayWidth(line); w > width {
+				t.Errorf("width %d: line %q is %d columns", width, line, w)
+			}
+		}
+	}
+
+	// Minimal format still shows the model when there is room after the name
+	formatter := newDisplayFormatter(TerminalLayout{Width: 24, Height: 24, ContentWidth: 16})
+	line := formatter.formatSingleLine("1. ", Environment{Name: "prod", URL: "https://api.anthropic.com", Model: "claude-opus-4-1-20250805"})
+	if line != "1. prod [opus-4-1]" {
+		t.Errorf("unexpected minimal line %q", line)
+	}
+}
+
+func TestShortModelName(t *testing.T) {
+	tests := []struct {
+		model string
+		width int
+		want  string
+		trunc bool
+	}{
+		{"claude-sonnet-4-5-20250929", 30, "claude-sonnet-4-5-20250929", false},
+		{"claude-sonnet-4-5-20250929", 20, "claude-sonnet-4-5", true},
+		{"claude-sonnet-4-5-20250929", 12, "sonnet-4-5", true},
+		{"claude-sonnet-4-5-20250929", 8, "sonne...", true},
+		{"my-gateway-model-name", 12, "my-gatewa...", true},
+	}
+	for _, tt := range tests {
+		got, trunc := shortModelName(tt.model, tt.width)
+		if got != tt.want || trunc != tt.trunc {
+			t.Errorf("shortModelName(%q, %d) = %q, %v; want %q, %v", tt.model, tt.width, got, trunc, tt.want, tt.trunc)
+		}
+	}
+}
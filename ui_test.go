@@ -735,3 +735,35 @@ func TestUIErrorHandling(t *testing.T) {
 		}
 	}
 }
+
+func TestKeyFingerprint(t *testing.T) {
+	a := keyFingerprint("sk-ant-REDACTED")
+	b := keyFingerprint("sk-ant-REDACTED")
+
+	if len(a) != keyFingerprintLength || len(b) != keyFingerprintLength {
+		t.Fatalf("unexpected fingerprint lengths: %q %q", a, b)
+	}
+	if a == b {
+		t.Error("keys with identical masks should have different fingerprints")
+	}
+	if a != keyFingerprint("sk-ant-REDACTED") {
+		t.Error("fingerprint should be deterministic")
+	}
+	if keyFingerprint("") != "" {
+		t.Error("empty key should have no fingerprint")
+	}
+
+	key := "sk-ant-REDACTED"
+	config := Config{Environments: []Environment{{Name: "fp", URL: "https://api.anthropic.com", APIKey: key}}}
+	output := captureStdout(t, func() {
+		if err := displayEnvironments(config); err != nil {
+			t.Fatalf("displayEnvironments() failed: %v", err)
+		}
+	})
+	if !strings.Contains(output, "fingerprint "+keyFingerprint(key)) {
+		t.Errorf("expected fingerprint in list output: %q", output)
+	}
+	if strings.Contains(output, key) {
+		t.Error("raw key leaked into list output")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// usageNow is the clock launches are stamped with; tests replace it
+var usageNow = time.Now
+
+// List orders for list --sort
+const (
+	sortByName     = "name"
+	sortByLastUsed = "last-used"
+)
+
+// recordLastUsed stamps name's last_used with the launch time. The config is reloaded
+// from the store right before the write, so changes made since this launch loaded it are
+// kept, and the save skips the backup: a timestamp is not worth rotating one out. An
+// environment renamed or removed in the meantime is left alone.
+func recordLastUsed(name string) error {
+	config, err := configStore.Load()
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i, env := range config.Environments {
+		if env.Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+	used := usageNow().UTC().Truncate(time.Second)
+	config.Environments[index].LastUsed = &used
+	return configStore.Save(config)
+}
+
+// sameTime reports whether two optional times are both unset or the same instant
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// describeLastUsed renders a last_used time relative to now, e.g. "3 hours ago"; older
+// than a month shows the date
+func describeLastUsed(used *time.Time, now time.Time) string {
+	if used == nil {
+		return "never"
+	}
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch age := now.Sub(*used); {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return plural(int(age/time.Minute), "minute")
+	case age < 24*time.Hour:
+		return plural(int(age/time.Hour), "hour")
+	case age < 30*24*time.Hour:
+		return plural(int(age/(24*time.Hour)), "day")
+	default:
+		return "on " + used.Local().Format("2006-01-02")
+	}
+}
+
+// sortEnvironments orders envs for list --sort: by name, or most recently used first with
+// never-used environments last. Ties keep config order; an empty order changes nothing.
+func sortEnvironments(envs []Environment, order string) []Environment {
+	sorted := append([]Environment(nil), envs...)
+	switch order {
+	case sortByName:
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	case sortByLastUsed:
+		stamp := func(env Environment) time.Time {
+			if env.LastUsed == nil {
+				return time.Time{}
+			}
+			return *env.LastUsed
+		}
+		sort.SliceStable(sorted, func(i, j int) bool { return stamp(sorted[i]).After(stamp(sorted[j])) })
+	}
+	return sorted
+}
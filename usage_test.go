@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// usedAt parses an RFC 3339 time for a LastUsed field
+func usedAt(t *testing.T, value string) *time.Time {
+	t.Helper()
+	used, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("bad test time %q: %v", value, err)
+	}
+	return &used
+}
+
+func withUsageClock(t *testing.T, now time.Time) {
+	t.Helper()
+	original := usageNow
+	usageNow = func() time.Time { return now }
+	t.Cleanup(func() { usageNow = original })
+}
+
+func TestLaunchRecordsLastUsed(t *testing.T) {
+	withTempConfigPath(t)
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	withUsageClock(t, now)
+
+	if err := saveConfig(Config{Environments: []Environment{
+		{Name: "work", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"},
+		{Name: "lab", URL: "https://lab.example.com", APIKey: "sk-ant-REDACTED"},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	originalLauncher := claudeLauncher
+	t.Cleanup(func() { claudeLauncher = originalLauncher })
+	claudeLauncher = func(Environment, []string, string) error { return nil }
+
+	// Another cce edits the config after this launch loaded it, while the picker is open
+	originalSelector := environmentSelector
+	t.Cleanup(func() { environmentSelector = originalSelector })
+	environmentSelector = func(config Config) (Environment, error) {
+		edited := config
+		edited.Environments = append([]Environment(nil), config.Environments...)
+		edited.Environments[1].Notes = "edited elsewhere"
+		if err := saveConfig(edited); err != nil {
+			t.Fatalf("saveConfig() failed: %v", err)
+		}
+		return config.Environments[0], nil
+	}
+
+	captureStdout(t, func() {
+		if err := runDefaultWithOptions("", nil, launchOptions{}); err != nil {
+			t.Fatalf("launch failed: %v", err)
+		}
+	})
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	if got := config.Environments[0].LastUsed; got == nil || !got.Equal(now) {
+		t.Errorf("work last_used = %v", got)
+	}
+	if config.Environments[1].LastUsed != nil || config.Environments[1].Notes != "edited elsewhere" {
+		t.Errorf("lab should keep its edit and stay unused, got %+v", config.Environments[1])
+	}
+
+	if err := recordLastUsed("renamed-meanwhile"); err != nil {
+		t.Errorf("a missing environment should be skipped, got %v", err)
+	}
+}
+
+func TestDescribeLastUsed(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	if got := describeLastUsed(nil, now); got != "never" {
+		t.Errorf("describeLastUsed(nil) = %q, want never", got)
+	}
+	tests := map[string]string{
+		"2026-03-04T11:59:30Z": "just now",
+		"2026-03-04T11:59:00Z": "1 minute ago",
+		"2026-03-04T09:00:00Z": "3 hours ago",
+		"2026-03-03T12:00:00Z": "1 day ago",
+	}
+	for value, want := range tests {
+		if got := describeLastUsed(usedAt(t, value), now); got != want {
+			t.Errorf("describeLastUsed(%q) = %q, want %q", value, got, want)
+		}
+	}
+	if got := describeLastUsed(usedAt(t, "2025-12-01T00:00:00Z"), now); !strings.HasPrefix(got, "on 2025-1") {
+		t.Errorf("old uses should show the date, got %q", got)
+	}
+}
+
+func TestListSortLastUsed(t *testing.T) {
+	withTempConfigPath(t)
+	withUsageClock(t, time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC))
+	if err := saveConfig(Config{Environments: []Environment{
+		{Name: "beta", URL: "https://b.example.com", APIKey: "sk-ant-REDACTED", LastUsed: usedAt(t, "2026-03-01T12:00:00Z")},
+		{Name: "alpha", URL: "https://a.example.com", APIKey: "sk-ant-REDACTED"},
+		{Name: "gamma", URL: "https://g.example.com", APIKey: "sk-ant-REDACTED", LastUsed: usedAt(t, "2026-03-04T10:00:00Z")},
+	}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+
+	for sortBy, want := range map[string]string{"last-used": "gamma\nbeta\nalpha\n", "name": "alpha\nbeta\ngamma\n"} {
+		opts, err := parseListOptions([]string{"--names", "--sort", sortBy})
+		if err != nil {
+			t.Fatalf("parseListOptions() failed: %v", err)
+		}
+		output := captureStdout(t, func() {
+			if err := runListWithOptions(opts); err != nil {
+				t.Fatalf("list failed: %v", err)
+			}
+		})
+		if output != want {
+			t.Errorf("--sort %s printed %q, want %q", sortBy, output, want)
+		}
+	}
+
+	output := captureStdout(t, func() {
+		if err := runListWithOptions(listOptions{Sort: sortByLastUsed}); err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+	})
+	gamma, alpha := strings.Index(output, "gamma"), strings.Index(output, "alpha")
+	if gamma < 0 || alpha < gamma || !strings.Contains(output, "Last Used: 2 hours ago") || !strings.Contains(output, "Last Used: never") {
+		t.Errorf("unexpected list output %q", output)
+	}
+
+	if _, err := parseListOptions([]string{"--sort", "size"}); err == nil {
+		t.Error("unknown sort orders should be rejected")
+	}
+}
+
+func TestGroupLaunchRecordsLastUsed(t *testing.T) {
+	saveGroupConfig(t)
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	withUsageClock(t, now)
+	stubGroupLauncher(t, func(env Environment, _ []string, _, _ io.Writer) (int, error) {
+		if env.Name == "stage-eu" {
+			return 0, errors.New("claude not found")
+		}
+		return 1, nil
+	})
+
+	captureStdoutAndStderr(t, func() error { return handleCommand([]string{"--group", "staging"}) })
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() failed: %v", err)
+	}
+	for _, env := range config.Environments {
+		ran := env.Name == "stage-us"
+		if got := env.LastUsed != nil && env.LastUsed.Equal(now); got != ran {
+			t.Errorf("%s last_used = %v, want recorded %v", env.Name, env.LastUsed, ran)
+		}
+	}
+}
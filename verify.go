@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Outcomes of one verification stage
+const (
+	verifyPassed  = "ok"
+	verifyFailed  = "FAILED"
+	verifySkipped = "skipped"
+)
+
+// verifyOptions holds flags accepted by the verify subcommand
+type verifyOptions struct {
+	All         bool     // Verify every environment
+	Names       []string // Environments to verify when not --all
+	Concurrency int      // Environments verified at once
+	Insecure    bool     // Skip TLS certificate verification in the network stage
+}
+
+// verifyStage is the outcome of one step of an environment's pipeline
+type verifyStage struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// verifyReport collects the stages run for one environment
+type verifyReport struct {
+	Name   string
+	Stages []verifyStage
+}
+
+// failed reports whether any stage failed
+func (r verifyReport) failed() bool {
+	for _, stage := range r.Stages {
+		if stage.Status == verifyFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVerifyOptions parses `verify [--all | <name>...] [--concurrency <n>] [--insecure]`
+func parseVerifyOptions(args []string) (verifyOptions, error) {
+	opts := verifyOptions{Concurrency: defaultCheckConcurrency}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--all":
+			opts.All = true
+		case arg == "--concurrency":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--concurrency flag requires a number")
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 1 || n > 32 {
+				return opts, fmt.Errorf("invalid --concurrency: '%s' must be between 1 and 32", args[i])
+			}
+			opts.Concurrency = n
+		case arg == "--insecure":
+			opts.Insecure = true
+		case strings.HasPrefix(arg, "-"):
+			return opts, fmt.Errorf("unknown verify flag '%s'", arg)
+		default:
+			opts.Names = append(opts.Names, arg)
+		}
+	}
+	if opts.All == (len(opts.Names) > 0) {
+		return opts, fmt.Errorf("verify needs either --all or environment names")
+	}
+	return opts, nil
+}
+
+// verifyEnvironment runs the pipeline a launch depends on: stored config validation, launch
+// environment preparation, then connectivity and auth. A stage is skipped once one fails.
+func verifyEnvironment(ctx context.Context, nv *networkValidator, env Environment) verifyReport {
+	report := verifyReport{Name: env.Name}
+	add := func(name, status, detail string) {
+		report.Stages = append(report.Stages, verifyStage{Name: name, Status: status, Detail: detail})
+	}
+
+	if err := validateStoredEnvironment(env); err != nil {
+		add("config", verifyFailed, err.Error())
+		add("launch", verifySkipped, "")
+		add("network", verifySkipped, "")
+		return report
+	}
+	add("config", verifyPassed, "")
+
+	if _, err := prepareEnvironment(env); err != nil {
+		add("launch", verifyFailed, err.Error())
+		add("network", verifySkipped, "")
+		return report
+	}
+	if env.SettingsDir != "" {
+		if _, err := expandSettingsDir(env.SettingsDir); err != nil {
+			add("launch", verifyFailed, err.Error())
+			add("network", verifySkipped, "")
+			return report
+		}
+	}
+	add("launch", verifyPassed, fmt.Sprintf("%d variable(s)", len(launchVariables(env))))
+
+	if isCloudProvider(env) {
+		add("network", verifySkipped, fmt.Sprintf("%s credentials are checked by the %s CLI", env.Provider, cloudCLI(env)))
+		return report
+	}
+	result, err := nv.checkEnvironmentContext(ctx, env)
+	if err != nil {
+		add("network", verifyFailed, firstLine(err.Error()))
+		return report
+	}
+	add("network", verifyPassed, result.describe())
+	return report
+}
+
+// renderVerifyReports prints the launcher check and each environment's stages
+func renderVerifyReports(w io.Writer, claudeStage verifyStage, reports []verifyReport) error {
+	line := func(indent string, stage verifyStage) error {
+		text := fmt.Sprintf("%s%-7s %s", indent, stage.Status, stage.Name)
+		if stage.Detail != "" {
+			text += "  " + firstLine(stage.Detail)
+		}
+		_, err := fmt.Fprintln(w, text)
+		return err
+	}
+
+	if err := line("", claudeStage); err != nil {
+		return fmt.Errorf("failed to display verification report: %w", err)
+	}
+	for _, report := range reports {
+		if _, err := fmt.Fprintf(w, "\n%s\n", report.Name); err != nil {
+			return fmt.Errorf("failed to display verification report: %w", err)
+		}
+		for _, stage := range report.Stages {
+			if err := line("  ", stage); err != nil {
+				return fmt.Errorf("failed to display verification report: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// runVerify verifies environments end to end with bounded concurrency and fails if the
+// claude launcher is missing or any environment fails a stage
+func runVerify(args []string) error {
+	opts, err := parseVerifyOptions(args)
+	if err != nil {
+		return fmt.Errorf("argument parsing failed: %w", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("configuration loading failed: %w", err)
+	}
+	envs, err := selectNamedEnvironments(config, opts.Names)
+	if err != nil {
+		return err
+	}
+
+	claudeStage := verifyStage{Name: "claude launcher", Status: verifyPassed}
+	if err := checkClaudeCodeExists(); err != nil {
+		claudeStage.Status, claudeStage.Detail = verifyFailed, err.Error()
+	} else if path, err := exec.LookPath("claude"); err == nil {
+		claudeStage.Detail = path
+	}
+
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	if _, err := fmt.Printf("Verifying %d environment(s), %d at a time...\n", len(envs), opts.Concurrency); err != nil {
+		return fmt.Errorf("failed to display header: %w", err)
+	}
+	nv := newNetworkValidator(0)
+	if opts.Insecure {
+		fmt.Fprintln(os.Stderr, insecureWarning)
+		nv.withInsecure()
+	}
+	reports := make([]verifyReport, len(envs))
+	dispatched := runBounded(ctx, len(envs), opts.Concurrency, func(i int) {
+		reports[i] = verifyEnvironment(ctx, nv, envs[i])
+	})
+	reports = reports[:dispatched]
+
+	if err := renderVerifyReports(os.Stdout, claudeStage, reports); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, report := range reports {
+		if report.failed() {
+			failed++
+		}
+	}
+	if _, err := fmt.Printf("\n%d passed, %d failed\n", len(reports)-failed, failed); err != nil {
+		return fmt.Errorf("failed to display summary: %w", err)
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("verification interrupted after %d of %d environment(s)", dispatched, len(envs))
+	case claudeStage.Status == verifyFailed:
+		return fmt.Errorf("verification failed: claude launcher not available")
+	case failed > 0:
+		return fmt.Errorf("verification failed for %d environment(s)", failed)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withFakeClaude puts an executable claude on PATH, or an empty PATH when present is false
+func withFakeClaude(t *testing.T, present bool) {
+	t.Helper()
+	dir := t.TempDir()
+	if present {
+		if err := ioutil.WriteFile(filepath.Join(dir, "claude"), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("failed to write fake claude: %v", err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestParseVerifyOptions(t *testing.T) {
+	if opts, err := parseVerifyOptions([]string{"--all", "--concurrency", "8"}); err != nil || !opts.All || opts.Concurrency != 8 {
+		t.Errorf("parseVerifyOptions(--all --concurrency 8) = %+v, %v", opts, err)
+	}
+	if opts, err := parseVerifyOptions([]string{"prod", "staging"}); err != nil || len(opts.Names) != 2 || opts.Concurrency != defaultCheckConcurrency {
+		t.Errorf("parseVerifyOptions(names) = %+v, %v", opts, err)
+	}
+	for _, args := range [][]string{{}, {"--all", "prod"}, {"--all", "--concurrency", "0"}, {"--all", "--concurrency"}, {"--bogus"}} {
+		if _, err := parseVerifyOptions(args); err == nil {
+			t.Errorf("parseVerifyOptions(%q) should fail", args)
+		}
+	}
+}
+
+func TestVerifyReportsEveryStage(t *testing.T) {
+	ok := newProbeServer(t, http.StatusOK)
+	rejected := newProbeServer(t, http.StatusUnauthorized)
+	path := withTempConfigPath(t)
+	content := `{"environments": [
+    {"name": "good", "url": "` + ok.URL + `", "api_key": "sk-ant-api03-good-key-123"},
+    {"name": "revoked", "url": "` + rejected.URL + `", "api_key": "sk-ant-api03-revoked-key"},
+    {"name": "aws", "provider": "bedrock", "region": "us-east-1"}
+  ]}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	withFakeClaude(t, true)
+
+	var err error
+	out := captureStdout(t, func() { err = runVerify([]string{"--all"}) })
+	if err == nil || !strings.Contains(err.Error(), "verification failed for 1 environment(s)") {
+		t.Fatalf("expected one failed environment, got %v", err)
+	}
+	for _, want := range []string{
+		"ok      claude launcher",
+		"\ngood\n  ok      config\n  ok      launch",
+		"\nrevoked\n  ok      config\n  ok      launch  2 variable(s)\n  FAILED  network",
+		"skipped network  bedrock credentials are checked by the aws CLI",
+		"2 passed, 1 failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestVerifyFailsWithoutClaude(t *testing.T) {
+	ok := newProbeServer(t, http.StatusOK)
+	path := withTempConfigPath(t)
+	content := `{"environments": [{"name": "good", "url": "` + ok.URL + `", "api_key": "sk-ant-api03-good-key-123"}]}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	withFakeClaude(t, false)
+
+	var err error
+	out := captureStdout(t, func() { err = runVerify([]string{"good"}) })
+	if err == nil || !strings.Contains(err.Error(), "claude launcher not available") {
+		t.Fatalf("expected launcher failure, got %v", err)
+	}
+	if !strings.Contains(out, "FAILED  claude launcher") || !strings.Contains(out, "1 passed, 0 failed") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestVerifyUnknownEnvironment(t *testing.T) {
+	withTempConfigPath(t)
+	if err := runVerify([]string{"missing"}); err == nil {
+		t.Error("verifying an unknown environment should fail")
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Restart pacing for --watch: the pause starts at watchBaseDelay and doubles after each
+// run shorter than watchStableRun, up to watchMaxDelay, so a crash loop slows down
+// while a normal exit after real work restarts quickly
+var (
+	watchBaseDelay = time.Second
+	watchMaxDelay  = 30 * time.Second
+	watchStableRun = 10 * time.Second
+)
+
+// maxWatchRestarts bounds --watch-max
+const maxWatchRestarts = 1000
+
+// parseWatchMax validates a --watch-max value; empty means no limit (0)
+func parseWatchMax(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > maxWatchRestarts {
+		return 0, fmt.Errorf("invalid --watch-max: '%s' must be between 1 and %d", value, maxWatchRestarts)
+	}
+	return n, nil
+}
+
+// validateWatch rejects launch modes that cannot be restarted in the foreground
+func validateWatch(opts launchOptions) error {
+	switch {
+	case !opts.Watch && opts.WatchMax > 0:
+		return fmt.Errorf("--watch-max requires --watch")
+	case !opts.Watch:
+		return nil
+	case opts.Detach:
+		return fmt.Errorf("--watch keeps cce attached and cannot be combined with --detach")
+	case opts.LogFile != "":
+		return fmt.Errorf("--watch cannot be combined with --log-file")
+	}
+	return nil
+}
+
+// watchExitError ends a --watch run whose last claude session failed; its code, claude's
+// exit code, becomes cce's
+type watchExitError struct {
+	Restarts int
+	code     int
+}
+
+func (e *watchExitError) Error() string {
+	return fmt.Sprintf("claude exited with code %d after %d restart(s)", e.code, e.Restarts)
+}
+
+// watchLauncher runs one claude session for --watch; tests replace it to avoid starting claude
+var watchLauncher = launchClaudeCodeAttached
+
+// launchClaudeCodeAttached runs claude as an attached child and returns its exit code, for
+// launches that have work left after claude exits (--watch, --wk-cleanup).
+// Ctrl-C reaches claude directly from the terminal, so cce ignores it while claude runs;
+// SIGTERM and SIGHUP are forwarded and reported as stopped, ending the watch.
+func launchClaudeCodeAttached(env Environment, args []string, workdir string, signals <-chan os.Signal) (code int, stopped bool, err error) {
+	if err := checkClaudeCodeExists(); err != nil {
+		return 0, false, fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+	envVars, err := prepareEnvironment(env)
+	if err != nil {
+		return 0, false, fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+	if err := ensureSettingsDir(env); err != nil {
+		return 0, false, fmt.Errorf("Claude Code launcher failed: %w", err)
+	}
+
+	cmd := exec.Command("claude", args...)
+	cmd.Dir = workdir
+	cmd.Env = envVars
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return 0, false, fmt.Errorf("Claude Code process start failed: %w", err)
+	}
+	if err := writeCurrentMarker(env.Name, os.Getpid()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record current environment: %v\n", err)
+	}
+	defer clearCurrentMarker(os.Getpid())
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	for {
+		select {
+		case sig := <-signals:
+			if sig != os.Interrupt {
+				stopped = true
+				cmd.Process.Signal(sig)
+			}
+		case err := <-done:
+			if err == nil {
+				return 0, stopped, nil
+			}
+			if exitError, ok := err.(*exec.ExitError); ok {
+				if status, ok := exitError.Sys().(syscall.WaitStatus); ok {
+					if status.Signaled() {
+						return 128 + int(status.Signal()), stopped, nil
+					}
+					return status.ExitStatus(), stopped, nil
+				}
+			}
+			return 0, stopped, fmt.Errorf("Claude Code execution failed: %w", err)
+		}
+	}
+}
+
+// runWatch launches claude again each time it exits, pausing between runs with a
+// backoff, until Ctrl-C during a pause, a forwarded SIGTERM/SIGHUP, or maxRestarts
+func runWatch(env Environment, claudeArgs []string, workdir string, maxRestarts int) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	delay := watchBaseDelay
+	for restarts := 0; ; restarts++ {
+		started := time.Now()
+		code, stopped, err := watchLauncher(env, claudeArgs, workdir, signals)
+		if err != nil {
+			return err
+		}
+		ran := time.Since(started)
+		if stopped {
+			return nil
+		}
+		if maxRestarts > 0 && restarts >= maxRestarts {
+			fmt.Fprintf(os.Stderr, "---- claude exited (code %d); --watch-max %d reached ----\n", code, maxRestarts)
+			if code != 0 {
+				return &watchExitError{Restarts: restarts, code: code}
+			}
+			return nil
+		}
+
+		if ran >= watchStableRun {
+			delay = watchBaseDelay
+		} else if restarts > 0 {
+			if delay *= 2; delay > watchMaxDelay {
+				delay = watchMaxDelay
+			}
+		}
+		// A Ctrl-C that ended claude must not also end the watch
+		select {
+		case <-signals:
+		default:
+		}
+
+		limit := ""
+		if maxRestarts > 0 {
+			limit = fmt.Sprintf(" %d/%d", restarts+1, maxRestarts)
+		}
+		fmt.Fprintf(os.Stderr, "---- claude exited (code %d) after %s; restart%s in %s, Ctrl-C to stop ----\n", code, ran.Round(time.Second), limit, delay)
+		select {
+		case <-time.After(delay):
+		case <-signals:
+			fmt.Fprintln(os.Stderr, "Stopped watching.")
+			return nil
+		}
+	}
+}
+
+// claudeExitError ends a launch cce waited on (--wk-cleanup) whose claude session failed;
+// its code, claude's exit code, becomes cce's
+type claudeExitError struct {
+	code int
+}
+
+func (e *claudeExitError) Error() string {
+	return fmt.Sprintf("claude exited with code %d", e.code)
+}
+
+// attachedLauncher runs claude for launches that clean up afterwards; tests replace it
+var attachedLauncher = launchClaudeCodeAttached
+
+// runClaudeAttached runs one claude session as a child instead of exec'ing it, so cce
+// is still around when claude exits
+func runClaudeAttached(env Environment, claudeArgs []string, workdir string) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	code, _, err := attachedLauncher(env, claudeArgs, workdir, signals)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return &claudeExitError{code: code}
+	}
+	return nil
+}
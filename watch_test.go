@@ -0,0 +1,92 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withFastWatch stubs the --watch launcher with one that returns codes in turn and
+// shortens the pauses so restarts happen at once
+func withFastWatch(t *testing.T, codes ...int) *[]time.Time {
+	t.Helper()
+	origLauncher, origBase, origMax, origStable := watchLauncher, watchBaseDelay, watchMaxDelay, watchStableRun
+	t.Cleanup(func() {
+		watchLauncher, watchBaseDelay, watchMaxDelay, watchStableRun = origLauncher, origBase, origMax, origStable
+	})
+	watchBaseDelay, watchMaxDelay, watchStableRun = time.Millisecond, 4*time.Millisecond, time.Hour
+
+	var runs []time.Time
+	watchLauncher = func(env Environment, args []string, workdir string, signals <-chan os.Signal) (int, bool, error) {
+		runs = append(runs, time.Now())
+		if len(runs) > len(codes) {
+			t.Fatalf("unexpected run %d", len(runs))
+		}
+		return codes[len(runs)-1], false, nil
+	}
+	return &runs
+}
+
+func TestWatchRestartsUntilMax(t *testing.T) {
+	withTempConfigPath(t)
+	env := Environment{Name: "dev", URL: "https://api.anthropic.com", APIKey: "sk-ant-REDACTED"}
+	if err := saveConfig(Config{Environments: []Environment{env}}); err != nil {
+		t.Fatalf("saveConfig() failed: %v", err)
+	}
+	runs := withFastWatch(t, 1, 1, 1, 9)
+
+	var err error
+	_, stderr, _ := captureStdoutAndStderr(t, func() error {
+		err = handleCommand([]string{"--env", "dev", "--watch", "--watch-max", "3", "--skip-preflight", "--", "chat"})
+		return nil
+	})
+	if len(*runs) != 4 {
+		t.Fatalf("expected the first run plus 3 restarts, got %d runs", len(*runs))
+	}
+	if err == nil || exitCodeFor(err) != 9 {
+		t.Errorf("expected claude's exit code from the last run, got %v", err)
+	}
+	if strings.Count(stderr, "claude exited (code 1)") != 3 || !strings.Contains(stderr, "restart 3/3") || !strings.Contains(stderr, "claude exited (code 9); --watch-max 3 reached") {
+		t.Errorf("expected a separator per run, got:\n%s", stderr)
+	}
+	// Quick exits back off: 1ms, 2ms, then 4ms (capped)
+	if !strings.Contains(stderr, "in 1ms") || !strings.Contains(stderr, "in 2ms") || !strings.Contains(stderr, "in 4ms") {
+		t.Errorf("expected growing pauses, got:\n%s", stderr)
+	}
+}
+
+func TestWatchStopsWhenLauncherIsStopped(t *testing.T) {
+	withFastWatch(t)
+	watchLauncher = func(Environment, []string, string, <-chan os.Signal) (int, bool, error) {
+		return 143, true, nil
+	}
+	if err := runWatch(Environment{Name: "dev"}, nil, "", 0); err != nil {
+		t.Errorf("a SIGTERM'd watch should end quietly, got %v", err)
+	}
+}
+
+func TestWatchFlagValidation(t *testing.T) {
+	for _, args := range [][]string{
+		{"--watch-max", "2", "--", "chat"},
+		{"--watch", "--watch-max", "0"},
+		{"--watch", "--detach", "-p", "hi"},
+		{"--watch", "--log-file", "run.log", "-p", "hi"},
+		{"--watch", "--group", "team", "-p", "hi"},
+	} {
+		err := handleCommand(args)
+		if err == nil || !strings.Contains(err.Error(), "watch") {
+			t.Errorf("%v: expected a --watch error, got %v", args, err)
+		}
+	}
+
+	result := parseArguments([]string{"--watch", "--watch-max=5", "-e", "dev", "chat"})
+	if result.CCEFlags["watch"] != "true" || result.CCEFlags["watch_max"] != "5" || strings.Join(result.ClaudeArgs, " ") != "chat" {
+		t.Errorf("unexpected parse: %+v", result)
+	}
+
+	result = parseArguments([]string{"-e", "dev", "chat", "--watch", "--watch-max", "5"})
+	if result.CCEFlags["watch"] != "" || strings.Join(result.ClaudeArgs, " ") != "chat --watch --watch-max 5" {
+		t.Errorf("claude's flags after its arguments were consumed: %+v", result)
+	}
+}
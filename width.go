@@ -0,0 +1,152 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wideRanges are the East Asian Wide/Fullwidth blocks and emoji presentation ranges that
+// terminals draw two columns wide. Sorted so runeWidth can stop early.
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F}, // Hangul Jamo initial consonants
+	{0x231A, 0x231B},
+	{0x2329, 0x232A},
+	{0x23E9, 0x23EC},
+	{0x23F0, 0x23F0},
+	{0x23F3, 0x23F3},
+	{0x25FD, 0x25FE},
+	{0x2614, 0x2615},
+	{0x2648, 0x2653},
+	{0x267F, 0x267F},
+	{0x2693, 0x2693},
+	{0x26A1, 0x26A1},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26CE, 0x26CE},
+	{0x26D4, 0x26D4},
+	{0x26EA, 0x26EA},
+	{0x26F2, 0x26F3},
+	{0x26F5, 0x26F5},
+	{0x26FA, 0x26FA},
+	{0x26FD, 0x26FD},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2728, 0x2728},
+	{0x274C, 0x274C},
+	{0x274E, 0x274E},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27B0, 0x27B0},
+	{0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B50},
+	{0x2B55, 0x2B55},
+	{0x2E80, 0x303E},   // CJK radicals, symbols and punctuation
+	{0x3041, 0x33FF},   // Kana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo Extended-A
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // Vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms, small form variants
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x1F004, 0x1F004}, // Emoji below are the default-emoji-presentation blocks
+	{0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A},
+	{0x1F200, 0x1F251},
+	{0x1F300, 0x1F64F},
+	{0x1F680, 0x1F6FF},
+	{0x1F7E0, 0x1F7EB},
+	{0x1F900, 0x1F9FF},
+	{0x1FA70, 0x1FAFF},
+	{0x20000, 0x2FFFD}, // CJK Extensions B-F
+	{0x30000, 0x3FFFD}, // CJK Extension G
+}
+
+// runeWidth returns the number of terminal columns r occupies: 0 for combining marks,
+// format characters (ZWJ, variation selectors) and controls, 2 for wide runes, else 1
+func runeWidth(r rune) int {
+	if r < 0x20 || r == 0x7F {
+		return 0
+	}
+	if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, wr := range wideRanges {
+		if r < wr.lo {
+			break
+		}
+		if r <= wr.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// displayWidth returns the number of terminal columns s occupies
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// truncateToWidth returns the longest prefix of s that fits in width columns. Zero-width
+// runes following the last kept rune stay with it so accents are not split off.
+func truncateToWidth(s string, width int) string {
+	used := 0
+	for i, r := range s {
+		w := runeWidth(r)
+		if used+w > width {
+			return s[:i]
+		}
+		used += w
+	}
+	return s
+}
+
+// tailToWidth returns the longest suffix of s that fits in width columns, never starting
+// on a combining mark
+func tailToWidth(s string, width int) string {
+	runes := []rune(s)
+	used := 0
+	start := len(runes)
+	for i := len(runes) - 1; i >= 0; i-- {
+		w := runeWidth(runes[i])
+		if used+w > width {
+			break
+		}
+		used += w
+		start = i
+	}
+	for start < len(runes) && runeWidth(runes[start]) == 0 {
+		start++
+	}
+	return string(runes[start:])
+}
+
+// fitWidth shortens s to at most width columns, ending in "..." when there is room for it
+func fitWidth(s string, width int) (string, bool) {
+	if displayWidth(s) <= width {
+		return s, false
+	}
+	if width <= 3 {
+		return truncateToWidth(s, width), true
+	}
+	return truncateToWidth(s, width-3) + "...", true
+}
+
+// padToWidth right-pads s with spaces to width columns
+func padToWidth(s string, width int) string {
+	if pad := width - displayWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
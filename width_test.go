@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"prod", 4},
+		{"生产环境", 8},
+		{"测试-staging", 12},
+		{"café", 4},
+		{"cafe\u0301", 4}, // e + combining acute accent
+		{"🚀", 2},
+		{"ＡＢ", 4}, // fullwidth Latin
+		{"한국", 4},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.input); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFitWidthNeverSplitsWideRunes(t *testing.T) {
+	got, truncated := fitWidth("生产环境主账号", 8)
+	if !truncated || got != "生产..." {
+		t.Errorf("fitWidth() = %q, %v; want %q, true", got, truncated, "生产...")
+	}
+	// An odd budget cannot hold half an ideograph, so the result is one column short
+	got, _ = fitWidth("生产环境主账号", 10)
+	if got != "生产环..." || displayWidth(got) != 9 {
+		t.Errorf("fitWidth(10) = %q (width %d)", got, displayWidth(got))
+	}
+	if got, truncated := fitWidth("生产", 4); truncated || got != "生产" {
+		t.Errorf("fitting text should be unchanged, got %q", got)
+	}
+	if got := tailToWidth("cafe\u0301", 1); got != "e\u0301" {
+		t.Errorf("tailToWidth() should keep the accent with its letter, got %q", got)
+	}
+}
+
+func TestSmartTruncateChineseName(t *testing.T) {
+	df := newDisplayFormatter(TerminalLayout{Width: 40, ContentWidth: 32})
+	name := "阿里云百炼国际版生产环境备用线路"
+	got, truncated := df.smartTruncateName(name)
+	if !truncated {
+		t.Fatalf("expected %q to be truncated to %d columns", name, df.nameWidth)
+	}
+	if w := displayWidth(got); w > df.nameWidth {
+		t.Errorf("truncated name %q is %d columns, limit %d", got, w, df.nameWidth)
+	}
+	if !strings.HasPrefix(got, "阿里") || !strings.HasSuffix(got, "线路") || !strings.Contains(got, "...") {
+		t.Errorf("expected head...tail truncation, got %q", got)
+	}
+}
+
+func TestFormatSingleLineFitsTerminalWithChineseNames(t *testing.T) {
+	for _, width := range []int{30, 50, 80} {
+		df := newDisplayFormatter(TerminalLayout{Width: width, ContentWidth: width - 8})
+		env := Environment{Name: "深圳研发中心测试环境", URL: "https://api.example.cn/v1/anthropic", Model: "claude-3-5-sonnet-20241022"}
+		line := df.formatSingleLine("► ", env)
+		if w := displayWidth(line); w > width {
+			t.Errorf("width %d: line %q is %d columns", width, line, w)
+		}
+		if !strings.HasPrefix(line, "► 深圳") {
+			t.Errorf("width %d: unexpected line %q", width, line)
+		}
+	}
+}
+
+func TestCheckResultsAlignWithChineseNames(t *testing.T) {
+	checks := []environmentCheck{
+		{Name: "生产", Result: networkCheckResult{StatusCode: 200}},
+		{Name: "staging", Result: networkCheckResult{StatusCode: 200}},
+	}
+	var buf bytes.Buffer
+	if _, _, err := renderEnvironmentChecks(&buf, checks); err != nil {
+		t.Fatalf("renderEnvironmentChecks() failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	first := displayWidth(lines[0][:strings.Index(lines[0], "ok")])
+	second := displayWidth(lines[1][:strings.Index(lines[1], "ok")])
+	if first != second {
+		t.Errorf("status columns misaligned (%d vs %d):\n%s", first, second, buf.String())
+	}
+}
@@ -3,9 +3,12 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -17,6 +20,36 @@ type WorktreeManager struct {
 	worktreeName string
 	worktreePath string
 	now          func() time.Time
+	basePath     string // Directory new worktrees go in; empty means the system temp dir
+	nameSuffix   string // Replaces the timestamp in generated names (--wk-name)
+	fresh        bool   // Always create a new worktree instead of reusing one
+	reused       bool   // Set when createWorktree picked up an existing worktree
+	created      bool   // Set when createWorktree ran git worktree add; only these are cleaned up
+}
+
+// WorktreeSettings configures where --wk puts new worktrees
+type WorktreeSettings struct {
+	BasePath string `json:"base_path,omitempty"` // Absolute or ~/ directory; --wk-path overrides it
+}
+
+// worktreeBasePath is settings.worktree.base_path, expanded, set by applyWorktreeSettings
+var worktreeBasePath string
+
+// applyWorktreeSettings validates and activates settings.worktree
+func applyWorktreeSettings(settings *ConfigSettings) error {
+	worktreeBasePath = ""
+	if settings == nil || settings.Worktree == nil || settings.Worktree.BasePath == "" {
+		return nil
+	}
+	if err := validateSettingsDir(settings.Worktree.BasePath); err != nil {
+		return fmt.Errorf("base_path: %w", err)
+	}
+	dir, err := expandSettingsDir(settings.Worktree.BasePath)
+	if err != nil {
+		return fmt.Errorf("base_path: %w", err)
+	}
+	worktreeBasePath = dir
+	return nil
 }
 
 // NewWorktreeManager builds a manager rooted at basePath (defaults to cwd).
@@ -181,14 +214,129 @@ func (wm *WorktreeManager) generateWorktreeName(branch string) string {
 		nowFn = time.Now
 	}
 
-	current := nowFn().UTC()
-	timestamp := fmt.Sprintf("%s-%09d", current.Format("20060102-150405"), current.Nanosecond())
-	name := fmt.Sprintf("%s-%s-%s", sanitizedProject, sanitizedBranch, timestamp)
+	suffix := wm.nameSuffix
+	if suffix == "" {
+		current := nowFn().UTC()
+		suffix = fmt.Sprintf("%s-%09d", current.Format("20060102-150405"), current.Nanosecond())
+	}
+	name := fmt.Sprintf("%s-%s-%s", sanitizedProject, sanitizedBranch, suffix)
 	wm.worktreeName = name
 	return name
 }
 
-// createWorktree runs `git worktree add -b <name> <path> <base-branch>`.
+// worktreeNamePattern matches names produced by generateWorktreeName for a project and branch.
+func worktreeNamePattern(project, branch string) *regexp.Regexp {
+	prefix := regexp.QuoteMeta(sanitizeBranchName(project) + "-" + sanitizeBranchName(branch) + "-")
+	return regexp.MustCompile("^" + prefix + `[0-9]{8}-[0-9]{6}-[0-9]{9}$`)
+}
+
+// worktreeEntry is one worktree registered with the repository
+type worktreeEntry struct {
+	Path   string
+	Branch string // Empty for a detached HEAD
+}
+
+// cceWorktreeSuffix matches the timestamp generateWorktreeName appends to every CCE worktree branch
+var cceWorktreeSuffix = regexp.MustCompile(`-[0-9]{8}-[0-9]{6}-[0-9]{9}$`)
+
+// listWorktrees parses `git worktree list --porcelain`; suggestion is shown if git fails.
+func (wm *WorktreeManager) listWorktrees(suggestion string) ([]worktreeEntry, error) {
+	if err := wm.detectGitRepo(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "-C", wm.repoPath, "worktree", "list", "--porcelain")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		errorCtx := newErrorContext("worktree listing", "worktree manager")
+		errorCtx.addContext("path", wm.repoPath)
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			errorCtx.addContext("git stderr", msg)
+		}
+		errorCtx.addSuggestion(suggestion)
+		return nil, errorCtx.formatError(err)
+	}
+
+	// Porcelain output is blank-line separated blocks of "worktree <path>" and "branch refs/heads/<name>"
+	var entries []worktreeEntry
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			entries = append(entries, worktreeEntry{Path: strings.TrimPrefix(line, "worktree ")})
+		case strings.HasPrefix(line, "branch refs/heads/") && len(entries) > 0:
+			entries[len(entries)-1].Branch = strings.TrimPrefix(line, "branch refs/heads/")
+		}
+	}
+	return entries, nil
+}
+
+// staleWorktrees lists CCE-created worktrees whose directory no longer exists
+func (wm *WorktreeManager) staleWorktrees() ([]worktreeEntry, error) {
+	entries, err := wm.listWorktrees("Run 'git worktree prune' to clean up by hand")
+	if err != nil {
+		return nil, err
+	}
+	var stale []worktreeEntry
+	for _, entry := range entries {
+		if !cceWorktreeSuffix.MatchString(entry.Branch) {
+			continue
+		}
+		if info, err := os.Stat(entry.Path); err == nil && info.IsDir() {
+			continue
+		}
+		stale = append(stale, entry)
+	}
+	return stale, nil
+}
+
+// removeWorktree unregisters a worktree; its branch is kept so no commits are lost
+func (wm *WorktreeManager) removeWorktree(path string) error {
+	cmd := exec.Command("git", "-C", wm.repoPath, "worktree", "remove", "--force", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("git worktree remove failed: %s", msg)
+		}
+		return fmt.Errorf("git worktree remove failed: %w", err)
+	}
+	return nil
+}
+
+// findExistingWorktree returns the newest CCE-created worktree for branch that still exists on disk.
+func (wm *WorktreeManager) findExistingWorktree(branch string) (string, string, error) {
+	entries, err := wm.listWorktrees("Use --wk-fresh to skip reuse detection")
+	if err != nil {
+		return "", "", err
+	}
+
+	pattern := worktreeNamePattern(filepath.Base(wm.repoPath), branch)
+	type candidate struct{ name, path string }
+	var candidates []candidate
+	for _, entry := range entries {
+		if !pattern.MatchString(entry.Branch) {
+			continue
+		}
+		if info, err := os.Stat(entry.Path); err != nil || !info.IsDir() {
+			continue // Stale entry; `cce doctor --fix` cleans it up
+		}
+		candidates = append(candidates, candidate{name: entry.Branch, path: entry.Path})
+	}
+
+	if len(candidates) == 0 {
+		return "", "", nil
+	}
+
+	// Timestamps are embedded in the name, so the lexically greatest is the newest
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].name > candidates[j].name })
+	return candidates[0].path, candidates[0].name, nil
+}
+
+// createWorktree runs `git worktree add -b <name> <path> <base-branch>`,
+// reusing an existing CCE worktree for the branch unless fresh mode is set.
 func (wm *WorktreeManager) createWorktree(baseBranch string) error {
 	if baseBranch == "" {
 		errorCtx := newErrorContext("worktree creation", "worktree manager")
@@ -200,12 +348,29 @@ func (wm *WorktreeManager) createWorktree(baseBranch string) error {
 		return err
 	}
 
+	if !wm.fresh && wm.nameSuffix == "" && wm.worktreeName == "" && wm.worktreePath == "" {
+		path, name, err := wm.findExistingWorktree(baseBranch)
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			wm.worktreeName = name
+			wm.worktreePath = path
+			wm.reused = true
+			return nil
+		}
+	}
+
 	if wm.worktreeName == "" {
 		wm.generateWorktreeName(baseBranch)
 	}
 
 	if wm.worktreePath == "" {
-		wm.worktreePath = filepath.Join(os.TempDir(), wm.worktreeName)
+		baseDir := wm.basePath
+		if baseDir == "" {
+			baseDir = os.TempDir()
+		}
+		wm.worktreePath = filepath.Join(baseDir, wm.worktreeName)
 	}
 
 	absPath, err := filepath.Abs(wm.worktreePath)
@@ -216,6 +381,16 @@ func (wm *WorktreeManager) createWorktree(baseBranch string) error {
 		return errorCtx.formatError(err)
 	}
 
+	if err := wm.checkWorktreeTarget(absPath); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+		errorCtx := newErrorContext("worktree path resolution", "worktree manager")
+		errorCtx.addContext("path", filepath.Dir(absPath))
+		errorCtx.addSuggestion("Pass a writable directory with --wk-path")
+		return errorCtx.formatError(err)
+	}
+
 	cmd := exec.Command("git", "-C", wm.repoPath, "worktree", "add", "-b", wm.worktreeName, absPath, baseBranch)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
@@ -233,6 +408,7 @@ func (wm *WorktreeManager) createWorktree(baseBranch string) error {
 	}
 
 	wm.worktreePath = absPath
+	wm.created = true
 	return nil
 }
 
@@ -241,6 +417,140 @@ func (wm *WorktreeManager) getWorktreePath() string {
 	return wm.worktreePath
 }
 
+// setFresh forces createWorktree to make a new worktree even if one can be reused.
+func (wm *WorktreeManager) setFresh(fresh bool) {
+	wm.fresh = fresh
+}
+
+// setBasePath puts new worktrees in dir instead of the system temp dir.
+func (wm *WorktreeManager) setBasePath(dir string) {
+	wm.basePath = dir
+}
+
+// setNameSuffix replaces the generated timestamp suffix; named worktrees are never reused.
+func (wm *WorktreeManager) setNameSuffix(suffix string) {
+	wm.nameSuffix = suffix
+}
+
+// checkWorktreeTarget rejects a new worktree path inside the repository's .git directory,
+// or one that an existing worktree, branch or directory already uses.
+func (wm *WorktreeManager) checkWorktreeTarget(absPath string) error {
+	gitDir, err := wm.gitCommonDir()
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(gitDir, absPath); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		errorCtx := newErrorContext("worktree path validation", "worktree manager")
+		errorCtx.addContext("path", absPath)
+		errorCtx.addContext("git directory", gitDir)
+		errorCtx.addSuggestion("Pass a --wk-path outside the repository's .git directory")
+		errorCtx.addSuggestion("Or change settings.worktree.base_path in the config")
+		return errorCtx.formatError(fmt.Errorf("worktree path is inside the .git directory"))
+	}
+
+	entries, err := wm.listWorktrees("Use --wk-fresh or another --wk-name")
+	if err != nil {
+		return err
+	}
+	conflict := ""
+	for _, entry := range entries {
+		switch {
+		case filepath.Clean(entry.Path) == absPath:
+			conflict = "an existing worktree already uses this path"
+		case entry.Branch == wm.worktreeName:
+			conflict = fmt.Sprintf("branch %s is already checked out in %s", entry.Branch, entry.Path)
+		}
+	}
+	if conflict == "" && exec.Command("git", "-C", wm.repoPath, "show-ref", "--verify", "--quiet", "refs/heads/"+wm.worktreeName).Run() == nil {
+		conflict = fmt.Sprintf("branch %s already exists", wm.worktreeName)
+	}
+	if files, err := ioutil.ReadDir(absPath); conflict == "" && (err == nil && len(files) > 0 || err != nil && !os.IsNotExist(err)) {
+		conflict = "the path already exists and is not an empty directory"
+	}
+	if conflict == "" {
+		return nil
+	}
+
+	errorCtx := newErrorContext("worktree path validation", "worktree manager")
+	errorCtx.addContext("path", absPath)
+	errorCtx.addContext("branch", wm.worktreeName)
+	if wm.nameSuffix != "" {
+		errorCtx.addSuggestion("Choose another --wk-name")
+	}
+	errorCtx.addSuggestion(fmt.Sprintf("Remove the old worktree with 'git worktree remove %s' and 'git branch -d %s'", absPath, wm.worktreeName))
+	errorCtx.addSuggestion("Run 'cce doctor --fix' to clean up stale worktrees")
+	return errorCtx.formatError(fmt.Errorf("worktree conflict: %s", conflict))
+}
+
+// gitCommonDir returns the absolute .git directory shared by every worktree of the repository
+func (wm *WorktreeManager) gitCommonDir() (string, error) {
+	cmd := exec.Command("git", "-C", wm.repoPath, "rev-parse", "--git-common-dir")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		errorCtx := newErrorContext("git directory detection", "worktree manager")
+		errorCtx.addContext("path", wm.repoPath)
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			errorCtx.addContext("git stderr", msg)
+		}
+		return "", errorCtx.formatError(err)
+	}
+	dir := strings.TrimSpace(stdout.String())
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(wm.repoPath, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// wasReused reports whether createWorktree reused an existing worktree.
+func (wm *WorktreeManager) wasReused() bool {
+	return wm.reused
+}
+
+// cleanupWorktree removes the worktree this manager created and returns its path. A
+// reused worktree is never touched, and one with uncommitted changes is kept unless
+// force is set. The worktree's branch is kept so no commits are lost.
+func (wm *WorktreeManager) cleanupWorktree(force bool) (string, error) {
+	if !wm.created || wm.worktreePath == "" {
+		return "", nil
+	}
+
+	if !force {
+		cmd := exec.Command("git", "-C", wm.worktreePath, "status", "--porcelain")
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			errorCtx := newErrorContext("worktree cleanup", "worktree manager")
+			errorCtx.addContext("path", wm.worktreePath)
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				errorCtx.addContext("git stderr", msg)
+			}
+			return "", errorCtx.formatError(err)
+		}
+		if strings.TrimSpace(stdout.String()) != "" {
+			return "", fmt.Errorf("worktree %s has uncommitted changes; kept it (commit them, or pass --force to remove it anyway)", wm.worktreePath)
+		}
+	}
+
+	args := []string{"-C", wm.repoPath, "worktree", "remove", wm.worktreePath}
+	if force {
+		args = []string{"-C", wm.repoPath, "worktree", "remove", "--force", wm.worktreePath}
+	}
+	cmd := exec.Command("git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git worktree remove failed: %s", msg)
+		}
+		return "", fmt.Errorf("git worktree remove failed: %w", err)
+	}
+	wm.created = false
+	return wm.worktreePath, nil
+}
+
 func sanitizeBranchName(branch string) string {
 	if branch == "" {
 		return "unknown"
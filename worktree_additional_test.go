@@ -1,9 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestWorktreeCreateValidation(t *testing.T) {
@@ -69,3 +72,173 @@ func TestSanitizeBranchNameCases(t *testing.T) {
 		})
 	}
 }
+
+func TestWorktreeReuse(t *testing.T) {
+	dir := initTempRepo(t)
+
+	first := NewWorktreeManager(dir)
+	if err := first.createWorktree("main"); err != nil {
+		t.Fatalf("createWorktree failed: %v", err)
+	}
+	firstPath := first.getWorktreePath()
+	t.Cleanup(func() { os.RemoveAll(firstPath) })
+	if first.wasReused() {
+		t.Fatal("first worktree should be newly created")
+	}
+
+	t.Run("second run reuses existing worktree", func(t *testing.T) {
+		second := NewWorktreeManager(dir)
+		if err := second.createWorktree("main"); err != nil {
+			t.Fatalf("createWorktree failed: %v", err)
+		}
+		if !second.wasReused() {
+			t.Fatal("expected existing worktree to be reused")
+		}
+		if second.getWorktreePath() != firstPath {
+			t.Fatalf("reused path = %s, want %s", second.getWorktreePath(), firstPath)
+		}
+	})
+
+	t.Run("fresh mode creates a new worktree", func(t *testing.T) {
+		fresh := NewWorktreeManager(dir)
+		fresh.setFresh(true)
+		fresh.now = func() time.Time { return time.Now().Add(time.Hour) }
+		if err := fresh.createWorktree("main"); err != nil {
+			t.Fatalf("createWorktree failed: %v", err)
+		}
+		t.Cleanup(func() { os.RemoveAll(fresh.getWorktreePath()) })
+		if fresh.wasReused() {
+			t.Fatal("fresh mode must not reuse")
+		}
+		if fresh.getWorktreePath() == firstPath {
+			t.Fatal("fresh mode returned the existing path")
+		}
+	})
+
+	t.Run("worktrees for other branches are ignored", func(t *testing.T) {
+		runGit(t, dir, "branch", "other")
+		wm := NewWorktreeManager(dir)
+		path, _, err := wm.findExistingWorktree("other")
+		if err != nil {
+			t.Fatalf("findExistingWorktree failed: %v", err)
+		}
+		if path != "" {
+			t.Fatalf("expected no reusable worktree for other branch, got %s", path)
+		}
+	})
+
+	t.Run("stale worktree directories are skipped", func(t *testing.T) {
+		repo := initTempRepo(t)
+		wm := NewWorktreeManager(repo)
+		if err := wm.createWorktree("main"); err != nil {
+			t.Fatalf("createWorktree failed: %v", err)
+		}
+		os.RemoveAll(wm.getWorktreePath())
+
+		again := NewWorktreeManager(repo)
+		path, _, err := again.findExistingWorktree("main")
+		if err != nil {
+			t.Fatalf("findExistingWorktree failed: %v", err)
+		}
+		if path != "" {
+			t.Fatalf("stale worktree should not be reused, got %s", path)
+		}
+	})
+}
+
+func TestWorktreeFreshFlagParsing(t *testing.T) {
+	result := parseArguments([]string{"--wk-fresh", "--env", "prod", "chat"})
+	if !result.WorktreeEnabled || !result.WorktreeFresh {
+		t.Fatalf("expected --wk-fresh to enable fresh worktree mode: %+v", result)
+	}
+	if len(result.ClaudeArgs) != 1 || result.ClaudeArgs[0] != "chat" {
+		t.Fatalf("--wk-fresh leaked into claude args: %v", result.ClaudeArgs)
+	}
+}
+
+func TestRenderWorktreeSummaryReused(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	caps := terminalCapabilities{SupportsANSI: false, IsTerminal: true}
+	if err := renderWorktreeSummaryWithStatus(&stdout, &stderr, "/tmp/wt", "warning: uncommitted changes detected in working tree", caps, false, true); err != nil {
+		t.Fatalf("renderWorktreeSummaryWithStatus failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Reusing worktree: /tmp/wt") {
+		t.Errorf("expected reuse headline, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Warning: uncommitted changes") {
+		t.Errorf("dirty warning should be shown for reused worktrees too, got %q", stderr.String())
+	}
+
+	stdout.Reset()
+	if err := renderWorktreeSummaryWithStatus(&stdout, &stderr, "/tmp/wt", "", caps, true, true); err != nil {
+		t.Fatalf("renderWorktreeSummaryWithStatus failed: %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "Worktree (reused): /tmp/wt") {
+		t.Errorf("expected headless reuse label, got %q", stdout.String())
+	}
+}
+
+func TestWorktreeBasePathAndName(t *testing.T) {
+	dir := initTempRepo(t)
+	base := filepath.Join(t.TempDir(), "worktrees")
+
+	wm := NewWorktreeManager(dir)
+	wm.setBasePath(base)
+	wm.setNameSuffix("login-fix")
+	if err := wm.createWorktree("main"); err != nil {
+		t.Fatalf("createWorktree failed: %v", err)
+	}
+	want := filepath.Join(base, sanitizeBranchName(filepath.Base(wm.repoPath))+"-main-login-fix")
+	if wm.getWorktreePath() != want || wm.worktreeName != filepath.Base(want) {
+		t.Fatalf("worktree = %s (%s), want %s", wm.getWorktreePath(), wm.worktreeName, want)
+	}
+
+	t.Run("same name conflicts instead of reusing", func(t *testing.T) {
+		again := NewWorktreeManager(dir)
+		again.setBasePath(base)
+		again.setNameSuffix("login-fix")
+		err := again.createWorktree("main")
+		if err == nil || !strings.Contains(err.Error(), "worktree conflict") || !strings.Contains(err.Error(), "--wk-name") {
+			t.Fatalf("expected a conflict with suggestions, got %v", err)
+		}
+	})
+
+	t.Run("path inside .git is rejected", func(t *testing.T) {
+		inside := NewWorktreeManager(dir)
+		inside.setBasePath(filepath.Join(dir, ".git", "wt"))
+		inside.setNameSuffix("x")
+		err := inside.createWorktree("main")
+		if err == nil || !strings.Contains(err.Error(), ".git directory") {
+			t.Fatalf("expected a .git path error, got %v", err)
+		}
+	})
+}
+
+func TestWorktreePathFlags(t *testing.T) {
+	result := parseArguments([]string{"--wk-path", "~/wt", "--env", "prod", "--wk-name", "fix", "chat"})
+	if result.Error != nil {
+		t.Fatalf("parse failed: %v", result.Error)
+	}
+	if !result.WorktreeEnabled || result.CCEFlags["wk_path"] != "~/wt" || result.CCEFlags["wk_name"] != "fix" {
+		t.Fatalf("unexpected parse: %+v", result)
+	}
+	if len(result.ClaudeArgs) != 1 || result.ClaudeArgs[0] != "chat" {
+		t.Fatalf("worktree flags leaked into claude args: %v", result.ClaudeArgs)
+	}
+
+	if err := resolveWorktreeOptions(&launchOptions{WorktreeName: "fix/login"}); err == nil || !strings.Contains(err.Error(), "fix-login") {
+		t.Errorf("expected an invalid --wk-name error, got %v", err)
+	}
+	opts := launchOptions{WorktreePath: "wt"}
+	if err := resolveWorktreeOptions(&opts); err != nil || !filepath.IsAbs(opts.WorktreePath) {
+		t.Errorf("expected an absolute --wk-path, got %q (%v)", opts.WorktreePath, err)
+	}
+
+	defer applyWorktreeSettings(nil)
+	if err := applyWorktreeSettings(&ConfigSettings{Worktree: &WorktreeSettings{BasePath: "relative"}}); err == nil {
+		t.Error("expected a relative base_path to be rejected")
+	}
+	if err := applyWorktreeSettings(&ConfigSettings{Worktree: &WorktreeSettings{BasePath: "/srv/worktrees"}}); err != nil || worktreeBasePath != "/srv/worktrees" {
+		t.Errorf("base_path not applied: %q (%v)", worktreeBasePath, err)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorktreeCleanup(t *testing.T) {
+	t.Run("clean worktree is removed", func(t *testing.T) {
+		wm := NewWorktreeManager(initTempRepo(t))
+		if err := wm.createWorktree("main"); err != nil {
+			t.Fatalf("createWorktree failed: %v", err)
+		}
+		path, err := wm.cleanupWorktree(false)
+		if err != nil || path != wm.getWorktreePath() {
+			t.Fatalf("cleanupWorktree() = %q, %v", path, err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be gone, stat err %v", path, err)
+		}
+	})
+
+	t.Run("dirty worktree is kept unless forced", func(t *testing.T) {
+		wm := NewWorktreeManager(initTempRepo(t))
+		if err := wm.createWorktree("main"); err != nil {
+			t.Fatalf("createWorktree failed: %v", err)
+		}
+		path := wm.getWorktreePath()
+		t.Cleanup(func() { os.RemoveAll(path) })
+		if err := ioutil.WriteFile(filepath.Join(path, "notes.txt"), []byte("wip"), 0644); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+
+		if _, err := wm.cleanupWorktree(false); err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+			t.Fatalf("expected an uncommitted changes error, got %v", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("dirty worktree should be kept: %v", err)
+		}
+		if removed, err := wm.cleanupWorktree(true); err != nil || removed != path {
+			t.Fatalf("forced cleanupWorktree() = %q, %v", removed, err)
+		}
+	})
+
+	t.Run("reused worktree is untouched", func(t *testing.T) {
+		repo := initTempRepo(t)
+		first := NewWorktreeManager(repo)
+		first.now = func() time.Time { return time.Now().Add(-time.Hour) }
+		if err := first.createWorktree("main"); err != nil {
+			t.Fatalf("createWorktree failed: %v", err)
+		}
+		t.Cleanup(func() { os.RemoveAll(first.getWorktreePath()) })
+
+		second := NewWorktreeManager(repo)
+		if err := second.createWorktree("main"); err != nil || !second.wasReused() {
+			t.Fatalf("expected the worktree to be reused (err %v)", err)
+		}
+		if path, err := second.cleanupWorktree(true); err != nil || path != "" {
+			t.Fatalf("reused worktree must not be removed, got %q, %v", path, err)
+		}
+		if _, err := os.Stat(first.getWorktreePath()); err != nil {
+			t.Fatalf("reused worktree disappeared: %v", err)
+		}
+	})
+}
+
+func TestFinishWorktreeCleanupKeepsLaunchResult(t *testing.T) {
+	wm := NewWorktreeManager(initTempRepo(t))
+	if err := wm.createWorktree("main"); err != nil {
+		t.Fatalf("createWorktree failed: %v", err)
+	}
+	launchErr := &claudeExitError{code: 3}
+
+	_, stderr, err := captureStdoutAndStderr(t, func() error {
+		return finishWorktreeCleanup(wm, false, launchErr)
+	})
+	if !errors.Is(err, launchErr) || exitCodeFor(err) != 3 {
+		t.Fatalf("expected claude's exit code to survive cleanup, got %v", err)
+	}
+	if !strings.Contains(stderr, "Removed worktree: "+wm.worktreePath) {
+		t.Errorf("expected the removed path on stderr, got %q", stderr)
+	}
+}
+
+func TestWorktreeCleanupFlags(t *testing.T) {
+	result := parseArguments([]string{"--wk-cleanup", "--force", "--env", "prod", "chat"})
+	if result.Error != nil {
+		t.Fatalf("parse failed: %v", result.Error)
+	}
+	if !result.WorktreeEnabled || result.CCEFlags["wk_cleanup"] != "true" || result.CCEFlags["force"] != "true" {
+		t.Fatalf("expected --wk-cleanup --force to be recorded: %+v", result)
+	}
+	if strings.Join(result.ClaudeArgs, " ") != "chat" {
+		t.Fatalf("cleanup flags leaked into claude args: %v", result.ClaudeArgs)
+	}
+
+	// After the first claude argument the same names are claude's and pass through
+	result = parseArguments([]string{"--env", "prod", "chat", "--force", "--wk-cleanup"})
+	if strings.Join(result.ClaudeArgs, " ") != "chat --force --wk-cleanup" || result.CCEFlags["force"] != "" {
+		t.Fatalf("claude's flags after its arguments were consumed: %v", result.ClaudeArgs)
+	}
+
+	for _, opts := range []launchOptions{
+		{WorktreeForce: true},
+		{WorktreeCleanup: true, Detach: true},
+		{WorktreeCleanup: true, LogFile: "run.log"},
+	} {
+		if err := validateWorktreeCleanup(opts); err == nil {
+			t.Errorf("%+v: expected a validation error", opts)
+		}
+	}
+	if err := validateWorktreeCleanup(launchOptions{WorktreeCleanup: true, WorktreeForce: true}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}